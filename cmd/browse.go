@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+var (
+	browseAPIURL    string
+	browseOffline   bool
+	browseOutputDir string
+)
+
+var browseCmd = &cobra.Command{
+	Use:   "browse",
+	Short: "Browse the template catalog in a full-screen TUI",
+	Long:  `Lists the claim templates with a detail pane for the highlighted template. Press / to filter by name, title or tag and enter to render the highlighted template with the interactive parameter forms. Requires a terminal.`,
+	Args:  cobra.NoArgs,
+	Run:   runBrowse,
+}
+
+func init() {
+	browseCmd.Flags().StringVarP(&browseAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	browseCmd.Flags().BoolVar(&browseOffline, "offline", false, "Browse the cached template catalog instead of fetching it from the API")
+	browseCmd.Flags().StringVarP(&browseOutputDir, "output-dir", "o", ".", "Default output directory when rendering the selected template")
+
+	rootCmd.AddCommand(browseCmd)
+}
+
+func runBrowse(cmd *cobra.Command, args []string) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		fmt.Println(renderError("claims browse needs an interactive terminal; use 'claims render --non-interactive' in scripts"))
+		os.Exit(1)
+	}
+
+	browseAPIURL, _ = resolveAPIURL(browseAPIURL)
+	config := &RenderConfig{
+		APIUrl:          splitAPIURLs(browseAPIURL)[0],
+		APIToken:        resolveAPIToken(""),
+		Offline:         browseOffline,
+		CacheTTL:        templates.DefaultCatalogTTL,
+		OutputDir:       browseOutputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		FileMode:        "overwrite",
+		Parallel:        1,
+		OutputOrder:     OutputOrderInput,
+		Interactive:     true,
+	}
+	config.APIUrls = []string{config.APIUrl}
+
+	if err := runBrowseSession(context.Background(), config); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+}
+
+// runBrowseSession shows the catalog browser and, if a template was chosen,
+// hands off to the interactive render flow for it
+func runBrowseSession(ctx context.Context, config *RenderConfig) error {
+	client, err := newRenderClient(config)
+	if err != nil {
+		return err
+	}
+
+	fetchCtx, stop := signalContext(ctx)
+	available, err := loadTemplateCatalog(fetchCtx, client, config)
+	stop()
+	if err != nil {
+		return fmt.Errorf("failed to fetch templates: %w", err)
+	}
+	if len(available) == 0 {
+		fmt.Println("No templates available.")
+		return nil
+	}
+
+	final, err := tea.NewProgram(newBrowseModel(available), tea.WithAltScreen()).Run()
+	if err != nil {
+		return fmt.Errorf("running browser: %w", err)
+	}
+
+	selected := final.(browseModel).chosen
+	if selected == "" {
+		return nil
+	}
+
+	// The interactive render collects the parameters via collectTemplateParams
+	fmt.Printf("Rendering %s from %s\n", selected, config.APIUrl)
+	config.Templates = []string{selected}
+	return runInteractiveRender(ctx, client, config)
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func browseTestTemplates() []templates.ClaimTemplate {
+	vm := testTemplate("vsphere-vm")
+	vm.Metadata.Title = "vSphere VM"
+	vm.Metadata.Tags = []string{"compute", "vsphere"}
+	db := testTemplate("postgresql")
+	db.Metadata.Tags = []string{"database"}
+	vol := testTemplate("volumeclaim")
+	vol.Metadata.Tags = []string{"storage"}
+	return []templates.ClaimTemplate{vm, db, vol}
+}
+
+// browseKeys feeds key presses to the model, typing runes literally
+func browseKeys(m browseModel, keys ...string) browseModel {
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(browseModel)
+	}
+	return m
+}
+
+func TestMatchesBrowseFilter(t *testing.T) {
+	vm := browseTestTemplates()[0]
+
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		{"", true},
+		{"vsphere-vm", true},
+		{"VSPHERE", true},
+		{"sphere v", true},
+		{"compute", true},
+		{"database", false},
+	}
+	for _, tt := range tests {
+		if got := matchesBrowseFilter(vm, tt.filter); got != tt.want {
+			t.Errorf("matchesBrowseFilter(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestBrowseModel_Update(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantVisible int
+		wantCurrent string
+		wantChosen  string
+	}{
+		{
+			name:        "move down and choose",
+			keys:        []string{"down", "enter"},
+			wantVisible: 3,
+			wantCurrent: "postgresql",
+			wantChosen:  "postgresql",
+		},
+		{
+			name:        "cursor stops at the last template",
+			keys:        []string{"j", "j", "j", "j"},
+			wantVisible: 3,
+			wantCurrent: "volumeclaim",
+		},
+		{
+			name:        "filter by tag",
+			keys:        []string{"/", "s", "t", "o", "enter"},
+			wantVisible: 1,
+			wantCurrent: "volumeclaim",
+		},
+		{
+			name:        "keys are typed into the filter while filtering",
+			keys:        []string{"/", "q", "j"},
+			wantVisible: 0,
+		},
+		{
+			name:        "backspace widens the filter",
+			keys:        []string{"/", "s", "q", "backspace"},
+			wantVisible: 3,
+			wantCurrent: "vsphere-vm",
+		},
+		{
+			name:        "esc clears the filter",
+			keys:        []string{"/", "d", "a", "t", "a", "esc"},
+			wantVisible: 3,
+			wantCurrent: "vsphere-vm",
+		},
+		{
+			name:        "cursor is clamped when the filter narrows",
+			keys:        []string{"down", "down", "/", "v", "s", "enter", "r"},
+			wantVisible: 1,
+			wantCurrent: "vsphere-vm",
+			wantChosen:  "vsphere-vm",
+		},
+		{
+			name:        "nothing chosen with no match",
+			keys:        []string{"/", "x", "y", "z", "enter", "enter"},
+			wantVisible: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := browseKeys(newBrowseModel(browseTestTemplates()), tt.keys...)
+
+			if len(m.visible) != tt.wantVisible {
+				t.Errorf("visible = %d, want %d", len(m.visible), tt.wantVisible)
+			}
+			current := ""
+			if c := m.current(); c != nil {
+				current = c.Metadata.Name
+			}
+			if current != tt.wantCurrent {
+				t.Errorf("current = %q, want %q", current, tt.wantCurrent)
+			}
+			if m.chosen != tt.wantChosen {
+				t.Errorf("chosen = %q, want %q", m.chosen, tt.wantChosen)
+			}
+		})
+	}
+}
+
+func TestBrowseDetail(t *testing.T) {
+	tmpl := browseTestTemplates()[0]
+	tmpl.Metadata.Description = "A virtual machine"
+	tmpl.Spec.Parameters = append(tmpl.Spec.Parameters,
+		templates.Parameter{Name: "cpu", Required: true, Description: "Number of CPUs"},
+		templates.Parameter{Name: "internal", Hidden: true},
+	)
+	tmpl.Spec.Secrets = []templates.SecretTemplate{{Name: "vm-credentials"}}
+
+	detail := browseDetail(&tmpl)
+	for _, want := range []string{"A virtual machine", "Tags:    compute, vsphere", "name = default-name", "cpu (required)", "Number of CPUs", "Secrets: vm-credentials"} {
+		if !strings.Contains(detail, want) {
+			t.Errorf("detail missing %q:\n%s", want, detail)
+		}
+	}
+	if strings.Contains(detail, "internal") {
+		t.Errorf("detail shows hidden parameter:\n%s", detail)
+	}
+}
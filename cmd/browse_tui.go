@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// Styles for the catalog browser
+var (
+	browsePaneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1)
+
+	browseSelectedStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("205"))
+
+	browseDimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241"))
+)
+
+const (
+	// browseListWidth is the width of the template list pane
+	browseListWidth = 32
+	// browseChromeLines is reserved for pane borders, the filter and help lines
+	browseChromeLines = 5
+)
+
+// browseModel is the bubbletea model behind 'claims browse'
+type browseModel struct {
+	items     []templates.ClaimTemplate
+	visible   []int // indices into items matching the filter
+	cursor    int   // position in visible
+	filter    string
+	filtering bool
+	width     int
+	height    int
+	chosen    string // template to render, set when the user presses enter
+}
+
+func newBrowseModel(items []templates.ClaimTemplate) browseModel {
+	m := browseModel{items: items}
+	m.applyFilter()
+	return m
+}
+
+// matchesBrowseFilter reports whether a template's name, title or one of its
+// tags contains the filter, ignoring case
+func matchesBrowseFilter(t templates.ClaimTemplate, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	filter = strings.ToLower(filter)
+	if strings.Contains(strings.ToLower(t.Metadata.Name), filter) ||
+		strings.Contains(strings.ToLower(t.Metadata.Title), filter) {
+		return true
+	}
+	for _, tag := range t.Metadata.Tags {
+		if strings.Contains(strings.ToLower(tag), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFilter recomputes the visible templates and keeps the cursor in range
+func (m *browseModel) applyFilter() {
+	m.visible = nil
+	for i, t := range m.items {
+		if matchesBrowseFilter(t, m.filter) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.visible)-1, 0))
+}
+
+// current returns the highlighted template, or nil if nothing matches
+func (m browseModel) current() *templates.ClaimTemplate {
+	if len(m.visible) == 0 {
+		return nil
+	}
+	return &m.items[m.visible[m.cursor]]
+}
+
+func (m browseModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.filtering {
+			return m.updateFilter(msg), nil
+		}
+
+		switch msg.String() {
+		case "q", "esc":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+		case "/":
+			m.filtering = true
+		case "enter", "r":
+			if t := m.current(); t != nil {
+				m.chosen = t.Metadata.Name
+				return m, tea.Quit
+			}
+		}
+	}
+	return m, nil
+}
+
+// updateFilter edits the filter while in filter mode; enter keeps it, esc clears it
+func (m browseModel) updateFilter(msg tea.KeyMsg) browseModel {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+	case tea.KeyBackspace:
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+	}
+	m.applyFilter()
+	return m
+}
+
+func (m browseModel) View() string {
+	height := max(m.height-browseChromeLines, 5)
+
+	// Template list, scrolled to keep the cursor visible
+	var list strings.Builder
+	start := max(m.cursor-height+1, 0)
+	for i := start; i < len(m.visible) && i < start+height; i++ {
+		name := m.items[m.visible[i]].Metadata.Name
+		if i == m.cursor {
+			list.WriteString(browseSelectedStyle.Render("> " + name))
+		} else {
+			list.WriteString("  " + name)
+		}
+		list.WriteString("\n")
+	}
+	if len(m.visible) == 0 {
+		list.WriteString(browseDimStyle.Render("no matching templates"))
+	}
+
+	detailWidth := max(m.width-browseListWidth-8, 30)
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		browsePaneStyle.Width(browseListWidth).Height(height).Render(strings.TrimRight(list.String(), "\n")),
+		browsePaneStyle.Width(detailWidth).Height(height).Render(browseDetail(m.current())),
+	)
+
+	filterLine := fmt.Sprintf("%d/%d templates", len(m.visible), len(m.items))
+	if m.filtering || m.filter != "" {
+		filterLine = fmt.Sprintf("Filter: %s", m.filter)
+		if m.filtering {
+			filterLine += "█"
+		}
+	}
+	help := "↑/↓ move • / filter • enter render • q quit"
+	if m.filtering {
+		help = "type to filter by name, title or tag • enter keep • esc clear"
+	}
+
+	return filterLine + "\n" + panes + "\n" + browseDimStyle.Render(help)
+}
+
+// browseDetail describes a template for the detail pane
+func browseDetail(t *templates.ClaimTemplate) string {
+	if t == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	title := t.Metadata.Title
+	if title == "" {
+		title = t.Metadata.Name
+	}
+	sb.WriteString(resourceHeaderStyle.Render(title) + "\n")
+	if t.Metadata.Description != "" {
+		sb.WriteString(t.Metadata.Description + "\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("Name:    %s\n", t.Metadata.Name))
+	sb.WriteString(fmt.Sprintf("Type:    %s\n", t.Spec.Type))
+	if t.Spec.Tag != "" {
+		sb.WriteString(fmt.Sprintf("Version: %s\n", t.Spec.Tag))
+	}
+	if len(t.Metadata.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("Tags:    %s\n", strings.Join(t.Metadata.Tags, ", ")))
+	}
+	if t.Metadata.Profile != "" {
+		sb.WriteString(fmt.Sprintf("Profile: %s\n", t.Metadata.Profile))
+	}
+
+	sb.WriteString("\nParameters:\n")
+	shown := 0
+	for _, p := range t.Spec.Parameters {
+		if p.Hidden {
+			continue
+		}
+		shown++
+		line := "  " + p.Name
+		if p.Required {
+			line += " (required)"
+		}
+		if p.Default != nil {
+			line += fmt.Sprintf(" = %v", p.Default)
+		}
+		sb.WriteString(line + "\n")
+		if p.Description != "" {
+			sb.WriteString(browseDimStyle.Render("    "+p.Description) + "\n")
+		}
+	}
+	if shown == 0 {
+		sb.WriteString("  none\n")
+	}
+
+	if len(t.Spec.Secrets) > 0 {
+		names := make([]string, len(t.Spec.Secrets))
+		for i, s := range t.Spec.Secrets {
+			names[i] = s.Name
+		}
+		sb.WriteString(fmt.Sprintf("\nSecrets: %s\n", strings.Join(names, ", ")))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
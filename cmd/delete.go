@@ -6,7 +6,6 @@ import (
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"github.com/stuttgart-things/claims/internal/banner"
 )
 
 var (
@@ -15,6 +14,7 @@ var (
 	deleteRepoURL      string
 	deleteRegistryPath string
 	deleteDryRun       bool
+	deleteNoSort       bool
 
 	// Git flags for delete (reuse same env vars)
 	deleteGitBranch       string
@@ -23,13 +23,17 @@ var (
 	deleteGitRemote       string
 	deleteGitUser         string
 	deleteGitToken        string
+	deleteGitSSHKey       string
+	deleteGitGPGSign      bool
+	deleteGitGPGKey       string
 
 	// PR flags for delete
-	deleteCreatePR      bool
-	deletePRTitle       string
-	deletePRDescription string
-	deletePRLabels      []string
-	deletePRBase        string
+	deleteCreatePR            bool
+	deletePRTitle             string
+	deletePRDescription       string
+	deletePRLabels            []string
+	deletePRBase              string
+	deleteCreateMissingLabels bool
 
 	// Mode flags for delete
 	deleteInteractive    bool
@@ -47,16 +51,20 @@ func init() {
 	deleteCmd.Flags().StringVar(&deleteResourceName, "resource-name", "", "Name of the claim resource to delete")
 	deleteCmd.Flags().StringVar(&deleteCategory, "category", "", "Category of the claim (e.g., infra, apps)")
 	deleteCmd.Flags().StringVar(&deleteRepoURL, "git-repo-url", "", "Clone from URL instead of using local repo")
-	deleteCmd.Flags().StringVar(&deleteRegistryPath, "registry-path", "claims/registry.yaml", "Path to registry.yaml within the repo")
+	deleteCmd.Flags().StringVar(&deleteRegistryPath, "registry-path", "", "Path to registry.yaml within the repo (default: discovered at claims/registry.yaml, registry.yaml or .claims/registry.yaml)")
 	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "Show what would be deleted without making changes")
+	deleteCmd.Flags().BoolVar(&deleteNoSort, "no-sort", false, "Keep the remaining kustomization.yaml resources in their order instead of sorting them")
 
 	// Git flags
 	deleteCmd.Flags().StringVar(&deleteGitBranch, "git-branch", "", "Branch to use/create")
 	deleteCmd.Flags().BoolVar(&deleteGitCreateBranch, "git-create-branch", false, "Create the branch if it doesn't exist")
 	deleteCmd.Flags().StringVar(&deleteGitMessage, "git-message", "", "Commit message (default: auto-generated)")
-	deleteCmd.Flags().StringVar(&deleteGitRemote, "git-remote", "origin", "Git remote name")
+	deleteCmd.Flags().StringVar(&deleteGitRemote, "git-remote", "", "Git remote name (default: the only remote; with several, asked for interactively)")
 	deleteCmd.Flags().StringVar(&deleteGitUser, "git-user", "", "Git username (or GIT_USER/GITHUB_USER env)")
 	deleteCmd.Flags().StringVar(&deleteGitToken, "git-token", "", "Git token (or GIT_TOKEN/GITHUB_TOKEN env)")
+	deleteCmd.Flags().StringVar(&deleteGitSSHKey, "ssh-key", "", "Private key for SSH remotes (or GIT_SSH_KEY env, default ~/.ssh/id_ed25519; passphrase from GIT_SSH_KEY_PASSPHRASE)")
+	deleteCmd.Flags().BoolVar(&deleteGitGPGSign, "gpg-sign", false, "GPG-sign the commit with the key from --gpg-key or GIT_SIGNING_KEY; fails if there is none")
+	deleteCmd.Flags().StringVar(&deleteGitGPGKey, "gpg-key", "", "Key to sign the commit with: armored private key, its file or a gpg key ID (or GIT_SIGNING_KEY env; passphrase from GIT_SIGNING_KEY_PASSPHRASE); implies --gpg-sign")
 
 	// PR flags
 	deleteCmd.Flags().BoolVar(&deleteCreatePR, "create-pr", false, "Create a pull request after push")
@@ -64,16 +72,25 @@ func init() {
 	deleteCmd.Flags().StringVar(&deletePRDescription, "pr-description", "", "PR description")
 	deleteCmd.Flags().StringSliceVar(&deletePRLabels, "pr-labels", nil, "PR labels (comma-separated)")
 	deleteCmd.Flags().StringVar(&deletePRBase, "pr-base", "main", "Base branch for PR")
+	deleteCmd.Flags().BoolVar(&deleteCreateMissingLabels, "create-missing-labels", false, "Create PR labels that don't exist in the repository (default: skip them with a warning)")
 
 	// Mode flags
 	deleteCmd.Flags().BoolVarP(&deleteInteractive, "interactive", "i", false, "Force interactive mode")
 	deleteCmd.Flags().BoolVar(&deleteNonInteractive, "non-interactive", false, "Force non-interactive mode")
 
+	addWebhookFlags(deleteCmd)
+
 	rootCmd.AddCommand(deleteCmd)
 }
 
 func runDelete(cmd *cobra.Command, args []string) {
-	banner.Show()
+	showBanner()
+
+	webhook, err := newWebhook()
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
 
 	config := &DeleteConfig{
 		ResourceName: deleteResourceName,
@@ -81,13 +98,16 @@ func runDelete(cmd *cobra.Command, args []string) {
 		RepoURL:      deleteRepoURL,
 		RegistryPath: deleteRegistryPath,
 		DryRun:       deleteDryRun,
+		NoSort:       deleteNoSort,
 	}
 
-	// Build git config
-	if deleteGitBranch != "" || deleteRepoURL != "" || deleteCreatePR {
+	// Build git config. Only a branch, repo URL or PR commits on their own;
+	// the other flags refine the commit set up in the interactive form.
+	deleteGitTarget := deleteGitBranch != "" || deleteRepoURL != "" || deleteCreatePR
+	if deleteGitTarget || deleteGitRemote != "" || deleteGitSSHKey != "" || deleteGitGPGSign || deleteGitGPGKey != "" {
 		config.GitConfig = &GitConfig{
-			Commit:       true,
-			Push:         true,
+			Commit:       deleteGitTarget,
+			Push:         deleteGitTarget,
 			CreateBranch: deleteGitCreateBranch,
 			Message:      deleteGitMessage,
 			Branch:       deleteGitBranch,
@@ -95,17 +115,21 @@ func runDelete(cmd *cobra.Command, args []string) {
 			RepoURL:      deleteRepoURL,
 			User:         deleteGitUser,
 			Token:        deleteGitToken,
+			SSHKey:       deleteGitSSHKey,
+			GPGSign:      deleteGitGPGSign,
+			GPGKey:       deleteGitGPGKey,
 		}
 	}
 
 	// Build PR config
 	if deleteCreatePR || deletePRTitle != "" || deletePRDescription != "" || len(deletePRLabels) > 0 {
 		config.PRConfig = &PRConfig{
-			Create:      deleteCreatePR,
-			Title:       deletePRTitle,
-			Description: deletePRDescription,
-			Labels:      deletePRLabels,
-			BaseBranch:  deletePRBase,
+			Create:              deleteCreatePR,
+			Title:               deletePRTitle,
+			Description:         deletePRDescription,
+			Labels:              deletePRLabels,
+			BaseBranch:          deletePRBase,
+			CreateMissingLabels: deleteCreateMissingLabels,
 		}
 	}
 
@@ -118,15 +142,18 @@ func runDelete(cmd *cobra.Command, args []string) {
 		config.Interactive = isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
 	}
 
-	var err error
 	if config.Interactive {
 		err = runDeleteInteractive(config)
 	} else {
 		err = runDeleteNonInteractive(config)
 	}
 
+	if werr := notifyWebhook(webhook, "delete", err); err == nil {
+		err = werr
+	}
+
 	if err != nil {
-		fmt.Println(errorStyle.Render(err.Error()))
+		fmt.Println(renderError(err.Error()))
 		os.Exit(1)
 	}
 }
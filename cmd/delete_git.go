@@ -10,23 +10,11 @@ import (
 
 // executeDeleteGitOperations performs git commit, push, and PR creation for a delete operation
 func executeDeleteGitOperations(result *DeleteResult, config *DeleteConfig, repoRoot string) error {
-	if config.GitConfig == nil {
+	if config.GitConfig == nil || !config.GitConfig.Commit {
 		return nil
 	}
 
-	// Resolve credentials
-	user, token := config.GitConfig.User, config.GitConfig.Token
-	if config.GitConfig.Push {
-		var err error
-		user, token, err = gitops.ResolveCredentials(user, token)
-		if err != nil {
-			return err
-		}
-	} else {
-		user, token = gitops.ResolveCredentialsOptional(user, token)
-	}
-
-	g, err := gitops.New(repoRoot, user, token)
+	g, err := openGitRepo(repoRoot, config.GitConfig, config.GitConfig.Push, config.Interactive)
 	if err != nil {
 		return err
 	}
@@ -52,21 +40,22 @@ func executeDeleteGitOperations(result *DeleteResult, config *DeleteConfig, repo
 	// Stage modified files (kustomization.yaml and registry.yaml)
 	var filesToAdd []string
 
-	kustomizationPath := filepath.Join(repoRoot, "claims", result.Category, "kustomization.yaml")
+	kustomizationPath := filepath.Join(repoRoot, result.kustomizationPath())
 	filesToAdd = append(filesToAdd, kustomizationPath)
 
-	registryPath := filepath.Join(repoRoot, config.RegistryPath)
-	filesToAdd = append(filesToAdd, registryPath)
+	if !registryReadOnly() {
+		registryPath := filepath.Join(repoRoot, config.RegistryPath)
+		filesToAdd = append(filesToAdd, registryPath)
+	}
 
 	fmt.Println("Staging changes...")
 	if err := g.AddFiles(filesToAdd); err != nil {
 		return err
 	}
 
-	// Stage the removed directory
-	// go-git's worktree.Add with the removed dir path stages the deletion
-	removedDir := filepath.Join(repoRoot, "claims", result.Category, result.ResourceName)
-	relRemoved, _ := filepath.Rel(repoRoot, removedDir)
+	// Stage the removed directory or file
+	// go-git's worktree.Add with the removed path stages the deletion
+	relRemoved := result.Path
 	worktree, err := g.GetRepo().Worktree()
 	if err != nil {
 		return fmt.Errorf("getting worktree: %w", err)
@@ -75,9 +64,9 @@ func executeDeleteGitOperations(result *DeleteResult, config *DeleteConfig, repo
 	if _, err := worktree.Add(relRemoved); err != nil {
 		// The directory is already removed, so we use status-based approach
 		// Stage the parent directory to pick up deletions
-		parentRel := filepath.Join("claims", result.Category)
+		parentRel := filepath.Dir(result.Path)
 		if err := worktree.AddGlob(parentRel + "/*"); err != nil {
-			fmt.Printf("Warning: could not stage removed files: %v\n", err)
+			warnf("could not stage removed files: %v", err)
 		}
 	}
 
@@ -87,19 +76,19 @@ func executeDeleteGitOperations(result *DeleteResult, config *DeleteConfig, repo
 		message = fmt.Sprintf("Delete claim: %s", result.ResourceName)
 	}
 
-	// Commit
+	// Commit, authored by the git user if one is set
+	user, _ := gitops.ResolveCredentialsOptional(config.GitConfig.User, config.GitConfig.Token)
 	fmt.Printf("Committing: %s\n", message)
-	if err := g.Commit(message, user, ""); err != nil {
+	hash, err := g.CommitWithHash(message, user, "")
+	if err != nil {
 		return err
 	}
+	outcome.setCommit(hash)
 	fmt.Println(successStyle.Render("Committed successfully"))
 
 	// Push
 	if config.GitConfig.Push {
 		remote := config.GitConfig.Remote
-		if remote == "" {
-			remote = "origin"
-		}
 
 		fmt.Printf("Pushing to %s...\n", remote)
 		if err := g.Push(remote, branchName); err != nil {
@@ -131,7 +120,7 @@ func executeDeletePRCreation(result *DeleteResult, config *DeleteConfig, repoPat
 
 	description := config.PRConfig.Description
 	if description == "" {
-		description = generateDeletePRDescription(result)
+		description = generateDeletePRDescription(result, config.RegistryPath)
 	}
 
 	baseBranch := config.PRConfig.BaseBranch
@@ -142,7 +131,7 @@ func executeDeletePRCreation(result *DeleteResult, config *DeleteConfig, repoPat
 	prConfig := gitops.PRConfig{
 		Title:       title,
 		Description: description,
-		Labels:      config.PRConfig.Labels,
+		Labels:      resolvePRLabels(config.PRConfig.Labels, config.PRConfig.CreateMissingLabels, repoPath),
 		BaseBranch:  baseBranch,
 		HeadBranch:  headBranch,
 	}
@@ -154,19 +143,27 @@ func executeDeletePRCreation(result *DeleteResult, config *DeleteConfig, repoPat
 	}
 
 	fmt.Println(successStyle.Render(fmt.Sprintf("Created PR: %s", pr.URL)))
+	outcome.setPR(pr.URL)
 	return nil
 }
 
-// generateDeletePRDescription creates a PR description for the delete operation
-func generateDeletePRDescription(result *DeleteResult) string {
+// generateDeletePRDescription creates a PR description for the delete
+// operation; registryPath is the registry updated, relative to the repo root
+func generateDeletePRDescription(result *DeleteResult, registryPath string) string {
 	var sb strings.Builder
 
 	sb.WriteString("## Summary\n\n")
 	sb.WriteString(fmt.Sprintf("Delete claim **%s** from category **%s**\n\n", result.ResourceName, result.Category))
 	sb.WriteString("## Changes\n\n")
-	sb.WriteString(fmt.Sprintf("- Removed directory: `%s`\n", result.Path))
-	sb.WriteString(fmt.Sprintf("- Updated `claims/%s/kustomization.yaml`\n", result.Category))
-	sb.WriteString("- Updated `claims/registry.yaml`\n")
+	if result.File {
+		sb.WriteString(fmt.Sprintf("- Removed file: `%s`\n", filepath.ToSlash(result.Path)))
+	} else {
+		sb.WriteString(fmt.Sprintf("- Removed directory: `%s`\n", filepath.ToSlash(result.Path)))
+	}
+	sb.WriteString(fmt.Sprintf("- Updated `%s`\n", filepath.ToSlash(result.kustomizationPath())))
+	if !registryReadOnly() {
+		sb.WriteString(fmt.Sprintf("- Updated `%s`\n", filepath.ToSlash(registryPath)))
+	}
 	sb.WriteString("\n---\n")
 	sb.WriteString("*Generated by claims CLI*\n")
 
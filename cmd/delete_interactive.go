@@ -17,6 +17,9 @@ func runDeleteInteractive(config *DeleteConfig) error {
 		return err
 	}
 
+	if err := resolveDeleteRegistryPath(config, repoRoot); err != nil {
+		return err
+	}
 	registryPath := filepath.Join(repoRoot, config.RegistryPath)
 
 	// Load registry
@@ -94,7 +97,7 @@ func runDeleteInteractive(config *DeleteConfig) error {
 	}
 
 	// Perform the deletion
-	result, err := performDelete(repoRoot, config.RegistryPath, entry.Name, entry.Category)
+	result, err := performDelete(repoRoot, config.RegistryPath, entry.Name, entry.Category, config.NoSort)
 	if err != nil {
 		return err
 	}
@@ -102,21 +105,25 @@ func runDeleteInteractive(config *DeleteConfig) error {
 	fmt.Println(successStyle.Render(fmt.Sprintf("\nDeleted claim: %s", result.ResourceName)))
 
 	// Ask about git operations if not already configured
-	if config.GitConfig == nil {
+	if config.GitConfig == nil || !config.GitConfig.Commit {
 		destChoice, err := runDeleteDestinationChoice()
 		if err != nil {
 			return fmt.Errorf("destination choice: %w", err)
 		}
 
 		if destChoice.useGit {
-			gitConfig, err := runGitDetailsForm(destChoice.createPR)
+			gitConfig, err := interactiveGitConfig(config.GitConfig, destChoice.createPR)
 			if err != nil {
 				return fmt.Errorf("git options: %w", err)
 			}
 			config.GitConfig = gitConfig
 
 			if destChoice.createPR {
-				prConfig, err := runPROptionsForm()
+				var existingLabels []string
+				if gitConfig.RepoURL == "" {
+					existingLabels = availablePRLabels(".")
+				}
+				prConfig, err := runPROptionsForm(existingLabels)
 				if err != nil {
 					return fmt.Errorf("PR options: %w", err)
 				}
@@ -21,6 +21,9 @@ func runDeleteNonInteractive(config *DeleteConfig) error {
 		return err
 	}
 
+	if err := resolveDeleteRegistryPath(config, repoRoot); err != nil {
+		return err
+	}
 	registryPath := filepath.Join(repoRoot, config.RegistryPath)
 
 	// Load registry
@@ -45,7 +48,7 @@ func runDeleteNonInteractive(config *DeleteConfig) error {
 		return printDeleteDryRun(config.ResourceName, category, entry.Path, repoRoot)
 	}
 
-	result, err := performDelete(repoRoot, config.RegistryPath, config.ResourceName, category)
+	result, err := performDelete(repoRoot, config.RegistryPath, config.ResourceName, category, config.NoSort)
 	if err != nil {
 		return err
 	}
@@ -83,48 +86,116 @@ func resolveRepoRoot(config *DeleteConfig) (string, error) {
 	return repoRoot, nil
 }
 
-// performDelete removes the claim directory, updates kustomization.yaml, and updates registry.yaml
-func performDelete(repoRoot, registryRelPath, resourceName, category string) (*DeleteResult, error) {
-	claimDir := filepath.Join(repoRoot, "claims", category, resourceName)
-	kustomizationPath := filepath.Join(repoRoot, "claims", category, "kustomization.yaml")
+// resolveDeleteRegistryPath sets config.RegistryPath to the registry
+// discovered in repoRoot when --registry-path wasn't given
+func resolveDeleteRegistryPath(config *DeleteConfig, repoRoot string) error {
+	if config.RegistryPath != "" {
+		return nil
+	}
+
+	path, err := registry.Discover(repoRoot)
+	if err != nil {
+		return fmt.Errorf("loading registry: %w", err)
+	}
+	rel, err := filepath.Rel(repoRoot, path)
+	if err != nil {
+		return err
+	}
+	config.RegistryPath = rel
+	return nil
+}
+
+// deleteTarget resolves what deleting a claim removes: the claim directory
+// claims/<category>/<name>, or, when there is none, the flat file the
+// registry entry points to (e.g. an encrypted secret written by 'claims
+// encrypt'). The returned path is relative to repoRoot.
+func deleteTarget(repoRoot, resourceName, category, entryPath string) (path string, isFile bool, err error) {
+	claimDir := filepath.Join("claims", category, resourceName)
+	if _, err := os.Stat(filepath.Join(repoRoot, claimDir)); err == nil {
+		return claimDir, false, nil
+	}
+	if entryPath != "" {
+		rel := filepath.FromSlash(entryPath)
+		if info, err := os.Stat(filepath.Join(repoRoot, rel)); err == nil && !info.IsDir() {
+			return rel, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("claim directory not found: %s", filepath.Join(repoRoot, claimDir))
+}
+
+// performDelete removes the claim directory (or the claim's flat file, see
+// deleteTarget), updates kustomization.yaml, sorting its resources unless
+// noSort is set, and updates registry.yaml unless it is read-only
+func performDelete(repoRoot, registryRelPath, resourceName, category string, noSort bool) (*DeleteResult, error) {
 	registryPath := filepath.Join(repoRoot, registryRelPath)
+	reg, regErr := registry.Load(registryPath)
+	entryPath := ""
+	if regErr == nil {
+		if entry := registry.FindEntry(reg, resourceName); entry != nil {
+			entryPath = entry.Path
+		}
+	}
 
-	// Verify claim directory exists
-	if _, err := os.Stat(claimDir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("claim directory not found: %s", claimDir)
+	target, isFile, err := deleteTarget(repoRoot, resourceName, category, entryPath)
+	if err != nil {
+		return nil, err
+	}
+	result := &DeleteResult{
+		ResourceName: resourceName,
+		Category:     category,
+		Path:         target,
+		File:         isFile,
 	}
 
-	// Remove claim directory
-	if err := os.RemoveAll(claimDir); err != nil {
-		return nil, fmt.Errorf("removing claim directory: %w", err)
+	// The kustomization next to a flat file lists it by file name; a claim
+	// directory is listed by the resource name
+	resource := resourceName
+	if isFile {
+		if err := os.Remove(filepath.Join(repoRoot, target)); err != nil {
+			return nil, fmt.Errorf("removing claim file: %w", err)
+		}
+		fmt.Printf("Removed file: %s\n", filepath.Join(repoRoot, target))
+		result.Kustomization = filepath.Join(filepath.Dir(target), "kustomization.yaml")
+		resource = filepath.Base(target)
+	} else {
+		if err := os.RemoveAll(filepath.Join(repoRoot, target)); err != nil {
+			return nil, fmt.Errorf("removing claim directory: %w", err)
+		}
+		fmt.Printf("Removed directory: %s\n", filepath.Join(repoRoot, target))
 	}
-	fmt.Printf("Removed directory: %s\n", claimDir)
 
 	// Update kustomization.yaml
+	kustomizationPath := filepath.Join(repoRoot, result.kustomizationPath())
 	if _, err := os.Stat(kustomizationPath); err == nil {
 		k, err := kustomize.Load(kustomizationPath)
 		if err != nil {
 			return nil, fmt.Errorf("loading kustomization: %w", err)
 		}
 
-		if err := kustomize.RemoveResource(k, resourceName); err != nil {
-			fmt.Printf("Warning: %v\n", err)
-		} else {
-			if err := kustomize.Save(kustomizationPath, k); err != nil {
-				return nil, fmt.Errorf("saving kustomization: %w", err)
+		// A flat file that isn't listed as a resource (e.g. an encrypted
+		// secret wired in through a KSOPS generator) leaves it unchanged
+		if !isFile || kustomize.HasResource(k, resource) {
+			if err := kustomize.RemoveResource(k, resource); err != nil {
+				warnf("%v", err)
+			} else {
+				if !noSort {
+					kustomize.SortResources(k)
+				}
+				if err := kustomize.Save(kustomizationPath, k); err != nil {
+					return nil, fmt.Errorf("saving kustomization: %w", err)
+				}
+				fmt.Printf("Updated kustomization: %s\n", kustomizationPath)
 			}
-			fmt.Printf("Updated kustomization: %s\n", kustomizationPath)
 		}
 	}
 
 	// Update registry.yaml
-	reg, err := registry.Load(registryPath)
-	if err != nil {
-		return nil, fmt.Errorf("loading registry: %w", err)
-	}
-
-	if err := registry.RemoveEntry(reg, resourceName); err != nil {
-		fmt.Printf("Warning: %v\n", err)
+	if registryReadOnly() {
+		printRegistryReadOnly()
+	} else if regErr != nil {
+		return nil, fmt.Errorf("loading registry: %w", regErr)
+	} else if err := registry.RemoveEntry(reg, resourceName); err != nil {
+		warnf("%v", err)
 	} else {
 		if err := registry.Save(registryPath, reg); err != nil {
 			return nil, fmt.Errorf("saving registry: %w", err)
@@ -132,20 +203,28 @@ func performDelete(repoRoot, registryRelPath, resourceName, category string) (*D
 		fmt.Printf("Updated registry: %s\n", registryPath)
 	}
 
-	return &DeleteResult{
-		ResourceName: resourceName,
-		Category:     category,
-		Path:         filepath.Join("claims", category, resourceName),
-	}, nil
+	outcome.addClaim("", result.ResourceName, result.Path)
+	return result, nil
 }
 
 // printDeleteDryRun shows what would be deleted
 func printDeleteDryRun(resourceName, category, path, repoRoot string) error {
+	registryAction := "remove entry from registry.yaml"
+	if registryReadOnly() {
+		registryAction = "unchanged (--registry-readonly)"
+	}
+
 	fmt.Println("\n=== DRY RUN - No changes made ===")
 	fmt.Printf("Would delete claim: %s\n", resourceName)
 	fmt.Printf("  Category:    %s\n", category)
+	if target, isFile, err := deleteTarget(repoRoot, resourceName, category, path); err == nil && isFile {
+		fmt.Printf("  File:        %s\n", filepath.Join(repoRoot, target))
+		fmt.Printf("  Registry:    %s\n", registryAction)
+		fmt.Printf("  Kustomize:   remove resource from %s\n", filepath.Join(filepath.Dir(target), "kustomization.yaml"))
+		return nil
+	}
 	fmt.Printf("  Directory:   %s\n", filepath.Join(repoRoot, "claims", category, resourceName))
-	fmt.Printf("  Registry:    remove entry from registry.yaml\n")
+	fmt.Printf("  Registry:    %s\n", registryAction)
 	fmt.Printf("  Kustomize:   remove resource from claims/%s/kustomization.yaml\n", category)
 	return nil
 }
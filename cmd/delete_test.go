@@ -181,6 +181,74 @@ func TestPerformDelete(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:         "deletes a flat encrypted secret file",
+			resourceName: "db-credentials",
+			category:     "apps",
+			setup: func(t *testing.T, repoRoot string) {
+				t.Helper()
+				// 'claims encrypt' writes a flat file, not a claim directory
+				dir := filepath.Join(repoRoot, "claims", "apps", "secrets")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				for _, name := range []string{"db-credentials-secret.enc.yaml", "other-secret.enc.yaml"} {
+					if err := os.WriteFile(filepath.Join(dir, name), []byte("sops: {}"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				}
+				k := &kustomize.Kustomization{
+					APIVersion: "kustomize.config.k8s.io/v1beta1",
+					Kind:       "Kustomization",
+					Resources:  []string{"db-credentials-secret.enc.yaml", "other-secret.enc.yaml"},
+				}
+				if err := kustomize.Save(filepath.Join(dir, "kustomization.yaml"), k); err != nil {
+					t.Fatal(err)
+				}
+
+				reg := registry.NewRegistry()
+				registry.AddEntry(reg, registry.ClaimEntry{
+					Name:     "db-credentials",
+					Template: "db-secret",
+					Category: "apps",
+					Path:     "claims/apps/secrets/db-credentials-secret.enc.yaml",
+					Status:   "active",
+					Source:   "cli-encrypt",
+				})
+				if err := registry.Save(filepath.Join(repoRoot, "claims", "registry.yaml"), reg); err != nil {
+					t.Fatal(err)
+				}
+			},
+			verify: func(t *testing.T, repoRoot string, result *DeleteResult) {
+				t.Helper()
+				dir := filepath.Join(repoRoot, "claims", "apps", "secrets")
+				if _, err := os.Stat(filepath.Join(dir, "db-credentials-secret.enc.yaml")); !os.IsNotExist(err) {
+					t.Error("encrypted secret file should have been removed")
+				}
+				if _, err := os.Stat(filepath.Join(dir, "other-secret.enc.yaml")); err != nil {
+					t.Error("other files in the directory should be kept")
+				}
+				if !result.File || result.Path != filepath.Join("claims", "apps", "secrets", "db-credentials-secret.enc.yaml") {
+					t.Errorf("result = %+v, want the removed file", result)
+				}
+
+				k, err := kustomize.Load(filepath.Join(dir, "kustomization.yaml"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(k.Resources) != 1 || k.Resources[0] != "other-secret.enc.yaml" {
+					t.Errorf("kustomization resources = %v, want [other-secret.enc.yaml]", k.Resources)
+				}
+
+				reg, err := registry.Load(filepath.Join(repoRoot, "claims", "registry.yaml"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if registry.FindEntry(reg, "db-credentials") != nil {
+					t.Error("registry should not contain db-credentials entry")
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -188,7 +256,7 @@ func TestPerformDelete(t *testing.T) {
 			repoRoot := t.TempDir()
 			tt.setup(t, repoRoot)
 
-			result, err := performDelete(repoRoot, "claims/registry.yaml", tt.resourceName, tt.category)
+			result, err := performDelete(repoRoot, "claims/registry.yaml", tt.resourceName, tt.category, false)
 
 			if tt.wantErr {
 				if err == nil {
@@ -274,6 +342,51 @@ func TestResolveRepoRoot(t *testing.T) {
 	}
 }
 
+func TestResolveDeleteRegistryPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		flagPath string
+		files    []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "explicit path is kept",
+			flagPath: "custom/registry.yaml",
+			want:     "custom/registry.yaml",
+		},
+		{
+			name:  "discovers registry at repo root",
+			files: []string{"registry.yaml"},
+			want:  "registry.yaml",
+		},
+		{
+			name:    "no registry found",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(repoRoot, f), []byte("claims: []\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			config := &DeleteConfig{RegistryPath: tt.flagPath}
+			err := resolveDeleteRegistryPath(config, repoRoot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveDeleteRegistryPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && config.RegistryPath != tt.want {
+				t.Errorf("RegistryPath = %s, want %s", config.RegistryPath, tt.want)
+			}
+		})
+	}
+}
+
 func TestGenerateDeletePRDescription(t *testing.T) {
 	result := &DeleteResult{
 		ResourceName: "my-vm",
@@ -281,7 +394,7 @@ func TestGenerateDeletePRDescription(t *testing.T) {
 		Path:         "claims/infra/my-vm",
 	}
 
-	desc := generateDeletePRDescription(result)
+	desc := generateDeletePRDescription(result, filepath.Join(".claims", "registry.yaml"))
 
 	// Check for expected sections and content
 	checks := []struct {
@@ -294,7 +407,7 @@ func TestGenerateDeletePRDescription(t *testing.T) {
 		{"summary heading", "## Summary"},
 		{"changes heading", "## Changes"},
 		{"kustomization mention", "kustomization.yaml"},
-		{"registry mention", "registry.yaml"},
+		{"registry mention", "- Updated `.claims/registry.yaml`"},
 	}
 
 	for _, c := range checks {
@@ -303,3 +416,12 @@ func TestGenerateDeletePRDescription(t *testing.T) {
 		}
 	}
 }
+
+func TestExecuteDeleteGitOperations_FlagOptionsOnly(t *testing.T) {
+	// --ssh-key alone only refines a commit; there is nothing to commit to,
+	// so the (non-repository) directory is never opened
+	config := &DeleteConfig{GitConfig: &GitConfig{SSHKey: "/keys/deploy"}}
+	if err := executeDeleteGitOperations(&DeleteResult{ResourceName: "my-vm"}, config, t.TempDir()); err != nil {
+		t.Errorf("executeDeleteGitOperations() = %v, want no git operations", err)
+	}
+}
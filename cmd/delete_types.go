@@ -1,5 +1,7 @@
 package cmd
 
+import "path/filepath"
+
 // DeleteConfig holds configuration for the delete command
 type DeleteConfig struct {
 	ResourceName string
@@ -9,6 +11,7 @@ type DeleteConfig struct {
 
 	Interactive bool
 	DryRun      bool
+	NoSort      bool // keep the existing order of kustomization resources instead of sorting them
 
 	GitConfig *GitConfig
 	PRConfig  *PRConfig
@@ -18,6 +21,18 @@ type DeleteConfig struct {
 type DeleteResult struct {
 	ResourceName string
 	Category     string
-	Path         string
-	Error        error
+	Path         string // removed directory or file, relative to the repo root
+	File         bool   // Path is a flat file (e.g. an encrypted secret), not a claim directory
+	// Kustomization is the kustomization.yaml listing the claim, relative to
+	// the repo root; "" = claims/<category>/kustomization.yaml
+	Kustomization string
+	Error         error
+}
+
+// kustomizationPath returns the kustomization.yaml listing the claim, relative to the repo root
+func (r *DeleteResult) kustomizationPath() string {
+	if r.Kustomization != "" {
+		return r.Kustomization
+	}
+	return filepath.Join("claims", r.Category, "kustomization.yaml")
 }
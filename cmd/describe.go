@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+var (
+	describeAPIURL string
+	describeOutput string
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe <template>",
+	Short: "Show the parameters a template expects",
+	Long:  `Prints a template's title, description, tags, source and version, followed by a table of its parameters with type, whether they are required, default, allowed values and pattern. Use -o json to print the template as the API returns it.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   runDescribe,
+}
+
+func init() {
+	describeCmd.Flags().StringVarP(&describeAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	describeCmd.Flags().StringVarP(&describeOutput, "output", "o", "table", "Output format (table, json)")
+
+	rootCmd.AddCommand(describeCmd)
+}
+
+func runDescribe(cmd *cobra.Command, args []string) {
+	describeAPIURL, _ = resolveAPIURL(describeAPIURL)
+	// Several endpoints may be configured; use the first like non-interactive render
+	describeAPIURL = splitAPIURLs(describeAPIURL)[0]
+
+	client, err := newCatalogClient(describeAPIURL, "", resolveAPIToken(""), "", false)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	if err := describe(ctx, client, args[0], describeOutput, os.Stdout); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+}
+
+// describe fetches template name and writes it to out in the given format
+func describe(ctx context.Context, client *templates.Client, name, output string, out io.Writer) error {
+	tmpl, err := fetchTemplate(ctx, client, name, false)
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		return fmt.Errorf("template %q not found (see 'claims browse' for the available templates)", name)
+	}
+	if err != nil {
+		return fmt.Errorf("fetching template: %w", err)
+	}
+
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(tmpl, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling JSON: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+	default:
+		describeTemplate(out, tmpl)
+	}
+	return nil
+}
+
+// describeTemplate writes the template's metadata and parameter table
+func describeTemplate(out io.Writer, t *templates.ClaimTemplate) {
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", t.Metadata.Name)
+	if t.Metadata.Title != "" {
+		fmt.Fprintf(w, "Title:\t%s\n", t.Metadata.Title)
+	}
+	if t.Metadata.Description != "" {
+		fmt.Fprintf(w, "Description:\t%s\n", t.Metadata.Description)
+	}
+	if len(t.Metadata.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(t.Metadata.Tags, ", "))
+	}
+	fmt.Fprintf(w, "Source:\t%s\n", t.Spec.Source)
+	if t.Spec.Tag != "" {
+		fmt.Fprintf(w, "Version:\t%s\n", t.Spec.Tag)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out, "\nParameters:")
+	printParameterTable(out, t.Spec.Parameters)
+
+	for _, s := range t.Spec.Secrets {
+		fmt.Fprintf(out, "\nSecret %s:\n", s.Name)
+		printParameterTable(out, s.Parameters)
+	}
+}
+
+// printParameterTable writes one row per parameter; empty cells show "-".
+// An EXAMPLES column is added when any parameter has examples.
+func printParameterTable(out io.Writer, parameters []templates.Parameter) {
+	if len(parameters) == 0 {
+		fmt.Fprintln(out, "  none")
+		return
+	}
+
+	withExamples := slices.ContainsFunc(parameters, func(p templates.Parameter) bool { return len(p.Examples) > 0 })
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if withExamples {
+		fmt.Fprintln(w, "  NAME\tTYPE\tREQUIRED\tDEFAULT\tEXAMPLES\tENUM\tPATTERN")
+		fmt.Fprintln(w, "  ----\t----\t--------\t-------\t--------\t----\t-------")
+	} else {
+		fmt.Fprintln(w, "  NAME\tTYPE\tREQUIRED\tDEFAULT\tENUM\tPATTERN")
+		fmt.Fprintln(w, "  ----\t----\t--------\t-------\t----\t-------")
+	}
+	for _, p := range parameters {
+		required := "no"
+		if p.Required {
+			required = "yes"
+		}
+		cells := []string{p.Name, orDash(p.Type), required, formatDefault(p.Default)}
+		if withExamples {
+			cells = append(cells, orDash(strings.Join(p.Examples, ", ")))
+		}
+		cells = append(cells, orDash(strings.Join(p.Enum, ", ")), orDash(p.Pattern))
+		fmt.Fprintf(w, "  %s\n", strings.Join(cells, "\t"))
+	}
+	w.Flush()
+}
+
+// formatDefault formats a parameter default; structured values are shown as JSON
+func formatDefault(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case string:
+		return orDash(v)
+	case []any, map[string]any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
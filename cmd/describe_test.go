@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestDescribeTemplate(t *testing.T) {
+	tmpl := &templates.ClaimTemplate{
+		Metadata: templates.ClaimTemplateMetadata{
+			Name:        "vsphere-vm",
+			Title:       "vSphere VM",
+			Description: "A virtual machine on vSphere",
+			Tags:        []string{"vm", "vsphere"},
+		},
+		Spec: templates.ClaimTemplateSpec{
+			Source: "oci://ghcr.io/stuttgart-things/vsphere-vm",
+			Tag:    "v1.2.0",
+			Parameters: []templates.Parameter{
+				{Name: "name", Type: "string", Required: true, Pattern: "^[a-z0-9-]+$"},
+				{Name: "cpu", Type: "integer", Default: float64(2)},
+				{Name: "size", Type: "string", Default: "small", Enum: []string{"small", "large"}},
+				{Name: "labels", Type: "object", Default: map[string]any{"team": "infra"}},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	describeTemplate(&out, tmpl)
+
+	want := `Name:        vsphere-vm
+Title:       vSphere VM
+Description: A virtual machine on vSphere
+Tags:        vm, vsphere
+Source:      oci://ghcr.io/stuttgart-things/vsphere-vm
+Version:     v1.2.0
+
+Parameters:
+  NAME    TYPE     REQUIRED  DEFAULT           ENUM          PATTERN
+  ----    ----     --------  -------           ----          -------
+  name    string   yes       -                 -             ^[a-z0-9-]+$
+  cpu     integer  no        2                 -             -
+  size    string   no        small             small, large  -
+  labels  object   no        {"team":"infra"}  -             -
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestPrintParameterTable_Examples(t *testing.T) {
+	var out bytes.Buffer
+	printParameterTable(&out, []templates.Parameter{
+		{Name: "name", Type: "string", Examples: []string{"web-01", "db-01"}},
+		{Name: "cpu", Type: "integer", Default: float64(2)},
+	})
+
+	want := `  NAME  TYPE     REQUIRED  DEFAULT  EXAMPLES       ENUM  PATTERN
+  ----  ----     --------  -------  --------       ----  -------
+  name  string   no        -        web-01, db-01  -     -
+  cpu   integer  no        2        -              -     -
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatalf("newCatalogClient: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := describe(context.Background(), client, "vsphere-vm", "json", &out); err != nil {
+		t.Fatalf("describe: %v", err)
+	}
+	var got templates.ClaimTemplate
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("expected JSON output: %v\n%s", err, out.String())
+	}
+	if got.Metadata.Name != "vsphere-vm" || len(got.Spec.Parameters) != 1 {
+		t.Errorf("unexpected template: %+v", got)
+	}
+
+	err = describe(context.Background(), client, "unknown", "table", &out)
+	if err == nil || !strings.Contains(err.Error(), `template "unknown" not found`) {
+		t.Errorf("expected a not-found error, got %v", err)
+	}
+}
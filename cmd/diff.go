@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/params"
+	"github.com/stuttgart-things/claims/internal/redact"
+	"github.com/stuttgart-things/claims/internal/registry"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+var (
+	diffAPIURL          string
+	diffDir             string
+	diffAgainstRegistry bool
+	diffFilter          []string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Report claims whose committed files drifted",
+	Long:  `With --against-registry, re-renders every active registry entry from its stored parameters and diffs the result against the committed file. Each claim is reported as in-sync, drifted (the file was edited by hand or the template changed upstream) or unrenderable (no stored parameters, or the render failed), followed by the diffs of the drifted claims. Exits non-zero if any claim drifted.`,
+	Args:  cobra.NoArgs,
+	Run:   runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	diffCmd.Flags().StringVar(&diffDir, "dir", ".", "Repository (or directory in it) holding the registry")
+	diffCmd.Flags().BoolVar(&diffAgainstRegistry, "against-registry", false, "Re-render registry entries from their stored parameters and diff against the committed files")
+	diffCmd.Flags().StringSliceVar(&diffFilter, "filter", nil, "Only check entries matching category=, template=, source= or label=key=value (repeatable)")
+
+	rootCmd.AddCommand(diffCmd)
+}
+
+// Drift status of a registry entry
+const (
+	driftInSync       = "in-sync"
+	driftDrifted      = "drifted"
+	driftUnrenderable = "unrenderable"
+)
+
+// claimDrift is the result of comparing one registry entry with a fresh render
+type claimDrift struct {
+	Name   string
+	Path   string
+	Status string
+	Reason string // why an entry is unrenderable
+	Diff   string // committed file to fresh render, for drifted entries
+}
+
+func runDiff(cmd *cobra.Command, args []string) {
+	if !diffAgainstRegistry {
+		fmt.Println(renderError("nothing to compare against (use --against-registry)"))
+		os.Exit(1)
+	}
+	sel, err := parseRegistryFilter(diffFilter)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	repoRoot, err := findRepoRoot(diffDir)
+	if err != nil {
+		fmt.Println(renderError(fmt.Sprintf("--against-registry needs a git repository: %v", err)))
+		os.Exit(1)
+	}
+
+	diffAPIURL, _ = resolveAPIURL(diffAPIURL)
+	// Several endpoints may be configured; use the first like non-interactive render
+	diffAPIURL = splitAPIURLs(diffAPIURL)[0]
+
+	client, err := newCatalogClient(diffAPIURL, "", resolveAPIToken(""), "", false)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	drift, err := registryDrift(ctx, client, repoRoot, sel)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	if printRegistryDrift(os.Stdout, drift) > 0 {
+		os.Exit(1)
+	}
+}
+
+// registryDrift re-renders the active registry entries under repoRoot
+// selected by sel from their stored parameters and compares each render with
+// the committed file. A missing file counts as drifted.
+func registryDrift(ctx context.Context, client *templates.Client, repoRoot string, sel registry.Selector) ([]claimDrift, error) {
+	registryPath, err := registry.Discover(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := registry.Load(registryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []claimDrift
+	for _, e := range registry.Select(reg, sel) {
+		if e.Status != "active" {
+			continue
+		}
+		d := claimDrift{Name: e.Name, Path: e.Path}
+		if len(e.Parameters) == 0 {
+			d.Status, d.Reason = driftUnrenderable, "no stored parameters"
+			drift = append(drift, d)
+			continue
+		}
+
+		content, err := client.RenderTemplateContext(ctx, e.Template, params.MergeParams(e.Parameters, nil))
+		if errors.Is(err, context.Canceled) {
+			return nil, fmt.Errorf("diff interrupted")
+		}
+		if err != nil {
+			d.Status, d.Reason = driftUnrenderable, err.Error()
+			drift = append(drift, d)
+			continue
+		}
+
+		committed, err := os.ReadFile(registryTargetPath(repoRoot, e))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading %s: %w", e.Path, err)
+		}
+		if d.Diff = DiffResult(string(committed), content, e.Path); d.Diff != "" {
+			d.Status = driftDrifted
+		} else {
+			d.Status = driftInSync
+		}
+		drift = append(drift, d)
+	}
+	return drift, nil
+}
+
+// printRegistryDrift writes the status of every entry, the diffs of the
+// drifted ones and a summary to out, and returns how many entries drifted
+func printRegistryDrift(out io.Writer, drift []claimDrift) (drifted int) {
+	if len(drift) == 0 {
+		fmt.Fprintln(out, "No active registry entries to check.")
+		return 0
+	}
+
+	unrenderable := 0
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STATUS\tNAME\tPATH")
+	fmt.Fprintln(w, "------\t----\t----")
+	for _, d := range drift {
+		note := ""
+		switch d.Status {
+		case driftDrifted:
+			drifted++
+		case driftUnrenderable:
+			unrenderable++
+			note = " (" + redact.String(d.Reason) + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s%s\n", d.Status, d.Name, d.Path, note)
+	}
+	w.Flush()
+
+	for _, d := range drift {
+		if d.Status == driftDrifted {
+			fmt.Fprintf(out, "\n%s", d.Diff)
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d of %s drifted", drifted, plural(len(drift), "claim"))
+	if unrenderable > 0 {
+		fmt.Fprintf(out, ", %d unrenderable", unrenderable)
+	}
+	fmt.Fprintln(out)
+	return drifted
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/registry"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestRegistryDrift(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("volumeclaim")})
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"claims/apps/in-sync.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: in-sync\n",
+		"claims/apps/edited.yaml":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: edited-by-hand\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := registry.NewRegistry()
+	for _, e := range []registry.ClaimEntry{
+		{Name: "in-sync", Path: "claims/apps/in-sync.yaml", Parameters: map[string]any{"name": "in-sync"}},
+		{Name: "edited", Path: "claims/apps/edited.yaml", Parameters: map[string]any{"name": "edited"}},
+		{Name: "deleted", Path: "claims/apps/deleted.yaml", Parameters: map[string]any{"name": "deleted"}},
+		{Name: "no-params", Path: "claims/apps/no-params.yaml"},
+		{Name: "retired", Path: "claims/apps/retired.yaml", Status: "deleted", Parameters: map[string]any{"name": "retired"}},
+	} {
+		e.Template = "volumeclaim"
+		if e.Status == "" {
+			e.Status = "active"
+		}
+		e.Category = "apps"
+		registry.AddEntry(reg, e)
+	}
+	if err := registry.Save(filepath.Join(repo, "claims", "registry.yaml"), reg); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := registryDrift(context.Background(), client, repo, registry.Selector{})
+	if err != nil {
+		t.Fatalf("registryDrift() error = %v", err)
+	}
+
+	want := map[string]string{
+		"in-sync":   driftInSync,
+		"edited":    driftDrifted,
+		"deleted":   driftDrifted,
+		"no-params": driftUnrenderable,
+	}
+	if len(drift) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(drift), len(want), drift)
+	}
+	for _, d := range drift {
+		if d.Status != want[d.Name] {
+			t.Errorf("%s: status = %q, want %q", d.Name, d.Status, want[d.Name])
+		}
+		if (d.Status == driftDrifted) != (d.Diff != "") {
+			t.Errorf("%s: diff = %q for status %q", d.Name, d.Diff, d.Status)
+		}
+	}
+
+	var out bytes.Buffer
+	if drifted := printRegistryDrift(&out, drift); drifted != 2 {
+		t.Errorf("printRegistryDrift() = %d, want 2", drifted)
+	}
+	for _, s := range []string{
+		"-  name: edited-by-hand",
+		"+  name: edited",
+		"--- /dev/null",
+		"no stored parameters",
+		"2 of 4 claims drifted, 1 unrenderable",
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("output missing %q:\n%s", s, out.String())
+		}
+	}
+}
+
+func TestRegistryDrift_InSync(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("volumeclaim")})
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := t.TempDir()
+	path := filepath.Join(repo, "claims", "apps", "data.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: data\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reg := registry.NewRegistry()
+	registry.AddEntry(reg, registry.ClaimEntry{
+		Name: "data", Template: "volumeclaim", Category: "apps", Status: "active",
+		Path: "claims/apps/data.yaml", Parameters: map[string]any{"name": "data"},
+	})
+	if err := registry.Save(filepath.Join(repo, "claims", "registry.yaml"), reg); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := registryDrift(context.Background(), client, repo, registry.Selector{})
+	if err != nil {
+		t.Fatalf("registryDrift() error = %v", err)
+	}
+	var out bytes.Buffer
+	if drifted := printRegistryDrift(&out, drift); drifted != 0 {
+		t.Errorf("printRegistryDrift() = %d, want 0:\n%s", drifted, out.String())
+	}
+	if !strings.Contains(out.String(), "0 of 1 claim drifted") {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -1,24 +1,42 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"github.com/stuttgart-things/claims/internal/banner"
 )
 
 var (
 	encryptAPIURL       string
+	encryptAPIToken     string
+	encryptCACert       string
+	encryptSkipTLS      bool
 	encryptTemplate     string
 	encryptSecretName   string
 	encryptNamespace    string
 	encryptParamsFile   string
+	encryptExpandEnv    bool
+	encryptStrictEnv    bool
 	encryptInlineParams []string
+	encryptSecretKeyMap []string
 	encryptOutputDir    string
 	encryptFilenamePat  string
 	encryptDryRun       bool
+	encryptVerify       bool
+	encryptMergeInto    string
+	encryptOverwrite    bool
+	encryptKSOPS        bool
+	encryptEncRegex     string
+	encryptUnencRegex   string
+	encryptKMS          string
+	encryptGCPKMS       string
+	encryptAzureKV      string
+	encryptParamOrder   string
+	encryptMaskPreview  bool
+	encryptNoCache      bool
 
 	// Git flags for encrypt
 	encryptGitBranch       string
@@ -28,13 +46,17 @@ var (
 	encryptGitRepoURL      string
 	encryptGitUser         string
 	encryptGitToken        string
+	encryptGitSSHKey       string
+	encryptGitGPGSign      bool
+	encryptGitGPGKey       string
 
 	// PR flags for encrypt
-	encryptCreatePR      bool
-	encryptPRTitle       string
-	encryptPRDescription string
-	encryptPRLabels      []string
-	encryptPRBase        string
+	encryptCreatePR            bool
+	encryptPRTitle             string
+	encryptPRDescription       string
+	encryptPRLabels            []string
+	encryptPRBase              string
+	encryptCreateMissingLabels bool
 
 	// Mode flags for encrypt
 	encryptInteractive    bool
@@ -44,29 +66,48 @@ var (
 var encryptCmd = &cobra.Command{
 	Use:   "encrypt",
 	Short: "Create a SOPS-encrypted Kubernetes Secret via Git PR",
-	Long:  `Fetches a template from the claim-machinery API, collects secret values, generates a Kubernetes Secret YAML, encrypts it with SOPS (age), and optionally commits via Git PR.`,
+	Long:  `Fetches a template from the claim-machinery API, collects secret values, generates a Kubernetes Secret YAML, encrypts it with SOPS (age or PGP keys from the environment, or a cloud KMS key), and optionally commits via Git PR.`,
 	Run:   runEncrypt,
 }
 
 func init() {
 	encryptCmd.Flags().StringVarP(&encryptAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	encryptCmd.Flags().StringVar(&encryptAPIToken, "api-token", "", "Bearer token for the API (default: $CLAIM_API_TOKEN)")
+	encryptCmd.Flags().StringVar(&encryptCACert, "ca-cert", "", "PEM CA certificate to trust for an HTTPS API signed by a private CA")
+	encryptCmd.Flags().BoolVar(&encryptSkipTLS, "insecure-skip-tls-verify", false, "Don't verify the API's TLS certificate (insecure; for testing only)")
+	encryptCmd.Flags().BoolVar(&encryptNoCache, "no-cache", false, "Fetch the template catalog from the API instead of reusing the cached one")
 	encryptCmd.Flags().StringVarP(&encryptTemplate, "template", "t", "", "Template name to use")
 	encryptCmd.Flags().StringVar(&encryptSecretName, "name", "", "Secret name")
 	encryptCmd.Flags().StringVar(&encryptNamespace, "namespace", "", "Secret namespace")
-	encryptCmd.Flags().StringVarP(&encryptParamsFile, "params-file", "f", "", "YAML/JSON file with parameters")
+	encryptCmd.Flags().StringVarP(&encryptParamsFile, "params-file", "f", "", "YAML/JSON/TOML file with parameters (- reads stdin)")
+	encryptCmd.Flags().BoolVar(&encryptExpandEnv, "expand-env", false, "Expand $VAR, ${VAR} and ${VAR:-default} in the params file from the environment before parsing")
+	encryptCmd.Flags().BoolVar(&encryptStrictEnv, "strict-env", false, "Like --expand-env, but fail on variables that are unset and have no default")
 	encryptCmd.Flags().StringSliceVarP(&encryptInlineParams, "param", "p", nil, "Inline param (key=value, repeatable)")
+	encryptCmd.Flags().StringArrayVar(&encryptSecretKeyMap, "secret-key-map", nil, "Rename a param to a Secret key (param=key, repeatable; unmapped params keep their name)")
 	encryptCmd.Flags().StringVarP(&encryptOutputDir, "output-dir", "o", ".", "Output directory for encrypted file")
 	encryptCmd.Flags().StringVar(&encryptFilenamePat, "filename-pattern", "{{.name}}-secret.enc.yaml", "Pattern for output filename")
 	encryptCmd.Flags().BoolVar(&encryptDryRun, "dry-run", false, "Show encrypted output without writing files")
+	encryptCmd.Flags().BoolVar(&encryptVerify, "verify", false, "Decrypt the result and check it matches before writing (needs a private key, e.g. $SOPS_AGE_KEY_FILE)")
+	encryptCmd.Flags().StringVar(&encryptMergeInto, "merge-into-secret", "", "Add the collected keys to this existing encrypted Secret and write it back (non-interactive)")
+	encryptCmd.Flags().BoolVar(&encryptOverwrite, "overwrite-keys", false, "With --merge-into-secret, replace keys the Secret already has instead of failing")
+	encryptCmd.Flags().BoolVar(&encryptKSOPS, "ksops", false, "Also list the encrypted file in a KSOPS generator (ksops.yaml) and add it to kustomization.yaml generators")
+	encryptCmd.Flags().StringVar(&encryptEncRegex, "encrypted-regex", "", "Only encrypt keys matching this regex (e.g. '^(data|stringData)$')")
+	encryptCmd.Flags().StringVar(&encryptUnencRegex, "unencrypted-regex", "", "Leave keys matching this regex unencrypted")
+	encryptCmd.Flags().StringVar(&encryptKMS, "kms", "", "AWS KMS key ARN(s) to encrypt with (comma-separated)")
+	encryptCmd.Flags().StringVar(&encryptGCPKMS, "gcp-kms", "", "GCP KMS resource ID(s) to encrypt with (comma-separated)")
+	encryptCmd.Flags().StringVar(&encryptAzureKV, "azure-kv", "", "Azure Key Vault key URL(s) to encrypt with (comma-separated)")
 
 	// Git flags
 	encryptCmd.Flags().StringVar(&encryptGitBranch, "git-branch", "", "Branch to use/create")
 	encryptCmd.Flags().BoolVar(&encryptGitCreateBranch, "git-create-branch", false, "Create the branch if it doesn't exist")
 	encryptCmd.Flags().StringVar(&encryptGitMessage, "git-message", "", "Commit message (default: auto-generated)")
-	encryptCmd.Flags().StringVar(&encryptGitRemote, "git-remote", "origin", "Git remote name")
+	encryptCmd.Flags().StringVar(&encryptGitRemote, "git-remote", "", "Git remote name (default: the only remote; with several, asked for interactively)")
 	encryptCmd.Flags().StringVar(&encryptGitRepoURL, "git-repo-url", "", "Clone from URL instead of using local repo")
 	encryptCmd.Flags().StringVar(&encryptGitUser, "git-user", "", "Git username (or GIT_USER/GITHUB_USER env)")
 	encryptCmd.Flags().StringVar(&encryptGitToken, "git-token", "", "Git token (or GIT_TOKEN/GITHUB_TOKEN env)")
+	encryptCmd.Flags().StringVar(&encryptGitSSHKey, "ssh-key", "", "Private key for SSH remotes (or GIT_SSH_KEY env, default ~/.ssh/id_ed25519; passphrase from GIT_SSH_KEY_PASSPHRASE)")
+	encryptCmd.Flags().BoolVar(&encryptGitGPGSign, "gpg-sign", false, "GPG-sign the commit with the key from --gpg-key or GIT_SIGNING_KEY; fails if there is none")
+	encryptCmd.Flags().StringVar(&encryptGitGPGKey, "gpg-key", "", "Key to sign the commit with: armored private key, its file or a gpg key ID (or GIT_SIGNING_KEY env; passphrase from GIT_SIGNING_KEY_PASSPHRASE); implies --gpg-sign")
 
 	// PR flags
 	encryptCmd.Flags().BoolVar(&encryptCreatePR, "create-pr", false, "Create a pull request after push")
@@ -74,16 +115,22 @@ func init() {
 	encryptCmd.Flags().StringVar(&encryptPRDescription, "pr-description", "", "PR description")
 	encryptCmd.Flags().StringSliceVar(&encryptPRLabels, "pr-labels", nil, "PR labels (comma-separated)")
 	encryptCmd.Flags().StringVar(&encryptPRBase, "pr-base", "main", "Base branch for PR")
+	encryptCmd.Flags().BoolVar(&encryptCreateMissingLabels, "create-missing-labels", false, "Create PR labels that don't exist in the repository (default: skip them with a warning)")
 
 	// Mode flags
+	encryptCmd.Flags().StringVar(&encryptParamOrder, "param-order", ParamOrderDeclared, "Order of the secret value form fields: required-first, declared or alphabetical (interactive)")
+	encryptCmd.Flags().BoolVar(&encryptMaskPreview, "mask-preview", true, "Show the values of hidden parameters as **** in the pre-encryption preview until revealed (interactive)")
 	encryptCmd.Flags().BoolVarP(&encryptInteractive, "interactive", "i", false, "Force interactive mode")
 	encryptCmd.Flags().BoolVar(&encryptNonInteractive, "non-interactive", false, "Force non-interactive mode")
 
+	addWebhookFlags(encryptCmd)
+	addSummaryFlags(encryptCmd)
+
 	rootCmd.AddCommand(encryptCmd)
 }
 
 func runEncrypt(cmd *cobra.Command, args []string) {
-	banner.Show()
+	showBanner()
 
 	// Get API URL from flag, environment, or default
 	if encryptAPIURL == "" {
@@ -94,22 +141,60 @@ func runEncrypt(cmd *cobra.Command, args []string) {
 	}
 
 	config := &EncryptConfig{
-		APIUrl:          encryptAPIURL,
-		Template:        encryptTemplate,
-		SecretName:      encryptSecretName,
-		SecretNamespace: encryptNamespace,
-		ParamsFile:      encryptParamsFile,
-		InlineParamsRaw: encryptInlineParams,
-		OutputDir:       encryptOutputDir,
-		FilenamePattern: encryptFilenamePat,
-		DryRun:          encryptDryRun,
+		APIUrl:           encryptAPIURL,
+		APIToken:         resolveAPIToken(encryptAPIToken),
+		CACert:           encryptCACert,
+		SkipTLSVerify:    encryptSkipTLS,
+		Template:         encryptTemplate,
+		SecretName:       encryptSecretName,
+		SecretNamespace:  encryptNamespace,
+		ParamsFile:       encryptParamsFile,
+		ExpandEnv:        encryptExpandEnv,
+		StrictEnv:        encryptStrictEnv,
+		InlineParamsRaw:  encryptInlineParams,
+		OutputDir:        encryptOutputDir,
+		FilenamePattern:  encryptFilenamePat,
+		DryRun:           encryptDryRun,
+		Verify:           encryptVerify,
+		MergeInto:        encryptMergeInto,
+		OverwriteKeys:    encryptOverwrite,
+		KSOPS:            encryptKSOPS,
+		EncryptedRegex:   encryptEncRegex,
+		UnencryptedRegex: encryptUnencRegex,
+		KMS:              encryptKMS,
+		GCPKMS:           encryptGCPKMS,
+		AzureKV:          encryptAzureKV,
+		ParamOrder:       encryptParamOrder,
+		MaskPreview:      encryptMaskPreview,
+		NoCache:          encryptNoCache,
+	}
+
+	// Reject bad regex options and key mappings before any prompts or API calls
+	if err := config.EncryptOptions().Validate(); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	keyMap, err := parseSecretKeyMap(encryptSecretKeyMap)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
 	}
+	config.SecretKeyMap = keyMap
 
-	// Build git config if any git flags are set
-	if encryptGitBranch != "" || encryptGitRepoURL != "" || encryptCreatePR {
+	webhook, err := newWebhook()
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+
+	// Build git config if any git flags are set. Only a branch, repo URL or
+	// PR commits on their own; the other flags refine the commit set up in
+	// the interactive form.
+	encryptGitTarget := encryptGitBranch != "" || encryptGitRepoURL != "" || encryptCreatePR
+	if encryptGitTarget || encryptGitRemote != "" || encryptGitSSHKey != "" || encryptGitGPGSign || encryptGitGPGKey != "" {
 		config.GitConfig = &GitConfig{
-			Commit:       true,
-			Push:         true,
+			Commit:       encryptGitTarget,
+			Push:         encryptGitTarget,
 			CreateBranch: encryptGitCreateBranch,
 			Message:      encryptGitMessage,
 			Branch:       encryptGitBranch,
@@ -117,17 +202,21 @@ func runEncrypt(cmd *cobra.Command, args []string) {
 			RepoURL:      encryptGitRepoURL,
 			User:         encryptGitUser,
 			Token:        encryptGitToken,
+			SSHKey:       encryptGitSSHKey,
+			GPGSign:      encryptGitGPGSign,
+			GPGKey:       encryptGitGPGKey,
 		}
 	}
 
 	// Build PR config if PR flags are set
 	if encryptCreatePR || encryptPRTitle != "" || encryptPRDescription != "" || len(encryptPRLabels) > 0 {
 		config.PRConfig = &PRConfig{
-			Create:      encryptCreatePR,
-			Title:       encryptPRTitle,
-			Description: encryptPRDescription,
-			Labels:      encryptPRLabels,
-			BaseBranch:  encryptPRBase,
+			Create:              encryptCreatePR,
+			Title:               encryptPRTitle,
+			Description:         encryptPRDescription,
+			Labels:              encryptPRLabels,
+			BaseBranch:          encryptPRBase,
+			CreateMissingLabels: encryptCreateMissingLabels,
 		}
 	}
 
@@ -140,15 +229,20 @@ func runEncrypt(cmd *cobra.Command, args []string) {
 		config.Interactive = isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
 	}
 
-	var err error
 	if config.Interactive {
-		err = runEncryptInteractive(config)
+		err = runEncryptInteractive(context.Background(), config)
 	} else {
-		err = runEncryptNonInteractive(config)
+		err = runEncryptNonInteractive(context.Background(), config)
 	}
 
+	if werr := notifyWebhook(webhook, "encrypt", err); err == nil {
+		err = werr
+	}
+	if serr := writeRunSummary(summaryJSONPath, "encrypt", err); err == nil {
+		err = serr
+	}
 	if err != nil {
-		fmt.Println(errorStyle.Render(err.Error()))
+		fmt.Println(renderError(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -13,29 +13,17 @@ import (
 
 // executeEncryptGitOperations performs git commit, push, and PR creation for an encrypt operation
 func executeEncryptGitOperations(result *EncryptResult, config *EncryptConfig) error {
-	if config.GitConfig == nil {
+	if config.GitConfig == nil || !config.GitConfig.Commit {
 		return nil
 	}
 
-	// Resolve credentials
-	user, token := config.GitConfig.User, config.GitConfig.Token
-	if config.GitConfig.Push {
-		var err error
-		user, token, err = gitops.ResolveCredentials(user, token)
-		if err != nil {
-			return err
-		}
-	} else {
-		user, token = gitops.ResolveCredentialsOptional(user, token)
-	}
-
 	// Find repo root from output path
 	repoRoot, err := findRepoRoot(filepath.Dir(result.OutputPath))
 	if err != nil {
 		return fmt.Errorf("output directory is not in a git repository: %w", err)
 	}
 
-	g, err := gitops.New(repoRoot, user, token)
+	g, err := openGitRepo(repoRoot, config.GitConfig, config.GitConfig.Push, config.Interactive)
 	if err != nil {
 		return err
 	}
@@ -61,10 +49,11 @@ func executeEncryptGitOperations(result *EncryptResult, config *EncryptConfig) e
 	// Stage files
 	var filesToAdd []string
 	filesToAdd = append(filesToAdd, result.OutputPath)
+	filesToAdd = append(filesToAdd, result.KSOPSFiles...)
 
-	// Also stage registry.yaml if it was updated
-	registryPath := filepath.Join(repoRoot, "claims", "registry.yaml")
-	if _, err := os.Stat(registryPath); err == nil {
+	// Also stage registry.yaml if it was updated (never when read-only)
+	registryPath := registryPathForRepo(repoRoot)
+	if _, err := os.Stat(registryPath); err == nil && !registryReadOnly() {
 		filesToAdd = append(filesToAdd, registryPath)
 	}
 
@@ -79,19 +68,20 @@ func executeEncryptGitOperations(result *EncryptResult, config *EncryptConfig) e
 		message = generateEncryptCommitMessage(result)
 	}
 
-	// Commit
+	// Commit, authored by the git user if one is set
+	user, _ := gitops.ResolveCredentialsOptional(config.GitConfig.User, config.GitConfig.Token)
 	fmt.Printf("Committing: %s\n", message)
-	if err := g.Commit(message, user, ""); err != nil {
+	hash, err := g.CommitWithHash(message, user, "")
+	if err != nil {
 		return err
 	}
+	outcome.setCommit(hash)
+	outcome.setBranch(branchName)
 	fmt.Println(successStyle.Render("Committed successfully"))
 
 	// Push
 	if config.GitConfig.Push {
 		remote := config.GitConfig.Remote
-		if remote == "" {
-			remote = "origin"
-		}
 
 		fmt.Printf("Pushing to %s...\n", remote)
 		if err := g.Push(remote, branchName); err != nil {
@@ -134,7 +124,7 @@ func executeEncryptPRCreation(result *EncryptResult, config *EncryptConfig, repo
 	prConfig := gitops.PRConfig{
 		Title:       title,
 		Description: description,
-		Labels:      config.PRConfig.Labels,
+		Labels:      resolvePRLabels(config.PRConfig.Labels, config.PRConfig.CreateMissingLabels, repoPath),
 		BaseBranch:  baseBranch,
 		HeadBranch:  headBranch,
 	}
@@ -146,6 +136,7 @@ func executeEncryptPRCreation(result *EncryptResult, config *EncryptConfig, repo
 	}
 
 	fmt.Println(successStyle.Render(fmt.Sprintf("Created PR: %s", pr.URL)))
+	outcome.setPR(pr.URL)
 	return nil
 }
 
@@ -173,14 +164,19 @@ func generateEncryptPRDescription(result *EncryptResult) string {
 	return sb.String()
 }
 
-// updateRegistryForEncrypt adds an entry to claims/registry.yaml for the encrypted secret
+// updateRegistryForEncrypt adds an entry to the repo's registry for the encrypted secret
 func updateRegistryForEncrypt(result *EncryptResult, outputDir string) {
+	if registryReadOnly() {
+		printRegistryReadOnly()
+		return
+	}
+
 	repoRoot, err := findRepoRoot(outputDir)
 	if err != nil {
 		return // Not in a git repo, skip registry update
 	}
 
-	registryPath := filepath.Join(repoRoot, "claims", "registry.yaml")
+	registryPath := registryPathForRepo(repoRoot)
 
 	// Load or create registry
 	reg, err := registry.Load(registryPath)
@@ -230,6 +226,6 @@ func updateRegistryForEncrypt(result *EncryptResult, outputDir string) {
 		return
 	}
 	if err := registry.Save(registryPath, reg); err != nil {
-		fmt.Printf("Warning: could not update registry: %v\n", err)
+		warnf("could not update registry: %v", err)
 	}
 }
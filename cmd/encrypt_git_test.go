@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/registry"
+)
+
+func TestUpdateRegistryForEncrypt_DiscoveredRegistry(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// The repo keeps its registry at the root instead of claims/
+	rootRegistry := filepath.Join(repoRoot, "registry.yaml")
+	if err := registry.Save(rootRegistry, registry.NewRegistry()); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(repoRoot, "claims", "secrets")
+	result := &EncryptResult{
+		TemplateName:    "app",
+		SecretName:      "app-secret",
+		SecretNamespace: "apps",
+		OutputPath:      filepath.Join(outputDir, "app-secret.enc.yaml"),
+	}
+
+	updateRegistryForEncrypt(result, outputDir)
+
+	reg, err := registry.Load(rootRegistry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry.FindEntry(reg, "app-secret") == nil {
+		t.Error("expected the entry in the discovered registry.yaml")
+	}
+	if _, err := os.Stat(filepath.Join(repoRoot, "claims", "registry.yaml")); !os.IsNotExist(err) {
+		t.Errorf("claims/registry.yaml should not be created, stat err = %v", err)
+	}
+}
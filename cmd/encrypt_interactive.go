@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,14 +15,26 @@ import (
 )
 
 // runEncryptInteractive runs the encrypt command in interactive mode
-func runEncryptInteractive(config *EncryptConfig) error {
+func runEncryptInteractive(ctx context.Context, config *EncryptConfig) error {
+	if err := validateParamOrder(config.ParamOrder); err != nil {
+		return err
+	}
+	if config.MergeInto != "" {
+		return fmt.Errorf("--merge-into-secret is only supported in non-interactive mode (add --non-interactive)")
+	}
+
 	// 1. Check SOPS prerequisites
 	fmt.Println(progressStyle.Render("Checking SOPS prerequisites..."))
-	recipients, err := sops.CheckSOPSAvailable()
+	recipients, err := sops.CheckSOPSAvailable(config.SOPSKeys())
 	if err != nil {
 		return fmt.Errorf("SOPS prerequisites: %w", err)
 	}
-	fmt.Println(successStyle.Render("SOPS available (age encryption)"))
+	fmt.Println(successStyle.Render(fmt.Sprintf("SOPS available (%s encryption)", recipients.Backends())))
+	if config.Verify {
+		if err := sops.CheckDecryptKey(recipients); err != nil {
+			return fmt.Errorf("--verify: %w", err)
+		}
+	}
 
 	// 2. Prompt/confirm API URL
 	confirmedURL, err := promptAPIURL(config.APIUrl)
@@ -32,8 +45,13 @@ func runEncryptInteractive(config *EncryptConfig) error {
 	fmt.Printf("\nConnecting to API: %s\n\n", config.APIUrl)
 
 	// 3. Fetch templates from API
-	client := templates.NewClient(config.APIUrl)
-	templateList, err := client.FetchTemplates()
+	client, err := newCatalogClient(config.APIUrl, "", config.APIToken, config.CACert, config.SkipTLSVerify)
+	if err != nil {
+		return err
+	}
+	fetchCtx, stop := signalContext(ctx)
+	templateList, err := fetchCatalogTemplates(fetchCtx, client, config.NoCache)
+	stop()
 	if err != nil {
 		return fmt.Errorf("fetching templates: %w", err)
 	}
@@ -116,7 +134,7 @@ func runEncryptInteractive(config *EncryptConfig) error {
 	}
 
 	// 6. Collect secret values from template parameters
-	stringData, err := collectSecretValues(tmpl)
+	stringData, err := collectSecretValues(tmpl, config.ParamOrder)
 	if err != nil {
 		return fmt.Errorf("collecting secret values: %w", err)
 	}
@@ -125,35 +143,31 @@ func runEncryptInteractive(config *EncryptConfig) error {
 		return fmt.Errorf("no secret values provided")
 	}
 
+	stringData, err = remapSecretKeys(stringData, config.SecretKeyMap)
+	if err != nil {
+		return err
+	}
+
 	// 7. Generate Secret YAML
 	fmt.Println(progressStyle.Render("\nGenerating Kubernetes Secret YAML..."))
-	secretYAML, err := sops.GenerateSecretYAML(sops.SecretData{
+	secret := sops.SecretData{
 		Name:       secretName,
 		Namespace:  secretNamespace,
 		StringData: stringData,
-	})
+	}
+	secretYAML, err := sops.GenerateSecretYAML(secret)
 	if err != nil {
 		return fmt.Errorf("generating secret YAML: %w", err)
 	}
 
-	// 8. Preview (pre-encryption) + confirm
-	fmt.Println(progressStyle.Render("\nSecret YAML (pre-encryption):"))
-	fmt.Println(yamlStyle.Render(string(secretYAML)))
-
-	var confirm bool
-	confirmForm := huh.NewForm(
-		huh.NewGroup(
-			huh.NewConfirm().
-				Title("Encrypt this secret?").
-				Description("The secret will be encrypted with SOPS (age) before saving").
-				Affirmative("Yes, encrypt").
-				Negative("Cancel").
-				Value(&confirm),
-		),
-	)
-
-	if err := confirmForm.Run(); err != nil {
-		return fmt.Errorf("confirmation: %w", err)
+	// 8. Preview (pre-encryption, hidden values masked) + confirm
+	var masked map[string]bool
+	if config.MaskPreview {
+		masked = maskedSecretKeys(tmpl, config.SecretKeyMap)
+	}
+	confirm, err := confirmSecretPreview(secret, masked)
+	if err != nil {
+		return err
 	}
 
 	if !confirm {
@@ -163,11 +177,18 @@ func runEncryptInteractive(config *EncryptConfig) error {
 
 	// 9. Encrypt
 	fmt.Println(progressStyle.Render("Encrypting with SOPS..."))
-	encrypted, err := sops.Encrypt(secretYAML, recipients)
+	encrypted, err := sops.Encrypt(secretYAML, recipients, config.EncryptOptions())
 	if err != nil {
 		return fmt.Errorf("encrypting: %w", err)
 	}
 	fmt.Println(successStyle.Render("Encrypted successfully"))
+	if config.Verify {
+		fmt.Println(progressStyle.Render("Verifying decryption..."))
+		if err := sops.Verify(encrypted, secretYAML); err != nil {
+			return fmt.Errorf("verifying: %w", err)
+		}
+		fmt.Println(successStyle.Render("Verified: the encrypted Secret decrypts to the same content"))
+	}
 
 	// Build result
 	result := &EncryptResult{
@@ -235,21 +256,33 @@ func runEncryptInteractive(config *EncryptConfig) error {
 	}
 	result.OutputPath = outputPath
 	fmt.Println(successStyle.Render(fmt.Sprintf("Saved: %s", outputPath)))
+	outcome.addEncryptResult(*result)
+
+	if config.KSOPS {
+		result.KSOPSFiles, err = wireKSOPS(outputDir, filename)
+		if err != nil {
+			return fmt.Errorf("wiring KSOPS generator: %w", err)
+		}
+	}
 
 	// 12. Update registry
 	updateRegistryForEncrypt(result, outputDir)
 
 	// 13. Git operations
 	if useGit {
-		if config.GitConfig == nil {
-			gitConfig, err := runGitDetailsForm(destChoice.createPR)
+		if config.GitConfig == nil || !config.GitConfig.Commit {
+			gitConfig, err := interactiveGitConfig(config.GitConfig, destChoice.createPR)
 			if err != nil {
 				return fmt.Errorf("git options: %w", err)
 			}
 			config.GitConfig = gitConfig
 
 			if destChoice.createPR {
-				prConfig, err := runPROptionsForm()
+				var existingLabels []string
+				if gitConfig.RepoURL == "" {
+					existingLabels = availablePRLabels(".")
+				}
+				prConfig, err := runPROptionsForm(existingLabels)
 				if err != nil {
 					return fmt.Errorf("PR options: %w", err)
 				}
@@ -265,9 +298,10 @@ func runEncryptInteractive(config *EncryptConfig) error {
 	return nil
 }
 
-// collectSecretValues collects secret values for each template parameter.
-// Hidden parameters use password-mode input.
-func collectSecretValues(tmpl *templates.ClaimTemplate) (map[string]string, error) {
+// collectSecretValues collects secret values for each template parameter,
+// showing the fields in the given --param-order. Hidden parameters use
+// password-mode input.
+func collectSecretValues(tmpl *templates.ClaimTemplate, order string) (map[string]string, error) {
 	if len(tmpl.Spec.Parameters) == 0 {
 		return nil, fmt.Errorf("template has no parameters")
 	}
@@ -276,7 +310,7 @@ func collectSecretValues(tmpl *templates.ClaimTemplate) (map[string]string, erro
 	var formGroups []*huh.Group
 	var currentFields []huh.Field
 
-	for _, p := range tmpl.Spec.Parameters {
+	for _, p := range orderParameters(tmpl.Spec.Parameters, order) {
 		defaultVal := ""
 		if p.Default != nil {
 			defaultVal = fmt.Sprintf("%v", p.Default)
@@ -295,6 +329,7 @@ func collectSecretValues(tmpl *templates.ClaimTemplate) (map[string]string, erro
 		if description == "" {
 			description = fmt.Sprintf("Value for secret key %q", p.Name)
 		}
+		description += exampleHint(p)
 
 		var field huh.Field
 
@@ -321,20 +356,32 @@ func collectSecretValues(tmpl *templates.ClaimTemplate) (map[string]string, erro
 					if p.Required && s == "" {
 						return fmt.Errorf("%s is required", p.Name)
 					}
-					return nil
+					if s == "" {
+						return nil
+					}
+					if err := templates.ValidatePattern(p, s); err != nil {
+						return err
+					}
+					return templates.ValidateRange(p, s)
 				})
 		} else {
 			// Normal parameters use standard input
 			field = huh.NewInput().
 				Title(title).
 				Description(description).
-				Placeholder(fmt.Sprintf("default: %v", p.Default)).
+				Placeholder(fieldPlaceholder(p)).
 				Value(paramValues[p.Name]).
 				Validate(func(s string) error {
 					if p.Required && s == "" {
 						return fmt.Errorf("%s is required", p.Name)
 					}
-					return nil
+					if s == "" {
+						return nil
+					}
+					if err := templates.ValidatePattern(p, s); err != nil {
+						return err
+					}
+					return templates.ValidateRange(p, s)
 				})
 		}
 
@@ -403,6 +450,10 @@ func printEncryptDryRun(result *EncryptResult, config *EncryptConfig) error {
 	fmt.Printf("Would write: %s\n", path)
 	fmt.Printf("  Template:   %s\n", result.TemplateName)
 	fmt.Printf("  Secret:     %s/%s\n", result.SecretNamespace, result.SecretName)
+	if config.KSOPS {
+		fmt.Printf("  KSOPS:      list in %s, add generator to %s\n",
+			filepath.Join(config.OutputDir, sops.KSOPSGeneratorFile), filepath.Join(config.OutputDir, "kustomization.yaml"))
+	}
 	fmt.Println()
 
 	// Show truncated encrypted content
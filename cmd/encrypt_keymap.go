@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parseSecretKeyMap parses --secret-key-map entries (param=key) into a
+// param -> Secret key map. Mapping two params to the same key is an error.
+func parseSecretKeyMap(entries []string) (map[string]string, error) {
+	keyMap := make(map[string]string, len(entries))
+	mappedFrom := make(map[string]string, len(entries))
+
+	for _, e := range entries {
+		param, key, ok := strings.Cut(e, "=")
+		param, key = strings.TrimSpace(param), strings.TrimSpace(key)
+		if !ok || param == "" || key == "" {
+			return nil, fmt.Errorf("invalid --secret-key-map %q (expected param=key)", e)
+		}
+		if prev, exists := keyMap[param]; exists && prev != key {
+			return nil, fmt.Errorf("--secret-key-map maps %s twice (%s and %s)", param, prev, key)
+		}
+		if other, exists := mappedFrom[key]; exists && other != param {
+			return nil, fmt.Errorf("--secret-key-map maps both %s and %s to key %s", other, param, key)
+		}
+		keyMap[param] = key
+		mappedFrom[key] = param
+	}
+
+	return keyMap, nil
+}
+
+// remapSecretKeys renames stringData keys according to keyMap. Unmapped
+// params keep their name. It fails if two params end up with the same key,
+// e.g. a param mapped onto the name of another, unmapped param.
+func remapSecretKeys(stringData, keyMap map[string]string) (map[string]string, error) {
+	if len(keyMap) == 0 {
+		return stringData, nil
+	}
+
+	// Iterate in sorted order so collision errors are deterministic
+	params := make([]string, 0, len(stringData))
+	for p := range stringData {
+		params = append(params, p)
+	}
+	sort.Strings(params)
+
+	result := make(map[string]string, len(stringData))
+	source := make(map[string]string, len(stringData))
+	for _, p := range params {
+		key := p
+		if mapped, ok := keyMap[p]; ok {
+			key = mapped
+		}
+		if other, exists := source[key]; exists {
+			return nil, fmt.Errorf("secret key collision: %s and %s both map to key %s", other, p, key)
+		}
+		result[key] = stringData[p]
+		source[key] = p
+	}
+
+	return result, nil
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSecretKeyMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "single mapping",
+			entries: []string{"dbPassword=POSTGRES_PASSWORD"},
+			want:    map[string]string{"dbPassword": "POSTGRES_PASSWORD"},
+		},
+		{
+			name:    "missing key",
+			entries: []string{"dbPassword="},
+			wantErr: true,
+		},
+		{
+			name:    "missing separator",
+			entries: []string{"dbPassword"},
+			wantErr: true,
+		},
+		{
+			name:    "two params mapped to the same key",
+			entries: []string{"dbPassword=PASSWORD", "adminPassword=PASSWORD"},
+			wantErr: true,
+		},
+		{
+			name:    "param mapped twice",
+			entries: []string{"dbPassword=A", "dbPassword=B"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSecretKeyMap(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSecretKeyMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSecretKeyMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemapSecretKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]string
+		keyMap  map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "mapping",
+			data:   map[string]string{"dbPassword": "s3cret", "dbUser": "app"},
+			keyMap: map[string]string{"dbPassword": "POSTGRES_PASSWORD", "dbUser": "POSTGRES_USER"},
+			want:   map[string]string{"POSTGRES_PASSWORD": "s3cret", "POSTGRES_USER": "app"},
+		},
+		{
+			name:   "partial mapping keeps unmapped names",
+			data:   map[string]string{"dbPassword": "s3cret", "dbUser": "app"},
+			keyMap: map[string]string{"dbPassword": "POSTGRES_PASSWORD"},
+			want:   map[string]string{"POSTGRES_PASSWORD": "s3cret", "dbUser": "app"},
+		},
+		{
+			name:   "mapping for an absent param is ignored",
+			data:   map[string]string{"dbUser": "app"},
+			keyMap: map[string]string{"dbPassword": "POSTGRES_PASSWORD"},
+			want:   map[string]string{"dbUser": "app"},
+		},
+		{
+			name:    "mapped key collides with unmapped param",
+			data:    map[string]string{"dbPassword": "s3cret", "password": "other"},
+			keyMap:  map[string]string{"dbPassword": "password"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := remapSecretKeys(tt.data, tt.keyMap)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("remapSecretKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("remapSecretKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
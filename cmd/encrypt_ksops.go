@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/stuttgart-things/claims/internal/kustomize"
+	"github.com/stuttgart-things/claims/internal/sops"
+)
+
+// wireKSOPS lists encryptedFile in the output directory's KSOPS generator
+// (ksops.yaml) and adds that generator to the directory's kustomization.yaml,
+// creating either file if needed. It returns the paths it wrote.
+func wireKSOPS(outputDir, encryptedFile string) ([]string, error) {
+	generatorPath := filepath.Join(outputDir, sops.KSOPSGeneratorFile)
+	gen, err := sops.LoadKSOPSGenerator(generatorPath)
+	if err != nil {
+		return nil, err
+	}
+	gen.AddFile("./" + filepath.ToSlash(encryptedFile))
+	if err := sops.SaveKSOPSGenerator(generatorPath, gen); err != nil {
+		return nil, err
+	}
+
+	kustomizationPath := filepath.Join(outputDir, "kustomization.yaml")
+	k, err := kustomize.LoadOrNew(kustomizationPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading kustomization: %w", err)
+	}
+	kustomize.AddGenerator(k, sops.KSOPSGeneratorFile)
+	if err := kustomize.Save(kustomizationPath, k); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Updated KSOPS generator: %s\n", generatorPath)
+	return []string{generatorPath, kustomizationPath}, nil
+}
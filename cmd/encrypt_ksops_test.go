@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/kustomize"
+	"github.com/stuttgart-things/claims/internal/sops"
+)
+
+func TestWireKSOPS(t *testing.T) {
+	outputDir := t.TempDir()
+
+	// An existing kustomization keeps its resources
+	kustomizationPath := filepath.Join(outputDir, "kustomization.yaml")
+	existing := "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - app.yaml\n"
+	if err := os.WriteFile(kustomizationPath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{"db-secret.enc.yaml", "api-secret.enc.yaml", "db-secret.enc.yaml"} {
+		written, err := wireKSOPS(outputDir, file)
+		if err != nil {
+			t.Fatalf("wireKSOPS(%s): %v", file, err)
+		}
+		if len(written) != 2 {
+			t.Fatalf("expected generator and kustomization paths, got %v", written)
+		}
+	}
+
+	gen, err := sops.LoadKSOPSGenerator(filepath.Join(outputDir, sops.KSOPSGeneratorFile))
+	if err != nil {
+		t.Fatalf("LoadKSOPSGenerator: %v", err)
+	}
+	if gen.APIVersion != sops.KSOPSAPIVersion || gen.Kind != sops.KSOPSKind {
+		t.Errorf("unexpected generator type %s/%s", gen.APIVersion, gen.Kind)
+	}
+	if got := strings.Join(gen.Files, ","); got != "./db-secret.enc.yaml,./api-secret.enc.yaml" {
+		t.Errorf("generator files = %s", got)
+	}
+	if !strings.Contains(gen.Metadata.Annotations["config.kubernetes.io/function"], "path: ksops") {
+		t.Errorf("expected ksops exec function annotation, got %v", gen.Metadata.Annotations)
+	}
+
+	k, err := kustomize.Load(kustomizationPath)
+	if err != nil {
+		t.Fatalf("kustomize.Load: %v", err)
+	}
+	if strings.Join(k.Generators, ",") != sops.KSOPSGeneratorFile {
+		t.Errorf("kustomization generators = %v", k.Generators)
+	}
+	if strings.Join(k.Resources, ",") != "app.yaml" {
+		t.Errorf("kustomization resources = %v, encrypted files must not be listed as resources", k.Resources)
+	}
+}
+
+func TestWireKSOPS_RejectsOtherManifest(t *testing.T) {
+	outputDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outputDir, sops.KSOPSGeneratorFile), []byte("kind: ConfigMap\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wireKSOPS(outputDir, "db-secret.enc.yaml"); err == nil {
+		t.Error("expected error when ksops.yaml is not a KSOPS generator")
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/stuttgart-things/claims/internal/sops"
+)
+
+// loadSecretForMerge decrypts the Secret at path for --merge-into-secret. The
+// plaintext is only held in memory.
+func loadSecretForMerge(path string) (sops.SecretData, error) {
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return sops.SecretData{}, fmt.Errorf("reading secret to merge into: %w", err)
+	}
+	decrypted, err := sops.Decrypt(encrypted)
+	if err != nil {
+		return sops.SecretData{}, fmt.Errorf("decrypting %s: %w", path, err)
+	}
+	secret, err := sops.ParseSecretYAML(decrypted)
+	if err != nil {
+		return sops.SecretData{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return secret, nil
+}
+
+// mergeTarget returns the name and namespace of the Secret --merge-into-secret
+// writes: those of existing. A --name or --namespace naming another Secret is
+// rejected rather than renaming the Secret in the file.
+func mergeTarget(name, namespace string, existing sops.SecretData) (string, string, error) {
+	if name != "" && name != existing.Name {
+		return "", "", fmt.Errorf("--name %s doesn't match the Secret to merge into (%s)", name, existing.Name)
+	}
+	if namespace != "" && namespace != existing.Namespace {
+		return "", "", fmt.Errorf("--namespace %s doesn't match the Secret to merge into (namespace %s)", namespace, existing.Namespace)
+	}
+	return existing.Name, existing.Namespace, nil
+}
+
+// mergeSecretData adds the keys of added to existing and returns the result;
+// neither map is modified. Keys present in both are only replaced with
+// overwrite, otherwise they are reported as conflicts.
+func mergeSecretData(existing, added map[string]string, overwrite bool) (map[string]string, error) {
+	var conflicts []string
+	merged := make(map[string]string, len(existing)+len(added))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range added {
+		if old, ok := existing[k]; ok && old != v && !overwrite {
+			conflicts = append(conflicts, k)
+			continue
+		}
+		merged[k] = v
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("the secret already has %s (use --overwrite-keys to replace them)", strings.Join(conflicts, ", "))
+	}
+	return merged, nil
+}
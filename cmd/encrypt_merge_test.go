@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/sops"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestMergeSecretData(t *testing.T) {
+	existing := map[string]string{"username": "admin", "password": "old"}
+
+	tests := []struct {
+		name      string
+		added     map[string]string
+		overwrite bool
+		want      map[string]string
+		wantErr   string
+	}{
+		{"new key", map[string]string{"token": "t"}, false, map[string]string{"username": "admin", "password": "old", "token": "t"}, ""},
+		{"same value", map[string]string{"username": "admin"}, false, map[string]string{"username": "admin", "password": "old"}, ""},
+		{"conflict", map[string]string{"password": "new", "username": "root"}, false, nil, "already has password, username"},
+		{"overwrite", map[string]string{"password": "new"}, true, map[string]string{"username": "admin", "password": "new"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeSecretData(existing, tt.added, tt.overwrite)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("mergeSecretData() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mergeSecretData() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeSecretData() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if existing["password"] != "old" || len(existing) != 2 {
+		t.Errorf("existing was modified: %v", existing)
+	}
+}
+
+func TestMergeTarget(t *testing.T) {
+	existing := sops.SecretData{Name: "app-secret", Namespace: "apps"}
+
+	tests := []struct {
+		name, flagName, flagNamespace string
+		wantErr                       string
+	}{
+		{name: "from the secret"},
+		{name: "matching flags", flagName: "app-secret", flagNamespace: "apps"},
+		{name: "other name", flagName: "db-secret", wantErr: "--name db-secret doesn't match the Secret to merge into (app-secret)"},
+		{name: "other namespace", flagNamespace: "prod", wantErr: "--namespace prod doesn't match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, namespace, err := mergeTarget(tt.flagName, tt.flagNamespace, existing)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("mergeTarget() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || name != "app-secret" || namespace != "apps" {
+				t.Errorf("mergeTarget() = %q, %q, %v", name, namespace, err)
+			}
+		})
+	}
+}
+
+func TestRunEncryptNonInteractive_MergeIntoSecret(t *testing.T) {
+	if !sops.CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+	if _, err := exec.LookPath("age-keygen"); err != nil {
+		t.Skip("age-keygen not installed, skipping integration test")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if out, err := exec.Command("age-keygen", "-o", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("age-keygen: %v: %s", err, out)
+	}
+	pub, err := exec.Command("age-keygen", "-y", keyFile).Output()
+	if err != nil {
+		t.Fatalf("age-keygen -y: %v", err)
+	}
+	t.Setenv("SOPS_AGE_RECIPIENTS", strings.TrimSpace(string(pub)))
+	t.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+	t.Setenv("SOPS_AGE_KEY", "")
+
+	// The existing Secret
+	plaintext, err := sops.GenerateSecretYAML(sops.SecretData{
+		Name:        "app-secret",
+		Namespace:   "apps",
+		Type:        "kubernetes.io/basic-auth",
+		Labels:      map[string]string{"app": "web"},
+		Annotations: map[string]string{"owner": "team-a"},
+		StringData:  map[string]string{"username": "admin", "password": "old"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := sops.Encrypt(plaintext, sops.Recipients{Age: strings.TrimSpace(string(pub))}, sops.EncryptOptions{})
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "app-secret.enc.yaml")
+	if err := os.WriteFile(path, encrypted, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("app")})
+	run := func(inline []string, overwrite bool) error {
+		return runEncryptNonInteractive(context.Background(), &EncryptConfig{
+			APIUrl:          api.URL,
+			NoCache:         true,
+			Template:        "app",
+			InlineParamsRaw: inline,
+			MergeInto:       path,
+			OverwriteKeys:   overwrite,
+			FilenamePattern: "{{.name}}-secret.enc.yaml",
+		})
+	}
+
+	if err := run([]string{"token=t0ken"}, false); err != nil {
+		t.Fatalf("merging a new key: %v", err)
+	}
+	secret, err := loadSecretForMerge(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sops.SecretData{
+		Name:        "app-secret",
+		Namespace:   "apps",
+		Type:        "kubernetes.io/basic-auth",
+		Labels:      map[string]string{"app": "web"},
+		Annotations: map[string]string{"owner": "team-a"},
+		StringData:  map[string]string{"username": "admin", "password": "old", "token": "t0ken"},
+	}
+	if !reflect.DeepEqual(secret, want) {
+		t.Errorf("merged secret = %+v, want %+v", secret, want)
+	}
+
+	if err := run([]string{"password=new"}, false); err == nil || !strings.Contains(err.Error(), "--overwrite-keys") {
+		t.Errorf("expected a conflict without --overwrite-keys, got %v", err)
+	}
+	err = runEncryptNonInteractive(context.Background(), &EncryptConfig{
+		APIUrl:          api.URL,
+		NoCache:         true,
+		Template:        "app",
+		InlineParamsRaw: []string{"extra=x"},
+		MergeInto:       path,
+		SecretName:      "other-secret",
+		FilenamePattern: "{{.name}}-secret.enc.yaml",
+	})
+	if err == nil || !strings.Contains(err.Error(), "doesn't match the Secret to merge into") {
+		t.Errorf("expected a name mismatch error, got %v", err)
+	}
+	if err := run([]string{"password=new"}, true); err != nil {
+		t.Fatalf("overwriting a key: %v", err)
+	}
+	if secret, err = loadSecretForMerge(path); err != nil || secret.StringData["password"] != "new" {
+		t.Errorf("expected the password to be replaced, got %+v, %v", secret, err)
+	}
+}
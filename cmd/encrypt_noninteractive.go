@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,15 +13,16 @@ import (
 )
 
 // runEncryptNonInteractive runs the encrypt command in non-interactive mode
-func runEncryptNonInteractive(config *EncryptConfig) error {
+func runEncryptNonInteractive(ctx context.Context, config *EncryptConfig) error {
 	// Validate required inputs
 	if config.Template == "" {
 		return fmt.Errorf("--template is required in non-interactive mode")
 	}
-	if config.SecretName == "" {
+	// Merging keeps the name and namespace of the existing Secret
+	if config.SecretName == "" && config.MergeInto == "" {
 		return fmt.Errorf("--name is required in non-interactive mode")
 	}
-	if config.SecretNamespace == "" {
+	if config.SecretNamespace == "" && config.MergeInto == "" {
 		return fmt.Errorf("--namespace is required in non-interactive mode")
 	}
 	if config.ParamsFile == "" && len(config.InlineParamsRaw) == 0 {
@@ -28,36 +31,43 @@ func runEncryptNonInteractive(config *EncryptConfig) error {
 
 	// Check SOPS prerequisites
 	fmt.Println("Checking SOPS prerequisites...")
-	recipients, err := sops.CheckSOPSAvailable()
+	recipients, err := sops.CheckSOPSAvailable(config.SOPSKeys())
 	if err != nil {
 		return fmt.Errorf("SOPS prerequisites: %w", err)
 	}
-	fmt.Println("SOPS available (age encryption)")
+	fmt.Printf("SOPS available (%s encryption)\n", recipients.Backends())
+	if config.Verify {
+		if err := sops.CheckDecryptKey(recipients); err != nil {
+			return fmt.Errorf("--verify: %w", err)
+		}
+	}
+	if config.MergeInto != "" {
+		if err := sops.CheckDecryptKey(recipients); err != nil {
+			return fmt.Errorf("--merge-into-secret: %w", err)
+		}
+	}
 
 	// Fetch templates to validate
 	fmt.Printf("Connecting to API: %s\n", config.APIUrl)
-	client := templates.NewClient(config.APIUrl)
-	available, err := client.FetchTemplates()
+	client, err := newCatalogClient(config.APIUrl, "", config.APIToken, config.CACert, config.SkipTLSVerify)
 	if err != nil {
-		return fmt.Errorf("fetching templates: %w", err)
+		return err
 	}
-
-	var tmpl *templates.ClaimTemplate
-	for i, t := range available {
-		if t.Metadata.Name == config.Template {
-			tmpl = &available[i]
-			break
-		}
+	fetchCtx, stop := signalContext(ctx)
+	_, err = fetchTemplate(fetchCtx, client, config.Template, config.NoCache)
+	stop()
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		return err
 	}
-	if tmpl == nil {
-		return fmt.Errorf("template not found: %s", config.Template)
+	if err != nil {
+		return fmt.Errorf("fetching templates: %w", err)
 	}
 
 	// Parse parameters
 	var mergedParams map[string]any
 
 	if config.ParamsFile != "" {
-		pf, err := params.ParseFile(config.ParamsFile)
+		pf, err := params.ParseFileWith(config.ParamsFile, paramsParseOptions(config.ExpandEnv, config.StrictEnv))
 		if err != nil {
 			return fmt.Errorf("parsing params file: %w", err)
 		}
@@ -73,11 +83,16 @@ func runEncryptNonInteractive(config *EncryptConfig) error {
 		mergedParams = make(map[string]any)
 	}
 
-	// Parse and merge inline params
-	inlineP, err := params.ParseInlineParams(config.InlineParamsRaw)
+	// Parse and merge inline params; these are secret values, so they are
+	// taken literally (no key-/key=null unsetting)
+	inlineSecrets, err := params.ParseInlineSecrets(config.InlineParamsRaw)
 	if err != nil {
 		return fmt.Errorf("parsing inline params: %w", err)
 	}
+	inlineP := make(map[string]any, len(inlineSecrets))
+	for k, v := range inlineSecrets {
+		inlineP[k] = v
+	}
 	mergedParams = params.MergeParams(mergedParams, inlineP)
 
 	// Build stringData from params
@@ -90,24 +105,52 @@ func runEncryptNonInteractive(config *EncryptConfig) error {
 		return fmt.Errorf("no secret values provided")
 	}
 
+	stringData, err = remapSecretKeys(stringData, config.SecretKeyMap)
+	if err != nil {
+		return err
+	}
+
+	var secret sops.SecretData
+	if config.MergeInto != "" {
+		fmt.Printf("Merging into %s...\n", config.MergeInto)
+		existing, err := loadSecretForMerge(config.MergeInto)
+		if err != nil {
+			return err
+		}
+		if config.SecretName, config.SecretNamespace, err = mergeTarget(config.SecretName, config.SecretNamespace, existing); err != nil {
+			return err
+		}
+		if stringData, err = mergeSecretData(existing.StringData, stringData, config.OverwriteKeys); err != nil {
+			return err
+		}
+		// Only the keys change; the type, labels and annotations are kept
+		secret = existing
+	}
+	secret.Name = config.SecretName
+	secret.Namespace = config.SecretNamespace
+	secret.StringData = stringData
+
 	// Generate Secret YAML
 	fmt.Println("Generating Kubernetes Secret YAML...")
-	secretYAML, err := sops.GenerateSecretYAML(sops.SecretData{
-		Name:       config.SecretName,
-		Namespace:  config.SecretNamespace,
-		StringData: stringData,
-	})
+	secretYAML, err := sops.GenerateSecretYAML(secret)
 	if err != nil {
 		return fmt.Errorf("generating secret YAML: %w", err)
 	}
 
 	// Encrypt
 	fmt.Println("Encrypting with SOPS...")
-	encrypted, err := sops.Encrypt(secretYAML, recipients)
+	encrypted, err := sops.Encrypt(secretYAML, recipients, config.EncryptOptions())
 	if err != nil {
 		return fmt.Errorf("encrypting: %w", err)
 	}
 	fmt.Println("Encrypted successfully")
+	if config.Verify {
+		fmt.Println("Verifying decryption...")
+		if err := sops.Verify(encrypted, secretYAML); err != nil {
+			return fmt.Errorf("verifying: %w", err)
+		}
+		fmt.Println("Verified: the encrypted Secret decrypts to the same content")
+	}
 
 	result := &EncryptResult{
 		TemplateName:    config.Template,
@@ -121,11 +164,17 @@ func runEncryptNonInteractive(config *EncryptConfig) error {
 		return printEncryptDryRun(result, config)
 	}
 
-	// Write encrypted file
+	// Write encrypted file; a merged Secret replaces the file it was read from
 	filename, err := generateEncryptFilename(config.FilenamePattern, config.SecretName, config.Template)
 	if err != nil {
 		return fmt.Errorf("generating filename: %w", err)
 	}
+	if config.MergeInto != "" {
+		config.OutputDir, filename = filepath.Split(config.MergeInto)
+		if config.OutputDir == "" {
+			config.OutputDir = "."
+		}
+	}
 
 	if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
@@ -137,6 +186,14 @@ func runEncryptNonInteractive(config *EncryptConfig) error {
 	}
 	result.OutputPath = outputPath
 	fmt.Printf("Saved: %s\n", outputPath)
+	outcome.addEncryptResult(*result)
+
+	if config.KSOPS {
+		result.KSOPSFiles, err = wireKSOPS(config.OutputDir, filename)
+		if err != nil {
+			return fmt.Errorf("wiring KSOPS generator: %w", err)
+		}
+	}
 
 	// Update registry
 	updateRegistryForEncrypt(result, config.OutputDir)
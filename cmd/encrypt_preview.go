@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	"github.com/stuttgart-things/claims/internal/sops"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// maskedValue replaces a secret value in the pre-encryption preview
+const maskedValue = "****"
+
+// maskedSecretKeys returns the Secret keys holding the values of the
+// template's hidden parameters, after --secret-key-map renaming
+func maskedSecretKeys(tmpl *templates.ClaimTemplate, keyMap map[string]string) map[string]bool {
+	masked := make(map[string]bool)
+	for _, p := range tmpl.Spec.Parameters {
+		if !p.Hidden {
+			continue
+		}
+		key := p.Name
+		if mapped, ok := keyMap[p.Name]; ok {
+			key = mapped
+		}
+		masked[key] = true
+	}
+	return masked
+}
+
+// secretPreview generates the Secret YAML shown before encryption, with the
+// values of the masked keys replaced by maskedValue. Only the preview is
+// masked; the real values are encrypted.
+func secretPreview(secret sops.SecretData, masked map[string]bool) ([]byte, error) {
+	shown := make(map[string]string, len(secret.StringData))
+	for k, v := range secret.StringData {
+		if masked[k] {
+			v = maskedValue
+		}
+		shown[k] = v
+	}
+	secret.StringData = shown
+	return sops.GenerateSecretYAML(secret)
+}
+
+// confirmSecretPreview shows the Secret before encryption and asks whether to
+// encrypt it. Masked values stay hidden unless the user chooses to show them.
+func confirmSecretPreview(secret sops.SecretData, masked map[string]bool) (bool, error) {
+	reveal := len(masked) == 0
+	for {
+		shownMask := masked
+		if reveal {
+			shownMask = nil
+		}
+		preview, err := secretPreview(secret, shownMask)
+		if err != nil {
+			return false, fmt.Errorf("generating secret YAML: %w", err)
+		}
+		fmt.Println(progressStyle.Render("\nSecret YAML (pre-encryption):"))
+		fmt.Println(yamlStyle.Render(string(preview)))
+
+		if len(masked) == 0 {
+			var confirm bool
+			confirmForm := huh.NewForm(
+				huh.NewGroup(
+					huh.NewConfirm().
+						Title("Encrypt this secret?").
+						Description("The secret will be encrypted with SOPS (age) before saving").
+						Affirmative("Yes, encrypt").
+						Negative("Cancel").
+						Value(&confirm),
+				),
+			)
+			if err := confirmForm.Run(); err != nil {
+				return false, fmt.Errorf("confirmation: %w", err)
+			}
+			return confirm, nil
+		}
+
+		toggle := "Show hidden values"
+		if reveal {
+			toggle = "Hide values"
+		}
+		var action string
+		actionForm := huh.NewForm(
+			huh.NewGroup(
+				huh.NewSelect[string]().
+					Title("Encrypt this secret?").
+					Description("The secret will be encrypted with SOPS (age) before saving; hidden values are encrypted as entered").
+					Options(
+						huh.NewOption("Yes, encrypt", "encrypt"),
+						huh.NewOption(toggle, "toggle"),
+						huh.NewOption("Cancel", "cancel"),
+					).
+					Value(&action),
+			),
+		)
+		if err := actionForm.Run(); err != nil {
+			return false, fmt.Errorf("confirmation: %w", err)
+		}
+
+		switch action {
+		case "encrypt":
+			return true, nil
+		case "toggle":
+			reveal = !reveal
+		default:
+			return false, nil
+		}
+	}
+}
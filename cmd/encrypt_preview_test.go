@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/sops"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestMaskedSecretKeys(t *testing.T) {
+	tmpl := &templates.ClaimTemplate{
+		Spec: templates.ClaimTemplateSpec{
+			Parameters: []templates.Parameter{
+				{Name: "username"},
+				{Name: "password", Hidden: true},
+				{Name: "apiKey", Hidden: true},
+			},
+		},
+	}
+
+	got := maskedSecretKeys(tmpl, map[string]string{"password": "DB_PASSWORD"})
+	want := map[string]bool{"DB_PASSWORD": true, "apiKey": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("masked keys = %v, want %v", got, want)
+	}
+}
+
+func TestSecretPreview(t *testing.T) {
+	secret := sops.SecretData{
+		Name:      "db-credentials",
+		Namespace: "apps",
+		StringData: map[string]string{
+			"username":    "admin",
+			"DB_PASSWORD": "s3cr3t-value",
+		},
+	}
+
+	preview, err := secretPreview(secret, map[string]bool{"DB_PASSWORD": true})
+	if err != nil {
+		t.Fatalf("secretPreview: %v", err)
+	}
+	out := string(preview)
+	if strings.Contains(out, "s3cr3t-value") {
+		t.Errorf("expected the hidden value to be masked:\n%s", out)
+	}
+	for _, want := range []string{"DB_PASSWORD: '****'", "username: admin", "name: db-credentials"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in preview:\n%s", want, out)
+		}
+	}
+	if secret.StringData["DB_PASSWORD"] != "s3cr3t-value" {
+		t.Error("expected the real value to be left untouched for encryption")
+	}
+
+	revealed, err := secretPreview(secret, nil)
+	if err != nil {
+		t.Fatalf("secretPreview: %v", err)
+	}
+	if !strings.Contains(string(revealed), "s3cr3t-value") {
+		t.Errorf("expected the value without masking:\n%s", revealed)
+	}
+}
@@ -1,9 +1,16 @@
 package cmd
 
+import "github.com/stuttgart-things/claims/internal/sops"
+
 // EncryptConfig holds configuration for the encrypt command
 type EncryptConfig struct {
 	// API configuration
-	APIUrl string
+	APIUrl   string
+	APIToken string // bearer token for the API; "" = unauthenticated
+	NoCache  bool   // always fetch the catalog from the API instead of reusing a fresh cache
+
+	CACert        string // PEM CA bundle trusted for an HTTPS API, in addition to the system roots
+	SkipTLSVerify bool   // don't verify the API's TLS certificate
 
 	// Template selection
 	Template string
@@ -14,15 +21,35 @@ type EncryptConfig struct {
 
 	// Parameter input
 	ParamsFile      string
+	ExpandEnv       bool // expand environment variables in the params file before parsing
+	StrictEnv       bool // fail on unset environment variables without a default (implies ExpandEnv)
 	InlineParamsRaw []string
 
+	// SecretKeyMap renames params to Secret stringData keys (param -> key)
+	SecretKeyMap map[string]string
+
+	// Partial encryption (passed through to sops)
+	EncryptedRegex   string
+	UnencryptedRegex string
+
+	// Cloud KMS keys (passed through to sops, in addition to age/PGP from the environment)
+	KMS     string
+	GCPKMS  string
+	AzureKV string
+
 	// Output configuration
 	OutputDir       string
 	FilenamePattern string
 	DryRun          bool
+	Verify          bool   // decrypt the encrypted Secret and compare it with the plaintext before writing
+	MergeInto       string // existing encrypted Secret the keys are added to and written back to
+	OverwriteKeys   bool   // with MergeInto, replace keys the Secret already has
+	KSOPS           bool   // also wire the file into a KSOPS generator and kustomization.yaml
 
 	// Mode control
 	Interactive bool
+	ParamOrder  string // form field order: ParamOrderDeclared (default), ParamOrderRequiredFirst or ParamOrderAlphabetical
+	MaskPreview bool   // mask the values of hidden parameters in the pre-encryption preview
 
 	// Git configuration
 	GitConfig *GitConfig
@@ -31,6 +58,24 @@ type EncryptConfig struct {
 	PRConfig *PRConfig
 }
 
+// EncryptOptions returns the sops options for this configuration
+func (c *EncryptConfig) EncryptOptions() sops.EncryptOptions {
+	return sops.EncryptOptions{
+		EncryptedRegex:   c.EncryptedRegex,
+		UnencryptedRegex: c.UnencryptedRegex,
+	}
+}
+
+// SOPSKeys returns the keys given by flags; CheckSOPSAvailable adds age and
+// PGP keys from the environment
+func (c *EncryptConfig) SOPSKeys() sops.Recipients {
+	return sops.Recipients{
+		KMS:     c.KMS,
+		GCPKMS:  c.GCPKMS,
+		AzureKV: c.AzureKV,
+	}
+}
+
 // EncryptResult holds the result of encrypting a single secret
 type EncryptResult struct {
 	TemplateName    string
@@ -39,4 +84,7 @@ type EncryptResult struct {
 	OutputPath      string
 	Content         string
 	Error           error
+
+	// KSOPSFiles are the generator and kustomization files written by --ksops
+	KSOPSFiles []string
 }
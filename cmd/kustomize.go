@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/kustomize"
+)
+
+var (
+	kustomizeDir   string
+	kustomizePrune bool
+)
+
+var kustomizeCmd = &cobra.Command{
+	Use:   "kustomize",
+	Short: "Maintain the claims kustomizations",
+	Long:  `Checks and repairs the kustomization.yaml files of the claim categories.`,
+}
+
+var kustomizeCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Find kustomization resources that no longer exist",
+	Long:  `Loads every claims/<category>/kustomization.yaml and reports resource entries pointing at files or directories that don't exist, which break 'kustomize build'. With --prune the dangling entries are removed. Remote resources are not checked.`,
+	Args:  cobra.NoArgs,
+	Run:   runKustomizeCheck,
+}
+
+func init() {
+	kustomizeCheckCmd.Flags().StringVar(&kustomizeDir, "dir", ".", "Repository (or directory) containing claims/")
+	kustomizeCheckCmd.Flags().BoolVar(&kustomizePrune, "prune", false, "Remove dangling resource entries instead of only reporting them")
+
+	kustomizeCmd.AddCommand(kustomizeCheckCmd)
+	rootCmd.AddCommand(kustomizeCmd)
+}
+
+// danglingKustomization lists the dangling resources of one kustomization.yaml
+type danglingKustomization struct {
+	Path      string
+	Resources []string
+}
+
+func runKustomizeCheck(cmd *cobra.Command, args []string) {
+	dir := kustomizeDir
+	if root, err := findRepoRoot(dir); err == nil {
+		dir = root
+	}
+
+	found, err := checkKustomizations(dir, kustomizePrune)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+
+	printDanglingResources(os.Stdout, found, kustomizePrune)
+	if len(found) > 0 && !kustomizePrune {
+		os.Exit(1)
+	}
+}
+
+// checkKustomizations finds the dangling resources of every
+// claims/<category>/kustomization.yaml under dir, removing them if prune is set
+func checkKustomizations(dir string, prune bool) ([]danglingKustomization, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "claims", "*", "kustomization.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []danglingKustomization
+	for _, path := range paths {
+		k, err := kustomize.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		dangling := kustomize.DanglingResources(filepath.Dir(path), k)
+		if len(dangling) == 0 {
+			continue
+		}
+
+		if prune {
+			for _, r := range dangling {
+				if err := kustomize.RemoveResource(k, r); err != nil {
+					return nil, err
+				}
+			}
+			if err := kustomize.Save(path, k); err != nil {
+				return nil, err
+			}
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		found = append(found, danglingKustomization{Path: rel, Resources: dangling})
+	}
+	return found, nil
+}
+
+// printDanglingResources reports the dangling resources per kustomization
+func printDanglingResources(out io.Writer, found []danglingKustomization, pruned bool) {
+	if len(found) == 0 {
+		fmt.Fprintln(out, "All kustomization resources exist.")
+		return
+	}
+
+	verb := "missing"
+	if pruned {
+		verb = "removed"
+	}
+	total := 0
+	for _, f := range found {
+		fmt.Fprintf(out, "%s:\n", f.Path)
+		for _, r := range f.Resources {
+			fmt.Fprintf(out, "  %s: %s\n", verb, r)
+		}
+		total += len(f.Resources)
+	}
+
+	if pruned {
+		fmt.Fprintf(out, "\nRemoved %s from %s.\n", plural(total, "dangling resource"), plural(len(found), "kustomization"))
+	} else {
+		fmt.Fprintf(out, "\n%s in %s; run with --prune to remove them.\n", plural(total, "dangling resource"), plural(len(found), "kustomization"))
+	}
+}
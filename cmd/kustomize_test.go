@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/kustomize"
+)
+
+func TestCheckKustomizations(t *testing.T) {
+	setup := func(t *testing.T) string {
+		t.Helper()
+		dir := t.TempDir()
+		infra := filepath.Join(dir, "claims", "infra")
+		apps := filepath.Join(dir, "claims", "apps")
+		for _, d := range []string{filepath.Join(infra, "web"), filepath.Join(apps, "shop")} {
+			if err := os.MkdirAll(d, 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		files := map[string]string{
+			filepath.Join(infra, "kustomization.yaml"): "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n  - web\n  # deleted by hand\n  - removed-vm\n  - gone-secret.enc.yaml\n",
+			filepath.Join(apps, "kustomization.yaml"):  "resources:\n  - shop\n",
+		}
+		for path, content := range files {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dir
+	}
+
+	t.Run("reports dangling resources", func(t *testing.T) {
+		dir := setup(t)
+		path := filepath.Join(dir, "claims", "infra", "kustomization.yaml")
+		before, _ := os.ReadFile(path)
+
+		found, err := checkKustomizations(dir, false)
+		if err != nil {
+			t.Fatalf("checkKustomizations: %v", err)
+		}
+		want := []danglingKustomization{{
+			Path:      filepath.Join("claims", "infra", "kustomization.yaml"),
+			Resources: []string{"removed-vm", "gone-secret.enc.yaml"},
+		}}
+		if !reflect.DeepEqual(found, want) {
+			t.Errorf("found = %+v, want %+v", found, want)
+		}
+
+		after, _ := os.ReadFile(path)
+		if !bytes.Equal(before, after) {
+			t.Error("expected the kustomization to be left alone without --prune")
+		}
+
+		var out bytes.Buffer
+		printDanglingResources(&out, found, false)
+		if !strings.Contains(out.String(), "missing: removed-vm") || !strings.Contains(out.String(), "2 dangling resources in 1 kustomization; run with --prune") {
+			t.Errorf("unexpected report:\n%s", out.String())
+		}
+	})
+
+	t.Run("prunes dangling resources", func(t *testing.T) {
+		dir := setup(t)
+		path := filepath.Join(dir, "claims", "infra", "kustomization.yaml")
+
+		if _, err := checkKustomizations(dir, true); err != nil {
+			t.Fatalf("checkKustomizations: %v", err)
+		}
+
+		k, err := kustomize.Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(k.Resources, []string{"web"}) {
+			t.Errorf("expected only web to remain, got %v", k.Resources)
+		}
+
+		found, err := checkKustomizations(dir, false)
+		if err != nil {
+			t.Fatalf("checkKustomizations: %v", err)
+		}
+		if len(found) != 0 {
+			t.Errorf("expected no dangling resources after pruning, got %+v", found)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -12,50 +13,71 @@ import (
 )
 
 var (
-	listRegistryPath string
-	listCategory     string
-	listTemplate     string
-	listOutput       string
+	listRegistryPaths []string
+	listCategory      string
+	listTemplate      string
+	listSource        string
+	listOutput        string
 )
 
+// listEntry is a registry entry tagged with the registry it was loaded from
+type listEntry struct {
+	registry.ClaimEntry
+	Registry string
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List claims from registry",
-	Long:  `Lists all claims registered in claims/registry.yaml, with optional filtering by category or template.`,
+	Long:  `Lists all claims in the repository's registry, with optional filtering by category, template or source. Without --registry-path the registry is discovered at claims/registry.yaml, registry.yaml or .claims/registry.yaml. Pass --registry-path multiple times to list claims across several registries.`,
 	Run:   runList,
 }
 
 func init() {
-	listCmd.Flags().StringVar(&listRegistryPath, "registry-path", "claims/registry.yaml", "Path to registry.yaml")
+	listCmd.Flags().StringSliceVar(&listRegistryPaths, "registry-path", nil, "Path(s) to registry.yaml (comma-separated or repeated; default: discovered in the repo)")
 	listCmd.Flags().StringVar(&listCategory, "category", "", "Filter by category")
 	listCmd.Flags().StringVar(&listTemplate, "template", "", "Filter by template")
+	listCmd.Flags().StringVar(&listSource, "source", "", "Filter by source (e.g. cli, ci, cli-encrypt)")
 	listCmd.Flags().StringVarP(&listOutput, "output", "o", "table", "Output format (table, json)")
 
 	rootCmd.AddCommand(listCmd)
 }
 
 func runList(cmd *cobra.Command, args []string) {
-	// Resolve registry path
-	registryPath := listRegistryPath
-
-	// If not absolute, try relative to repo root
-	if !filepath.IsAbs(registryPath) {
-		cwd, err := os.Getwd()
-		if err == nil {
-			repoRoot, err := findRepoRoot(cwd)
-			if err == nil {
-				registryPath = filepath.Join(repoRoot, registryPath)
-			}
+	sel := registry.Selector{Category: listCategory, Template: listTemplate, Source: listSource}
+	if len(listRegistryPaths) > 1 {
+		entries, err := loadListEntries(listRegistryPaths, sel)
+		if err != nil {
+			fmt.Println(renderError(fmt.Sprintf("Error loading registry: %v", err)))
+			os.Exit(1)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No claims found.")
+			return
+		}
+
+		switch listOutput {
+		case "json":
+			printJSON(entries)
+		default:
+			printRegistryTable(entries)
 		}
+		return
 	}
 
+	registryPath, err := defaultListRegistryPath(listRegistryPaths)
+	if err != nil {
+		fmt.Println(renderError(fmt.Sprintf("Error loading registry: %v", err)))
+		os.Exit(1)
+	}
 	reg, err := registry.Load(registryPath)
 	if err != nil {
-		fmt.Println(errorStyle.Render(fmt.Sprintf("Error loading registry: %v", err)))
+		fmt.Println(renderError(fmt.Sprintf("Error loading registry: %v", err)))
 		os.Exit(1)
 	}
 
-	entries := registry.FilterEntries(reg, listCategory, listTemplate)
+	entries := registry.Select(reg, sel)
 
 	if len(entries) == 0 {
 		fmt.Println("No claims found.")
@@ -70,6 +92,67 @@ func runList(cmd *cobra.Command, args []string) {
 	}
 }
 
+// defaultListRegistryPath returns the single registry to list: the given
+// --registry-path, or the registry discovered in the current repository
+func defaultListRegistryPath(paths []string) (string, error) {
+	if len(paths) > 0 {
+		return resolveListRegistryPath(paths[0]), nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	repoRoot, err := findRepoRoot(cwd)
+	if err != nil {
+		// Outside a repo, keep looking relative to the working directory
+		repoRoot = cwd
+	}
+	return registry.Discover(repoRoot)
+}
+
+// resolveListRegistryPath resolves a relative registry path against the repo root
+func resolveListRegistryPath(registryPath string) string {
+	if filepath.IsAbs(registryPath) {
+		return registryPath
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return registryPath
+	}
+	repoRoot, err := findRepoRoot(cwd)
+	if err != nil {
+		return registryPath
+	}
+	return filepath.Join(repoRoot, registryPath)
+}
+
+// loadListEntries loads and filters several registries into one view.
+// Entries are tagged with the registry path they came from and sorted by name, then registry.
+// Names may legitimately repeat across registries, so no dedup is done.
+func loadListEntries(paths []string, sel registry.Selector) ([]listEntry, error) {
+	var entries []listEntry
+	for _, p := range paths {
+		reg, err := registry.Load(resolveListRegistryPath(p))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		for _, e := range registry.Select(reg, sel) {
+			entries = append(entries, listEntry{ClaimEntry: e, Registry: p})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Registry < entries[j].Registry
+	})
+
+	return entries, nil
+}
+
 func printTable(entries []registry.ClaimEntry) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintln(w, "NAME\tTEMPLATE\tCATEGORY\tNAMESPACE\tSTATUS\tCREATED BY\tSOURCE")
@@ -83,10 +166,24 @@ func printTable(entries []registry.ClaimEntry) {
 	w.Flush()
 }
 
-func printJSON(entries []registry.ClaimEntry) {
+// printRegistryTable prints entries from multiple registries with a REGISTRY column
+func printRegistryTable(entries []listEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTEMPLATE\tCATEGORY\tNAMESPACE\tSTATUS\tCREATED BY\tSOURCE\tREGISTRY")
+	fmt.Fprintln(w, "----\t--------\t--------\t---------\t------\t----------\t------\t--------")
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			e.Name, e.Template, e.Category, e.Namespace, e.Status, e.CreatedBy, e.Source, e.Registry)
+	}
+
+	w.Flush()
+}
+
+func printJSON(entries any) {
 	data, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
-		fmt.Println(errorStyle.Render(fmt.Sprintf("Error marshalling JSON: %v", err)))
+		fmt.Println(renderError(fmt.Sprintf("Error marshalling JSON: %v", err)))
 		os.Exit(1)
 	}
 	fmt.Println(string(data))
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -131,3 +132,77 @@ func TestPrintJSON(t *testing.T) {
 		t.Errorf("expected second entry namespace production, got %s", parsed[1].Namespace)
 	}
 }
+
+func TestLoadListEntries_MultipleRegistries(t *testing.T) {
+	dir := t.TempDir()
+
+	regA := registry.NewRegistry()
+	registry.AddEntry(regA, registry.ClaimEntry{Name: "my-vm", Template: "vsphere-vm", Category: "infra"})
+	registry.AddEntry(regA, registry.ClaimEntry{Name: "app-pvc", Template: "volumeclaim", Category: "apps"})
+	pathA := filepath.Join(dir, "repo-a.yaml")
+	if err := registry.Save(pathA, regA); err != nil {
+		t.Fatal(err)
+	}
+
+	regB := registry.NewRegistry()
+	registry.AddEntry(regB, registry.ClaimEntry{Name: "my-vm", Template: "vsphere-vm", Category: "infra"})
+	pathB := filepath.Join(dir, "repo-b.yaml")
+	if err := registry.Save(pathB, regB); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := loadListEntries([]string{pathB, pathA}, registry.Selector{})
+	if err != nil {
+		t.Fatalf("loadListEntries: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries (no dedup across registries), got %d", len(entries))
+	}
+
+	want := []struct{ name, registry string }{
+		{"app-pvc", pathA},
+		{"my-vm", pathA},
+		{"my-vm", pathB},
+	}
+	for i, w := range want {
+		if entries[i].Name != w.name || entries[i].Registry != w.registry {
+			t.Errorf("entry %d: expected %s from %s, got %s from %s", i, w.name, w.registry, entries[i].Name, entries[i].Registry)
+		}
+	}
+
+	// Filters apply across all registries
+	filtered, err := loadListEntries([]string{pathA, pathB}, registry.Selector{Category: "infra"})
+	if err != nil {
+		t.Fatalf("loadListEntries: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Errorf("expected 2 infra entries, got %d", len(filtered))
+	}
+
+	// Table output includes the REGISTRY column with source tags
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	printRegistryTable(entries)
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	for _, s := range []string{"REGISTRY", pathA, pathB} {
+		if !strings.Contains(output, s) {
+			t.Errorf("table output should contain %q", s)
+		}
+	}
+}
+
+func TestLoadListEntries_MissingRegistry(t *testing.T) {
+	if _, err := loadListEntries([]string{"/nonexistent/registry.yaml"}, registry.Selector{}); err == nil {
+		t.Fatal("expected error for missing registry")
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/notify"
+	"github.com/stuttgart-things/claims/internal/redact"
+)
+
+// Webhook flags, shared by render, delete and encrypt
+var (
+	webhookURL     string
+	webhookHeaders []string
+	webhookStrict  bool
+)
+
+// addWebhookFlags registers the --webhook flags on cmd
+func addWebhookFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&webhookURL, "webhook", "", "POST a JSON summary of the operation (claims, paths, commit, PR, success) to this URL when it completes")
+	cmd.Flags().StringArrayVar(&webhookHeaders, "webhook-header", nil, "Header sent with the webhook (key=value, repeatable), e.g. Authorization=Bearer <token>")
+	cmd.Flags().BoolVar(&webhookStrict, "webhook-strict", false, "Fail the command when the webhook can't be delivered (default: warn)")
+}
+
+// newWebhook returns the webhook configured by the flags, or nil without --webhook
+func newWebhook() (*notify.Webhook, error) {
+	if webhookURL == "" {
+		return nil, nil
+	}
+	headers, err := notify.ParseHeaders(webhookHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewWebhook(webhookURL, headers), nil
+}
+
+// notifyWebhook posts the outcome of command to webhook (if set). A failed
+// delivery is reported as a warning, or returned with --webhook-strict.
+func notifyWebhook(webhook *notify.Webhook, command string, runErr error) error {
+	if webhook == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notify.DefaultTimeout)
+	defer cancel()
+	if err := webhook.Send(ctx, outcome.Event(command, runErr)); err != nil {
+		if webhookStrict {
+			return fmt.Errorf("delivering webhook: %w", err)
+		}
+		warnf("delivering webhook: %v", err)
+	}
+	return nil
+}
+
+// outcome collects what the running command did, for the --webhook event
+var outcome outcomeCollector
+
+// outcomeCollector records templates, claims, paths, commit and PR of a
+// command run; safe for concurrent use
+type outcomeCollector struct {
+	mu    sync.Mutex
+	event notify.Event
+
+	// results and branch are only reported by --summary-json
+	results []RenderResult
+	branch  string
+}
+
+// addClaim records a claim rendered from template (may be "") and written to
+// path (may be ""); duplicates are skipped
+func (o *outcomeCollector) addClaim(template, claim, path string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	appendNew := func(list []string, value string) []string {
+		if value == "" || slices.Contains(list, value) {
+			return list
+		}
+		return append(list, value)
+	}
+	o.event.Templates = appendNew(o.event.Templates, template)
+	o.event.Claims = appendNew(o.event.Claims, claim)
+	o.event.Paths = appendNew(o.event.Paths, path)
+}
+
+// addRenderResults records the successful results and the files they were written to
+func (o *outcomeCollector) addRenderResults(results []RenderResult, config OutputConfig) {
+	for _, r := range results {
+		if r.Error == nil {
+			o.addClaim(r.TemplateName, r.ResourceName, "")
+		}
+	}
+	written := writtenPaths(results, config)
+	for _, path := range written {
+		o.addClaim("", "", path)
+	}
+
+	// Results combined by --single-file were written to the combined file
+	combinedPath := ""
+	if combined := combinedResults(results, config); len(written) > 0 && hasSuccessfulResult(combined) {
+		combinedPath = combinedFilePath(combined, config)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, r := range results {
+		switch {
+		case r.Error != nil || len(written) == 0:
+			r.OutputPath = ""
+		case !splitsOutput(r, config):
+			r.OutputPath = combinedPath
+		}
+		o.results = append(o.results, r)
+	}
+}
+
+// addEncryptResult records an encrypted secret and the file it was written to
+func (o *outcomeCollector) addEncryptResult(r EncryptResult) {
+	if r.Error == nil {
+		o.addClaim(r.TemplateName, r.SecretName, r.OutputPath)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results = append(o.results, RenderResult{
+		TemplateName: r.TemplateName,
+		ResourceName: r.SecretName,
+		OutputPath:   r.OutputPath,
+		Error:        r.Error,
+	})
+}
+
+// setBranch records the branch the run committed to
+func (o *outcomeCollector) setBranch(branch string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.branch = branch
+}
+
+// setCommit records the commit created by the run
+func (o *outcomeCollector) setCommit(hash string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.event.Commit = hash
+}
+
+// setPR records the pull request opened by the run
+func (o *outcomeCollector) setPR(url string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.event.PRURL = url
+}
+
+// Event returns the recorded outcome as the event for command, failed if runErr is set
+func (o *outcomeCollector) Event(command string, runErr error) notify.Event {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	event := o.event
+	event.Command = command
+	event.Success = runErr == nil
+	if runErr != nil {
+		event.Error = redact.String(runErr.Error())
+	}
+	event.Timestamp = time.Now().UTC()
+	return event
+}
+
+// Summary returns the recorded outcome as the --summary-json report for
+// command, failed if runErr is set or any result failed
+func (o *outcomeCollector) Summary(command string, runErr error) runSummary {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	agg := &RenderResults{Results: o.results, GitCommit: o.event.Commit, PRUrl: o.event.PRURL}
+	return newRunSummary(command, agg, o.branch, runErr)
+}
+
+// Reset discards the recorded outcome
+func (o *outcomeCollector) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.event = notify.Event{}
+	o.results = nil
+	o.branch = ""
+}
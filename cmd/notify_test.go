@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/notify"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestNotifyWebhook_Render(t *testing.T) {
+	api := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	var events []notify.Event
+	var auth string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		var event notify.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decoding event: %v", err)
+		}
+		events = append(events, event)
+	}))
+	defer hook.Close()
+
+	outcome.Reset()
+	outputDir := t.TempDir()
+	config := &RenderConfig{
+		APIUrl:          api.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	runErr := runNonInteractive(context.Background(), config)
+	if runErr != nil {
+		t.Fatalf("runNonInteractive: %v", runErr)
+	}
+
+	webhook := notify.NewWebhook(hook.URL, map[string]string{"Authorization": "Bearer t0ken"})
+	if err := notifyWebhook(webhook, "render", runErr); err != nil {
+		t.Fatalf("notifyWebhook: %v", err)
+	}
+	if err := notifyWebhook(webhook, "render", errors.New("git operations: push rejected")); err != nil {
+		t.Fatalf("notifyWebhook: %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	got := events[0]
+	if got.Command != "render" || !got.Success || got.Error != "" {
+		t.Errorf("unexpected status: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Templates, []string{"vsphere-vm"}) || !reflect.DeepEqual(got.Claims, []string{"web"}) {
+		t.Errorf("unexpected templates/claims: %v %v", got.Templates, got.Claims)
+	}
+	if want := []string{filepath.Join(outputDir, "vsphere-vm-web.yaml")}; !reflect.DeepEqual(got.Paths, want) {
+		t.Errorf("paths = %v, want %v", got.Paths, want)
+	}
+	if got.Timestamp.IsZero() {
+		t.Error("expected a timestamp")
+	}
+	if auth != "Bearer t0ken" {
+		t.Errorf("expected the configured header, got %q", auth)
+	}
+
+	if failed := events[1]; failed.Success || failed.Error != "git operations: push rejected" {
+		t.Errorf("expected a failed event, got %+v", failed)
+	}
+}
+
+func TestNotifyWebhook_DeliveryFailure(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer hook.Close()
+	webhook := notify.NewWebhook(hook.URL, nil)
+
+	t.Cleanup(func() { webhookStrict = false })
+
+	warnings.Reset()
+	webhookStrict = false
+	if err := notifyWebhook(webhook, "delete", nil); err != nil {
+		t.Errorf("expected only a warning, got %v", err)
+	}
+	if warnings.Count() != 1 || !strings.Contains(warnings.messages[0], "503") {
+		t.Errorf("expected a delivery warning, got %v", warnings.messages)
+	}
+
+	webhookStrict = true
+	if err := notifyWebhook(webhook, "delete", nil); err == nil || !strings.Contains(err.Error(), "delivering webhook") {
+		t.Errorf("expected a delivery error with --webhook-strict, got %v", err)
+	}
+
+	if err := notifyWebhook(nil, "delete", nil); err != nil {
+		t.Errorf("expected no-op without --webhook, got %v", err)
+	}
+}
@@ -1,31 +1,83 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
-	"github.com/stuttgart-things/claims/internal/banner"
+	"github.com/stuttgart-things/claims/internal/sops"
+	"github.com/stuttgart-things/claims/internal/templates"
 )
 
 var (
 	apiURL          string
+	apiToken        string
+	caCert          string
+	skipTLSVerify   bool
 	outputDir       string
+	toStdout        bool
 	dryRun          bool
 	singleFile      bool
 	filenamePattern string
 	templateNames   []string
+	templateTags    []string
+	templateVersion string
+	resourcePrefix  string
+	resourceSuffix  string
+	affixNameParam  bool
+	resourceName    string
+	setNameParam    bool
+	offline         bool
+	refreshCache    bool
+	noCache         bool
+	cacheTTL        time.Duration
 
 	// Non-interactive mode flags
-	paramsFile     string
+	paramsFiles    []string
 	inlineParams   []string
+	rawStrings     bool
+	expandEnv      bool
+	strictEnv      bool
+	setParams      []string
+	paramFileYAML  []string
+	paramFileJSON  []string
+	matrix         []string
+	noDefaults     bool
 	inlineSecrets  []string
 	skipSecrets    bool
 	combineSecrets bool
 	interactive    bool
 	nonInteractive bool
 	fileMode       string
+	keepCRLF       bool
+	allowCollision bool
+	mergeDups      bool
+	onlyNew        bool
+	strict         bool
+	kustomization  string
+	rootKustomize  bool
+	noSortKustom   bool
+	argoCDLayout   bool
+	registrySource string
+	previewLines   int
+	paramOrder     string
+	parallel       int
+	secretParallel int
+	outputOrder    string
+	renderTimeout  time.Duration
+	explain        bool
+	checkRequired  bool
+	changedOnly    bool
+	baseRef        string
+	registryFilter []string
+	diffOnly       bool
+	showDiff       bool
+	apply          bool
+	watchTemplates bool
+	watchInterval  time.Duration
 
 	// Git flags
 	gitCommit       bool
@@ -37,13 +89,19 @@ var (
 	gitRepoURL      string
 	gitUser         string
 	gitToken        string
+	gitSSHKey       string
+	gitCheckPush    bool
+	gitSignOff      bool
+	gitGPGSign      bool
+	gitGPGKey       string
 
 	// PR flags
-	createPR      bool
-	prTitle       string
-	prDescription string
-	prLabels      []string
-	prBase        string
+	createPR            bool
+	prTitle             string
+	prDescription       string
+	prLabels            []string
+	prBase              string
+	createMissingLabels bool
 )
 
 var renderCmd = &cobra.Command{
@@ -55,21 +113,70 @@ var renderCmd = &cobra.Command{
 
 func init() {
 	renderCmd.Flags().StringVarP(&apiURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
-	renderCmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "Output directory for rendered files")
+	renderCmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "Output directory for rendered files (- streams them to stdout; may use {{.labels.<key>}}, {{.annotations.<key>}} and {{.namespace}} of the rendered resource)")
+	renderCmd.Flags().StringVar(&apiToken, "api-token", "", "Bearer token for the API (default: $CLAIM_API_TOKEN)")
+	renderCmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM CA certificate to trust for an HTTPS API signed by a private CA")
+	renderCmd.Flags().BoolVar(&skipTLSVerify, "insecure-skip-tls-verify", false, "Don't verify the API's TLS certificate (insecure; for testing only)")
+	renderCmd.Flags().BoolVar(&toStdout, "stdout", false, "Stream the rendered claims to stdout instead of writing files (same as -o -)")
 	renderCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print output without writing files")
+	renderCmd.Flags().BoolVar(&diffOnly, "diff-only", false, "Print a diff of the rendered output against the files on disk without writing anything (non-interactive)")
+	renderCmd.Flags().BoolVar(&showDiff, "diff", false, "Print a diff of the rendered output against the files on disk before writing them; combine with --dry-run to only preview (non-interactive)")
 	renderCmd.Flags().BoolVar(&singleFile, "single-file", false, "Combine all resources into one file")
 	renderCmd.Flags().StringVar(&filenamePattern, "filename-pattern", "{{.template}}-{{.name}}.yaml", "Pattern for output filenames")
 	renderCmd.Flags().StringSliceVarP(&templateNames, "templates", "t", nil, "Templates to render (comma-separated or repeated)")
+	renderCmd.Flags().StringSliceVar(&templateTags, "tag", nil, "Only offer templates carrying all of these tags in the template selection (comma-separated or repeated; interactive)")
+	renderCmd.Flags().StringVar(&templateVersion, "template-version", "", "Render at this template version (tag) instead of the catalog default; requires a single template")
+	renderCmd.Flags().StringVar(&resourcePrefix, "resource-prefix", "", "Prefix for resource names in filenames and registry (not rendered content)")
+	renderCmd.Flags().StringVar(&resourceSuffix, "resource-suffix", "", "Suffix for resource names in filenames and registry (not rendered content)")
+	renderCmd.Flags().StringVar(&resourceName, "resource-name", "", "Resource name for the filename and registry of a single-template render, instead of the name param (non-interactive)")
+	renderCmd.Flags().BoolVar(&setNameParam, "set-name-param", false, "Also send --resource-name as the 'name' parameter")
+	renderCmd.Flags().BoolVar(&affixNameParam, "affix-name-param", false, "Also apply --resource-prefix/--resource-suffix to the 'name' parameter sent to the API")
+	renderCmd.Flags().BoolVar(&offline, "offline", false, "Use the cached template catalog for forms and validation (rendering still calls the API)")
+	renderCmd.Flags().BoolVar(&refreshCache, "refresh-cache", false, "Fetch the template catalog from the API and update the cache, even with --offline")
+	renderCmd.Flags().BoolVar(&noCache, "no-cache", false, "Fetch the template catalog from the API instead of reusing the cached one")
+	renderCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", templates.DefaultCatalogTTL, "Maximum age of the cached catalog before it is fetched again (rejected with --offline; 0 = never expires)")
 
 	// Non-interactive mode flags
-	renderCmd.Flags().StringVarP(&paramsFile, "params-file", "f", "", "YAML/JSON file with parameters")
-	renderCmd.Flags().StringArrayVarP(&inlineParams, "param", "p", nil, "Inline param (key=value, repeatable)")
+	renderCmd.Flags().StringArrayVarP(&paramsFiles, "params-file", "f", nil, "YAML/JSON/TOML file with parameters (- reads stdin, repeatable; later files override earlier ones)")
+	renderCmd.Flags().BoolVar(&expandEnv, "expand-env", false, "Expand $VAR, ${VAR} and ${VAR:-default} in params files from the environment before parsing")
+	renderCmd.Flags().BoolVar(&strictEnv, "strict-env", false, "Like --expand-env, but fail on variables that are unset and have no default")
+	renderCmd.Flags().BoolVar(&changedOnly, "changed-only", false, "Only render params file entries that changed since --base-ref (non-interactive)")
+	renderCmd.Flags().StringVar(&baseRef, "base-ref", "main", "Git revision --changed-only compares the params file against")
+	renderCmd.Flags().StringArrayVar(&registryFilter, "params-from-registry-filter", nil, "Re-render registry claims matching category=X, template=X or label=key=value (repeatable, all must match) with their stored params (non-interactive)")
+	renderCmd.Flags().StringArrayVarP(&inlineParams, "param", "p", nil, "Inline param (key=value, repeatable; key- or key=null unsets)")
+	renderCmd.Flags().BoolVar(&rawStrings, "raw-strings", false, "Send --param values as strings instead of converting numbers and true/false")
+	renderCmd.Flags().StringArrayVar(&setParams, "set", nil, "Nested param by dotted path (a.b.c=value, a.b[0]=value; repeatable; path=null unsets)")
+	renderCmd.Flags().StringArrayVar(&paramFileYAML, "param-file-yaml", nil, "Nested param parsed from a YAML file (a.b=file.yaml; repeatable), e.g. resources=resources.yaml")
+	renderCmd.Flags().StringArrayVar(&paramFileJSON, "param-file-json", nil, "Nested param parsed from a JSON file (a.b=file.json; repeatable)")
+	renderCmd.Flags().BoolVar(&noDefaults, "no-defaults", false, "Send only the given parameters instead of filling in the template defaults for unset ones (non-interactive)")
+	renderCmd.Flags().StringArrayVar(&matrix, "matrix", nil, "Render every entry once per combination of values (key=v1,v2; repeatable), named <name>-<value>-... (non-interactive)")
 	renderCmd.Flags().StringArrayVarP(&inlineSecrets, "secret", "s", nil, "Secret param (key=value, repeatable)")
 	renderCmd.Flags().BoolVar(&skipSecrets, "skip-secrets", false, "Skip secret generation even if template defines them")
 	renderCmd.Flags().BoolVar(&combineSecrets, "combine-secrets", false, "Save encrypted secrets in the same file as rendered output (--- separated)")
 	renderCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Force interactive mode")
 	renderCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Force non-interactive mode")
+	renderCmd.Flags().BoolVar(&explain, "explain", false, "Print the resolved plan (API URL, templates, params with their origin, output, registry, git/PR) and exit without rendering")
+	renderCmd.Flags().BoolVar(&checkRequired, "check-required-only", false, "Only check that the required parameters are set for the selected templates and exit without rendering")
+	renderCmd.Flags().StringVar(&paramOrder, "param-order", ParamOrderDeclared, "Order of the parameter form fields: required-first, declared or alphabetical (interactive)")
+	renderCmd.Flags().IntVar(&previewLines, "preview-lines", 0, "Lines of YAML shown per resource in review (default: fit terminal height)")
+	renderCmd.Flags().IntVar(&parallel, "parallel", 1, "Number of templates to render concurrently")
+	renderCmd.Flags().IntVar(&secretParallel, "encrypt-parallel", sops.DefaultEncryptWorkers, "Number of secrets to encrypt with sops concurrently")
+	renderCmd.Flags().DurationVar(&renderTimeout, "render-timeout", 0, "Overall deadline for fetching and rendering all templates, e.g. 2m (0 = none)")
+	renderCmd.Flags().StringVar(&outputOrder, "render-concurrency-order", OutputOrderInput, "Order of combined output and results under --parallel: input (params file/selection order) or completion")
 	renderCmd.Flags().StringVar(&fileMode, "file-mode", "overwrite", "File write mode: overwrite (default) or append")
+	renderCmd.Flags().BoolVar(&mergeDups, "merge-duplicates", false, "Merge entries with the same template and name (later entries win) instead of failing")
+	renderCmd.Flags().BoolVar(&allowCollision, "allow-collisions", false, "Proceed with a warning when several entries produce the same output filename (default: error)")
+	renderCmd.Flags().BoolVar(&onlyNew, "only-new", false, "Skip entries whose resource name is already an active claim in the registry (non-interactive)")
+	renderCmd.Flags().BoolVar(&strict, "strict", false, "Fail instead of warning when rendered files are ignored by .gitignore and would not be committed")
+	renderCmd.Flags().StringVar(&kustomization, "kustomization", "", "Add the written files to the resources of this kustomization.yaml (or the one in this directory) and stage it for commit")
+	renderCmd.Flags().BoolVar(&rootKustomize, "generate-root-kustomization", false, "Write or update a kustomization.yaml at the root of the output directory listing every written file, so the directory builds with kustomize build")
+	renderCmd.Flags().BoolVar(&noSortKustom, "no-sort", false, "Append new entries to kustomization.yaml resources instead of keeping them sorted")
+	renderCmd.Flags().BoolVar(&argoCDLayout, "argocd-layout", false, "Write each claim to apps/<category>/<name>/ below the output directory with an ArgoCD Application per claim in applications/ (non-interactive)")
+	renderCmd.Flags().StringVar(&registrySource, "source", "", "Source recorded for rendered claims in the registry (default: ci when a CI environment such as $CI is detected, else cli)")
+	renderCmd.Flags().BoolVar(&apply, "apply", false, "Run kubectl apply on the rendered claims against the current kube-context (non-interactive)")
+	renderCmd.Flags().BoolVar(&watchTemplates, "watch-templates", false, "Keep polling the template catalog and re-render whenever a rendered template's definition changes; skips git/PR steps (non-interactive)")
+	renderCmd.Flags().DurationVar(&watchInterval, "watch-interval", DefaultWatchInterval, "How often --watch-templates polls the template catalog")
+	renderCmd.Flags().BoolVar(&keepCRLF, "keep-crlf", false, "Keep CRLF line endings in rendered output (default: normalize to LF)")
 
 
 	// Git flags
@@ -78,10 +185,15 @@ func init() {
 	renderCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Branch to use/create")
 	renderCmd.Flags().BoolVar(&gitCreateBranch, "git-create-branch", false, "Create the branch if it doesn't exist")
 	renderCmd.Flags().StringVar(&gitMessage, "git-message", "", "Commit message (default: auto-generated)")
-	renderCmd.Flags().StringVar(&gitRemote, "git-remote", "origin", "Git remote name")
+	renderCmd.Flags().StringVar(&gitRemote, "git-remote", "", "Git remote name (default: the only remote; with several, asked for interactively)")
 	renderCmd.Flags().StringVar(&gitRepoURL, "git-repo-url", "", "Clone from URL instead of using local repo")
 	renderCmd.Flags().StringVar(&gitUser, "git-user", "", "Git username (or GIT_USER/GITHUB_USER env)")
 	renderCmd.Flags().StringVar(&gitToken, "git-token", "", "Git token (or GIT_TOKEN/GITHUB_TOKEN env)")
+	renderCmd.Flags().StringVar(&gitSSHKey, "ssh-key", "", "Private key for SSH remotes (or GIT_SSH_KEY env, default ~/.ssh/id_ed25519; passphrase from GIT_SSH_KEY_PASSPHRASE)")
+	renderCmd.Flags().BoolVar(&gitSignOff, "sign-off", false, "Add a Signed-off-by trailer (DCO) for the commit author to the commit message")
+	renderCmd.Flags().BoolVar(&gitGPGSign, "gpg-sign", false, "GPG-sign the commit with the key from --gpg-key or GIT_SIGNING_KEY; fails if there is none")
+	renderCmd.Flags().StringVar(&gitGPGKey, "gpg-key", "", "Key to sign the commit with: armored private key, its file or a gpg key ID (or GIT_SIGNING_KEY env; passphrase from GIT_SIGNING_KEY_PASSPHRASE); implies --gpg-sign")
+	renderCmd.Flags().BoolVar(&gitCheckPush, "check-push", false, "Verify the remote is reachable with the git credentials before rendering (also done by --dry-run with --git-push)")
 
 	// PR flags
 	renderCmd.Flags().BoolVar(&createPR, "create-pr", false, "Create a pull request after push")
@@ -89,30 +201,73 @@ func init() {
 	renderCmd.Flags().StringVar(&prDescription, "pr-description", "", "PR description")
 	renderCmd.Flags().StringSliceVar(&prLabels, "pr-labels", nil, "PR labels (comma-separated)")
 	renderCmd.Flags().StringVar(&prBase, "pr-base", "main", "Base branch for PR")
+	renderCmd.Flags().BoolVar(&createMissingLabels, "create-missing-labels", false, "Create PR labels that don't exist in the repository (default: skip them with a warning)")
+
+	addWebhookFlags(renderCmd)
+	addSummaryFlags(renderCmd)
 
 	rootCmd.AddCommand(renderCmd)
 }
 
 func runRender(cmd *cobra.Command, args []string) {
-	banner.Show()
+	if err := resolveStdoutFlags(cmd.Flags().Changed("output-dir")); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
 
-	// Get API URL from flag, environment, or default.
-	// CLAIM_API_URL supports colon-separated multiple endpoints (URL colons preserved).
-	if apiURL == "" {
-		apiURL = os.Getenv("CLAIM_API_URL")
+	// With -o - stdout carries the claims; everything else goes to stderr
+	if outputDir == StdoutDir {
+		defer redirectProgressToStderr()()
 	}
-	if apiURL == "" {
-		apiURL = "http://localhost:8080"
+	showBanner()
+
+	webhook, err := newWebhook()
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
 	}
 
+	// Get API URL from flag, environment, or default.
+	// CLAIM_API_URL supports colon-separated multiple endpoints (URL colons preserved).
+	var apiURLSource string
+	apiURL, apiURLSource = resolveAPIURL(apiURL)
+
 	// Build render config
 	config := &RenderConfig{
 		APIUrl:           apiURL,
 		APIUrls:          splitAPIURLs(apiURL),
+		APIUrlSource:     apiURLSource,
+		APIToken:         resolveAPIToken(apiToken),
+		CACert:           caCert,
+		SkipTLSVerify:    skipTLSVerify,
+		Offline:          offline,
+		RefreshCache:     refreshCache,
+		NoCache:          noCache,
+		CacheTTL:         cacheTTL,
 		Templates:        templateNames,
-		ParamsFile:       paramsFile,
+		Tags:             templateTags,
+		TemplateVersion:  templateVersion,
+		ParamsFiles:      paramsFiles,
+		ExpandEnv:        expandEnv,
+		StrictEnv:        strictEnv,
+		ChangedOnly:      changedOnly,
+		BaseRef:          baseRef,
+		RegistryFilter:   registryFilter,
+		DiffOnly:         diffOnly,
+		Diff:             showDiff,
 		InlineParamsRaw:  inlineParams,
+		RawStrings:       rawStrings,
+		SetParamsRaw:     setParams,
+		ParamFileYAMLRaw: paramFileYAML,
+		ParamFileJSONRaw: paramFileJSON,
+		MatrixRaw:        matrix,
+		NoDefaults:       noDefaults,
 		InlineSecretsRaw: inlineSecrets,
+		ResourcePrefix:   resourcePrefix,
+		ResourceSuffix:   resourceSuffix,
+		AffixNameParam:   affixNameParam,
+		ResourceName:     resourceName,
+		SetNameParam:     setNameParam,
 		SkipSecrets:      skipSecrets,
 		CombineSecrets:   combineSecrets,
 		OutputDir:        outputDir,
@@ -120,10 +275,31 @@ func runRender(cmd *cobra.Command, args []string) {
 		SingleFile:       singleFile,
 		DryRun:           dryRun,
 		FileMode:         fileMode,
+		KeepCRLF:         keepCRLF,
+		AllowCollisions:  allowCollision,
+		MergeDuplicates:  mergeDups,
+		OnlyNew:          onlyNew,
+		Strict:           strict,
+		Kustomization:    kustomization,
+		RootKustomize:    rootKustomize,
+		NoSort:           noSortKustom,
+		ArgoCDLayout:     argoCDLayout,
+		Source:           resolveRegistrySource(registrySource),
+		Apply:            apply,
+		WatchTemplates:   watchTemplates,
+		WatchInterval:    watchInterval,
+		PreviewLines:     previewLines,
+		ParamOrder:       paramOrder,
+		Explain:          explain,
+		CheckRequired:    checkRequired,
+		Parallel:         parallel,
+		EncryptParallel:  secretParallel,
+		OutputOrder:      outputOrder,
+		RenderTimeout:    renderTimeout,
 	}
 
 	// Build git config if any git flags are set
-	if gitCommit || gitPush || gitBranch != "" || gitRepoURL != "" || createPR {
+	if gitCommit || gitPush || gitBranch != "" || gitRepoURL != "" || createPR || gitCheckPush || gitSignOff || gitRemote != "" || gitSSHKey != "" || gitGPGSign || gitGPGKey != "" {
 		config.GitConfig = &GitConfig{
 			Commit:       gitCommit || gitPush || createPR, // Push/PR implies commit
 			Push:         gitPush || createPR,              // PR implies push
@@ -134,17 +310,23 @@ func runRender(cmd *cobra.Command, args []string) {
 			RepoURL:      gitRepoURL,
 			User:         gitUser,
 			Token:        gitToken,
+			SSHKey:       gitSSHKey,
+			CheckPush:    gitCheckPush,
+			SignOff:      gitSignOff,
+			GPGSign:      gitGPGSign,
+			GPGKey:       gitGPGKey,
 		}
 	}
 
 	// Build PR config if PR flags are set
 	if createPR || prTitle != "" || prDescription != "" || len(prLabels) > 0 {
 		config.PRConfig = &PRConfig{
-			Create:      createPR,
-			Title:       prTitle,
-			Description: prDescription,
-			Labels:      prLabels,
-			BaseBranch:  prBase,
+			Create:              createPR,
+			Title:               prTitle,
+			Description:         prDescription,
+			Labels:              prLabels,
+			BaseBranch:          prBase,
+			CreateMissingLabels: createMissingLabels,
 		}
 	}
 
@@ -158,7 +340,25 @@ func runRender(cmd *cobra.Command, args []string) {
 		config.Interactive = isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
 	}
 
-	var err error
+	if config.Explain {
+		plan, err := explainRender(config)
+		if err != nil {
+			fmt.Println(renderError(err.Error()))
+			os.Exit(1)
+		}
+		fmt.Print(plan)
+		return
+	}
+
+	if config.CheckRequired {
+		config.APIUrl = config.APIUrls[0]
+		if err := checkRequiredOnly(context.Background(), config, os.Stdout); err != nil {
+			fmt.Println(renderError(err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
 	if config.Interactive {
 		// Interactive mode — select or confirm API endpoint
 		var selectedURL string
@@ -175,16 +375,26 @@ func runRender(cmd *cobra.Command, args []string) {
 		config.APIUrl = selectedURL
 		fmt.Printf("\nConnecting to API: %s\n\n", config.APIUrl)
 
-		err = runInteractive(config)
+		err = runInteractive(context.Background(), config)
 	} else {
 		// Non-interactive: use first URL
 		config.APIUrl = config.APIUrls[0]
 		fmt.Printf("Connecting to API: %s\n\n", config.APIUrl)
-		err = runNonInteractive(config)
+		if config.WatchTemplates {
+			err = runWatchTemplates(context.Background(), config)
+		} else {
+			err = runNonInteractive(context.Background(), config)
+		}
 	}
 
+	if werr := notifyWebhook(webhook, "render", err); err == nil {
+		err = werr
+	}
+	if serr := writeRunSummary(summaryJSONPath, "render", err); err == nil {
+		err = serr
+	}
 	if err != nil {
-		fmt.Println(errorStyle.Render(err.Error()))
+		fmt.Println(renderError(err.Error()))
 		os.Exit(1)
 	}
 }
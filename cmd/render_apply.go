@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// applyRendered runs kubectl apply against the current kube-context for the
+// written claims, one -f per file. When nothing was written (--dry-run or
+// -o -) the combined output is piped to kubectl apply -f - instead, as a
+// server-side dry run under --dry-run. kubectl's output is streamed and a
+// failed apply is returned as an error.
+func applyRendered(results []RenderResult, config *RenderConfig, outputConfig OutputConfig) error {
+	if _, err := exec.LookPath("kubectl"); err != nil {
+		return fmt.Errorf("kubectl not found: --apply needs kubectl on PATH")
+	}
+
+	kubeContext, err := currentKubeContext()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"apply"}
+	var stdin string
+	if paths := writtenPaths(results, outputConfig); len(paths) > 0 {
+		for _, path := range paths {
+			args = append(args, "-f", path)
+		}
+	} else {
+		stdin = combineResults(results)
+		if strings.TrimSpace(stdin) == "" {
+			fmt.Println("Nothing to apply")
+			return nil
+		}
+		args = append(args, "-f", "-")
+		if config.DryRun {
+			args = append(args, "--dry-run=server")
+		}
+	}
+
+	fmt.Printf("Applying to kube-context: %s\n", kubeContext)
+	cmd := exec.Command("kubectl", args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("kubectl apply failed: %w", err)
+	}
+	return nil
+}
+
+// currentKubeContext returns the name of the kube-context kubectl targets
+func currentKubeContext() (string, error) {
+	cmd := exec.Command("kubectl", "config", "current-context")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("reading current kube-context: %s", msg)
+		}
+		return "", fmt.Errorf("reading current kube-context: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// writtenPaths returns the files the successful results were written to,
+// including the --single-file combined file, without duplicates
+func writtenPaths(results []RenderResult, config OutputConfig) []string {
+	if config.DryRun || config.Stdout {
+		return nil
+	}
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	if combined := combinedResults(results, config); hasSuccessfulResult(combined) {
+		add(combinedFilePath(combined, config))
+	}
+	for _, r := range results {
+		if r.Error == nil {
+			add(r.OutputPath)
+		}
+	}
+	return paths
+}
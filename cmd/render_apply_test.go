@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// fakeKubectl puts a kubectl script on PATH that reports the context
+// "kind-dev", logs the arguments and stdin of every apply to the returned
+// file, and fails the apply when fail is set
+func fakeKubectl(t *testing.T, fail bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "kubectl.log")
+	exit := "0"
+	if fail {
+		exit = "1"
+	}
+	script := `#!/bin/sh
+if [ "$1" = "config" ]; then
+  echo kind-dev
+  exit 0
+fi
+echo "args: $*" >> "` + logPath + `"
+if [ "$3" = "-" ]; then
+  cat >> "` + logPath + `"
+fi
+echo "applied"
+exit ` + exit + `
+`
+	if err := os.WriteFile(filepath.Join(dir, "kubectl"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func TestRunNonInteractive_Apply(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	newConfig := func(outputDir string) *RenderConfig {
+		return &RenderConfig{
+			APIUrl:          server.URL,
+			Templates:       []string{"vsphere-vm"},
+			InlineParamsRaw: []string{"name=web"},
+			OutputDir:       outputDir,
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			Apply:           true,
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		}
+	}
+
+	t.Run("applies the written files", func(t *testing.T) {
+		logPath := fakeKubectl(t, false)
+		outputDir := t.TempDir()
+
+		if err := runNonInteractive(context.Background(), newConfig(outputDir)); err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+
+		log, err := os.ReadFile(logPath)
+		if err != nil {
+			t.Fatalf("kubectl apply was not run: %v", err)
+		}
+		want := "args: apply -f " + filepath.Join(outputDir, "vsphere-vm-web.yaml")
+		if !strings.Contains(string(log), want) {
+			t.Errorf("expected %q in kubectl log, got:\n%s", want, log)
+		}
+	})
+
+	t.Run("pipes the output on dry run", func(t *testing.T) {
+		logPath := fakeKubectl(t, false)
+		config := newConfig(t.TempDir())
+		config.DryRun = true
+
+		if err := runNonInteractive(context.Background(), config); err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+
+		log, _ := os.ReadFile(logPath)
+		if !strings.Contains(string(log), "args: apply -f - --dry-run=server") {
+			t.Errorf("expected a server-side dry run from stdin, got:\n%s", log)
+		}
+		if !strings.Contains(string(log), "name: web") {
+			t.Errorf("expected the rendered claim on stdin, got:\n%s", log)
+		}
+	})
+
+	t.Run("fails when apply fails", func(t *testing.T) {
+		fakeKubectl(t, true)
+
+		err := runNonInteractive(context.Background(), newConfig(t.TempDir()))
+		if err == nil || !strings.Contains(err.Error(), "kubectl apply failed") {
+			t.Errorf("expected a kubectl apply error, got %v", err)
+		}
+	})
+
+	t.Run("fails without kubectl", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		err := runNonInteractive(context.Background(), newConfig(t.TempDir()))
+		if err == nil || !strings.Contains(err.Error(), "kubectl not found") {
+			t.Errorf("expected a kubectl not found error, got %v", err)
+		}
+	})
+}
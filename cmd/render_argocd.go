@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/stuttgart-things/claims/internal/gitops"
+	"gopkg.in/yaml.v3"
+)
+
+// --argocd-layout writes each claim to apps/<category>/<name>/ below the
+// output directory and an ArgoCD Application per claim to applications/,
+// ready to be picked up by an app-of-apps
+const (
+	argoCDAppsDir         = "apps"
+	argoCDApplicationsDir = "applications"
+	argoCDClaimFile       = "claim.yaml"
+	argoCDNamespace       = "argocd"
+	argoCDDestination     = "https://kubernetes.default.svc"
+)
+
+// argoCDApplication is the subset of an ArgoCD Application written by
+// --argocd-layout
+type argoCDApplication struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Project string `yaml:"project"`
+		Source  struct {
+			RepoURL        string `yaml:"repoURL"`
+			TargetRevision string `yaml:"targetRevision"`
+			Path           string `yaml:"path"`
+		} `yaml:"source"`
+		Destination struct {
+			Server    string `yaml:"server"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"destination"`
+	} `yaml:"spec"`
+}
+
+// argoCDCategory returns the category directory of a claim: its "category"
+// parameter, else its template name
+func argoCDCategory(r RenderResult) string {
+	if category, ok := r.Params["category"].(string); ok && category != "" {
+		return category
+	}
+	return r.TemplateName
+}
+
+// argoCDDestinationNamespace returns the namespace a claim's Application
+// deploys to: its "namespace" parameter, else the namespace of its rendered
+// documents, else "default"
+func argoCDDestinationNamespace(r RenderResult) string {
+	if namespace, ok := r.Params["namespace"].(string); ok && namespace != "" {
+		return namespace
+	}
+	if namespace := r.namespace(); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
+
+// argoCDClaimDir returns apps/<category>/<name> below outputDir
+func argoCDClaimDir(outputDir string, r RenderResult) string {
+	return filepath.Join(staticDir(outputDir), argoCDAppsDir, argoCDCategory(r), r.ResourceName)
+}
+
+// argoCDApplicationPath returns where the Application of a claim is written
+func argoCDApplicationPath(outputDir string, r RenderResult) string {
+	return filepath.Join(staticDir(outputDir), argoCDApplicationsDir, argoCDCategory(r)+"-"+r.ResourceName+".yaml")
+}
+
+// applyArgoCDLayout points each successful result at
+// apps/<category>/<name>/claim.yaml below the output directory. Results
+// with a fixed target path, e.g. re-rendered registry entries, keep it.
+func applyArgoCDLayout(results []RenderResult, config *RenderConfig) error {
+	if config.SingleFile {
+		return errors.New("--argocd-layout writes a directory per claim and can't be combined with --single-file")
+	}
+	for i, r := range results {
+		if r.Error != nil || r.TargetPath != "" {
+			continue
+		}
+		results[i].TargetPath = filepath.Join(argoCDClaimDir(config.OutputDir, r), argoCDClaimFile)
+	}
+	return nil
+}
+
+// argoCDRepoURL returns the repository URL the Applications point at: the
+// --git-repo-url, else the URL of the push remote of the repository holding
+// the output directory (see resolveGitRemote). Credentials in the remote URL
+// are never written.
+func argoCDRepoURL(repoRoot string, config *RenderConfig) (string, error) {
+	gc := config.GitConfig
+	if gc == nil {
+		gc = &GitConfig{}
+	}
+	rawURL := gc.RepoURL
+	if rawURL == "" {
+		g, err := gitops.New(repoRoot, "", "")
+		if err != nil {
+			return "", err
+		}
+		if err := resolveGitRemote(g, gc, config.Interactive); err != nil {
+			return "", fmt.Errorf("--argocd-layout needs the repository URL: %w", err)
+		}
+		if rawURL, err = g.GetRemoteURL(gc.Remote); err != nil {
+			return "", fmt.Errorf("--argocd-layout needs the repository URL: %w", err)
+		}
+	}
+	return repositoryURL(rawURL)
+}
+
+// repositoryURL rebuilds a remote URL from its host and owner/repo slug,
+// dropping credentials: https://host/owner/repo.git, or git@host:owner/repo.git
+// for an SSH remote
+func repositoryURL(rawURL string) (string, error) {
+	info, err := gitops.ParseRemoteURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	host := info.Host
+	if info.Port != "" {
+		host += ":" + info.Port
+	}
+	if info.Scheme == "ssh" {
+		if info.Port != "" {
+			return fmt.Sprintf("ssh://git@%s/%s.git", host, info.Slug()), nil
+		}
+		return fmt.Sprintf("git@%s:%s.git", host, info.Slug()), nil
+	}
+	return fmt.Sprintf("%s://%s/%s.git", info.Scheme, host, info.Slug()), nil
+}
+
+// newArgoCDApplication returns the Application of a claim whose directory is
+// claimPath, relative to the repository root
+func newArgoCDApplication(r RenderResult, repoURL, claimPath string) argoCDApplication {
+	var app argoCDApplication
+	app.APIVersion = "argoproj.io/v1alpha1"
+	app.Kind = "Application"
+	app.Metadata.Name = argoCDCategory(r) + "-" + r.ResourceName
+	app.Metadata.Namespace = argoCDNamespace
+	app.Spec.Project = "default"
+	app.Spec.Source.RepoURL = repoURL
+	app.Spec.Source.TargetRevision = "HEAD"
+	app.Spec.Source.Path = claimPath
+	app.Spec.Destination.Server = argoCDDestination
+	app.Spec.Destination.Namespace = argoCDDestinationNamespace(r)
+	return app
+}
+
+// writeArgoCDApplications writes the Application of every written claim and
+// returns their paths
+func writeArgoCDApplications(results []RenderResult, config *RenderConfig) ([]string, error) {
+	repoRoot, err := findRepoRoot(config.OutputDir)
+	if err != nil {
+		return nil, fmt.Errorf("--argocd-layout needs the output directory in a git repository: %w", err)
+	}
+	repoURL, err := argoCDRepoURL(repoRoot, config)
+	if err != nil {
+		return nil, err
+	}
+	absRoot, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, r := range results {
+		if r.Error != nil || r.OutputPath == "" {
+			continue
+		}
+		absDir, err := filepath.Abs(filepath.Dir(r.OutputPath))
+		if err != nil {
+			return written, err
+		}
+		rel, err := filepath.Rel(absRoot, absDir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return written, fmt.Errorf("%s is outside the repository %s", r.OutputPath, repoRoot)
+		}
+
+		app := newArgoCDApplication(r, repoURL, path.Clean(filepath.ToSlash(rel)))
+		data, err := yaml.Marshal(app)
+		if err != nil {
+			return written, fmt.Errorf("marshalling Application for %s: %w", r.ResourceName, err)
+		}
+		appPath := argoCDApplicationPath(config.OutputDir, r)
+		if err := os.MkdirAll(filepath.Dir(appPath), 0755); err != nil {
+			return written, fmt.Errorf("creating applications directory: %w", err)
+		}
+		if err := os.WriteFile(appPath, data, 0644); err != nil {
+			return written, fmt.Errorf("writing Application: %w", err)
+		}
+		fmt.Printf("Saved Application: %s\n", appPath)
+		written = append(written, appPath)
+	}
+	return written, nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// catalogPath returns the configured catalog cache path or the default location
+func (c *RenderConfig) catalogPath() (string, error) {
+	if c.CatalogPath != "" {
+		return c.CatalogPath, nil
+	}
+	return templates.DefaultCatalogPath()
+}
+
+// Transient API failures (e.g. during a rollout of the API) are retried with
+// exponential backoff: 0.5s, 1s, 2s
+const (
+	apiMaxRetries     = 3
+	apiRetryBaseDelay = 500 * time.Millisecond
+)
+
+var (
+	// maxParallelAPI is --max-parallel-api: the most API requests in flight
+	// at once across all clients of the command; 0 = unlimited
+	maxParallelAPI int
+
+	apiLimiterOnce sync.Once
+	apiLimiter     *templates.Limiter
+)
+
+// sharedAPILimiter returns the --max-parallel-api limiter shared by every API
+// client, so fetching and parallel rendering are bounded together
+func sharedAPILimiter() *templates.Limiter {
+	apiLimiterOnce.Do(func() {
+		apiLimiter = templates.NewLimiter(maxParallelAPI)
+	})
+	return apiLimiter
+}
+
+// newRenderClient creates an API client that caches every fetched catalog
+func newRenderClient(config *RenderConfig) (*templates.Client, error) {
+	return newCatalogClient(config.APIUrl, config.CatalogPath, config.APIToken, config.CACert, config.SkipTLSVerify)
+}
+
+// newCatalogClient creates an API client authenticating with token (if set)
+// whose FetchTemplates results are cached at catalogPath (empty =
+// templates.DefaultCatalogPath()). caCert is trusted in addition to the
+// system roots; skipTLSVerify disables certificate verification.
+func newCatalogClient(apiURL, catalogPath, token, caCert string, skipTLSVerify bool) (*templates.Client, error) {
+	client, err := templates.NewClientWithTLS(apiURL, caCert, skipTLSVerify)
+	if err != nil {
+		return nil, err
+	}
+	client.MaxRetries = apiMaxRetries
+	client.RetryBaseDelay = apiRetryBaseDelay
+	client.Limiter = sharedAPILimiter()
+	client.AuthToken = token
+	if catalogPath == "" {
+		path, err := templates.DefaultCatalogPath()
+		if err != nil {
+			return client, nil
+		}
+		catalogPath = path
+	}
+	client.CatalogPath = catalogPath
+	return client, nil
+}
+
+// resolveAPIToken returns the API bearer token from the --api-token flag,
+// falling back to $CLAIM_API_TOKEN; "" means unauthenticated requests
+func resolveAPIToken(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv("CLAIM_API_TOKEN")
+}
+
+// loadTemplateCatalog returns the template definitions used to drive forms and
+// validation. Online they come from the cached catalog while it is younger
+// than --cache-ttl and from the API otherwise; in offline mode only the cached
+// catalog is used. --refresh-cache and --no-cache force a fetch (and cache
+// update) in either mode.
+func loadTemplateCatalog(ctx context.Context, client *templates.Client, config *RenderConfig) ([]templates.ClaimTemplate, error) {
+	if config.RefreshCache || config.NoCache {
+		return client.FetchTemplatesContext(ctx)
+	}
+	if !config.Offline {
+		return client.FetchTemplatesCachedContext(ctx, config.CacheTTL)
+	}
+
+	path, err := config.catalogPath()
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := templates.LoadCatalog(path)
+	if err != nil {
+		return nil, fmt.Errorf("offline mode needs a cached catalog (run once online or use --refresh-cache): %w", err)
+	}
+
+	ttl := config.CacheTTL
+	if catalog.Expired(ttl, time.Now()) {
+		return nil, fmt.Errorf("cached catalog from %s is older than %s; run with --refresh-cache to update it",
+			catalog.FetchedAt.Local().Format(time.RFC3339), ttl)
+	}
+
+	if catalog.APIUrl != "" && catalog.APIUrl != config.APIUrl {
+		warnf("cached catalog was fetched from %s, not %s", catalog.APIUrl, config.APIUrl)
+	}
+
+	return catalog.Items, nil
+}
+
+// loadRenderTemplates returns the template definitions for a render of the
+// given template names. Online, a render of a single template fetches only
+// that template; the whole catalog is loaded for several templates, in
+// offline mode and when the API doesn't know the name (it may be an alias,
+// or the API may predate single-template lookups).
+func loadRenderTemplates(ctx context.Context, client *templates.Client, config *RenderConfig, names []string) ([]templates.ClaimTemplate, error) {
+	if len(names) > 0 && !config.Offline && !config.RefreshCache {
+		single := true
+		for _, name := range names {
+			single = single && name == names[0]
+		}
+		if single {
+			tmpl, err := client.GetTemplateContext(ctx, names[0])
+			if err == nil {
+				return []templates.ClaimTemplate{*tmpl}, nil
+			}
+			if !errors.Is(err, templates.ErrTemplateNotFound) {
+				return nil, err
+			}
+		}
+	}
+	return loadTemplateCatalog(ctx, client, config)
+}
+
+// fetchTemplate fetches a single template by name, falling back to the
+// catalog when the API doesn't know it, as APIs predating single-template
+// lookups answer 404 for every name
+func fetchTemplate(ctx context.Context, client *templates.Client, name string, noCache bool) (*templates.ClaimTemplate, error) {
+	tmpl, err := client.GetTemplateContext(ctx, name)
+	if !errors.Is(err, templates.ErrTemplateNotFound) {
+		return tmpl, err
+	}
+
+	available, err := fetchCatalogTemplates(ctx, client, noCache)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range available {
+		if t.Metadata.Name == name {
+			return &available[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", templates.ErrTemplateNotFound, name)
+}
+
+// fetchCatalogTemplates lists the templates from the cached catalog while it
+// is younger than templates.DefaultCatalogTTL, or from the API with noCache
+func fetchCatalogTemplates(ctx context.Context, client *templates.Client, noCache bool) ([]templates.ClaimTemplate, error) {
+	if noCache {
+		return client.FetchTemplatesContext(ctx)
+	}
+	return client.FetchTemplatesCachedContext(ctx, templates.DefaultCatalogTTL)
+}
+
+// aliasesPath returns the template aliases file: $CLAIMS_ALIASES_FILE or the default location
+func aliasesPath() (string, error) {
+	if path := os.Getenv("CLAIMS_ALIASES_FILE"); path != "" {
+		return path, nil
+	}
+	return templates.DefaultAliasesPath()
+}
+
+// loadAliases loads the configured template aliases; no file means no aliases
+func (c *RenderConfig) loadAliases() (templates.Aliases, error) {
+	path := c.AliasesPath
+	if path == "" {
+		var err error
+		if path, err = aliasesPath(); err != nil {
+			return templates.Aliases{}, nil
+		}
+	}
+	return templates.LoadAliases(path)
+}
+
+// resolveTemplateAlias maps a user-supplied template name to its catalog name.
+// A real template wins over an alias of the same name, with a warning.
+func resolveTemplateAlias(name string, aliases templates.Aliases, known map[string]bool) string {
+	resolved, shadowed := aliases.Resolve(name, known)
+	if shadowed {
+		warnf("template alias %q is shadowed by a template of the same name; using the template", name)
+	} else if resolved != name {
+		fmt.Printf("Template alias %s -> %s\n", name, resolved)
+	}
+	return resolved
+}
+
+// catalogNames returns the set of template names in a catalog
+func catalogNames(available []templates.ClaimTemplate) map[string]bool {
+	known := make(map[string]bool, len(available))
+	for _, t := range available {
+		known[t.Metadata.Name] = true
+	}
+	return known
+}
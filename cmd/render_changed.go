@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/stuttgart-things/claims/internal/gitops"
+	"github.com/stuttgart-things/claims/internal/params"
+)
+
+// paramsParseOptions returns the params file options for --expand-env and
+// --strict-env; strict implies expansion
+func paramsParseOptions(expand, strict bool) params.ParseOptions {
+	return params.ParseOptions{ExpandEnv: expand || strict, StrictEnv: strict}
+}
+
+// loadParamsFileTemplates returns the entries of the params file(s), merged
+// in --params-file order. With --changed-only only entries that differ from
+// the base ref are returned.
+func loadParamsFileTemplates(config *RenderConfig) ([]params.TemplateParams, error) {
+	opts := paramsParseOptions(config.ExpandEnv, config.StrictEnv)
+	if !config.ChangedOnly {
+		files := make([]*params.ParameterFile, 0, len(config.ParamsFiles))
+		for _, pattern := range config.ParamsFiles {
+			pf, err := params.ParseFilesWith(pattern, opts)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, pf)
+		}
+		return params.MergeParameterFiles(files).Templates, nil
+	}
+	if len(config.ParamsFiles) > 1 {
+		return nil, fmt.Errorf("--changed-only supports a single --params-file")
+	}
+	return changedParamsTemplates(config.ParamsFiles[0], config.BaseRef, opts)
+}
+
+// changedParamsTemplates compares each file matched by pattern with its
+// version at baseRef and returns the entries that were added or changed.
+// A file that doesn't exist at baseRef counts as entirely new.
+func changedParamsTemplates(pattern, baseRef string, opts params.ParseOptions) ([]params.TemplateParams, error) {
+	if pattern == params.Stdin {
+		return nil, fmt.Errorf("--changed-only compares params files with git and can't read params from stdin")
+	}
+	files, err := params.ExpandFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []params.TemplateParams
+	for _, f := range files {
+		pf, err := params.ParseFileWith(f, opts)
+		if err != nil {
+			return nil, err
+		}
+		base, err := paramsAtRevision(f, baseRef, opts)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, params.ChangedTemplates(pf.Templates, base)...)
+	}
+	return changed, nil
+}
+
+// paramsAtRevision parses the params file at path as committed at rev. It
+// returns no entries if the file didn't exist at rev.
+func paramsAtRevision(path, rev string, opts params.ParseOptions) ([]params.TemplateParams, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := findRepoRoot(filepath.Dir(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("--changed-only: %s is not in a git repository", path)
+	}
+	relPath, err := filepath.Rel(repoRoot, absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := gitops.New(repoRoot, "", "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := g.FileAtRevision(rev, relPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("--changed-only: %w", err)
+	}
+
+	pf, err := params.ParseWith(bytes.NewReader(data), filepath.Ext(path), opts)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s at %s: %w", path, rev, err)
+	}
+	return pf.Templates, nil
+}
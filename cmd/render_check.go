@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// checkRequiredOnly is the --check-required-only pre-flight: it resolves the
+// templates and parameters like a non-interactive render, fetches the catalog
+// for their schema and fails listing the required parameters each entry
+// leaves unset. Nothing is rendered, and types, enums and patterns are not
+// checked (see 'claims validate').
+func checkRequiredOnly(ctx context.Context, config *RenderConfig, out io.Writer) error {
+	if len(config.ParamsFiles) == 0 && len(config.Templates) == 0 && len(config.RegistryFilter) == 0 {
+		return fmt.Errorf("--check-required-only requires --params-file, --templates or --params-from-registry-filter")
+	}
+
+	templateParams, err := resolveTemplateParams(config)
+	if err != nil {
+		return err
+	}
+
+	client, err := newRenderClient(config)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	names := make([]string, len(templateParams))
+	for i, tp := range templateParams {
+		names[i] = tp.Name
+	}
+	available, err := loadRenderTemplates(ctx, client, config, names)
+	if err != nil {
+		return fmt.Errorf("fetching templates: %w", err)
+	}
+
+	aliases, err := config.loadAliases()
+	if err != nil {
+		return err
+	}
+	known := catalogNames(available)
+	lookup := make(map[string]*templates.ClaimTemplate)
+	for i, t := range available {
+		lookup[t.Metadata.Name] = &available[i]
+	}
+
+	var problems []paramsProblem
+	for i, tp := range templateParams {
+		name := resolveTemplateAlias(tp.Name, aliases, known)
+		tmpl := lookup[name]
+		if tmpl == nil {
+			problems = append(problems, paramsProblem{Entry: i + 1, Template: name, Message: "template not found"})
+			continue
+		}
+		for _, p := range templates.MissingRequired(tmpl, tp.Parameters) {
+			problems = append(problems, paramsProblem{Entry: i + 1, Template: name, Parameter: p, Message: "required parameter is not set"})
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("missing required parameters:\n%s", formatParamsProblems(problems))
+	}
+
+	fmt.Fprintf(out, "OK: all required parameters set for %s\n", plural(len(templateParams), "template"))
+	return nil
+}
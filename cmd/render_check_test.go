@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestCheckRequiredOnly(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Spec.Parameters = append(vm.Spec.Parameters,
+		templates.Parameter{Name: "datacenter", Type: "string", Required: true},
+		templates.Parameter{Name: "size", Type: "string", Enum: []string{"small", "large"}},
+	)
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm, testTemplate("postgres")})
+
+	paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web
+  - name: postgres
+    parameters:
+      name: db
+  - name: vsphere-vm
+    parameters:
+      name: api
+      datacenter: dc1
+      size: huge
+`
+	if err := os.WriteFile(paramsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputDir := t.TempDir()
+	config := &RenderConfig{
+		APIUrl:      server.URL,
+		ParamsFiles: []string{paramsFile},
+		OutputDir:   outputDir,
+		CatalogPath: filepath.Join(t.TempDir(), "catalog.json"),
+	}
+
+	var out bytes.Buffer
+	err := checkRequiredOnly(context.Background(), config, &out)
+	if err == nil {
+		t.Fatal("expected missing required parameters")
+	}
+	if want := "entry 1 (vsphere-vm): datacenter: required parameter is not set"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected %q in the error, got %v", want, err)
+	}
+	// Only required parameters are checked: the enum violation of entry 3 isn't reported
+	if strings.Contains(err.Error(), "entry 2") || strings.Contains(err.Error(), "entry 3") {
+		t.Errorf("expected only entry 1 to be reported, got %v", err)
+	}
+
+	// Nothing is rendered
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("expected no files to be written, got %d", len(entries))
+	}
+
+	config.ParamsFiles = nil
+	config.Templates = []string{"vsphere-vm"}
+	config.InlineParamsRaw = []string{"datacenter=dc1"}
+	out.Reset()
+	if err := checkRequiredOnly(context.Background(), config, &out); err != nil {
+		t.Fatalf("checkRequiredOnly: %v", err)
+	}
+	if !strings.Contains(out.String(), "OK: all required parameters set for 1 template") {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
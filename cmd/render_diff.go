@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/stuttgart-things/claims/internal/textdiff"
+)
+
+// DiffResult returns a unified diff from old, the content of the file at
+// path, to the newly rendered content, or "" if they are equal. An empty old
+// is a new file: it is diffed from /dev/null so every line is an addition.
+func DiffResult(old, new, path string) string {
+	from := path
+	if old == "" {
+		from = "/dev/null"
+	}
+	return textdiff.Unified(old, new, from, path+" (rendered)", 3)
+}
+
+// writeResultDiffs writes a diff of each successful result against the file
+// it would be written to, and returns how many of the files change and how
+// many were compared. Failed results are skipped.
+func writeResultDiffs(w io.Writer, results []RenderResult, config OutputConfig) (changed, total int) {
+	type target struct{ path, content string }
+	var targets []target
+	for _, r := range results {
+		if r.Error != nil {
+			fmt.Fprintf(w, "# Skipping failed render: %s/%s - %v\n", r.TemplateName, r.ResourceName, r.Error)
+			continue
+		}
+		if splitsOutput(r, config) {
+			targets = append(targets, target{resultPath(r, config), r.Content})
+		}
+	}
+	if combined := combinedResults(results, config); hasSuccessfulResult(combined) {
+		targets = append(targets, target{combinedFilePath(combined, config), combineResults(combined)})
+	}
+
+	for _, t := range targets {
+		existing, err := os.ReadFile(t.path)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(w, "# Cannot read %s: %v\n", t.path, err)
+			continue
+		}
+		if diff := DiffResult(string(existing), t.content, t.path); diff != "" {
+			changed++
+			fmt.Fprint(w, diff)
+		}
+	}
+	return changed, len(targets)
+}
+
+// printResultDiffs prints a unified diff of each successful result against
+// the file it would be written to, then how many files would change
+func printResultDiffs(results []RenderResult, config OutputConfig) {
+	fmt.Println("\n=== DIFF ONLY - No files written ===")
+	changed, total := writeResultDiffs(os.Stdout, results, config)
+	fmt.Printf("\n%d of %s would change\n", changed, plural(total, "file"))
+}
+
+// printRenderDiff prints the --diff of the results against the files on disk
+// before they are written
+func printRenderDiff(results []RenderResult, config OutputConfig) {
+	fmt.Println("\n=== DIFF against files on disk ===")
+	changed, total := writeResultDiffs(os.Stdout, results, config)
+	verb := "will change"
+	if config.DryRun {
+		verb = "would change"
+	}
+	fmt.Printf("\n%d of %s %s\n", changed, plural(total, "file"), verb)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffResult(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "unchanged",
+			old:  "kind: VM\nname: web\n",
+			new:  "kind: VM\nname: web\n",
+			want: "",
+		},
+		{
+			name: "changed value",
+			old:  "kind: VM\nname: web\ncpu: 2\n",
+			new:  "kind: VM\nname: web\ncpu: 4\n",
+			want: "--- out/vm-web.yaml\n+++ out/vm-web.yaml (rendered)\n@@ -1,3 +1,3 @@\n kind: VM\n name: web\n-cpu: 2\n+cpu: 4\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "kind: VM\nname: web\n",
+			want: "--- /dev/null\n+++ out/vm-web.yaml (rendered)\n@@ -0,0 +1,2 @@\n+kind: VM\n+name: web\n",
+		},
+		{
+			name: "removed line",
+			old:  "kind: VM\nname: web\ndisk: 10Gi\n",
+			new:  "kind: VM\nname: web\n",
+			want: "--- out/vm-web.yaml\n+++ out/vm-web.yaml (rendered)\n@@ -1,3 +1,2 @@\n kind: VM\n name: web\n-disk: 10Gi\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffResult(tt.old, tt.new, "out/vm-web.yaml"); got != tt.want {
+				t.Errorf("DiffResult() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteResultDiffs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "vm-web.yaml"), []byte("kind: VM\nname: web\ncpu: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vm-db.yaml"), []byte("kind: VM\nname: db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []RenderResult{
+		{TemplateName: "vm", ResourceName: "web", Content: "kind: VM\nname: web\ncpu: 4\n"},
+		{TemplateName: "vm", ResourceName: "db", Content: "kind: VM\nname: db\n"},
+		{TemplateName: "vm", ResourceName: "cache", Content: "kind: VM\nname: cache\n"},
+		{TemplateName: "vm", ResourceName: "broken", Error: os.ErrInvalid},
+	}
+	config := OutputConfig{Directory: dir, FilenamePattern: "{{.template}}-{{.name}}.yaml"}
+
+	var out bytes.Buffer
+	changed, total := writeResultDiffs(&out, results, config)
+	if changed != 2 || total != 3 {
+		t.Errorf("changed/total = %d/%d, want 2/3", changed, total)
+	}
+	for _, want := range []string{"-cpu: 2\n+cpu: 4", "--- /dev/null\n+++ " + filepath.Join(dir, "vm-cache.yaml"), "Skipping failed render: vm/broken"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in diff output:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "vm-db.yaml") {
+		t.Errorf("expected no diff for the unchanged file:\n%s", out.String())
+	}
+}
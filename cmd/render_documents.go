@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenderedDoc is one YAML document of a rendered claim
+type RenderedDoc struct {
+	Kind      string
+	Name      string
+	Namespace string
+	Raw       string // the document's text, without the --- separator
+}
+
+// documentSeparator matches a --- line separating YAML documents
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*(?:#.*)?\r?$`)
+
+// parseDocuments splits rendered content on --- lines into its documents.
+// Documents holding only whitespace or comments are skipped; a document
+// that isn't valid YAML is kept with just its Raw text.
+func parseDocuments(content string) []RenderedDoc {
+	var docs []RenderedDoc
+	for _, raw := range documentSeparator.Split(content, -1) {
+		raw = strings.Trim(raw, "\r\n")
+		var doc struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		var node yaml.Node
+		if err := yaml.Unmarshal([]byte(raw), &node); err == nil && len(node.Content) == 0 {
+			continue // empty or comments only
+		}
+		if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+			docs = append(docs, RenderedDoc{Raw: raw})
+			continue
+		}
+		docs = append(docs, RenderedDoc{
+			Kind:      doc.Kind,
+			Name:      doc.Metadata.Name,
+			Namespace: doc.Metadata.Namespace,
+			Raw:       raw,
+		})
+	}
+	return docs
+}
+
+// namespace returns the namespace of the first rendered document that sets
+// one, or ""
+func (r RenderResult) namespace() string {
+	for _, d := range r.Documents {
+		if d.Namespace != "" {
+			return d.Namespace
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDocuments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []RenderedDoc
+	}{
+		{
+			name:    "single document",
+			content: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: apps\n",
+			want: []RenderedDoc{
+				{Kind: "ConfigMap", Name: "web", Namespace: "apps", Raw: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: apps"},
+			},
+		},
+		{
+			name:    "multiple documents",
+			content: "---\nkind: Namespace\nmetadata:\n  name: apps\n---\nkind: PersistentVolumeClaim\nmetadata:\n  name: data\n  namespace: apps\n--- # trailing\n",
+			want: []RenderedDoc{
+				{Kind: "Namespace", Name: "apps", Raw: "kind: Namespace\nmetadata:\n  name: apps"},
+				{Kind: "PersistentVolumeClaim", Name: "data", Namespace: "apps", Raw: "kind: PersistentVolumeClaim\nmetadata:\n  name: data\n  namespace: apps"},
+			},
+		},
+		{
+			name:    "empty and comment-only documents are skipped",
+			content: "# generated\n---\n\n---\nkind: VM\nmetadata:\n  name: web\n",
+			want: []RenderedDoc{
+				{Kind: "VM", Name: "web", Raw: "kind: VM\nmetadata:\n  name: web"},
+			},
+		},
+		{
+			name:    "separator inside a block scalar is kept",
+			content: "kind: ConfigMap\nmetadata:\n  name: script\ndata:\n  run.sh: |\n    ---\n    echo hi\n",
+			want: []RenderedDoc{
+				{Kind: "ConfigMap", Name: "script", Raw: "kind: ConfigMap\nmetadata:\n  name: script\ndata:\n  run.sh: |\n    ---\n    echo hi"},
+			},
+		},
+		{
+			name:    "invalid YAML keeps the raw text",
+			content: "kind: [unclosed\n",
+			want:    []RenderedDoc{{Raw: "kind: [unclosed"}},
+		},
+		{
+			name:    "CRLF line endings",
+			content: "kind: VM\r\nmetadata:\r\n  name: web\r\n---\r\nkind: VM\r\nmetadata:\r\n  name: db\r\n",
+			want: []RenderedDoc{
+				{Kind: "VM", Name: "web", Raw: "kind: VM\r\nmetadata:\r\n  name: web"},
+				{Kind: "VM", Name: "db", Raw: "kind: VM\r\nmetadata:\r\n  name: db"},
+			},
+		},
+		{name: "empty", content: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseDocuments(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDocuments() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderResultNamespace(t *testing.T) {
+	r := RenderResult{Documents: parseDocuments("kind: Namespace\nmetadata:\n  name: apps\n---\nkind: VM\nmetadata:\n  name: web\n  namespace: apps\n")}
+	if got := r.namespace(); got != "apps" {
+		t.Errorf("namespace() = %q, want apps", got)
+	}
+	if got := (RenderResult{}).namespace(); got != "" {
+		t.Errorf("namespace() without documents = %q, want empty", got)
+	}
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/stuttgart-things/claims/internal/params"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// API URL origins reported by --explain
+const (
+	APIURLFromFlag    = "--api-url"
+	APIURLFromEnv     = "$CLAIM_API_URL"
+	APIURLFromDefault = "default"
+)
+
+// resolveAPIURL returns the API URL from the --api-url flag, $CLAIM_API_URL
+// or the built-in default, together with where it came from
+func resolveAPIURL(flagValue string) (string, string) {
+	if flagValue != "" {
+		return flagValue, APIURLFromFlag
+	}
+	if env := os.Getenv("CLAIM_API_URL"); env != "" {
+		return env, APIURLFromEnv
+	}
+	return "http://localhost:8080", APIURLFromDefault
+}
+
+// explainRender describes what a render with this configuration would do,
+// without calling the API or touching files. Parameters are annotated with
+// the source that won: --param over the params file over template defaults.
+// Template defaults are only known when a cached catalog is available.
+func explainRender(config *RenderConfig) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Render plan (--explain: nothing is rendered or written)\n\n")
+
+	// API
+	sb.WriteString(fmt.Sprintf("API URL:   %s (from %s)\n", config.APIUrls[0], config.APIUrlSource))
+	if len(config.APIUrls) > 1 {
+		sb.WriteString(fmt.Sprintf("           %d endpoints configured; non-interactive uses the first, interactive prompts\n", len(config.APIUrls)))
+	}
+	mode := "non-interactive"
+	if config.Interactive {
+		mode = "interactive"
+	}
+	sb.WriteString(fmt.Sprintf("Mode:      %s\n", mode))
+	if config.TemplateVersion != "" {
+		sb.WriteString(fmt.Sprintf("Version:   %s (checked against the API before rendering)\n", config.TemplateVersion))
+	}
+
+	// Templates and parameters
+	sb.WriteString("\nTemplates:\n")
+	if config.Interactive && len(config.ParamsFiles) == 0 && len(config.Templates) == 0 {
+		sb.WriteString("  selected interactively\n")
+	} else if err := explainTemplates(&sb, config); err != nil {
+		return "", err
+	}
+
+	// Output
+	sb.WriteString("\nOutput:\n")
+	sb.WriteString(fmt.Sprintf("  Directory: %s\n", config.OutputDir))
+	switch {
+	case config.SingleFile:
+		sb.WriteString("  Files:     one combined file (--single-file)\n")
+	default:
+		sb.WriteString(fmt.Sprintf("  Pattern:   %s\n", config.FilenamePattern))
+	}
+	if config.FileMode == "append" {
+		sb.WriteString("  Existing files are appended to (--file-mode append)\n")
+	}
+	if config.DryRun {
+		sb.WriteString("  Dry run: output is printed, not written\n")
+	}
+	if config.DiffOnly {
+		sb.WriteString("  Diff only: changes against the files on disk are printed, nothing is written\n")
+	}
+
+	// Registry
+	sb.WriteString("\nRegistry:  ")
+	switch {
+	case config.DryRun:
+		sb.WriteString("not updated (--dry-run)\n")
+	case config.DiffOnly:
+		sb.WriteString("not updated (--diff-only)\n")
+	default:
+		if repoRoot, err := findRepoRoot(config.OutputDir); err == nil {
+			sb.WriteString(registryPathForRepo(repoRoot) + "\n")
+		} else {
+			sb.WriteString("not updated (output directory is not in a git repository)\n")
+		}
+	}
+
+	if repoRoot, err := findRepoRoot(config.OutputDir); err == nil && !config.DryRun && !config.DiffOnly {
+		if path := categoryKustomizationPath(repoRoot, filepath.Join(staticDir(config.OutputDir), "claim.yaml")); path != "" {
+			sb.WriteString(fmt.Sprintf("Kustomize: add written files to %s\n", path))
+		}
+	}
+	if config.Kustomization != "" && !config.DryRun && !config.DiffOnly {
+		sb.WriteString(fmt.Sprintf("Kustomize: add written files to %s\n", kustomizationFilePath(config.Kustomization)))
+	}
+	if config.RootKustomize && !config.DryRun && !config.DiffOnly {
+		sb.WriteString(fmt.Sprintf("Kustomize: list written files in %s\n", rootKustomizationPath(config.OutputDir)))
+	}
+
+	// Git and PR
+	sb.WriteString("\nGit:       ")
+	sb.WriteString(explainGitPlan(config) + "\n")
+
+	return sb.String(), nil
+}
+
+// explainTemplates writes each template with its merged parameters and their origins
+func explainTemplates(sb *strings.Builder, config *RenderConfig) error {
+	resolved, err := resolveTemplateParams(config)
+	if err != nil {
+		return err
+	}
+
+	// Re-read the unmerged inputs to attribute each value to its source
+	var fileTemplates []params.TemplateParams
+	if len(config.ParamsFiles) > 0 {
+		fileTemplates, err = loadParamsFileTemplates(config)
+		if err != nil {
+			return err
+		}
+	}
+	inline, err := parseInlineParams(config)
+	if err != nil {
+		return err
+	}
+	set, err := params.ParseSetParams(config.SetParamsRaw)
+	if err != nil {
+		return err
+	}
+	fileYAML, err := params.ParseFileParams(config.ParamFileYAMLRaw, "yaml")
+	if err != nil {
+		return err
+	}
+	fileJSON, err := params.ParseFileParams(config.ParamFileJSONRaw, "json")
+	if err != nil {
+		return err
+	}
+
+	catalog := explainCatalog(config)
+	if catalog == nil {
+		sb.WriteString("  (template defaults not shown: no cached catalog for this API URL)\n")
+	}
+
+	aliases, err := config.loadAliases()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(catalog))
+	for name := range catalog {
+		known[name] = true
+	}
+
+	if config.ChangedOnly {
+		sb.WriteString(fmt.Sprintf("  only entries changed since %s (--changed-only)\n", config.BaseRef))
+	}
+	if config.OnlyNew {
+		sb.WriteString("  entries already active in the registry are skipped (--only-new)\n")
+	}
+	registryRerender := len(config.RegistryFilter) > 0
+	if registryRerender {
+		sb.WriteString(fmt.Sprintf("  registry claims matching %s, written back to their registry paths (--params-from-registry-filter)\n", strings.Join(config.RegistryFilter, ", ")))
+	}
+	if len(resolved) == 0 {
+		sb.WriteString("  none\n")
+	}
+
+	for i, tp := range resolved {
+		var fileParams map[string]any
+		if i < len(fileTemplates) {
+			fileParams = fileTemplates[i].Parameters
+		}
+		// With --templates, inline params only apply to the listed templates
+		inlineApplied := len(config.Templates) == 0 || slices.Contains(config.Templates, tp.Name)
+		tp.Name = resolveTemplateAlias(tp.Name, aliases, known)
+
+		type line struct{ key, value, origin string }
+		var lines []line
+		for k, v := range tp.Parameters {
+			origin := "params file"
+			if registryRerender {
+				origin = "registry"
+			}
+			if iv, ok := inline[k]; inlineApplied && ok && !params.IsUnset(iv) {
+				origin = "--param"
+			}
+			if _, ok := fileYAML[k]; inlineApplied && ok {
+				origin = "--param-file-yaml"
+			}
+			if _, ok := fileJSON[k]; inlineApplied && ok {
+				origin = "--param-file-json"
+			}
+			if sv, ok := set[k]; inlineApplied && ok && !params.IsUnset(sv) {
+				origin = "--set"
+			}
+			lines = append(lines, line{k, fmt.Sprintf("%v", v), origin})
+		}
+		if inlineApplied {
+			for k, v := range inline {
+				if _, inFile := fileParams[k]; params.IsUnset(v) && inFile {
+					lines = append(lines, line{k, "(unset)", "--param overrides params file"})
+				}
+			}
+		}
+		if tmpl := catalog[tp.Name]; tmpl != nil {
+			for _, p := range tmpl.Spec.Parameters {
+				if _, set := tp.Parameters[p.Name]; set || p.Default == nil {
+					continue
+				}
+				if iv, ok := inline[p.Name]; inlineApplied && ok && params.IsUnset(iv) {
+					continue
+				}
+				lines = append(lines, line{p.Name, fmt.Sprintf("%v", p.Default), "template default"})
+			}
+		}
+		sort.Slice(lines, func(a, b int) bool { return lines[a].key < lines[b].key })
+
+		// Mirror runNonInteractive: seed the default name before affixing it
+		jobParams := tp.Parameters
+		if config.AffixNameParam {
+			if _, ok := jobParams["name"]; !ok {
+				if d := templateNameDefault(catalog[tp.Name]); d != "" {
+					jobParams = params.MergeParams(jobParams, map[string]any{"name": d})
+				}
+			}
+			jobParams = config.affixNameParam(jobParams)
+		}
+		job := renderJob{TemplateName: tp.Name, Params: jobParams}
+		resourceName := jobResourceName(job, catalog[tp.Name], config)
+		sb.WriteString(fmt.Sprintf("  %s (resource %s", tp.Name, resourceName))
+		planned := RenderResult{TemplateName: tp.Name, ResourceName: resourceName, Output: tp.Output}
+		plannedConfig := OutputConfig{FilenamePattern: config.FilenamePattern, SingleFile: config.SingleFile}
+		if !registryRerender && splitsOutput(planned, plannedConfig) {
+			if path, err := resultFilePath(planned, plannedConfig); err == nil {
+				sb.WriteString(", file " + path)
+			}
+		}
+		sb.WriteString(")\n")
+
+		width := 0
+		for _, l := range lines {
+			width = max(width, len(l.key))
+		}
+		for _, l := range lines {
+			sb.WriteString(fmt.Sprintf("    %-*s = %s  [%s]\n", width, l.key, l.value, l.origin))
+		}
+		if len(tp.Secrets) > 0 || len(config.InlineSecretsRaw) > 0 {
+			sb.WriteString("    secrets: values provided (not shown)\n")
+		}
+	}
+	return nil
+}
+
+// explainCatalog returns the cached catalog keyed by template name, or nil if
+// there is no usable cache for the configured API URL
+func explainCatalog(config *RenderConfig) map[string]*templates.ClaimTemplate {
+	path, err := config.catalogPath()
+	if err != nil {
+		return nil
+	}
+	cat, err := templates.LoadCatalog(path)
+	if err != nil || cat.APIUrl != config.APIUrls[0] {
+		return nil
+	}
+	lookup := make(map[string]*templates.ClaimTemplate, len(cat.Items))
+	for i, t := range cat.Items {
+		lookup[t.Metadata.Name] = &cat.Items[i]
+	}
+	return lookup
+}
+
+// explainGitPlan summarizes the configured git and PR steps
+func explainGitPlan(config *RenderConfig) string {
+	gc := config.GitConfig
+	if gc == nil || (!gc.Commit && !gc.Push) {
+		return "none"
+	}
+	if config.DryRun {
+		return "skipped (--dry-run)"
+	}
+	if config.DiffOnly {
+		return "skipped (--diff-only)"
+	}
+
+	var steps []string
+	if gc.RepoURL != "" {
+		steps = append(steps, "clone "+gc.RepoURL)
+	}
+	if gc.Branch != "" {
+		if gc.CreateBranch {
+			steps = append(steps, "create branch "+gc.Branch)
+		} else {
+			steps = append(steps, "check out branch "+gc.Branch)
+		}
+	}
+	commit := "commit"
+	if gc.GPGSign || gc.GPGKey != "" {
+		commit = "GPG-signed commit"
+	}
+	if gc.SignOff {
+		commit += " with Signed-off-by"
+	}
+	steps = append(steps, commit)
+	if gc.Push && gc.Remote == "" {
+		steps = append(steps, "push to the repository's remote")
+	} else if gc.Push {
+		steps = append(steps, "push to "+gc.Remote)
+	}
+	if pr := config.PRConfig; pr != nil && pr.Create {
+		prStep := "open PR into " + pr.BaseBranch
+		if len(pr.Labels) > 0 {
+			prStep += " with labels " + strings.Join(pr.Labels, ", ")
+		}
+		steps = append(steps, prStep)
+	}
+	return strings.Join(steps, ", then ")
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestResolveAPIURL(t *testing.T) {
+	t.Setenv("CLAIM_API_URL", "http://env:8080")
+
+	if url, src := resolveAPIURL("http://flag:8080"); url != "http://flag:8080" || src != APIURLFromFlag {
+		t.Errorf("flag: got %s from %s", url, src)
+	}
+	if url, src := resolveAPIURL(""); url != "http://env:8080" || src != APIURLFromEnv {
+		t.Errorf("env: got %s from %s", url, src)
+	}
+
+	t.Setenv("CLAIM_API_URL", "")
+	if url, src := resolveAPIURL(""); url != "http://localhost:8080" || src != APIURLFromDefault {
+		t.Errorf("default: got %s from %s", url, src)
+	}
+}
+
+func TestResolveAPIToken(t *testing.T) {
+	t.Setenv("CLAIM_API_TOKEN", "env-token")
+
+	if got := resolveAPIToken("flag-token"); got != "flag-token" {
+		t.Errorf("flag: got %q", got)
+	}
+	if got := resolveAPIToken(""); got != "env-token" {
+		t.Errorf("env: got %q", got)
+	}
+	client, err := newRenderClient(&RenderConfig{APIUrl: "http://api", APIToken: resolveAPIToken("")})
+	if err != nil {
+		t.Fatalf("newRenderClient: %v", err)
+	}
+	if client.AuthToken != "env-token" {
+		t.Errorf("client token = %q, want env-token", client.AuthToken)
+	}
+
+	t.Setenv("CLAIM_API_TOKEN", "")
+	if got := resolveAPIToken(""); got != "" {
+		t.Errorf("unset: got %q, want no token", got)
+	}
+}
+
+func TestExplainRender_Precedence(t *testing.T) {
+	t.Setenv("CLAIM_API_URL", "http://env:8080")
+	url, source := resolveAPIURL("")
+
+	dir := t.TempDir()
+	paramsFile := filepath.Join(dir, "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: file-vm
+      cpu: 2
+      disk: 50Gi
+`
+	if err := os.WriteFile(paramsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cached catalog supplies the template default for memory
+	catalogPath := filepath.Join(dir, "catalog.json")
+	tmpl := testTemplate("vsphere-vm")
+	tmpl.Spec.Parameters = append(tmpl.Spec.Parameters, templates.Parameter{Name: "memory", Type: "string", Default: "4Gi"})
+	if err := templates.SaveCatalog(catalogPath, &templates.Catalog{APIUrl: url, Items: []templates.ClaimTemplate{tmpl}}); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		APIUrl:          url,
+		APIUrls:         splitAPIURLs(url),
+		APIUrlSource:    source,
+		CatalogPath:     catalogPath,
+		ParamsFiles:     []string{paramsFile},
+		InlineParamsRaw: []string{"name=flag-vm", "disk-"},
+		OutputDir:       filepath.Join(dir, "out"),
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+	}
+
+	plan, err := explainRender(config)
+	if err != nil {
+		t.Fatalf("explainRender: %v", err)
+	}
+
+	wantLines := []string{
+		`API URL:\s+http://env:8080 \(from \$CLAIM_API_URL\)`,
+		`vsphere-vm \(resource flag-vm, file vsphere-vm-flag-vm\.yaml\)`,
+		`name\s+= flag-vm\s+\[--param\]`,
+		`cpu\s+= 2\s+\[params file\]`,
+		`disk\s+= \(unset\)\s+\[--param overrides params file\]`,
+		`memory\s+= 4Gi\s+\[template default\]`,
+		`Git:\s+none`,
+	}
+	for _, want := range wantLines {
+		if !regexp.MustCompile(want).MatchString(plan) {
+			t.Errorf("expected plan to match %q, got:\n%s", want, plan)
+		}
+	}
+	if strings.Contains(plan, "file-vm") {
+		t.Errorf("params file name should be overridden by --param, got:\n%s", plan)
+	}
+	if _, err := os.Stat(config.OutputDir); !os.IsNotExist(err) {
+		t.Error("explain must not create the output directory")
+	}
+}
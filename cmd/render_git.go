@@ -5,45 +5,179 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/huh"
 	"github.com/stuttgart-things/claims/internal/gitops"
+	"github.com/stuttgart-things/claims/internal/redact"
 	"github.com/stuttgart-things/claims/internal/registry"
 )
 
-// executeGitOperations performs git commit and push if configured
-func executeGitOperations(results []RenderResult, config *RenderConfig) error {
-	if config.GitConfig == nil || (!config.GitConfig.Commit && !config.GitConfig.Push) {
+// preflightPush checks push access before any rendering when --check-push is
+// set, or when --dry-run is combined with a push
+func preflightPush(config *RenderConfig) error {
+	gc := config.GitConfig
+	if gc == nil || !(gc.CheckPush || (config.DryRun && gc.Push)) {
 		return nil
 	}
 
-	// Resolve credentials if pushing
-	user, token := config.GitConfig.User, config.GitConfig.Token
-	if config.GitConfig.Push {
-		var err error
-		user, token, err = gitops.ResolveCredentials(user, token)
+	target := gc.RepoURL
+	var err error
+	if target != "" {
+		auth, authErr := gitops.ResolveAuth(target, gc.User, gc.Token, gc.SSHKey, true)
+		if authErr != nil {
+			return authErr
+		}
+		fmt.Println("Checking push access...")
+		err = gitops.CheckRemoteAccessWithAuth(target, auth)
+	} else {
+		target = gc.Remote
+		repoPath, findErr := findRepoRoot(config.OutputDir)
+		if findErr != nil {
+			return fmt.Errorf("output directory is not in a git repository: %w", findErr)
+		}
+		g, openErr := openGitRepo(repoPath, gc, true, config.Interactive)
+		if openErr != nil {
+			return openErr
+		}
+		fmt.Println("Checking push access...")
+		err = g.CheckPushAccess(gc.Remote)
+	}
+	if err != nil {
+		return fmt.Errorf("push access check failed for %s: %w", target, err)
+	}
+
+	fmt.Println(successStyle.Render(fmt.Sprintf("Push access confirmed: %s", target)))
+	return nil
+}
+
+// openGitRepo opens the repository at repoPath and sets the credentials for
+// gc.Remote: the SSH key for an SSH remote, else the HTTP user and token.
+// When pushing or checking push access gc.Remote is settled through
+// resolveGitRemote first; commit-only runs never touch a remote. Missing
+// credentials are only an error if required is set.
+func openGitRepo(repoPath string, gc *GitConfig, required, interactive bool) (*gitops.GitOps, error) {
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		return nil, err
+	}
+	if gc.Push || gc.CheckPush {
+		if err := resolveGitRemote(g, gc, interactive); err != nil {
+			return nil, err
+		}
+	}
+	// Without the remote, push and the access check report it themselves
+	remoteURL, _ := g.GetRemoteURL(gc.Remote)
+	auth, err := gitops.ResolveAuth(remoteURL, gc.User, gc.Token, gc.SSHKey, required)
+	if err != nil {
+		return nil, err
+	}
+	g.SetAuth(auth)
+	if err := configureSigning(g, gc); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// configureSigning sets the key commits are signed with when --gpg-sign or
+// --gpg-key asks for signed commits. A missing key fails instead of letting
+// the commit go out unsigned.
+func configureSigning(g *gitops.GitOps, gc *GitConfig) error {
+	if !gc.GPGSign && gc.GPGKey == "" {
+		return nil
+	}
+	key, err := gitops.ResolveSigningKey(gc.GPGKey, "")
+	if err != nil {
+		return err
+	}
+	g.SetSigningKey(key)
+	return nil
+}
+
+// resolveGitRemote fills in gc.Remote when --git-remote isn't given: the
+// repository's only remote, else the one the user picks when interactive.
+// Non-interactive runs in a repository with several remotes must name one. A
+// repository without remotes keeps "origin", for push to report missing.
+func resolveGitRemote(g *gitops.GitOps, gc *GitConfig, interactive bool) error {
+	if gc.Remote != "" {
+		return nil
+	}
+	remotes, err := g.RemoteNames()
+	if err != nil {
+		return err
+	}
+	switch {
+	case len(remotes) == 0:
+		gc.Remote = "origin"
+	case len(remotes) == 1:
+		gc.Remote = remotes[0]
+	case interactive:
+		remote, err := selectRemote(g, remotes)
 		if err != nil {
 			return err
 		}
-	} else {
-		// For commit only, credentials are optional
-		user, token = gitops.ResolveCredentialsOptional(user, token)
+		gc.Remote = remote
+	default:
+		return fmt.Errorf("the repository has several remotes (%s); choose one with --git-remote", strings.Join(remotes, ", "))
+	}
+	return nil
+}
+
+// selectRemote asks which of remotes to push to. It is a variable so tests
+// can answer the prompt.
+var selectRemote = func(g *gitops.GitOps, remotes []string) (string, error) {
+	options := make([]huh.Option[string], len(remotes))
+	for i, r := range remotes {
+		options[i] = huh.NewOption(pushTarget(g, r), r)
+	}
+	remote := remotes[0]
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Which remote should be pushed to?").
+				Options(options...).
+				Value(&remote),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return "", err
+	}
+	return remote, nil
+}
+
+// executeGitOperations performs git commit and push if configured, recording
+// the commit hash and PR URL on the aggregate
+func executeGitOperations(agg *RenderResults, config *RenderConfig) error {
+	results := agg.Results
+	if config.GitConfig == nil || (!config.GitConfig.Commit && !config.GitConfig.Push) {
+		return nil
 	}
 
 	var g *gitops.GitOps
 	var tmpDir string
 	var err error
 
-	// Clone-based or local workflow
-	if config.GitConfig.RepoURL != "" {
-		fmt.Printf("Cloning %s...\n", config.GitConfig.RepoURL)
-		g, tmpDir, err = gitops.Clone(config.GitConfig.RepoURL, user, token)
+	// Clone-based or local workflow. Credentials are only required for a push.
+	if gc := config.GitConfig; gc.RepoURL != "" {
+		auth, err := gitops.ResolveAuth(gc.RepoURL, gc.User, gc.Token, gc.SSHKey, gc.Push)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cloning %s...\n", gc.RepoURL)
+		g, tmpDir, err = gitops.CloneWithAuth(gc.RepoURL, auth)
 		if err != nil {
 			return err
 		}
 		defer g.Cleanup()
+		if err := configureSigning(g, gc); err != nil {
+			return err
+		}
+		if gc.Remote == "" {
+			gc.Remote = "origin" // the clone's only remote is --git-repo-url
+		}
 
 		// Adjust output directory to be inside cloned repo
 		config.OutputDir = filepath.Join(tmpDir, config.OutputDir)
@@ -53,25 +187,12 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 		if err != nil {
 			return fmt.Errorf("output directory is not in a git repository: %w", err)
 		}
-		g, err = gitops.New(repoPath, user, token)
+		g, err = openGitRepo(repoPath, config.GitConfig, config.GitConfig.Push, config.Interactive)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Create branch if requested
-	if config.GitConfig.CreateBranch && config.GitConfig.Branch != "" {
-		fmt.Printf("Creating branch: %s\n", config.GitConfig.Branch)
-		if err := g.CreateBranch(config.GitConfig.Branch); err != nil {
-			return err
-		}
-	} else if config.GitConfig.Branch != "" {
-		fmt.Printf("Checking out branch: %s\n", config.GitConfig.Branch)
-		if err := g.CheckoutBranch(config.GitConfig.Branch); err != nil {
-			return err
-		}
-	}
-
 	// Collect file paths
 	var filePaths []string
 	for _, r := range results {
@@ -84,12 +205,82 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 		return fmt.Errorf("no files to commit")
 	}
 
-	// Also stage registry.yaml if it was updated
-	repoPath := g.RepoPath
-	registryPath := filepath.Join(repoPath, "claims", "registry.yaml")
-	if _, err := os.Stat(registryPath); err == nil {
+	// Also stage registry.yaml if it was updated; a read-only registry is
+	// left to whatever generates it
+	registryPath := registryPathForRepo(g.RepoPath)
+	if _, err := os.Stat(registryPath); err == nil && !registryReadOnly() {
 		filePaths = append(filePaths, registryPath)
 	}
+	// And the kustomizations the rendered files were added to: their
+	// category's and the --kustomization file
+	staged := make(map[string]bool)
+	for _, r := range results {
+		if r.OutputPath == "" || r.Error != nil {
+			continue
+		}
+		path := categoryKustomizationPath(g.RepoPath, r.OutputPath)
+		if path == "" || staged[path] {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			staged[path] = true
+			filePaths = append(filePaths, path)
+		}
+	}
+	// And the ArgoCD Applications of --argocd-layout
+	if config.ArgoCDLayout {
+		for _, r := range results {
+			if r.OutputPath == "" || r.Error != nil {
+				continue
+			}
+			if path := argoCDApplicationPath(config.OutputDir, r); !staged[path] {
+				if _, err := os.Stat(path); err == nil {
+					staged[path] = true
+					filePaths = append(filePaths, path)
+				}
+			}
+		}
+	}
+	if path := kustomizationFilePath(config.Kustomization); config.Kustomization != "" && !staged[path] {
+		filePaths = append(filePaths, path)
+	}
+	if path := rootKustomizationPath(config.OutputDir); config.RootKustomize {
+		if _, err := os.Stat(path); err == nil {
+			filePaths = append(filePaths, path)
+		}
+	}
+
+	if err := checkIgnoredOutput(g, filePaths, config.Strict); err != nil {
+		return err
+	}
+
+	// In a local checkout, leave the current branch alone when the render
+	// matches what's committed (no new branch, no checkout). A new branch
+	// starts from HEAD, so comparing against HEAD is exact; checking out an
+	// existing branch is handled by the staged-changes check below.
+	if config.GitConfig.RepoURL == "" && (config.GitConfig.Branch == "" || config.GitConfig.CreateBranch) {
+		changed, err := g.HasChanges(filePaths)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Println(successStyle.Render("No changes to commit; skipping commit, push and PR"))
+			return nil
+		}
+	}
+
+	// Create branch if requested
+	if config.GitConfig.CreateBranch && config.GitConfig.Branch != "" {
+		fmt.Printf("Creating branch: %s\n", config.GitConfig.Branch)
+		if err := g.CreateBranch(config.GitConfig.Branch); err != nil {
+			return err
+		}
+	} else if config.GitConfig.Branch != "" {
+		fmt.Printf("Checking out branch: %s\n", config.GitConfig.Branch)
+		if err := g.CheckoutBranch(config.GitConfig.Branch); err != nil {
+			return err
+		}
+	}
 
 	// Stage files
 	fmt.Println("Staging files...")
@@ -97,6 +288,16 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 		return err
 	}
 
+	// Skip commit/push/PR when the rendered output matches what's committed
+	hasChanges, err := g.HasStagedChanges()
+	if err != nil {
+		return err
+	}
+	if !hasChanges {
+		fmt.Println(successStyle.Render("No changes to commit; skipping commit, push and PR"))
+		return nil
+	}
+
 	// Generate commit message
 	message := config.GitConfig.Message
 	if message == "" {
@@ -108,20 +309,29 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 		}
 		message = fmt.Sprintf("Rendered claims: %s", strings.Join(names, ", "))
 	}
+	// The git user, if one is set, authors the commit
+	user, _ := gitops.ResolveCredentialsOptional(config.GitConfig.User, config.GitConfig.Token)
+	authorName, authorEmail := gitops.AuthorIdentity(user, "")
+	if config.GitConfig.SignOff {
+		message = gitops.AppendSignOff(message, authorName, authorEmail)
+	}
 
 	// Commit
 	fmt.Printf("Committing: %s\n", message)
-	if err := g.Commit(message, user, ""); err != nil {
+	hash, err := g.CommitWithHash(message, authorName, authorEmail)
+	if err != nil {
 		return err
 	}
+	agg.GitCommit = hash
+	outcome.setCommit(hash)
+	if branch, err := g.GetCurrentBranch(); err == nil {
+		outcome.setBranch(branch)
+	}
 	fmt.Println(successStyle.Render("Committed successfully"))
 
 	// Push if requested
 	if config.GitConfig.Push {
 		remote := config.GitConfig.Remote
-		if remote == "" {
-			remote = "origin"
-		}
 
 		// Get branch name to push
 		branch := config.GitConfig.Branch
@@ -132,7 +342,20 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 			}
 		}
 
-		fmt.Printf("Pushing to %s...\n", remote)
+		// Show where the push goes; interactive users confirm it first
+		target := pushTarget(g, remote)
+		if config.Interactive {
+			ok, err := confirmPush(target, branch)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				fmt.Println("Push cancelled; the commit is kept locally")
+				return nil
+			}
+		}
+
+		fmt.Printf("Pushing %s to %s...\n", branch, target)
 		if err := g.Push(remote, branch); err != nil {
 			return err
 		}
@@ -141,24 +364,91 @@ func executeGitOperations(results []RenderResult, config *RenderConfig) error {
 		// Create PR if requested (after successful push)
 		if config.PRConfig != nil && config.PRConfig.Create {
 			repoPath := g.RepoPath
-			if err := executePRCreation(results, config, repoPath); err != nil {
+			prURL, err := executePRCreation(results, config, repoPath)
+			if err != nil {
 				return fmt.Errorf("creating pull request: %w", err)
 			}
+			agg.PRUrl = prURL
+			outcome.setPR(prURL)
 		}
 	}
 
 	return nil
 }
 
-// updateRegistryForRender adds entries to claims/registry.yaml for successful renders
+// pushTarget describes a remote by name and URL, e.g.
+// "origin (https://github.com/org/repo.git)". Credentials in the URL are masked.
+func pushTarget(g *gitops.GitOps, remote string) string {
+	url, err := g.GetRemoteURL(remote)
+	if err != nil {
+		return remote
+	}
+	return fmt.Sprintf("%s (%s)", remote, redact.String(url))
+}
+
+// confirmPush asks whether to push branch to target. It is a variable so
+// tests can answer the prompt.
+var confirmPush = func(target, branch string) (bool, error) {
+	confirm := true
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Push %s to %s?", branch, target)).
+				Affirmative("Yes, push").
+				Negative("Cancel").
+				Value(&confirm),
+		),
+	)
+	if err := form.Run(); err != nil {
+		return false, err
+	}
+	return confirm, nil
+}
+
+// printGitSummary prints the commit and PR references for the render, if any
+func printGitSummary(agg *RenderResults) {
+	if agg.GitCommit == "" {
+		return
+	}
+
+	summary := fmt.Sprintf("Committed %s", shortHash(agg.GitCommit))
+	if agg.PRUrl != "" {
+		summary += fmt.Sprintf(", PR: %s", agg.PRUrl)
+	}
+	fmt.Println(successStyle.Render(summary))
+}
+
+// shortHash abbreviates a commit hash to the conventional 7 characters
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// registryPathForRepo returns the repo's existing registry (see
+// registry.Discover), or where a new one is created if there is none
+func registryPathForRepo(repoRoot string) string {
+	if path, err := registry.Discover(repoRoot); err == nil {
+		return path
+	}
+	return filepath.Join(repoRoot, filepath.FromSlash(registry.DefaultPath))
+}
+
+// updateRegistryForRender adds entries to the repo's registry for successful renders
 func updateRegistryForRender(results []RenderResult, config *RenderConfig) {
+	if registryReadOnly() {
+		printRegistryReadOnly()
+		return
+	}
+
 	// Try to find repo root from output directory
 	repoRoot, err := findRepoRoot(config.OutputDir)
 	if err != nil {
 		return // Not in a git repo, skip registry update
 	}
 
-	registryPath := filepath.Join(repoRoot, "claims", "registry.yaml")
+	registryPath := registryPathForRepo(repoRoot)
 
 	// Load or create registry
 	reg, err := registry.Load(registryPath)
@@ -170,20 +460,22 @@ func updateRegistryForRender(results []RenderResult, config *RenderConfig) {
 		reg = registry.NewRegistry()
 	}
 
-	// Determine repository name from git remote (best effort)
+	// Determine repository name from the push remote (best effort). A clone's
+	// origin is --git-repo-url, so both workflows record the same owner/repo
+	// slug, never a URL that could carry credentials.
 	repoName := ""
-	if config.GitConfig != nil && config.GitConfig.RepoURL != "" {
-		repoName = config.GitConfig.RepoURL
-	}
-	if repoName == "" {
-		// Try to read remote URL from local repo
-		g, err := gitops.New(repoRoot, "", "")
-		if err == nil {
-			if url, err := g.GetRemoteURL("origin"); err == nil {
-				repoName = extractRepoSlug(url)
-			}
+	remote := "origin"
+	if config.GitConfig != nil && config.GitConfig.Remote != "" {
+		remote = config.GitConfig.Remote
+	}
+	if g, err := gitops.New(repoRoot, "", ""); err == nil {
+		if url, err := g.GetRemoteURL(remote); err == nil {
+			repoName = extractRepoSlug(url)
 		}
 	}
+	if repoName == "" && config.GitConfig != nil && config.GitConfig.RepoURL != "" {
+		repoName = extractRepoSlug(config.GitConfig.RepoURL)
+	}
 
 	// Resolve git user for createdBy
 	createdBy := "cli"
@@ -191,17 +483,6 @@ func updateRegistryForRender(results []RenderResult, config *RenderConfig) {
 		createdBy = config.GitConfig.User
 	}
 
-	// Compute category from output directory relative to claims/
-	category := ""
-	absOutputDir, _ := filepath.Abs(config.OutputDir)
-	relOut, err := filepath.Rel(filepath.Join(repoRoot, "claims"), absOutputDir)
-	if err == nil && relOut != ".." && !strings.HasPrefix(relOut, "..") {
-		parts := strings.SplitN(relOut, string(filepath.Separator), 2)
-		if len(parts) > 0 && parts[0] != "." {
-			category = parts[0]
-		}
-	}
-
 	updated := false
 	for _, r := range results {
 		if r.Error != nil || r.OutputPath == "" {
@@ -218,13 +499,23 @@ func updateRegistryForRender(results []RenderResult, config *RenderConfig) {
 		entry := registry.ClaimEntry{
 			Name:       r.ResourceName,
 			Template:   r.TemplateName,
-			Category:   category,
+			Category:   registryCategory(repoRoot, filepath.Dir(absOutPath)),
+			Namespace:  r.namespace(),
 			CreatedAt:  time.Now().UTC().Format(time.RFC3339),
 			CreatedBy:  createdBy,
-			Source:     "cli",
+			Source:     registrySourceOrDefault(config.Source),
 			Repository: repoName,
-			Path:       relPath,
+			Path:       filepath.ToSlash(relPath),
 			Status:     "active",
+			Parameters: r.Params,
+		}
+
+		// Re-rendering an existing claim keeps its original creation time
+		// and labels, so identical output leaves registry.yaml unchanged
+		if existing := registry.FindEntry(reg, entry.Name); existing != nil &&
+			existing.Template == entry.Template && existing.Path == entry.Path {
+			entry.CreatedAt = existing.CreatedAt
+			entry.Labels = existing.Labels
 		}
 
 		registry.AddEntry(reg, entry)
@@ -237,11 +528,31 @@ func updateRegistryForRender(results []RenderResult, config *RenderConfig) {
 			return
 		}
 		if err := registry.Save(registryPath, reg); err != nil {
-			fmt.Printf("Warning: could not update registry: %v\n", err)
+			warnf("could not update registry: %v", err)
 		}
 	}
 }
 
+// checkIgnoredOutput warns about output files matched by .gitignore, which
+// staging silently skips; with strict set they are an error instead
+func checkIgnoredOutput(g *gitops.GitOps, filePaths []string, strict bool) error {
+	ignored, err := g.IgnoredFiles(filePaths)
+	if err != nil {
+		warnf("could not check .gitignore: %v", err)
+		return nil
+	}
+	if len(ignored) == 0 {
+		return nil
+	}
+	if strict {
+		return fmt.Errorf("output is ignored by .gitignore and would not be committed: %s", strings.Join(ignored, ", "))
+	}
+	for _, path := range ignored {
+		warnf("%s is ignored by .gitignore and will not be committed", path)
+	}
+	return nil
+}
+
 // findRepoRoot finds the git repository root from a starting path
 func findRepoRoot(startPath string) (string, error) {
 	absPath, err := filepath.Abs(startPath)
@@ -263,23 +574,19 @@ func findRepoRoot(startPath string) (string, error) {
 	}
 }
 
-// extractRepoSlug extracts "owner/repo" from a git remote URL.
-// Supports both HTTPS (https://github.com/owner/repo.git) and SSH (git@github.com:owner/repo.git).
+// extractRepoSlug extracts "owner/repo" from a git remote URL (see
+// gitops.ParseRemoteURL). For nested groups only the innermost group is kept,
+// e.g. "subgroup/repo". Unparseable URLs such as local paths fall back to
+// their last two path elements.
 func extractRepoSlug(url string) string {
-	// Remove trailing .git
-	url = strings.TrimSuffix(url, ".git")
-
-	// SSH format: git@github.com:owner/repo
-	if idx := strings.Index(url, ":"); strings.Contains(url, "@") && idx > 0 {
-		slug := url[idx+1:]
-		return slug
+	if info, err := gitops.ParseRemoteURL(url); err == nil {
+		return path.Base(info.Owner) + "/" + info.Repo
 	}
 
-	// HTTPS format: https://github.com/owner/repo
+	url = strings.TrimSuffix(url, ".git")
 	parts := strings.Split(url, "/")
 	if len(parts) >= 2 {
 		return parts[len(parts)-2] + "/" + parts[len(parts)-1]
 	}
-
 	return url
 }
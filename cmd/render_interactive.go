@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -43,20 +45,77 @@ type TemplateParams struct {
 }
 
 // runInteractive runs the render command in interactive mode
-func runInteractive(config *RenderConfig) error {
-	client := templates.NewClient(config.APIUrl)
-	return runInteractiveRender(client, config)
+func runInteractive(ctx context.Context, config *RenderConfig) error {
+	if err := validateOutputOrder(config.OutputOrder); err != nil {
+		return err
+	}
+	if err := validateParamOrder(config.ParamOrder); err != nil {
+		return err
+	}
+	if err := preflightPush(config); err != nil {
+		return err
+	}
+	if config.TemplateVersion != "" {
+		warnf("--template-version is only supported in non-interactive mode; rendering catalog defaults")
+	}
+	if config.ChangedOnly {
+		warnf("--changed-only is only supported in non-interactive mode; ignoring it")
+	}
+	if config.OnlyNew {
+		warnf("--only-new is only supported in non-interactive mode; ignoring it")
+	}
+	if len(config.RegistryFilter) > 0 {
+		warnf("--params-from-registry-filter is only supported in non-interactive mode; ignoring it")
+	}
+	if config.DiffOnly {
+		warnf("--diff-only is only supported in non-interactive mode; ignoring it")
+	}
+	if config.Diff {
+		warnf("--diff is only supported in non-interactive mode; ignoring it")
+	}
+	if config.ArgoCDLayout {
+		warnf("--argocd-layout is only supported in non-interactive mode; ignoring it")
+	}
+	if config.MergeDuplicates {
+		warnf("--merge-duplicates is only supported in non-interactive mode; ignoring it")
+	}
+	if len(config.MatrixRaw) > 0 {
+		warnf("--matrix is only supported in non-interactive mode; ignoring it")
+	}
+	if config.Apply {
+		warnf("--apply is only supported in non-interactive mode; ignoring it")
+	}
+	if len(config.ParamFileYAMLRaw) > 0 || len(config.ParamFileJSONRaw) > 0 {
+		warnf("--param-file-yaml and --param-file-json are only supported in non-interactive mode; ignoring them")
+	}
+	if config.WatchTemplates {
+		warnf("--watch-templates is only supported in non-interactive mode; ignoring it")
+	}
+	if config.ResourceName != "" {
+		warnf("--resource-name is only supported in non-interactive mode; ignoring it")
+	}
+	client, err := newRenderClient(config)
+	if err != nil {
+		return err
+	}
+	return runInteractiveRender(ctx, client, config)
 }
 
 // runInteractiveRender runs the interactive render flow
-func runInteractiveRender(client *templates.Client, config *RenderConfig) error {
-	// Fetch templates from API
-	templateList, err := client.FetchTemplates()
+func runInteractiveRender(ctx context.Context, client *templates.Client, config *RenderConfig) error {
+	// Fetch templates from API (or the cached catalog when offline)
+	fetchCtx, stop := signalContext(ctx)
+	templateList, err := loadTemplateCatalog(fetchCtx, client, config)
+	stop()
 	if err != nil {
 		return fmt.Errorf("failed to fetch templates: %w", err)
 	}
 
-	fmt.Printf("Loaded %d templates from API\n\n", len(templateList))
+	source := "API"
+	if config.Offline && !config.RefreshCache {
+		source = "cached catalog"
+	}
+	fmt.Printf("Loaded %d templates from %s\n\n", len(templateList), source)
 
 	// Build template map
 	templateMap := make(map[string]*templates.ClaimTemplate)
@@ -67,16 +126,30 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 	// Select templates (multi-select or use config values)
 	var selectedNames []string
 	if len(config.Templates) > 0 {
-		// Validate provided template names
+		aliases, err := config.loadAliases()
+		if err != nil {
+			return err
+		}
+		known := catalogNames(templateList)
+
+		// Resolve aliases and validate provided template names
 		for _, name := range config.Templates {
+			name = resolveTemplateAlias(name, aliases, known)
 			if _, exists := templateMap[name]; !exists {
 				return fmt.Errorf("template not found: %s", name)
 			}
+			selectedNames = append(selectedNames, name)
 		}
-		selectedNames = config.Templates
 	} else {
-		// Interactive multi-select
-		selectedNames, err = selectTemplates(templateList)
+		// Interactive multi-select, narrowed to the templates carrying every --tag
+		candidates := templates.FilterByTags(templateList, config.Tags)
+		if len(candidates) == 0 {
+			return fmt.Errorf("no template has all of the tags: %s", strings.Join(config.Tags, ", "))
+		}
+		if len(config.Tags) > 0 {
+			fmt.Printf("%d template(s) tagged %s\n", len(candidates), strings.Join(config.Tags, ", "))
+		}
+		selectedNames, err = selectTemplates(candidates)
 		if err != nil {
 			return fmt.Errorf("selecting templates: %w", err)
 		}
@@ -105,7 +178,7 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 	}
 
 	// Collect parameters for each selected template
-	allParams, err := collectAllParams(selectedNames, templateMap)
+	allParams, err := collectAllParams(selectedNames, templateMap, config.ParamOrder)
 	if err != nil {
 		return fmt.Errorf("collecting parameters: %w", err)
 	}
@@ -155,11 +228,14 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 
 	// Render all templates
 	fmt.Println("\nRendering templates...")
-	results := renderAllTemplates(client, allParams)
+	results := renderAllTemplates(ctx, client, allParams, config)
+	if renderInterrupted(results) {
+		return fmt.Errorf("render interrupted")
+	}
 
 	// Review loop - allows going back to edit parameters
 	for {
-		action, editIndex, err := ReviewResults(results)
+		action, editIndex, err := ReviewResults(results, config.PreviewLines)
 		if err != nil {
 			return fmt.Errorf("review: %w", err)
 		}
@@ -178,25 +254,29 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 			))
 			fmt.Printf("%s\n\n", tmpl.Metadata.Description)
 
-			newParams, err := collectTemplateParams(tmpl)
+			newParams, err := collectTemplateParams(tmpl, config.ParamOrder)
 			if err != nil {
 				fmt.Printf("Error collecting parameters: %v\n", err)
 				continue // Stay in review loop
 			}
 
 			// Re-render the template
+			newParams = config.affixNameParam(newParams)
 			fmt.Printf("Re-rendering %s... ", tmpl.Metadata.Name)
-			content, err := client.RenderTemplate(tmpl.Metadata.Name, newParams)
+			renderCtx, stop := signalContext(ctx)
+			content, err := client.RenderTemplateContext(renderCtx, tmpl.Metadata.Name, newParams)
+			stop()
 			if err != nil {
 				fmt.Println(errorStyle.Render("failed"))
 				results[editIndex].Error = err
 			} else {
 				fmt.Println(successStyle.Render("done"))
 				results[editIndex].Content = content
+				results[editIndex].Documents = parseDocuments(content)
 				results[editIndex].Params = newParams
 				results[editIndex].Error = nil
 				if name, ok := newParams["name"]; ok {
-					results[editIndex].ResourceName = fmt.Sprintf("%v", name)
+					results[editIndex].ResourceName = resourceNameFromParam(name, config)
 				}
 			}
 			continue // Loop back to review
@@ -235,6 +315,8 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 			SingleFile:      config.SingleFile,
 			DryRun:          config.DryRun,
 			FileMode:        config.FileMode,
+			KeepCRLF:        config.KeepCRLF,
+			Stdout:          config.OutputDir == StdoutDir,
 		}
 	} else {
 		// Get example template and name for filename preview
@@ -269,10 +351,11 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 				return nil
 			}
 			outputConfig = *formConfig
+			outputConfig.KeepCRLF = config.KeepCRLF
 
 			// If git was chosen, collect git options now
 			if destChoice.useGit {
-				gitConfig, err := runGitDetailsForm(destChoice.createPR)
+				gitConfig, err := interactiveGitConfig(config.GitConfig, destChoice.createPR)
 				if err != nil {
 					return fmt.Errorf("git options: %w", err)
 				}
@@ -280,7 +363,11 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 
 				// If PR was chosen, collect PR options
 				if destChoice.createPR {
-					prConfig, err := runPROptionsForm()
+					var existingLabels []string
+					if gitConfig.RepoURL == "" {
+						existingLabels = availablePRLabels(outputConfig.Directory)
+					}
+					prConfig, err := runPROptionsForm(existingLabels)
 					if err != nil {
 						return fmt.Errorf("PR options: %w", err)
 					}
@@ -295,6 +382,7 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 	if err := WriteResults(results, outputConfig); err != nil {
 		return fmt.Errorf("writing output: %w", err)
 	}
+	outcome.addRenderResults(results, outputConfig)
 
 	// Process secrets using values collected earlier
 	config.OutputDir = outputConfig.Directory
@@ -326,17 +414,17 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 
 		secretResults, err := processTemplateSecrets(tmpl, r.Params, secretValues, config)
 		if err != nil {
-			fmt.Printf("%s\n", errorStyle.Render(fmt.Sprintf("Secret processing failed: %v", err)))
+			fmt.Printf("%s\n", renderError(fmt.Sprintf("Secret processing failed: %v", err)))
 			continue
 		}
 
 		for _, sr := range secretResults {
 			if sr.Error != nil {
-				fmt.Printf("%s\n", errorStyle.Render(fmt.Sprintf("  Secret error (%s): %v", sr.SecretName, sr.Error)))
+				fmt.Printf("%s\n", renderError(fmt.Sprintf("  Secret error (%s): %v", sr.SecretName, sr.Error)))
 			} else if config.CombineSecrets && r.OutputPath != "" && !outputConfig.DryRun {
 				// Append encrypted secret to the rendered output file
 				if err := appendToFile(r.OutputPath, sr.Content); err != nil {
-					fmt.Printf("%s\n", errorStyle.Render(fmt.Sprintf("  Failed to combine: %v", err)))
+					fmt.Printf("%s\n", renderError(fmt.Sprintf("  Failed to combine: %v", err)))
 				} else {
 					fmt.Printf("%s\n", successStyle.Render(fmt.Sprintf("  Appended encrypted secret to: %s", r.OutputPath)))
 					// Remove the separate secret file if it was written
@@ -350,16 +438,27 @@ func runInteractiveRender(client *templates.Client, config *RenderConfig) error
 		}
 	}
 
+	// Claims streamed to stdout are neither registered nor committed
+	if outputConfig.Stdout {
+		warnStdoutSkipsGit(config)
+		return nil
+	}
+
 	// Update registry if output was written (and not dry-run)
 	if !outputConfig.DryRun {
 		updateRegistryForRender(results, config)
+		if err := updateRenderKustomizations(results, outputConfig, config); err != nil {
+			return err
+		}
 	}
 
 	// Execute git operations if configured (and not dry-run)
 	if !outputConfig.DryRun && config.GitConfig != nil {
-		if err := executeGitOperations(results, config); err != nil {
+		agg := &RenderResults{Results: results, OutputDir: config.OutputDir}
+		if err := executeGitOperations(agg, config); err != nil {
 			return fmt.Errorf("git operations: %w", err)
 		}
+		printGitSummary(agg)
 	}
 
 	return nil
@@ -567,7 +666,7 @@ func createNewFolder(basePath string) (string, bool, error) {
 
 	// Create the folder
 	if err := os.MkdirAll(newPath, 0755); err != nil {
-		fmt.Println(errorStyle.Render(fmt.Sprintf("Failed to create folder: %v", err)))
+		fmt.Println(renderError(fmt.Sprintf("Failed to create folder: %v", err)))
 		return "", false, nil
 	}
 
@@ -739,13 +838,36 @@ func runOutputFormWithValidation(requireGitRepo bool, resultCount int, exampleTe
 	}, false, nil
 }
 
+// gitDetailsForm runs the git details form. It is a variable so tests can
+// replace the prompt.
+var gitDetailsForm = runGitDetailsForm
+
+// interactiveGitConfig asks for the git details and keeps the options the
+// form doesn't ask for (remote, credentials, SSH key, sign-off and signing)
+// from flags, the GitConfig built from the command line, if any
+func interactiveGitConfig(flags *GitConfig, createPR bool) (*GitConfig, error) {
+	gitConfig, err := gitDetailsForm(createPR)
+	if err != nil {
+		return nil, err
+	}
+	if flags != nil {
+		gitConfig.Remote = flags.Remote
+		gitConfig.User = flags.User
+		gitConfig.Token = flags.Token
+		gitConfig.SSHKey = flags.SSHKey
+		gitConfig.SignOff = flags.SignOff
+		gitConfig.GPGSign = flags.GPGSign
+		gitConfig.GPGKey = flags.GPGKey
+	}
+	return gitConfig, nil
+}
+
 // runGitDetailsForm prompts for git commit details (branch, message, push)
 // If createPR is true, push is implied and user won't be asked about it
 func runGitDetailsForm(createPR bool) (*GitConfig, error) {
 	gitConfig := &GitConfig{
 		Commit: true,
 		Push:   createPR, // PR implies push
-		Remote: "origin",
 	}
 
 	// If creating a PR, we need a new branch (can't PR from main to main)
@@ -849,15 +971,33 @@ func runGitDetailsForm(createPR bool) (*GitConfig, error) {
 	return gitConfig, nil
 }
 
-// runPROptionsForm prompts for PR details (title, description, labels, base branch)
-func runPROptionsForm() (*PRConfig, error) {
+// runPROptionsForm prompts for PR details (title, description, labels, base branch).
+// When the repository's labels are known they are offered as a multi-select;
+// otherwise labels are entered as free text.
+func runPROptionsForm(existingLabels []string) (*PRConfig, error) {
 	var (
-		prTitle       string
-		prDescription string
-		prLabels      string
-		prBase        string = "main"
+		prTitle        string
+		prDescription  string
+		prLabels       string
+		selectedLabels []string
+		prBase         string = "main"
 	)
 
+	var labelField huh.Field
+	if len(existingLabels) > 0 {
+		labelField = huh.NewMultiSelect[string]().
+			Title("Labels").
+			Description("Labels defined in the repository").
+			Options(huh.NewOptions(existingLabels...)...).
+			Value(&selectedLabels)
+	} else {
+		labelField = huh.NewInput().
+			Title("Labels").
+			Description("Comma-separated labels (e.g., infrastructure,automated)").
+			Placeholder("infrastructure,automated").
+			Value(&prLabels)
+	}
+
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewInput().
@@ -872,11 +1012,7 @@ func runPROptionsForm() (*PRConfig, error) {
 				Value(&prDescription).
 				CharLimit(1000),
 
-			huh.NewInput().
-				Title("Labels").
-				Description("Comma-separated labels (e.g., infrastructure,automated)").
-				Placeholder("infrastructure,automated").
-				Value(&prLabels),
+			labelField,
 
 			huh.NewInput().
 				Title("Base branch").
@@ -890,7 +1026,7 @@ func runPROptionsForm() (*PRConfig, error) {
 	}
 
 	// Parse labels
-	var labels []string
+	labels := selectedLabels
 	if prLabels != "" {
 		for _, l := range strings.Split(prLabels, ",") {
 			trimmed := strings.TrimSpace(l)
@@ -940,8 +1076,7 @@ func selectTemplates(available []templates.ClaimTemplate) ([]string, error) {
 	// Build options from filtered templates
 	options := make([]huh.Option[string], len(filtered))
 	for i, t := range filtered {
-		label := fmt.Sprintf("%s - %s", t.Metadata.Name, t.Metadata.Title)
-		options[i] = huh.NewOption(label, t.Metadata.Name)
+		options[i] = huh.NewOption(templateOptionLabel(t), t.Metadata.Name)
 	}
 
 	form := huh.NewForm(
@@ -967,6 +1102,16 @@ func selectTemplates(available []templates.ClaimTemplate) ([]string, error) {
 	return selected, nil
 }
 
+// templateOptionLabel returns the selection label of a template: its name
+// and title, followed by its tags if it has any
+func templateOptionLabel(t templates.ClaimTemplate) string {
+	label := fmt.Sprintf("%s - %s", t.Metadata.Name, t.Metadata.Title)
+	if len(t.Metadata.Tags) > 0 {
+		label += fmt.Sprintf(" [%s]", strings.Join(t.Metadata.Tags, ", "))
+	}
+	return label
+}
+
 // distinctProfiles returns sorted unique profile names from templates.
 func distinctProfiles(available []templates.ClaimTemplate) []string {
 	seen := make(map[string]bool)
@@ -1014,7 +1159,7 @@ func selectProfile(profiles []string) (string, error) {
 }
 
 // collectAllParams collects parameters for all selected templates
-func collectAllParams(selectedNames []string, templateMap map[string]*templates.ClaimTemplate) ([]TemplateParams, error) {
+func collectAllParams(selectedNames []string, templateMap map[string]*templates.ClaimTemplate, order string) ([]TemplateParams, error) {
 	var allParams []TemplateParams
 
 	for i, name := range selectedNames {
@@ -1027,7 +1172,7 @@ func collectAllParams(selectedNames []string, templateMap map[string]*templates.
 		fmt.Printf("%s\n\n", tmpl.Metadata.Description)
 
 		// Collect params for this template
-		params, err := collectTemplateParams(tmpl)
+		params, err := collectTemplateParams(tmpl, order)
 		if err != nil {
 			return nil, fmt.Errorf("collecting params for %s: %w", name, err)
 		}
@@ -1041,8 +1186,50 @@ func collectAllParams(selectedNames []string, templateMap map[string]*templates.
 	return allParams, nil
 }
 
-// collectTemplateParams collects parameters for a single template
-func collectTemplateParams(tmpl *templates.ClaimTemplate) (map[string]any, error) {
+// Parameter ordering in the interactive forms
+const (
+	ParamOrderDeclared      = "declared"       // order the template declares them in
+	ParamOrderRequiredFirst = "required-first" // required parameters first, each part in declared order
+	ParamOrderAlphabetical  = "alphabetical"   // by parameter name
+)
+
+// validateParamOrder checks a --param-order value
+func validateParamOrder(order string) error {
+	switch order {
+	case "", ParamOrderDeclared, ParamOrderRequiredFirst, ParamOrderAlphabetical:
+		return nil
+	default:
+		return fmt.Errorf("invalid --param-order %q: must be %s, %s or %s", order, ParamOrderRequiredFirst, ParamOrderDeclared, ParamOrderAlphabetical)
+	}
+}
+
+// orderParameters returns the parameters in the form order; the template's
+// slice is not modified. An empty order keeps the declared order.
+func orderParameters(parameters []templates.Parameter, order string) []templates.Parameter {
+	ordered := slices.Clone(parameters)
+	switch order {
+	case ParamOrderRequiredFirst:
+		slices.SortStableFunc(ordered, func(a, b templates.Parameter) int {
+			switch {
+			case a.Required == b.Required:
+				return 0
+			case a.Required:
+				return -1
+			default:
+				return 1
+			}
+		})
+	case ParamOrderAlphabetical:
+		slices.SortStableFunc(ordered, func(a, b templates.Parameter) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+	return ordered
+}
+
+// collectTemplateParams collects parameters for a single template, showing
+// the fields in the given --param-order
+func collectTemplateParams(tmpl *templates.ClaimTemplate, order string) (map[string]any, error) {
 	params := make(map[string]any)
 	paramValues := make(map[string]*string)
 	multiValues := make(map[string]*[]string)
@@ -1051,7 +1238,7 @@ func collectTemplateParams(tmpl *templates.ClaimTemplate) (map[string]any, error
 	var formGroups []*huh.Group
 	var currentFields []huh.Field
 
-	for _, p := range tmpl.Spec.Parameters {
+	for _, p := range orderParameters(tmpl.Spec.Parameters, order) {
 		isMultiselect := p.Multiselect && len(p.Enum) > 0
 
 		if isMultiselect {
@@ -1173,41 +1360,65 @@ func createMultiselectField(p templates.Parameter, value *[]string) huh.Field {
 }
 
 // renderAllTemplates renders all templates and returns results
-func renderAllTemplates(client *templates.Client, allParams []TemplateParams) []RenderResult {
-	var results []RenderResult
+func renderAllTemplates(ctx context.Context, client *templates.Client, allParams []TemplateParams, config *RenderConfig) []RenderResult {
+	jobs := make([]renderJob, len(allParams))
+	for i, tp := range allParams {
+		jobs[i] = renderJob{Index: i, TemplateName: tp.TemplateName, Params: config.affixNameParam(tp.Params)}
+	}
+
+	sigCtx, stop := signalContext(ctx)
+	defer stop()
+	ctx, cancel := config.renderContext(sigCtx)
+	defer cancel()
 
-	for _, tp := range allParams {
-		fmt.Printf("  Rendering %s... ", tp.TemplateName)
+	results := renderJobsParallel(jobs, config.Parallel, config.OutputOrder, func(job renderJob) RenderResult {
+		if err := ctx.Err(); err != nil {
+			return RenderResult{TemplateName: job.TemplateName, Params: job.Params, Error: err}
+		}
 
-		content, err := client.RenderTemplate(tp.TemplateName, tp.Params)
+		content, err := client.RenderTemplateContext(ctx, job.TemplateName, job.Params)
 		if err != nil {
-			fmt.Println(errorStyle.Render("failed"))
-			results = append(results, RenderResult{
-				TemplateName: tp.TemplateName,
-				Params:       tp.Params,
+			fmt.Printf("  Rendering %s... %s\n", job.TemplateName, errorStyle.Render("failed"))
+			return RenderResult{
+				TemplateName: job.TemplateName,
+				Params:       job.Params,
 				Error:        err,
-			})
-			continue
+			}
 		}
 
 		// Extract resource name for filename
-		resourceName := "output"
-		if name, ok := tp.Params["name"]; ok {
-			resourceName = fmt.Sprintf("%v", name)
+		resourceName := config.affixResourceName("output")
+		if name, ok := job.Params["name"]; ok {
+			resourceName = resourceNameFromParam(name, config)
 		}
 
-		fmt.Println(successStyle.Render("done"))
-		results = append(results, RenderResult{
-			TemplateName: tp.TemplateName,
+		fmt.Printf("  Rendering %s... %s\n", job.TemplateName, successStyle.Render("done"))
+		return RenderResult{
+			TemplateName: job.TemplateName,
 			ResourceName: resourceName,
 			Content:      content,
-			Params:       tp.Params,
-		})
+			Documents:    parseDocuments(content),
+			Params:       job.Params,
+		}
+	})
+
+	if renderTimedOut(results) {
+		printRenderTimeoutReport(results, config.RenderTimeout)
 	}
 
 	return results
 }
 
+// resourceNameFromParam derives the resource name from a "name" parameter value.
+// The prefix/suffix is applied unless it was already applied to the param itself.
+func resourceNameFromParam(name any, config *RenderConfig) string {
+	resourceName := fmt.Sprintf("%v", name)
+	if config.AffixNameParam {
+		return resourceName
+	}
+	return config.affixResourceName(resourceName)
+}
+
 // splitAPIURLs splits a colon-separated list of API URLs.
 // Colons inside http:// and https:// schemes are preserved.
 func splitAPIURLs(raw string) []string {
@@ -1351,6 +1562,8 @@ func createField(p templates.Parameter, value *string) huh.Field {
 	if p.Pattern != "" {
 		description += fmt.Sprintf(" (pattern: %s)", p.Pattern)
 	}
+	description += constraintHint(p)
+	description += exampleHint(p)
 
 	// If parameter has enum values, use Select
 	if len(p.Enum) > 0 {
@@ -1389,23 +1602,81 @@ func createField(p templates.Parameter, value *string) huh.Field {
 		return huh.NewInput().
 			Title(title).
 			Description(description).
-			Placeholder(fmt.Sprintf("default: %v", p.Default)).
+			Placeholder(fieldPlaceholder(p)).
 			Value(value).
-			Validate(func(s string) error {
-				if s == "" {
-					return nil
-				}
-				if _, err := strconv.Atoi(s); err != nil {
-					return fmt.Errorf("must be a number")
-				}
-				return nil
-			})
+			Validate(inputValidator(p))
 
 	default: // string
 		return huh.NewInput().
 			Title(title).
 			Description(description).
-			Placeholder(fmt.Sprintf("default: %v", p.Default)).
-			Value(value)
+			Placeholder(fieldPlaceholder(p)).
+			Value(value).
+			Validate(inputValidator(p))
 	}
 }
+
+// inputValidator checks a typed form value: integers must be numbers, and
+// values must match the parameter's pattern. Empty input is left to the
+// template default.
+func inputValidator(p templates.Parameter) func(string) error {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if p.Type == "integer" {
+			if _, err := strconv.Atoi(s); err != nil {
+				return fmt.Errorf("must be a number")
+			}
+		}
+		if err := templates.ValidatePattern(p, s); err != nil {
+			return err
+		}
+		return templates.ValidateRange(p, s)
+	}
+}
+
+// fieldPlaceholder is the input placeholder, shown while the field is empty:
+// the parameter's examples when it has no default to prefill, otherwise the
+// default
+func fieldPlaceholder(p templates.Parameter) string {
+	if len(p.Examples) > 0 && p.Default == nil {
+		return "e.g. " + strings.Join(p.Examples, ", ")
+	}
+	return fmt.Sprintf("default: %v", p.Default)
+}
+
+// exampleHint lists the parameter's examples for the form description when
+// its default takes the field, e.g. " (e.g. web-01, db-01)"; "" otherwise
+func exampleHint(p templates.Parameter) string {
+	if len(p.Examples) == 0 || p.Default == nil {
+		return ""
+	}
+	return " (e.g. " + strings.Join(p.Examples, ", ") + ")"
+}
+
+// constraintHint describes the parameter's bounds for the form description,
+// e.g. " (1-64)" or " (at most 63 characters)"; "" without bounds
+func constraintHint(p templates.Parameter) string {
+	var parts []string
+	switch {
+	case p.Min != nil && p.Max != nil:
+		parts = append(parts, fmt.Sprintf("%d-%d", *p.Min, *p.Max))
+	case p.Min != nil:
+		parts = append(parts, fmt.Sprintf("at least %d", *p.Min))
+	case p.Max != nil:
+		parts = append(parts, fmt.Sprintf("at most %d", *p.Max))
+	}
+	switch {
+	case p.MinLength != nil && p.MaxLength != nil:
+		parts = append(parts, fmt.Sprintf("%d-%d characters", *p.MinLength, *p.MaxLength))
+	case p.MinLength != nil:
+		parts = append(parts, fmt.Sprintf("at least %d characters", *p.MinLength))
+	case p.MaxLength != nil:
+		parts = append(parts, fmt.Sprintf("at most %d characters", *p.MaxLength))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestOrderParameters(t *testing.T) {
+	parameters := []templates.Parameter{
+		{Name: "name", Required: true},
+		{Name: "disk", Type: "string"},
+		{Name: "cpu", Required: true, Enum: []string{"2", "4"}},
+		{Name: "tags", Multiselect: true, Enum: []string{"a", "b"}},
+		{Name: "bucket", Required: true},
+		{Name: "annotations"},
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"name", "disk", "cpu", "tags", "bucket", "annotations"}},
+		{ParamOrderDeclared, []string{"name", "disk", "cpu", "tags", "bucket", "annotations"}},
+		{ParamOrderRequiredFirst, []string{"name", "cpu", "bucket", "disk", "tags", "annotations"}},
+		{ParamOrderAlphabetical, []string{"annotations", "bucket", "cpu", "disk", "name", "tags"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			ordered := orderParameters(parameters, tt.order)
+			var got []string
+			for _, p := range ordered {
+				got = append(got, p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderParameters(%q) = %v, want %v", tt.order, got, tt.want)
+			}
+			// Parameters move whole, keeping their enum and type settings
+			for _, p := range ordered {
+				if p.Name == "cpu" && !reflect.DeepEqual(p.Enum, []string{"2", "4"}) {
+					t.Errorf("cpu lost its enum: %+v", p)
+				}
+			}
+		})
+	}
+
+	if parameters[1].Name != "disk" {
+		t.Error("orderParameters modified the template's parameters")
+	}
+}
+
+func TestInputValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   templates.Parameter
+		input   string
+		wantErr string
+	}{
+		{"empty input keeps the default", templates.Parameter{Type: "integer", Pattern: "^[0-9]$"}, "", ""},
+		{"integer", templates.Parameter{Type: "integer"}, "4", ""},
+		{"integer not a number", templates.Parameter{Type: "integer"}, "four", "must be a number"},
+		{"integer pattern", templates.Parameter{Type: "integer", Pattern: "^[1-8]$"}, "16", `"16" does not match pattern ^[1-8]$`},
+		{"string match", templates.Parameter{Pattern: "^[a-z0-9-]+$"}, "web-1", ""},
+		{"string no match", templates.Parameter{Pattern: "^[a-z0-9-]+$"}, "Web_1", `"Web_1" does not match pattern`},
+		{"bad pattern", templates.Parameter{Pattern: "^[a-z+$"}, "web", "invalid pattern"},
+		{"integer at the maximum", templates.Parameter{Type: "integer", Max: intPtr(64)}, "64", ""},
+		{"integer above the maximum", templates.Parameter{Type: "integer", Max: intPtr(64)}, "65", "greater than the maximum 64"},
+		{"string too long", templates.Parameter{MaxLength: intPtr(3)}, "web-1", "longer than 3 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inputValidator(tt.param)(tt.input)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestConstraintHint(t *testing.T) {
+	tests := []struct {
+		param templates.Parameter
+		want  string
+	}{
+		{templates.Parameter{}, ""},
+		{templates.Parameter{Min: intPtr(1), Max: intPtr(64)}, " (1-64)"},
+		{templates.Parameter{Min: intPtr(1)}, " (at least 1)"},
+		{templates.Parameter{MaxLength: intPtr(63)}, " (at most 63 characters)"},
+		{templates.Parameter{Max: intPtr(8), MinLength: intPtr(1), MaxLength: intPtr(2)}, " (at most 8, 1-2 characters)"},
+	}
+	for _, tt := range tests {
+		if got := constraintHint(tt.param); got != tt.want {
+			t.Errorf("constraintHint(%+v) = %q, want %q", tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestFieldPlaceholder_Examples(t *testing.T) {
+	tests := []struct {
+		name            string
+		param           templates.Parameter
+		wantPlaceholder string
+		wantHint        string
+	}{
+		{"default only", templates.Parameter{Default: "small"}, "default: small", ""},
+		{"examples without default", templates.Parameter{Examples: []string{"web-01", "db-01"}}, "e.g. web-01, db-01", ""},
+		{"default and examples", templates.Parameter{Default: float64(2), Examples: []string{"4", "8"}}, "default: 2", " (e.g. 4, 8)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldPlaceholder(tt.param); got != tt.wantPlaceholder {
+				t.Errorf("fieldPlaceholder() = %q, want %q", got, tt.wantPlaceholder)
+			}
+			if got := exampleHint(tt.param); got != tt.wantHint {
+				t.Errorf("exampleHint() = %q, want %q", got, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestValidateParamOrder(t *testing.T) {
+	for _, order := range []string{"", ParamOrderDeclared, ParamOrderRequiredFirst, ParamOrderAlphabetical} {
+		if err := validateParamOrder(order); err != nil {
+			t.Errorf("validateParamOrder(%q) error: %v", order, err)
+		}
+	}
+	if err := validateParamOrder("random"); err == nil {
+		t.Error("expected error for an unknown order")
+	}
+}
+
+func TestTemplateOptionLabel(t *testing.T) {
+	tmpl := templates.ClaimTemplate{Metadata: templates.ClaimTemplateMetadata{Name: "vsphere-vm", Title: "vSphere VM"}}
+	if got := templateOptionLabel(tmpl); got != "vsphere-vm - vSphere VM" {
+		t.Errorf("label without tags = %q", got)
+	}
+
+	tmpl.Metadata.Tags = []string{"vm", "infra"}
+	if got := templateOptionLabel(tmpl); got != "vsphere-vm - vSphere VM [vm, infra]" {
+		t.Errorf("label with tags = %q", got)
+	}
+}
+
+func TestRunInteractiveRender_NoTemplateMatchesTags(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Metadata.Tags = []string{"vm"}
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm})
+
+	config := &RenderConfig{
+		APIUrl:      server.URL,
+		Tags:        []string{"vm", "database"},
+		CatalogPath: filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	client, err := newRenderClient(config)
+	if err != nil {
+		t.Fatalf("newRenderClient: %v", err)
+	}
+	err = runInteractiveRender(context.Background(), client, config)
+	if err == nil || !strings.Contains(err.Error(), "no template has all of the tags: vm, database") {
+		t.Errorf("expected a no-match error, got %v", err)
+	}
+}
+
+func TestInteractiveGitConfig_KeepsFlagOptions(t *testing.T) {
+	orig := gitDetailsForm
+	gitDetailsForm = func(createPR bool) (*GitConfig, error) {
+		return &GitConfig{Commit: true, Push: true, Message: "from form"}, nil
+	}
+	t.Cleanup(func() { gitDetailsForm = orig })
+
+	flags := &GitConfig{Remote: "upstream", SSHKey: "/keys/deploy", SignOff: true}
+	got, err := interactiveGitConfig(flags, false)
+	if err != nil {
+		t.Fatalf("interactiveGitConfig: %v", err)
+	}
+	want := &GitConfig{Commit: true, Push: true, Message: "from form", Remote: "upstream", SSHKey: "/keys/deploy", SignOff: true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("interactiveGitConfig() = %+v, want %+v", got, want)
+	}
+
+	if got, err := interactiveGitConfig(nil, false); err != nil || got.SSHKey != "" || got.Message != "from form" {
+		t.Errorf("interactiveGitConfig(nil) = %+v, %v", got, err)
+	}
+}
+
+func TestInteractiveGitConfig_GPGSignOnly(t *testing.T) {
+	orig := gitDetailsForm
+	gitDetailsForm = func(createPR bool) (*GitConfig, error) {
+		return &GitConfig{Commit: true}, nil
+	}
+	t.Cleanup(func() { gitDetailsForm = orig })
+
+	// What encrypt and delete build from a lone --gpg-sign: nothing to
+	// commit to yet, so the form still runs, but signing must survive it
+	flags := &GitConfig{GPGSign: true, GPGKey: "0xA1B2C3D4"}
+	got, err := interactiveGitConfig(flags, false)
+	if err != nil {
+		t.Fatalf("interactiveGitConfig: %v", err)
+	}
+	if !got.Commit || !got.GPGSign || got.GPGKey != "0xA1B2C3D4" {
+		t.Errorf("interactiveGitConfig() = %+v, want a signed commit", got)
+	}
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/stuttgart-things/claims/internal/kustomize"
+)
+
+// kustomizationFilePath resolves --kustomization: a directory stands for the
+// kustomization.yaml inside it
+func kustomizationFilePath(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, "kustomization.yaml")
+	}
+	return path
+}
+
+// rootKustomizationPath returns the kustomization.yaml written by
+// --generate-root-kustomization at the root of the output directory
+func rootKustomizationPath(outputDir string) string {
+	return filepath.Join(staticDir(outputDir), "kustomization.yaml")
+}
+
+// kustomizationResource returns the resources entry for a written file,
+// relative to the kustomization's directory: the file itself when it sits
+// next to the kustomization, else the directory holding it
+func kustomizationResource(kustomizationDir, outputPath string) (string, error) {
+	rel, err := kustomizationRelPath(kustomizationDir, outputPath)
+	if err != nil {
+		return "", err
+	}
+	if dir := path.Dir(rel); dir != "." {
+		rel = dir
+	}
+	return rel, nil
+}
+
+// kustomizationRelPath returns outputPath relative to the kustomization's
+// directory, with forward slashes; it fails for files outside the directory
+func kustomizationRelPath(kustomizationDir, outputPath string) (string, error) {
+	absDir, err := filepath.Abs(kustomizationDir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the kustomization directory %s", outputPath, kustomizationDir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// categoryKustomizationPath returns the kustomization.yaml of the category
+// directory holding outputPath (claims/<category>/kustomization.yaml), or ""
+// if the file is outside claims/<category>/
+func categoryKustomizationPath(repoRoot, outputPath string) string {
+	absPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return ""
+	}
+	category := registryCategory(repoRoot, filepath.Dir(absPath))
+	if category == "" {
+		return ""
+	}
+	return filepath.Join(repoRoot, "claims", category, "kustomization.yaml")
+}
+
+// updateCategoryKustomizations adds each file written below
+// claims/<category>/ to that category's kustomization.yaml, creating it if
+// missing, so Kustomize picks up new claims the way delete drops them.
+// Kustomizations also updated through --kustomization or
+// --generate-root-kustomization are left to those. A file is only saved when
+// its resources changed.
+func updateCategoryKustomizations(results []RenderResult, config *RenderConfig) error {
+	repoRoot, err := findRepoRoot(config.OutputDir)
+	if err != nil {
+		return nil // Not in a git repo, no categories
+	}
+
+	skip := make(map[string]bool)
+	if config.Kustomization != "" {
+		if path, err := filepath.Abs(kustomizationFilePath(config.Kustomization)); err == nil {
+			skip[path] = true
+		}
+	}
+	if config.RootKustomize {
+		if path, err := filepath.Abs(rootKustomizationPath(config.OutputDir)); err == nil {
+			skip[path] = true
+		}
+	}
+
+	// Group the written files by category kustomization, in result order
+	var paths []string
+	files := make(map[string][]string)
+	for _, r := range results {
+		if r.Error != nil || r.OutputPath == "" {
+			continue
+		}
+		path := categoryKustomizationPath(repoRoot, r.OutputPath)
+		if abs, err := filepath.Abs(path); path == "" || err != nil || skip[abs] {
+			continue
+		}
+		if _, ok := files[path]; !ok {
+			paths = append(paths, path)
+		}
+		files[path] = append(files[path], r.OutputPath)
+	}
+
+	for _, path := range paths {
+		k, err := kustomize.LoadOrNew(path)
+		if err != nil {
+			return err
+		}
+		before := len(k.Resources)
+		for _, file := range files[path] {
+			resource, err := kustomizationResource(filepath.Dir(path), file)
+			if err != nil {
+				continue // categoryKustomizationPath only returns enclosing directories
+			}
+			kustomize.AddResource(k, resource)
+		}
+		if _, err := os.Stat(path); err == nil && len(k.Resources) == before {
+			continue
+		}
+
+		if !config.NoSort {
+			kustomize.SortResources(k)
+		}
+		if err := kustomize.Save(path, k); err != nil {
+			return err
+		}
+		fmt.Printf("Updated kustomization: %s\n", path)
+	}
+	return nil
+}
+
+// updateRenderKustomizations adds the written files to the kustomization.yaml
+// of their category, the --kustomization file and the
+// --generate-root-kustomization file
+func updateRenderKustomizations(results []RenderResult, outputConfig OutputConfig, config *RenderConfig) error {
+	if config.Kustomization != "" && config.RootKustomize {
+		root, err1 := filepath.Abs(rootKustomizationPath(config.OutputDir))
+		explicit, err2 := filepath.Abs(kustomizationFilePath(config.Kustomization))
+		if err1 == nil && err2 == nil && root == explicit {
+			return fmt.Errorf("--kustomization and --generate-root-kustomization both update %s; use one of them", root)
+		}
+	}
+	if err := updateCategoryKustomizations(results, config); err != nil {
+		return fmt.Errorf("updating category kustomization: %w", err)
+	}
+	if config.Kustomization != "" {
+		if err := updateKustomization(results, config); err != nil {
+			return fmt.Errorf("updating kustomization: %w", err)
+		}
+	}
+	if config.RootKustomize {
+		if err := updateRootKustomization(results, outputConfig, config.NoSort); err != nil {
+			return fmt.Errorf("updating root kustomization: %w", err)
+		}
+	}
+	return nil
+}
+
+// updateKustomization adds the files written for successful results to the
+// --kustomization file's resources, keeping them free of duplicates and,
+// unless --no-sort is set, sorted. Files outside the kustomization's directory are skipped with a
+// warning.
+func updateKustomization(results []RenderResult, config *RenderConfig) error {
+	path := kustomizationFilePath(config.Kustomization)
+	k, err := kustomize.Load(path)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, r := range results {
+		if r.Error != nil || r.OutputPath == "" {
+			continue
+		}
+		resource, err := kustomizationResource(filepath.Dir(path), r.OutputPath)
+		if err != nil {
+			warnf("not adding to %s: %v", path, err)
+			continue
+		}
+		kustomize.AddResource(k, resource)
+		added++
+	}
+	if added == 0 {
+		return nil
+	}
+
+	if !config.NoSort {
+		kustomize.SortResources(k)
+	}
+	if err := kustomize.Save(path, k); err != nil {
+		return err
+	}
+	fmt.Printf("Updated kustomization: %s\n", path)
+	return nil
+}
+
+// updateRootKustomization writes or updates the kustomization.yaml at the
+// root of the output directory so it lists every written file as a resource,
+// making the directory buildable with kustomize build. Unlike
+// --kustomization, files in subdirectories are listed themselves rather than
+// their directory. Files outside the output directory are skipped with a
+// warning. The resources are sorted unless noSort is set.
+func updateRootKustomization(results []RenderResult, outputConfig OutputConfig, noSort bool) error {
+	written := writtenPaths(results, outputConfig)
+	if len(written) == 0 {
+		return nil
+	}
+
+	path := rootKustomizationPath(outputConfig.Directory)
+	k, err := kustomize.LoadOrNew(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range written {
+		resource, err := kustomizationRelPath(filepath.Dir(path), p)
+		if err != nil {
+			warnf("not adding to %s: %v", path, err)
+			continue
+		}
+		kustomize.AddResource(k, resource)
+	}
+
+	if !noSort {
+		kustomize.SortResources(k)
+	}
+	if err := kustomize.Save(path, k); err != nil {
+		return err
+	}
+	fmt.Printf("Updated root kustomization: %s\n", path)
+	return nil
+}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/stuttgart-things/claims/internal/kustomize"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestKustomizationResource(t *testing.T) {
+	dir := filepath.Join("claims", "infra")
+
+	tests := []struct {
+		name       string
+		outputPath string
+		want       string
+		wantErr    bool
+	}{
+		{"file next to the kustomization", filepath.Join(dir, "vm.yaml"), "vm.yaml", false},
+		{"file in a claim directory", filepath.Join(dir, "my-vm", "claim.yaml"), "my-vm", false},
+		{"nested claim directory", filepath.Join(dir, "vms", "web", "claim.yaml"), "vms/web", false},
+		{"outside the kustomization directory", filepath.Join("claims", "apps", "app.yaml"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kustomizationResource(dir, tt.outputPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("kustomizationResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("kustomizationResource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateKustomization(t *testing.T) {
+	warnings.Reset()
+	t.Cleanup(warnings.Reset)
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "claims", "infra")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "kustomization.yaml")
+	original := "# infra claims\nresources:\n  - z-app\n  - b.yaml\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(dir, "a.yaml")},
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(dir, "b.yaml")},
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(dir, "my-vm", "claim.yaml")},
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(dir, "my-vm", "secret.yaml")},
+		{TemplateName: "postgresql", OutputPath: filepath.Join(root, "claims", "apps", "db.yaml")},
+		{TemplateName: "broken", Error: os.ErrInvalid},
+	}
+
+	// A directory stands for the kustomization.yaml inside it
+	if err := updateKustomization(results, &RenderConfig{Kustomization: dir}); err != nil {
+		t.Fatalf("updateKustomization() error: %v", err)
+	}
+
+	k, err := kustomize.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a.yaml", "b.yaml", "my-vm", "z-app"}
+	if !reflect.DeepEqual(k.Resources, want) {
+		t.Errorf("resources = %v, want %v", k.Resources, want)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "# infra claims") {
+		t.Errorf("comment not preserved:\n%s", data)
+	}
+	if warnings.Count() != 1 || !strings.Contains(warnings.messages[0], "db.yaml") {
+		t.Errorf("expected one warning for the file outside the directory, got %v", warnings.messages)
+	}
+
+	// A missing kustomization is an error, not silently created
+	if err := updateKustomization(results, &RenderConfig{Kustomization: filepath.Join(root, "missing.yaml")}); err == nil {
+		t.Error("expected error for a missing kustomization")
+	}
+}
+
+func TestRunNonInteractive_Kustomization(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+	if err := os.WriteFile(path, []byte("resources: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		OutputDir:       dir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		Kustomization:   path,
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("runNonInteractive: %v", err)
+	}
+
+	k, err := kustomize.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"vsphere-vm-web.yaml"}; !reflect.DeepEqual(k.Resources, want) {
+		t.Errorf("resources = %v, want %v", k.Resources, want)
+	}
+}
+
+func TestUpdateRootKustomization(t *testing.T) {
+	warnings.Reset()
+	t.Cleanup(warnings.Reset)
+
+	root := t.TempDir()
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\nname: web\n"},
+		{TemplateName: "vsphere-vm", ResourceName: "db", Content: "kind: VM\nname: db\n"},
+		{TemplateName: "postgresql", ResourceName: "orders", Content: "kind: DB\n", TargetPath: filepath.Join(root, "apps", "orders.yaml")},
+		{TemplateName: "broken", ResourceName: "x", Error: os.ErrInvalid},
+	}
+	outputConfig := OutputConfig{Directory: root, FilenamePattern: "{{.template}}-{{.name}}.yaml"}
+	if err := WriteResults(results, outputConfig); err != nil {
+		t.Fatalf("WriteResults() error: %v", err)
+	}
+
+	if err := updateRenderKustomizations(results, outputConfig, &RenderConfig{OutputDir: root, RootKustomize: true}); err != nil {
+		t.Fatalf("updateRenderKustomizations() error: %v", err)
+	}
+
+	path := filepath.Join(root, "kustomization.yaml")
+	k, err := kustomize.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"apps/orders.yaml", "vsphere-vm-db.yaml", "vsphere-vm-web.yaml"}
+	if !reflect.DeepEqual(k.Resources, want) {
+		t.Errorf("resources = %v, want %v", k.Resources, want)
+	}
+	if k.APIVersion != kustomize.DefaultAPIVersion || k.Kind != kustomize.DefaultKind {
+		t.Errorf("expected a new kustomization to set apiVersion and kind, got %q/%q", k.APIVersion, k.Kind)
+	}
+
+	// An existing root kustomization is updated, not reset
+	if err := os.WriteFile(path, []byte("# root\nresources:\n  - vsphere-vm-web.yaml\n  - flux-system\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateRootKustomization(results, outputConfig, false); err != nil {
+		t.Fatalf("updateRootKustomization() error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# root", "flux-system", "vsphere-vm-db.yaml"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in the root kustomization:\n%s", want, data)
+		}
+	}
+
+	// --kustomization pointing at the same file is rejected
+	err = updateRenderKustomizations(results, outputConfig, &RenderConfig{OutputDir: root, RootKustomize: true, Kustomization: root})
+	if err == nil || !strings.Contains(err.Error(), "both update") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestUpdateCategoryKustomizations(t *testing.T) {
+	repoRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	infra := filepath.Join(repoRoot, "claims", "infra")
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(infra, "web", "claim.yaml")},
+		{TemplateName: "vsphere-vm", OutputPath: filepath.Join(infra, "vsphere-vm-db.yaml")},
+		{TemplateName: "postgresql", OutputPath: filepath.Join(repoRoot, "claims", "apps", "orders.yaml")},
+		{TemplateName: "postgresql", OutputPath: filepath.Join(repoRoot, "out", "outside.yaml")},
+		{TemplateName: "broken", OutputPath: filepath.Join(infra, "broken.yaml"), Error: os.ErrInvalid},
+	}
+	for _, r := range results[:4] {
+		if err := os.MkdirAll(filepath.Dir(r.OutputPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(r.OutputPath, []byte("kind: VM\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := &RenderConfig{OutputDir: repoRoot}
+
+	t.Run("creates missing kustomizations", func(t *testing.T) {
+		if err := updateCategoryKustomizations(results, config); err != nil {
+			t.Fatalf("updateCategoryKustomizations() error: %v", err)
+		}
+		for path, want := range map[string][]string{
+			filepath.Join(infra, "kustomization.yaml"):                      {"vsphere-vm-db.yaml", "web"},
+			filepath.Join(repoRoot, "claims", "apps", "kustomization.yaml"): {"orders.yaml"},
+		} {
+			k, err := kustomize.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(k.Resources, want) || k.Kind != kustomize.DefaultKind {
+				t.Errorf("%s: resources = %v (kind %q), want %v", path, k.Resources, k.Kind, want)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(repoRoot, "out", "kustomization.yaml")); !os.IsNotExist(err) {
+			t.Error("expected no kustomization outside claims/<category>/")
+		}
+	})
+
+	t.Run("adds are idempotent", func(t *testing.T) {
+		path := filepath.Join(infra, "kustomization.yaml")
+		edited := "# infra claims\nresources:\n  - web\n  - vsphere-vm-db.yaml\n"
+		if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := updateCategoryKustomizations(results, config); err != nil {
+			t.Fatalf("updateCategoryKustomizations() error: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != edited {
+			t.Errorf("expected an up-to-date kustomization to be left alone, got:\n%s", data)
+		}
+	})
+
+	t.Run("leaves --kustomization to its own update", func(t *testing.T) {
+		path := filepath.Join(infra, "kustomization.yaml")
+		if err := os.WriteFile(path, []byte("resources: []\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := updateCategoryKustomizations(results, &RenderConfig{OutputDir: repoRoot, Kustomization: infra}); err != nil {
+			t.Fatalf("updateCategoryKustomizations() error: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "resources: []\n" {
+			t.Errorf("expected the --kustomization file to be skipped, got:\n%s", data)
+		}
+	})
+}
+
+func TestExecuteGitOperations_StagesCategoryKustomization(t *testing.T) {
+	repoRoot := t.TempDir()
+	repo, err := git.PlainInit(repoRoot, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+
+	outputDir := filepath.Join(repoRoot, "claims", "infra")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	outPath := filepath.Join(outputDir, "vsphere-vm-web.yaml")
+	if err := os.WriteFile(outPath, []byte("kind: ConfigMap\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := []RenderResult{{TemplateName: "vsphere-vm", ResourceName: "web", OutputPath: outPath}}
+	config := &RenderConfig{OutputDir: outputDir, GitConfig: &GitConfig{Commit: true}}
+	if err := updateRenderKustomizations(results, OutputConfig{Directory: outputDir}, config); err != nil {
+		t.Fatalf("updateRenderKustomizations() error: %v", err)
+	}
+	if err := executeGitOperations(&RenderResults{Results: results, OutputDir: outputDir}, config); err != nil {
+		t.Fatalf("executeGitOperations: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("reading HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := commit.File("claims/infra/kustomization.yaml")
+	if err != nil {
+		t.Fatalf("expected the category kustomization in the commit: %v", err)
+	}
+	if content, _ := file.Contents(); !strings.Contains(content, "- vsphere-vm-web.yaml") {
+		t.Errorf("committed kustomization doesn't list the claim:\n%s", content)
+	}
+}
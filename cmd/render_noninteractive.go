@@ -1,116 +1,243 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/stuttgart-things/claims/internal/params"
+	"github.com/stuttgart-things/claims/internal/redact"
+	"github.com/stuttgart-things/claims/internal/registry"
 	"github.com/stuttgart-things/claims/internal/templates"
 )
 
 // runNonInteractive runs the render command in non-interactive mode
-func runNonInteractive(config *RenderConfig) error {
+func runNonInteractive(ctx context.Context, config *RenderConfig) error {
 	// Validate required inputs
-	if config.ParamsFile == "" && len(config.Templates) == 0 {
-		return fmt.Errorf("non-interactive mode requires --params-file or --templates")
+	if len(config.ParamsFiles) == 0 && len(config.Templates) == 0 && len(config.RegistryFilter) == 0 {
+		return fmt.Errorf("non-interactive mode requires --params-file, --templates or --params-from-registry-filter")
+	}
+	if err := validateOutputOrder(config.OutputOrder); err != nil {
+		return err
+	}
+	if err := preflightPush(config); err != nil {
+		return err
 	}
 
-	client := templates.NewClient(config.APIUrl)
-
-	// Parse parameter file if provided
-	var templateParams []params.TemplateParams
-	if config.ParamsFile != "" {
-		pf, err := params.ParseFile(config.ParamsFile)
-		if err != nil {
-			return err
-		}
-		templateParams = pf.Templates
+	client, err := newRenderClient(config)
+	if err != nil {
+		return err
 	}
 
-	// Parse inline params
-	inlineParams, err := params.ParseInlineParams(config.InlineParamsRaw)
+	// Ctrl-C aborts fetching and rendering; signal handling is released
+	// before files are written and git/PR steps run
+	sigCtx, stop := signalContext(ctx)
+	defer stop()
+
+	// Bound fetching and rendering by --render-timeout
+	ctx, cancel := config.renderContext(sigCtx)
+	defer cancel()
+
+	templateParams, err := resolveTemplateParams(config)
 	if err != nil {
 		return err
 	}
+	if config.ChangedOnly && len(templateParams) == 0 {
+		fmt.Printf("No template entries changed since %s; nothing to render\n", config.BaseRef)
+		return nil
+	}
 
-	// If templates specified via flag, use those
-	if len(config.Templates) > 0 {
-		// Override or create template params
-		for _, tmplName := range config.Templates {
-			// Find existing params from file or create new
-			found := false
-			for i, tp := range templateParams {
-				if tp.Name == tmplName {
-					templateParams[i].Parameters = params.MergeParams(tp.Parameters, inlineParams)
-					found = true
-					break
-				}
-			}
-			if !found {
-				templateParams = append(templateParams, params.TemplateParams{
-					Name:       tmplName,
-					Parameters: inlineParams,
-				})
-			}
+	// Registry re-renders write each claim back to its recorded path
+	var targetPaths []string
+	if len(config.RegistryFilter) > 0 {
+		entries, repoRoot, missing, err := registryRerenderEntries(config)
+		if err != nil {
+			return err
 		}
-	} else {
-		// Apply inline params to all templates from file
-		for i := range templateParams {
-			templateParams[i].Parameters = params.MergeParams(templateParams[i].Parameters, inlineParams)
+		for _, name := range missing {
+			warnf("registry entry %s has no stored parameters; render it once with --params-file or --templates to record them", name)
+		}
+		if len(entries) == 0 {
+			fmt.Println("No registry entries with stored parameters match the filter; nothing to render")
+			return nil
+		}
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name
+			targetPaths = append(targetPaths, registryTargetPath(repoRoot, e))
 		}
+		fmt.Printf("Re-rendering %d registry claim(s): %s\n", len(entries), strings.Join(names, ", "))
 	}
 
 	// Validate templates exist and build lookup map
-	available, err := client.FetchTemplates()
+	names := make([]string, len(templateParams))
+	for i, tp := range templateParams {
+		names[i] = tp.Name
+	}
+	available, err := loadRenderTemplates(ctx, client, config, names)
 	if err != nil {
 		return fmt.Errorf("fetching templates: %w", err)
 	}
+
+	// Resolve template aliases to catalog names
+	aliases, err := config.loadAliases()
+	if err != nil {
+		return err
+	}
+	known := catalogNames(available)
+	for i := range templateParams {
+		templateParams[i].Name = resolveTemplateAlias(templateParams[i].Name, aliases, known)
+	}
 	templateLookup := make(map[string]*templates.ClaimTemplate)
 	for i, t := range available {
 		templateLookup[t.Metadata.Name] = &available[i]
 	}
-	for _, tp := range templateParams {
-		if templateLookup[tp.Name] == nil {
-			return fmt.Errorf("template not found: %s", tp.Name)
+	// Pre-flight: the same checks as 'claims validate'
+	if problems := validateTemplateParams(templateParams, templateLookup); len(problems) > 0 {
+		return fmt.Errorf("invalid template parameters:\n%s", formatParamsProblems(problems))
+	}
+
+	// Resolve a pinned template version against the tags the API offers
+	tag := ""
+	if config.TemplateVersion != "" {
+		for _, tp := range templateParams {
+			if tp.Name != templateParams[0].Name {
+				return fmt.Errorf("--template-version requires a single template, got %s and %s", templateParams[0].Name, tp.Name)
+			}
+		}
+		if len(templateParams) > 0 {
+			tag, err = resolveTemplateVersion(ctx, client, templateParams[0].Name, config.TemplateVersion)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	// Render all templates
-	var results []RenderResult
-	for _, tp := range templateParams {
-		fmt.Printf("Rendering %s...\n", tp.Name)
+	// Prepare render jobs in input order
+	unsetKeys, err := unsetInlineKeys(config.InlineParamsRaw)
+	if err != nil {
+		return err
+	}
+	jobs := make([]renderJob, len(templateParams))
+	for i, tp := range templateParams {
+		// Seed the name param from the template default so --affix-name-param
+		// also covers templates whose name isn't set explicitly
+		if config.AffixNameParam {
+			if _, ok := tp.Parameters["name"]; !ok {
+				if d := templateNameDefault(templateLookup[tp.Name]); d != "" {
+					tp.Parameters = params.MergeParams(tp.Parameters, map[string]any{"name": d})
+				}
+			}
+			tp.Parameters = config.affixNameParam(tp.Parameters)
+			templateParams[i].Parameters = tp.Parameters
+		}
+		// Fill in template defaults like the interactive form; keys removed
+		// with --param key- stay unset
+		if tmpl := templateLookup[tp.Name]; tmpl != nil && !config.NoDefaults {
+			withDefaults := templates.ApplyDefaults(*tmpl, tp.Parameters)
+			for key := range unsetKeys {
+				if _, given := tp.Parameters[key]; !given {
+					delete(withDefaults, key)
+				}
+			}
+			tp.Parameters = withDefaults
+			templateParams[i].Parameters = tp.Parameters
+		}
+		jobs[i] = renderJob{Index: i, TemplateName: tp.Name, Params: tp.Parameters, Output: tp.Output}
+		if targetPaths != nil {
+			jobs[i].TargetPath = targetPaths[i]
+		}
+	}
 
-		content, err := client.RenderTemplate(tp.Name, tp.Parameters)
-		if err != nil {
-			fmt.Printf("  ERROR: %v\n", err)
-			results = append(results, RenderResult{
-				TemplateName: tp.Name,
-				Error:        err,
+	// Drop entries whose claims are already registered
+	if config.OnlyNew {
+		var skipped []string
+		jobs, templateParams, skipped = filterNewJobs(jobs, templateParams, loadRenderRegistry(config), func(job renderJob) string {
+			return jobResourceName(job, templateLookup[job.TemplateName], config)
+		})
+		if len(skipped) > 0 {
+			fmt.Printf("Skipping %d already registered claim(s) (--only-new): %s\n", len(skipped), strings.Join(skipped, ", "))
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No new claims to render")
+			return nil
+		}
+	}
+
+	// Refuse batches where two entries would write the same output file
+	if config.FileMode != "append" && targetPaths == nil && config.OutputDir != StdoutDir {
+		// Paths built from the rendered metadata are only known after rendering
+		var planned []RenderResult
+		for _, job := range jobs {
+			if jobUsesRenderedMetadata(job, config) {
+				continue
+			}
+			planned = append(planned, RenderResult{
+				TemplateName: job.TemplateName,
+				ResourceName: jobResourceName(job, templateLookup[job.TemplateName], config),
+				Output:       job.Output,
 			})
-			continue
 		}
+		collisions, err := findResultPathCollisions(planned, OutputConfig{
+			Directory:       config.OutputDir,
+			FilenamePattern: config.FilenamePattern,
+			SingleFile:      config.SingleFile,
+		})
+		if err != nil {
+			return err
+		}
+		if len(collisions) > 0 {
+			if !config.AllowCollisions {
+				return fmt.Errorf("output filename collisions (use --allow-collisions to proceed):\n%s", formatFilenameCollisions(collisions))
+			}
+			warnf("output filename collisions, later entries overwrite earlier ones:\n%s", formatFilenameCollisions(collisions))
+		}
+	}
 
-		resourceName := "output"
-		if name, ok := tp.Parameters["name"]; ok {
-			resourceName = fmt.Sprintf("%v", name)
-		} else if tmpl := templateLookup[tp.Name]; tmpl != nil {
-			for _, p := range tmpl.Spec.Parameters {
-				if p.Name == "name" {
-					if d, ok := p.Default.(string); ok && d != "" {
-						resourceName = d
-					}
-					break
-				}
+	// Render all templates
+	results := renderJobsParallel(jobs, config.Parallel, config.OutputOrder, func(job renderJob) RenderResult {
+		// Don't start new renders once the deadline has passed
+		if err := ctx.Err(); err != nil {
+			return RenderResult{TemplateName: job.TemplateName, Error: err}
+		}
+
+		fmt.Printf("Rendering %s...\n", job.TemplateName)
+
+		content, err := client.RenderTemplateVersionContext(ctx, job.TemplateName, tag, job.Params)
+		if err != nil {
+			fmt.Printf("  ERROR (%s): %s\n", job.TemplateName, redact.String(err.Error()))
+			return RenderResult{
+				TemplateName: job.TemplateName,
+				Error:        err,
 			}
 		}
 
-		results = append(results, RenderResult{
-			TemplateName: tp.Name,
-			ResourceName: resourceName,
+		fmt.Printf("  Rendered %s successfully\n", job.TemplateName)
+		return RenderResult{
+			TemplateName: job.TemplateName,
+			ResourceName: jobResourceName(job, templateLookup[job.TemplateName], config),
+			TargetPath:   job.TargetPath,
+			Output:       job.Output,
 			Content:      content,
-			Params:       tp.Parameters,
-		})
-		fmt.Printf("  Rendered successfully\n")
+			Documents:    parseDocuments(content),
+			Params:       job.Params,
+		}
+	})
+
+	// Ctrl-C aborts before anything is written
+	interrupted := errors.Is(ctx.Err(), context.Canceled)
+	stop()
+	if interrupted {
+		return fmt.Errorf("render interrupted")
+	}
+
+	timedOut := renderTimedOut(results)
+	if timedOut {
+		printRenderTimeoutReport(results, config.RenderTimeout)
 	}
 
 	// Check for any errors
@@ -121,6 +248,15 @@ func runNonInteractive(config *RenderConfig) error {
 		}
 	}
 
+	if config.ArgoCDLayout {
+		if config.OutputDir == StdoutDir {
+			warnf("--argocd-layout writes files; ignoring it with --stdout")
+			config.ArgoCDLayout = false
+		} else if err := applyArgoCDLayout(results, config); err != nil {
+			return err
+		}
+	}
+
 	// Write output
 	outputConfig := OutputConfig{
 		Directory:       config.OutputDir,
@@ -128,11 +264,30 @@ func runNonInteractive(config *RenderConfig) error {
 		SingleFile:      config.SingleFile,
 		DryRun:          config.DryRun,
 		FileMode:        config.FileMode,
+		KeepCRLF:        config.KeepCRLF,
+		Stdout:          config.OutputDir == StdoutDir,
+	}
+
+	// --diff-only previews the changes and stops before anything is written
+	if config.DiffOnly {
+		printResultDiffs(results, outputConfig)
+		if hasErrors {
+			return fmt.Errorf("some templates failed to render")
+		}
+		return nil
+	}
+	if config.Diff {
+		if outputConfig.Stdout {
+			warnf("--diff has no files to compare against with --stdout; ignoring it")
+		} else {
+			printRenderDiff(results, outputConfig)
+		}
 	}
 
 	if err := WriteResults(results, outputConfig); err != nil {
 		return err
 	}
+	outcome.addRenderResults(results, outputConfig)
 
 	// Process secrets for templates that define them
 	for _, tp := range templateParams {
@@ -182,21 +337,319 @@ func runNonInteractive(config *RenderConfig) error {
 		}
 	}
 
+	// Apply only a complete render: a partial one would leave the cluster
+	// out of step with the claims
+	if config.Apply {
+		if hasErrors || timedOut {
+			warnf("not applying: some templates failed to render")
+		} else if err := applyRendered(results, config, outputConfig); err != nil {
+			return err
+		}
+	}
+
+	// Claims streamed to stdout are neither registered nor committed
+	if config.OutputDir == StdoutDir {
+		warnStdoutSkipsGit(config)
+		if hasErrors {
+			return fmt.Errorf("some templates failed to render")
+		}
+		return nil
+	}
+
+	if config.ArgoCDLayout {
+		if config.DryRun {
+			for _, r := range results {
+				if r.Error == nil {
+					fmt.Printf("Would write Application: %s\n", argoCDApplicationPath(config.OutputDir, r))
+				}
+			}
+		} else {
+			apps, err := writeArgoCDApplications(results, config)
+			for _, p := range apps {
+				outcome.addClaim("", "", p)
+			}
+			if err != nil {
+				return fmt.Errorf("writing ArgoCD Applications: %w", err)
+			}
+		}
+	}
+
 	// Update registry if output was written (and not dry-run)
 	if !config.DryRun {
 		updateRegistryForRender(results, config)
+		if err := updateRenderKustomizations(results, outputConfig, config); err != nil {
+			return err
+		}
 	}
 
 	// Execute git operations if configured (and not dry-run)
 	if !config.DryRun {
-		if err := executeGitOperations(results, config); err != nil {
+		agg := &RenderResults{Results: results, OutputDir: config.OutputDir}
+		if err := executeGitOperations(agg, config); err != nil {
 			return fmt.Errorf("git operations: %w", err)
 		}
+		printGitSummary(agg)
 	}
 
+	if timedOut {
+		return fmt.Errorf("render timeout of %s exceeded", config.RenderTimeout)
+	}
 	if hasErrors {
 		return fmt.Errorf("some templates failed to render")
 	}
 
 	return nil
 }
+
+// nestedParams merges the values parsed from --param-file-yaml and
+// --param-file-json files with the --set assignments, which win on
+// conflicting paths
+func nestedParams(config *RenderConfig) (map[string]any, error) {
+	yamlParams, err := params.ParseFileParams(config.ParamFileYAMLRaw, "yaml")
+	if err != nil {
+		return nil, err
+	}
+	jsonParams, err := params.ParseFileParams(config.ParamFileJSONRaw, "json")
+	if err != nil {
+		return nil, err
+	}
+	setParams, err := params.ParseSetParams(config.SetParamsRaw)
+	if err != nil {
+		return nil, err
+	}
+	return params.MergeTree(params.MergeTree(yamlParams, jsonParams), setParams), nil
+}
+
+// resolveTemplateParams builds the templates and merged parameters for a
+// non-interactive render from --params-file, --templates, --param and --set
+func resolveTemplateParams(config *RenderConfig) ([]params.TemplateParams, error) {
+	// Parse parameter file if provided
+	var templateParams []params.TemplateParams
+	if config.ChangedOnly {
+		if len(config.ParamsFiles) == 0 {
+			return nil, fmt.Errorf("--changed-only requires --params-file")
+		}
+		if len(config.Templates) > 0 {
+			return nil, fmt.Errorf("--changed-only cannot be combined with --templates")
+		}
+	}
+	if len(config.ParamsFiles) > 0 {
+		fileTemplates, err := loadParamsFileTemplates(config)
+		if err != nil {
+			return nil, err
+		}
+		templateParams = fileTemplates
+	}
+	if len(config.RegistryFilter) > 0 {
+		if err := validateRegistryRerender(config); err != nil {
+			return nil, err
+		}
+		entries, _, _, err := registryRerenderEntries(config)
+		if err != nil {
+			return nil, err
+		}
+		templateParams = registryTemplateParams(entries)
+	}
+
+	// Parse inline params
+	inlineParams, err := parseInlineParams(config)
+	if err != nil {
+		return nil, err
+	}
+	setParams, err := nestedParams(config)
+	if err != nil {
+		return nil, err
+	}
+	// --param replaces top-level keys; --param-file-* and --set then merge into nested paths
+	applyInline := func(p map[string]any) map[string]any {
+		return params.MergeTree(params.MergeParams(p, inlineParams), setParams)
+	}
+
+	// If templates specified via flag, use those
+	if len(config.Templates) > 0 {
+		// Override or create template params
+		for _, tmplName := range config.Templates {
+			// Find existing params from file or create new
+			found := false
+			for i, tp := range templateParams {
+				if tp.Name == tmplName {
+					templateParams[i].Parameters = applyInline(tp.Parameters)
+					found = true
+					break
+				}
+			}
+			if !found {
+				templateParams = append(templateParams, params.TemplateParams{
+					Name:       tmplName,
+					Parameters: applyInline(nil),
+				})
+			}
+		}
+	} else {
+		// Apply inline params to all templates from file
+		for i := range templateParams {
+			templateParams[i].Parameters = applyInline(templateParams[i].Parameters)
+		}
+	}
+
+	if duplicates := params.FindDuplicates(templateParams); len(duplicates) > 0 {
+		// --allow-collisions is about output filenames only; duplicates of
+		// one claim always need --merge-duplicates
+		if !config.MergeDuplicates {
+			return nil, fmt.Errorf("duplicate template entries (use --merge-duplicates to merge them):\n%s", formatDuplicates(duplicates))
+		}
+		templateParams = params.MergeDuplicates(templateParams)
+	}
+
+	// Expand --matrix last, so the derived names build on the final name param
+	axes, err := params.ParseMatrix(config.MatrixRaw)
+	if err != nil {
+		return nil, err
+	}
+	templateParams, err = params.ExpandMatrix(templateParams, axes)
+	if err != nil {
+		return nil, err
+	}
+	return applyResourceName(templateParams, config)
+}
+
+// applyResourceName checks that --resource-name names a single entry and,
+// with --set-name-param, sets it as the entry's "name" parameter
+func applyResourceName(templateParams []params.TemplateParams, config *RenderConfig) ([]params.TemplateParams, error) {
+	if config.ResourceName == "" {
+		if config.SetNameParam {
+			return nil, fmt.Errorf("--set-name-param requires --resource-name")
+		}
+		return templateParams, nil
+	}
+	if len(templateParams) != 1 {
+		return nil, fmt.Errorf("--resource-name needs a single template entry to render, got %d", len(templateParams))
+	}
+	if config.SetNameParam {
+		templateParams[0].Parameters = params.MergeParams(templateParams[0].Parameters, map[string]any{"name": config.ResourceName})
+	}
+	return templateParams, nil
+}
+
+// formatDuplicates renders one indented line per group of duplicate entries
+func formatDuplicates(duplicates []params.Duplicate) string {
+	lines := make([]string, len(duplicates))
+	for i, d := range duplicates {
+		lines[i] = "  " + d.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadRenderRegistry loads the registry of the repository containing the
+// output directory. It returns nil, meaning nothing is registered yet, when
+// there is no registry; other problems are reported as warnings.
+func loadRenderRegistry(config *RenderConfig) *registry.ClaimRegistry {
+	repoRoot, err := findRepoRoot(config.OutputDir)
+	if err != nil {
+		warnf("--only-new: output directory is not in a git repository; rendering all entries")
+		return nil
+	}
+	reg, err := registry.Load(registryPathForRepo(repoRoot))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		warnf("--only-new: %v; rendering all entries", err)
+		return nil
+	}
+	return reg
+}
+
+// filterNewJobs drops jobs whose resource name is an active entry in reg,
+// together with their template params, and re-numbers the remaining jobs.
+// It returns the kept jobs and params and the skipped resource names.
+func filterNewJobs(jobs []renderJob, templateParams []params.TemplateParams, reg *registry.ClaimRegistry, resourceName func(renderJob) string) ([]renderJob, []params.TemplateParams, []string) {
+	if reg == nil {
+		return jobs, templateParams, nil
+	}
+
+	var keptJobs []renderJob
+	var keptParams []params.TemplateParams
+	var skipped []string
+	for i, job := range jobs {
+		name := resourceName(job)
+		if entry := registry.FindEntry(reg, name); entry != nil && entry.Status == "active" {
+			skipped = append(skipped, name)
+			continue
+		}
+		job.Index = len(keptJobs)
+		keptJobs = append(keptJobs, job)
+		keptParams = append(keptParams, templateParams[i])
+	}
+	return keptJobs, keptParams, skipped
+}
+
+// parseInlineParams parses --param, converting numbers and booleans unless
+// --raw-strings is set
+func parseInlineParams(config *RenderConfig) (map[string]any, error) {
+	if config.RawStrings {
+		return params.ParseInlineParamsRaw(config.InlineParamsRaw)
+	}
+	return params.ParseInlineParams(config.InlineParamsRaw)
+}
+
+// unsetInlineKeys returns the keys --param removes with key- or key=null
+func unsetInlineKeys(raw []string) (map[string]bool, error) {
+	inline, err := params.ParseInlineParams(raw)
+	if err != nil {
+		return nil, err
+	}
+	unset := make(map[string]bool)
+	for key, value := range inline {
+		if params.IsUnset(value) {
+			unset[key] = true
+		}
+	}
+	return unset, nil
+}
+
+// jobUsesRenderedMetadata reports whether the output directory or filename
+// pattern of a job references the metadata of the rendered resource
+func jobUsesRenderedMetadata(job renderJob, config *RenderConfig) bool {
+	patterns := []string{config.OutputDir, config.FilenamePattern}
+	if o := job.Output; o != nil {
+		patterns = append(patterns, o.Dir, o.Pattern)
+	}
+	return slices.ContainsFunc(patterns, usesRenderedMetadata)
+}
+
+// jobResourceName derives the resource name used for a job's output filename
+// and registry entry. --resource-name replaces the derived name; the prefix
+// and suffix still apply.
+func jobResourceName(job renderJob, tmpl *templates.ClaimTemplate, config *RenderConfig) string {
+	if config.ResourceName != "" {
+		return config.affixResourceName(config.ResourceName)
+	}
+	resourceName := "output"
+	if name, ok := job.Params["name"]; ok {
+		resourceName = fmt.Sprintf("%v", name)
+	} else if d := templateNameDefault(tmpl); d != "" {
+		resourceName = d
+	}
+	if !config.AffixNameParam {
+		// With --affix-name-param the name param already carries the affix
+		resourceName = config.affixResourceName(resourceName)
+	}
+	return resourceName
+}
+
+// templateNameDefault returns the default of a template's "name" parameter, if any
+func templateNameDefault(tmpl *templates.ClaimTemplate) string {
+	if tmpl == nil {
+		return ""
+	}
+	for _, p := range tmpl.Spec.Parameters {
+		if p.Name == "name" {
+			if d, ok := p.Default.(string); ok {
+				return d
+			}
+			break
+		}
+	}
+	return ""
+}
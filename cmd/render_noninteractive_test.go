@@ -0,0 +1,1197 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/registry"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// newTestAPIServer starts a fake claim-machinery API serving the given templates.
+// The order endpoint renders a ConfigMap named after the "name" parameter.
+func newTestAPIServer(t *testing.T, items []templates.ClaimTemplate) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Path == "/api/v1/claim-templates" {
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: items})
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/order") {
+			var req templates.OrderRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			rendered := fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %v\n", req.Parameters["name"])
+			json.NewEncoder(w).Encode(templates.OrderResponse{Rendered: rendered})
+			return
+		}
+
+		if name, ok := strings.CutPrefix(r.URL.Path, "/api/v1/claim-templates/"); ok {
+			for _, item := range items {
+				if item.Metadata.Name == name {
+					json.NewEncoder(w).Encode(item)
+					return
+				}
+			}
+		}
+
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// testTemplate returns a minimal claim template with a "name" parameter
+func testTemplate(name string) templates.ClaimTemplate {
+	return templates.ClaimTemplate{
+		Metadata: templates.ClaimTemplateMetadata{Name: name, Title: name},
+		Spec: templates.ClaimTemplateSpec{
+			Parameters: []templates.Parameter{
+				{Name: "name", Title: "Name", Type: "string", Default: "default-name"},
+			},
+		},
+	}
+}
+
+func TestRunNonInteractive_ResourcePrefixSuffix(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	tests := []struct {
+		name           string
+		params         []string
+		affixNameParam bool
+		wantFile       string
+		wantRegistry   string
+		wantRendered   string
+	}{
+		{
+			name:         "prefix and suffix wrap filename and registry name",
+			params:       []string{"name=my-vm"},
+			wantFile:     "vsphere-vm-prod-my-vm-01.yaml",
+			wantRegistry: "prod-my-vm-01",
+			wantRendered: "name: my-vm\n",
+		},
+		{
+			name:         "template default name is wrapped",
+			wantFile:     "vsphere-vm-prod-default-name-01.yaml",
+			wantRegistry: "prod-default-name-01",
+		},
+		{
+			name:           "affix-name-param rewrites rendered content",
+			params:         []string{"name=my-vm"},
+			affixNameParam: true,
+			wantFile:       "vsphere-vm-prod-my-vm-01.yaml",
+			wantRegistry:   "prod-my-vm-01",
+			wantRendered:   "name: prod-my-vm-01\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			outputDir := filepath.Join(repoRoot, "claims", "infra")
+
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Templates:       []string{"vsphere-vm"},
+				InlineParamsRaw: tt.params,
+				OutputDir:       outputDir,
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				ResourcePrefix:  "prod-",
+				ResourceSuffix:  "-01",
+				AffixNameParam:  tt.affixNameParam,
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			}
+
+			if err := runNonInteractive(context.Background(), config); err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, tt.wantFile))
+			if err != nil {
+				t.Fatalf("expected output file %s: %v", tt.wantFile, err)
+			}
+			if tt.wantRendered != "" && !strings.Contains(string(content), tt.wantRendered) {
+				t.Errorf("expected rendered content to contain %q, got:\n%s", tt.wantRendered, content)
+			}
+
+			reg, err := registry.Load(filepath.Join(repoRoot, "claims", "registry.yaml"))
+			if err != nil {
+				t.Fatalf("loading registry: %v", err)
+			}
+			if registry.FindEntry(reg, tt.wantRegistry) == nil {
+				t.Errorf("expected registry entry %q, got %+v", tt.wantRegistry, reg.Claims)
+			}
+		})
+	}
+}
+
+func TestRunNonInteractive_ResourceName(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm"), testTemplate("postgresql")})
+
+	tests := []struct {
+		name         string
+		setNameParam bool
+		prefix       string
+		wantFile     string
+		wantRegistry string
+		wantRendered string
+	}{
+		{
+			name:         "overrides filename and registry only",
+			wantFile:     "vsphere-vm-web-frontend.yaml",
+			wantRegistry: "web-frontend",
+			wantRendered: "name: my-vm\n",
+		},
+		{
+			name:         "set-name-param also renders it",
+			setNameParam: true,
+			wantFile:     "vsphere-vm-web-frontend.yaml",
+			wantRegistry: "web-frontend",
+			wantRendered: "name: web-frontend\n",
+		},
+		{
+			name:         "prefix still applies",
+			prefix:       "prod-",
+			wantFile:     "vsphere-vm-prod-web-frontend.yaml",
+			wantRegistry: "prod-web-frontend",
+			wantRendered: "name: my-vm\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			outputDir := filepath.Join(repoRoot, "claims", "infra")
+
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Templates:       []string{"vsphere-vm"},
+				InlineParamsRaw: []string{"name=my-vm"},
+				OutputDir:       outputDir,
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				ResourcePrefix:  tt.prefix,
+				ResourceName:    "web-frontend",
+				SetNameParam:    tt.setNameParam,
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			}
+			if err := runNonInteractive(context.Background(), config); err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, tt.wantFile))
+			if err != nil {
+				t.Fatalf("expected output file %s: %v", tt.wantFile, err)
+			}
+			if !strings.Contains(string(content), tt.wantRendered) {
+				t.Errorf("expected rendered content to contain %q, got:\n%s", tt.wantRendered, content)
+			}
+
+			reg, err := registry.Load(filepath.Join(repoRoot, "claims", "registry.yaml"))
+			if err != nil {
+				t.Fatalf("loading registry: %v", err)
+			}
+			if registry.FindEntry(reg, tt.wantRegistry) == nil {
+				t.Errorf("expected registry entry %q, got %+v", tt.wantRegistry, reg.Claims)
+			}
+		})
+	}
+
+	t.Run("rejects several templates", func(t *testing.T) {
+		config := &RenderConfig{Templates: []string{"vsphere-vm", "postgresql"}, ResourceName: "web"}
+		if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "single template entry") {
+			t.Errorf("expected a single-entry error, got %v", err)
+		}
+	})
+	t.Run("set-name-param needs resource-name", func(t *testing.T) {
+		config := &RenderConfig{Templates: []string{"vsphere-vm"}, SetNameParam: true}
+		if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "requires --resource-name") {
+			t.Errorf("expected a missing --resource-name error, got %v", err)
+		}
+	})
+}
+
+func TestRunNonInteractive_OfflineCatalog(t *testing.T) {
+	// The API refuses to list templates; only rendering is available
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/claim-templates" {
+			http.Error(w, "listing unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/order") {
+			json.NewEncoder(w).Encode(templates.OrderResponse{Rendered: "apiVersion: v1\nkind: ConfigMap\n"})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		fetchedAt time.Time
+		offline   bool
+		noCache   bool
+		wantErr   string
+	}{
+		{name: "fresh cache drives validation", fetchedAt: time.Now(), offline: true},
+		{name: "expired cache is rejected", fetchedAt: time.Now().Add(-48 * time.Hour), offline: true, wantErr: "--refresh-cache"},
+		{name: "online mode reuses fresh cache", fetchedAt: time.Now()},
+		{name: "online mode refetches expired cache", fetchedAt: time.Now().Add(-48 * time.Hour), wantErr: "fetching templates"},
+		{name: "no-cache ignores fresh cache", fetchedAt: time.Now(), noCache: true, wantErr: "fetching templates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalogPath := filepath.Join(t.TempDir(), "catalog.json")
+			if err := templates.SaveCatalog(catalogPath, &templates.Catalog{
+				APIUrl:    server.URL,
+				FetchedAt: tt.fetchedAt,
+				Items:     []templates.ClaimTemplate{testTemplate("vsphere-vm")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Offline:         tt.offline,
+				NoCache:         tt.noCache,
+				CacheTTL:        templates.DefaultCatalogTTL,
+				CatalogPath:     catalogPath,
+				Templates:       []string{"vsphere-vm"},
+				InlineParamsRaw: []string{"name=my-vm"},
+				OutputDir:       t.TempDir(),
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				DryRun:          true,
+			}
+
+			err := runNonInteractive(context.Background(), config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("runNonInteractive: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunNonInteractive_FilenameCollisions(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+	// Different claims, but the pattern ignores the name
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: my-vm
+  - name: vsphere-vm
+    parameters:
+      name: other-vm
+`
+	if err := os.WriteFile(paramsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newConfig := func(allow bool) *RenderConfig {
+		return &RenderConfig{
+			APIUrl:          server.URL,
+			ParamsFiles:     []string{paramsFile},
+			OutputDir:       t.TempDir(),
+			FilenamePattern: "{{.template}}.yaml",
+			DryRun:          true,
+			AllowCollisions: allow,
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		}
+	}
+
+	err := runNonInteractive(context.Background(), newConfig(false))
+	if err == nil {
+		t.Fatal("expected collision error")
+	}
+	if !strings.Contains(err.Error(), "vsphere-vm.yaml") {
+		t.Errorf("expected error to name the colliding file, got: %v", err)
+	}
+
+	if err := runNonInteractive(context.Background(), newConfig(true)); err != nil {
+		t.Errorf("expected --allow-collisions to proceed, got: %v", err)
+	}
+}
+
+func TestRunNonInteractive_Matrix(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+	outputDir := t.TempDir()
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		MatrixRaw:       []string{"region=eu,us", "size=small,large"},
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("runNonInteractive: %v", err)
+	}
+
+	for _, name := range []string{"web-eu-small", "web-eu-large", "web-us-small", "web-us-large"} {
+		data, err := os.ReadFile(filepath.Join(outputDir, "vsphere-vm-"+name+".yaml"))
+		if err != nil {
+			t.Errorf("expected claim for %s: %v", name, err)
+			continue
+		}
+		if !strings.Contains(string(data), "name: "+name) {
+			t.Errorf("claim for %s has the wrong name:\n%s", name, data)
+		}
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 4 {
+		t.Errorf("expected 4 files, got %d", len(entries))
+	}
+}
+
+func TestRunNonInteractive_DuplicateEntries(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: my-vm
+      cpu: 2
+  - name: vsphere-vm
+    parameters:
+      name: other-vm
+  - name: vsphere-vm
+    parameters:
+      name: my-vm
+      cpu: 4
+`
+	if err := os.WriteFile(paramsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newConfig := func(merge bool) *RenderConfig {
+		return &RenderConfig{
+			APIUrl:          server.URL,
+			ParamsFiles:     []string{paramsFile},
+			OutputDir:       t.TempDir(),
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			MergeDuplicates: merge,
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		}
+	}
+
+	err := runNonInteractive(context.Background(), newConfig(false))
+	if err == nil || !strings.Contains(err.Error(), "vsphere-vm/my-vm (entries 1, 3)") {
+		t.Fatalf("expected duplicate error listing the entries, got: %v", err)
+	}
+
+	// --allow-collisions only concerns filenames and doesn't let duplicates through
+	allow := newConfig(false)
+	allow.AllowCollisions = true
+	err = runNonInteractive(context.Background(), allow)
+	if err == nil || !strings.Contains(err.Error(), "use --merge-duplicates") {
+		t.Fatalf("expected duplicate error with --allow-collisions, got: %v", err)
+	}
+
+	config := newConfig(true)
+	templateParams, err := resolveTemplateParams(config)
+	if err != nil {
+		t.Fatalf("resolveTemplateParams() error: %v", err)
+	}
+	if len(templateParams) != 2 || templateParams[0].Parameters["cpu"] != 4 {
+		t.Errorf("expected my-vm merged with cpu 4 and other-vm kept, got %+v", templateParams)
+	}
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("expected --merge-duplicates to render, got: %v", err)
+	}
+	if entries, _ := os.ReadDir(config.OutputDir); len(entries) != 2 {
+		t.Errorf("expected 2 files, got %d", len(entries))
+	}
+}
+
+func TestRunNonInteractive_TemplateVersion(t *testing.T) {
+	newServer := func(versions []string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/api/v1/claim-templates":
+				json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: []templates.ClaimTemplate{testTemplate("vsphere-vm")}})
+			case strings.HasSuffix(r.URL.Path, "/versions") && versions != nil:
+				json.NewEncoder(w).Encode(templates.TemplateVersionList{Versions: versions})
+			case strings.HasSuffix(r.URL.Path, "/order"):
+				var req templates.OrderRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				rendered := fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %v\n  tag: %q\n", req.Parameters["name"], req.Tag)
+				json.NewEncoder(w).Encode(templates.OrderResponse{Rendered: rendered})
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	tests := []struct {
+		name     string
+		versions []string // nil = versions endpoint not supported
+		version  string
+		wantErr  bool
+		wantTag  string
+	}{
+		{name: "available version is requested", versions: []string{"v1.0.0", "v1.1.0"}, version: "v1.1.0", wantTag: `tag: "v1.1.0"`},
+		{name: "unknown version is rejected", versions: []string{"v1.0.0"}, version: "v9.9.9", wantErr: true},
+		{name: "unsupported API renders catalog default", version: "v1.1.0", wantTag: `tag: ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer(tt.versions)
+			outputDir := t.TempDir()
+
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Templates:       []string{"vsphere-vm"},
+				TemplateVersion: tt.version,
+				InlineParamsRaw: []string{"name=my-vm"},
+				OutputDir:       outputDir,
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			}
+
+			err := runNonInteractive(context.Background(), config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error for unavailable version")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(outputDir, "vsphere-vm-my-vm.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(content), tt.wantTag) {
+				t.Errorf("expected %s in rendered output, got:\n%s", tt.wantTag, content)
+			}
+		})
+	}
+}
+
+func TestRunNonInteractive_TemplateAliases(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm"), testTemplate("postgresql")})
+
+	aliasesPath := filepath.Join(t.TempDir(), "aliases.yaml")
+	aliases := "aliases:\n  vm: vsphere-vm\n  postgresql: vsphere-vm\n"
+	if err := os.WriteFile(aliasesPath, []byte(aliases), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		wantFile string
+	}{
+		{name: "alias resolves to catalog name", template: "vm", wantFile: "vsphere-vm-my-res.yaml"},
+		{name: "real template wins over alias", template: "postgresql", wantFile: "postgresql-my-res.yaml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputDir := t.TempDir()
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Templates:       []string{tt.template},
+				InlineParamsRaw: []string{"name=my-res"},
+				OutputDir:       outputDir,
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+				AliasesPath:     aliasesPath,
+			}
+
+			if err := runNonInteractive(context.Background(), config); err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, tt.wantFile)); err != nil {
+				t.Errorf("expected output file %s: %v", tt.wantFile, err)
+			}
+		})
+	}
+}
+
+func TestResolveTemplateParams_Set(t *testing.T) {
+	paramsPath := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: my-vm
+      disk:
+        size: 10Gi
+        type: ssd
+`
+	if err := os.WriteFile(paramsPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		ParamsFiles:     []string{paramsPath},
+		InlineParamsRaw: []string{"name=other-vm"},
+		SetParamsRaw:    []string{"disk.size=20Gi", "network.dns[0]=8.8.8.8"},
+	}
+	resolved, err := resolveTemplateParams(config)
+	if err != nil {
+		t.Fatalf("resolveTemplateParams: %v", err)
+	}
+
+	got := resolved[0].Parameters
+	want := map[string]any{
+		"name":    "other-vm",
+		"disk":    map[string]any{"size": "20Gi", "type": "ssd"},
+		"network": map[string]any{"dns": []any{"8.8.8.8"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parameters = %#v, want %#v", got, want)
+	}
+}
+
+func TestResolveTemplateParams_ParamFile(t *testing.T) {
+	dir := t.TempDir()
+	resourcesPath := filepath.Join(dir, "resources.yaml")
+	if err := os.WriteFile(resourcesPath, []byte("requests:\n  cpu: 500m\n  memory: 1Gi\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	labelsPath := filepath.Join(dir, "labels.json")
+	if err := os.WriteFile(labelsPath, []byte(`{"team": "infra", "tier": "web"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		Templates:        []string{"vsphere-vm"},
+		InlineParamsRaw:  []string{"name=web"},
+		ParamFileYAMLRaw: []string{"app.resources=" + resourcesPath},
+		ParamFileJSONRaw: []string{"labels=" + labelsPath},
+		SetParamsRaw:     []string{"app.resources.requests.cpu=1"},
+	}
+	resolved, err := resolveTemplateParams(config)
+	if err != nil {
+		t.Fatalf("resolveTemplateParams: %v", err)
+	}
+
+	got := resolved[0].Parameters
+	want := map[string]any{
+		"name":   "web",
+		"app":    map[string]any{"resources": map[string]any{"requests": map[string]any{"cpu": "1", "memory": "1Gi"}}},
+		"labels": map[string]any{"team": "infra", "tier": "web"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parameters = %#v, want %#v", got, want)
+	}
+
+	config.ParamFileJSONRaw = []string{"labels=" + resourcesPath}
+	if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "as JSON") {
+		t.Errorf("expected a JSON parse error, got %v", err)
+	}
+}
+
+func TestResolveTemplateParams_MultipleParamsFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": "templates:\n  - name: vsphere-vm\n    parameters:\n      name: web\n      cpu: 2\n      disk:\n        size: 10Gi\n        type: ssd\n",
+		"prod.yaml": "templates:\n  - name: vsphere-vm\n    parameters:\n      cpu: 4\n      disk:\n        size: 50Gi\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &RenderConfig{
+		ParamsFiles:     []string{filepath.Join(dir, "base.yaml"), filepath.Join(dir, "prod.yaml")},
+		InlineParamsRaw: []string{"cpu=8"},
+	}
+	resolved, err := resolveTemplateParams(config)
+	if err != nil {
+		t.Fatalf("resolveTemplateParams: %v", err)
+	}
+	if len(resolved) != 1 {
+		t.Fatalf("expected the entries to be merged, got %+v", resolved)
+	}
+
+	got := resolved[0].Parameters
+	want := map[string]any{
+		"name": "web",
+		"cpu":  8,
+		"disk": map[string]any{"size": "50Gi", "type": "ssd"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parameters = %#v, want %#v", got, want)
+	}
+
+	config.ChangedOnly = true
+	if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "single --params-file") {
+		t.Errorf("expected --changed-only to reject several files, got %v", err)
+	}
+}
+
+func TestResolveTemplateParams_ExpandEnv(t *testing.T) {
+	t.Setenv("VM_NAME", "web-01")
+	path := filepath.Join(t.TempDir(), "params.yaml")
+	content := "templates:\n  - name: vsphere-vm\n    parameters:\n      name: ${VM_NAME}\n      cpu: ${CPU_UNSET_TEST:-2}\n      owner: ${OWNER_UNSET_TEST}\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{ParamsFiles: []string{path}, ExpandEnv: true}
+	resolved, err := resolveTemplateParams(config)
+	if err != nil {
+		t.Fatalf("resolveTemplateParams: %v", err)
+	}
+	got := resolved[0].Parameters
+	if got["name"] != "web-01" || got["cpu"] != 2 {
+		t.Errorf("expected expanded parameters, got %v", got)
+	}
+
+	config = &RenderConfig{ParamsFiles: []string{path}, StrictEnv: true}
+	if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "OWNER_UNSET_TEST") {
+		t.Errorf("expected --strict-env to name the unset variable, got %v", err)
+	}
+}
+
+func TestRunNonInteractive_OnlyNew(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	repoRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(repoRoot, "claims", "infra")
+
+	// web-1 is already registered, web-2 was deleted earlier
+	reg := registry.NewRegistry()
+	registry.AddEntry(reg, registry.ClaimEntry{Name: "web-1", Template: "vsphere-vm", Category: "infra", Status: "active"})
+	registry.AddEntry(reg, registry.ClaimEntry{Name: "web-2", Template: "vsphere-vm", Category: "infra", Status: "deleted"})
+	if err := os.MkdirAll(filepath.Join(repoRoot, "claims"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Save(filepath.Join(repoRoot, "claims", "registry.yaml"), reg); err != nil {
+		t.Fatal(err)
+	}
+
+	paramsPath := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+  - name: vsphere-vm
+    parameters:
+      name: web-2
+  - name: vsphere-vm
+    parameters:
+      name: web-3
+`
+	if err := os.WriteFile(paramsPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		ParamsFiles:     []string{paramsPath},
+		OnlyNew:         true,
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("runNonInteractive: %v", err)
+	}
+
+	for name, want := range map[string]bool{"web-1": false, "web-2": true, "web-3": true} {
+		_, err := os.Stat(filepath.Join(outputDir, "vsphere-vm-"+name+".yaml"))
+		if got := err == nil; got != want {
+			t.Errorf("%s rendered = %v, want %v", name, got, want)
+		}
+	}
+
+	// A second run finds everything registered and renders nothing
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("second runNonInteractive: %v", err)
+	}
+}
+
+func TestRunNonInteractive_RegistryFilter(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm"), testTemplate("postgresql")})
+
+	setup := func(t *testing.T) string {
+		t.Helper()
+		repoRoot := t.TempDir()
+		if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		reg := registry.NewRegistry()
+		for _, e := range []registry.ClaimEntry{
+			{Name: "web-1", Template: "vsphere-vm", Category: "infra", Path: "claims/infra/web-1.yaml", Status: "active",
+				CreatedAt: "2026-01-01T00:00:00Z", Labels: map[string]string{"env": "prod"}, Parameters: map[string]any{"name": "web-1"}},
+			{Name: "web-2", Template: "vsphere-vm", Category: "infra", Path: "claims/infra/web-2.yaml", Status: "active",
+				Labels: map[string]string{"env": "dev"}, Parameters: map[string]any{"name": "web-2"}},
+			{Name: "legacy", Template: "vsphere-vm", Category: "infra", Path: "claims/infra/legacy.yaml", Status: "active"},
+			{Name: "old", Template: "vsphere-vm", Category: "infra", Path: "claims/infra/old.yaml", Status: "deleted",
+				Parameters: map[string]any{"name": "old"}},
+			{Name: "db", Template: "postgresql", Category: "apps", Path: "claims/apps/db.yaml", Status: "active",
+				Parameters: map[string]any{"name": "db"}},
+		} {
+			registry.AddEntry(reg, e)
+		}
+		if err := os.MkdirAll(filepath.Join(repoRoot, "claims"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := registry.Save(filepath.Join(repoRoot, "claims", "registry.yaml"), reg); err != nil {
+			t.Fatal(err)
+		}
+		return repoRoot
+	}
+
+	tests := []struct {
+		name   string
+		filter []string
+		want   []string // claims (re-)rendered to their registry paths
+	}{
+		{"by category", []string{"category=infra"}, []string{"web-1", "web-2"}},
+		{"by category and label", []string{"category=infra", "label=env=prod"}, []string{"web-1"}},
+		{"by template", []string{"template=postgresql"}, []string{"db"}},
+	}
+
+	all := map[string]string{
+		"web-1":  "claims/infra/web-1.yaml",
+		"web-2":  "claims/infra/web-2.yaml",
+		"legacy": "claims/infra/legacy.yaml",
+		"old":    "claims/infra/old.yaml",
+		"db":     "claims/apps/db.yaml",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := setup(t)
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				RegistryFilter:  tt.filter,
+				OutputDir:       repoRoot,
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			}
+			if err := runNonInteractive(context.Background(), config); err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+
+			for name, path := range all {
+				_, err := os.Stat(filepath.Join(repoRoot, filepath.FromSlash(path)))
+				want := false
+				for _, w := range tt.want {
+					want = want || w == name
+				}
+				if got := err == nil; got != want {
+					t.Errorf("%s rendered = %v, want %v", name, got, want)
+				}
+			}
+
+			// Re-rendered entries keep their creation time and labels
+			reg, err := registry.Load(filepath.Join(repoRoot, "claims", "registry.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(reg.Claims) != len(all) {
+				t.Errorf("registry has %d claims, want %d", len(reg.Claims), len(all))
+			}
+			web1 := registry.FindEntry(reg, "web-1")
+			if web1.CreatedAt != "2026-01-01T00:00:00Z" || web1.Labels["env"] != "prod" || web1.Category != "infra" {
+				t.Errorf("web-1 entry not preserved: %+v", web1)
+			}
+		})
+	}
+
+	t.Run("diff only writes nothing", func(t *testing.T) {
+		repoRoot := setup(t)
+		existing := filepath.Join(repoRoot, "claims", "infra", "web-1.yaml")
+		if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(existing, []byte("stale\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		config := &RenderConfig{
+			APIUrl:          server.URL,
+			RegistryFilter:  []string{"label=env=prod"},
+			DiffOnly:        true,
+			OutputDir:       repoRoot,
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		}
+		if err := runNonInteractive(context.Background(), config); err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+
+		if data, _ := os.ReadFile(existing); string(data) != "stale\n" {
+			t.Errorf("--diff-only overwrote %s: %q", existing, data)
+		}
+	})
+
+	t.Run("diff then writes", func(t *testing.T) {
+		repoRoot := setup(t)
+		existing := filepath.Join(repoRoot, "claims", "infra", "web-1.yaml")
+		if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(existing, []byte("stale\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		config := &RenderConfig{
+			APIUrl:          server.URL,
+			RegistryFilter:  []string{"label=env=prod"},
+			Diff:            true,
+			OutputDir:       repoRoot,
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		}
+		if err := runNonInteractive(context.Background(), config); err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+
+		if data, _ := os.ReadFile(existing); string(data) == "stale\n" {
+			t.Errorf("--diff didn't write %s", existing)
+		}
+	})
+
+	t.Run("conflicting options", func(t *testing.T) {
+		config := &RenderConfig{RegistryFilter: []string{"category=infra"}, Templates: []string{"vsphere-vm"}, OutputDir: setup(t)}
+		if _, err := resolveTemplateParams(config); err == nil || !strings.Contains(err.Error(), "cannot be combined") {
+			t.Errorf("expected conflict error, got %v", err)
+		}
+	})
+}
+
+func TestResolveRegistrySource(t *testing.T) {
+	tests := []struct {
+		name string
+		flag string
+		env  map[string]string
+		want string
+	}{
+		{name: "default", want: "cli"},
+		{name: "explicit source", flag: "backstage", env: map[string]string{"CI": "true"}, want: "backstage"},
+		{name: "CI=true", env: map[string]string{"CI": "true"}, want: "ci"},
+		{name: "GitHub Actions", env: map[string]string{"GITHUB_ACTIONS": "true"}, want: "ci"},
+		{name: "GitLab CI", env: map[string]string{"GITLAB_CI": "true"}, want: "ci"},
+		{name: "CI=false", env: map[string]string{"CI": "false"}, want: "cli"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Isolate from the environment the tests themselves run in
+			for _, name := range ciEnvVars {
+				t.Setenv(name, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := resolveRegistrySource(tt.flag); got != tt.want {
+				t.Errorf("resolveRegistrySource(%q) = %q, want %q", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRegistryFilter(t *testing.T) {
+	tests := []struct {
+		raw     []string
+		want    registry.Selector
+		wantErr bool
+	}{
+		{raw: []string{"category=infra", "template=vsphere-vm"}, want: registry.Selector{Category: "infra", Template: "vsphere-vm"}},
+		{raw: []string{"label=env=prod", "label=team=ops"}, want: registry.Selector{Labels: map[string]string{"env": "prod", "team": "ops"}}},
+		{raw: []string{"source=ci"}, want: registry.Selector{Source: "ci"}},
+		{raw: []string{"category"}, wantErr: true},
+		{raw: []string{"label=env"}, wantErr: true},
+		{raw: []string{"owner=me"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRegistryFilter(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRegistryFilter(%v) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseRegistryFilter(%v) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestRunNonInteractive_Stdout(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	var buf bytes.Buffer
+	stdout, stdoutStarted = &buf, false
+	t.Cleanup(func() { stdout, stdoutStarted = os.Stdout, false })
+
+	// Run in a repository so a registry update would be noticed
+	repoRoot := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repoRoot, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repoRoot)
+
+	paramsPath := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+  - name: vsphere-vm
+    parameters:
+      name: web-2
+`
+	if err := os.WriteFile(paramsPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		ParamsFiles:     []string{paramsPath},
+		OutputDir:       StdoutDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		GitConfig:       &GitConfig{Commit: true},
+	}
+	if err := runNonInteractive(context.Background(), config); err != nil {
+		t.Fatalf("runNonInteractive: %v", err)
+	}
+
+	want := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-1\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-2\n"
+	if got := buf.String(); got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+
+	entries, err := os.ReadDir(repoRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only .git in the repository, got %d entries", len(entries))
+	}
+}
+
+func TestLoadRenderTemplates(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v1/claim-templates":
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{
+				Items: []templates.ClaimTemplate{testTemplate("vsphere-vm"), testTemplate("postgres")},
+			})
+		case "/api/v1/claim-templates/vsphere-vm":
+			json.NewEncoder(w).Encode(testTemplate("vsphere-vm"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		names        []string
+		offline      bool
+		wantRequests []string
+		wantCount    int
+	}{
+		{
+			name:         "single template is fetched by name",
+			names:        []string{"vsphere-vm", "vsphere-vm"},
+			wantRequests: []string{"/api/v1/claim-templates/vsphere-vm"},
+			wantCount:    1,
+		},
+		{
+			name:         "several templates load the catalog",
+			names:        []string{"vsphere-vm", "postgres"},
+			wantRequests: []string{"/api/v1/claim-templates"},
+			wantCount:    2,
+		},
+		{
+			name:         "unknown name falls back to the catalog",
+			names:        []string{"vm"},
+			wantRequests: []string{"/api/v1/claim-templates/vm", "/api/v1/claim-templates"},
+			wantCount:    2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			config := &RenderConfig{
+				APIUrl:      server.URL,
+				CatalogPath: filepath.Join(t.TempDir(), "catalog.json"),
+			}
+			client, err := newRenderClient(config)
+			if err != nil {
+				t.Fatalf("newRenderClient: %v", err)
+			}
+			available, err := loadRenderTemplates(context.Background(), client, config, tt.names)
+			if err != nil {
+				t.Fatalf("loadRenderTemplates: %v", err)
+			}
+			if len(available) != tt.wantCount {
+				t.Errorf("expected %d templates, got %d", tt.wantCount, len(available))
+			}
+			if strings.Join(requests, " ") != strings.Join(tt.wantRequests, " ") {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestFetchTemplate(t *testing.T) {
+	// An API without single-template lookups answers 404 for every name
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/claim-templates" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: []templates.ClaimTemplate{testTemplate("vsphere-vm")}})
+	}))
+	defer server.Close()
+
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatalf("newCatalogClient: %v", err)
+	}
+
+	tmpl, err := fetchTemplate(context.Background(), client, "vsphere-vm", true)
+	if err != nil {
+		t.Fatalf("fetchTemplate: %v", err)
+	}
+	if tmpl.Metadata.Name != "vsphere-vm" {
+		t.Errorf("expected vsphere-vm, got %s", tmpl.Metadata.Name)
+	}
+
+	_, err = fetchTemplate(context.Background(), client, "missing", true)
+	if !errors.Is(err, templates.ErrTemplateNotFound) {
+		t.Errorf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestRunNonInteractive_UnreadableCACert(t *testing.T) {
+	config := &RenderConfig{
+		APIUrl:          "https://claims.internal",
+		CACert:          filepath.Join(t.TempDir(), "missing-ca.pem"),
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		OutputDir:       t.TempDir(),
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+	err := runNonInteractive(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "reading CA certificate") || !strings.Contains(err.Error(), "missing-ca.pem") {
+		t.Errorf("expected a CA certificate error, got %v", err)
+	}
+}
+
+func TestRunNonInteractive_Defaults(t *testing.T) {
+	tmpl := testTemplate("vsphere-vm")
+	tmpl.Spec.Parameters = append(tmpl.Spec.Parameters,
+		templates.Parameter{Name: "cpu", Type: "integer", Default: float64(2)},
+		templates.Parameter{Name: "size", Type: "string", Default: "small"},
+		templates.Parameter{Name: "token", Type: "string", Default: "generated", Hidden: true},
+	)
+
+	var sent map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/claim-templates":
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: []templates.ClaimTemplate{tmpl}})
+		case strings.HasSuffix(r.URL.Path, "/order"):
+			var req templates.OrderRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			sent = req.Parameters
+			json.NewEncoder(w).Encode(templates.OrderResponse{Rendered: "kind: ConfigMap\n"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		inline     []string
+		noDefaults bool
+		want       map[string]any
+	}{
+		{
+			name:   "defaults fill unset params",
+			inline: []string{"name=web", "size=large"},
+			want:   map[string]any{"name": "web", "size": "large", "cpu": float64(2)},
+		},
+		{
+			name:   "removed params stay unset",
+			inline: []string{"name=web", "cpu-"},
+			want:   map[string]any{"name": "web", "size": "small"},
+		},
+		{
+			name:       "no defaults",
+			inline:     []string{"name=web"},
+			noDefaults: true,
+			want:       map[string]any{"name": "web"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent = nil
+			config := &RenderConfig{
+				APIUrl:          server.URL,
+				Templates:       []string{"vsphere-vm"},
+				InlineParamsRaw: tt.inline,
+				NoDefaults:      tt.noDefaults,
+				OutputDir:       t.TempDir(),
+				FilenamePattern: "{{.template}}-{{.name}}.yaml",
+				CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			}
+			if err := runNonInteractive(context.Background(), config); err != nil {
+				t.Fatalf("runNonInteractive: %v", err)
+			}
+			if !reflect.DeepEqual(sent, tt.want) {
+				t.Errorf("sent %v, want %v", sent, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,29 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"gopkg.in/yaml.v3"
 )
 
+// StdoutDir as the output directory (-o -) streams the rendered claims to
+// stdout instead of writing files
+const StdoutDir = "-"
+
+// stdout receives the claims streamed by -o -. It is the process's stdout at
+// startup, so it stays the real stdout while progress output is redirected.
+var stdout io.Writer = os.Stdout
+
+// stdoutStarted records whether a document was already streamed, so later
+// documents are preceded by a --- separator
+var stdoutStarted bool
+
 // OutputConfig holds configuration for file output
 type OutputConfig struct {
 	Directory       string
@@ -16,53 +32,321 @@ type OutputConfig struct {
 	SingleFile      bool
 	DryRun          bool
 	FileMode        string // "overwrite" (default) or "append"
+	KeepCRLF        bool   // skip CRLF -> LF normalization of rendered content
+	Stdout          bool   // stream to stdout instead of writing files (--stdout, -o -)
 }
 
-// FileInfo holds information used for filename generation
+// errDryRunStdout rejects --dry-run together with streaming to stdout
+var errDryRunStdout = errors.New("--dry-run and --stdout (-o -) are mutually exclusive: -o - already writes nothing to disk")
+
+// resolveStdoutFlags applies --stdout to the output directory and rejects
+// combinations it can't honor. outputDirSet tells whether -o was given.
+func resolveStdoutFlags(outputDirSet bool) error {
+	if toStdout {
+		if outputDirSet && outputDir != StdoutDir {
+			return fmt.Errorf("--stdout and --output-dir %s are mutually exclusive", outputDir)
+		}
+		outputDir = StdoutDir
+	}
+	if dryRun && outputDir == StdoutDir {
+		return errDryRunStdout
+	}
+	if summaryJSONPath == "-" && outputDir == StdoutDir {
+		return errors.New("--summary-json - can't be combined with --stdout; write the summary to a file")
+	}
+	return nil
+}
+
+// FileInfo holds information used for filename and directory generation
 type FileInfo struct {
 	TemplateName string
 	ResourceName string
+
+	// Metadata of the rendered resource, see resourceMetadata
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
 }
 
 // GenerateFilename creates a filename from pattern and file info
 func GenerateFilename(pattern string, info FileInfo) (string, error) {
-	tmpl, err := template.New("filename").Parse(pattern)
+	return executePathPattern("filename", pattern, info)
+}
+
+// executePathPattern executes a filename or output directory pattern. It can
+// reference .template, .name, .namespace, .labels.<key> and
+// .annotations.<key>; referencing a key the resource doesn't set is an error.
+func executePathPattern(kind, pattern string, info FileInfo) (string, error) {
+	tmpl, err := template.New(kind).Option("missingkey=error").Parse(pattern)
 	if err != nil {
-		return "", fmt.Errorf("invalid filename pattern: %w", err)
+		return "", fmt.Errorf("invalid %s pattern: %w", kind, err)
 	}
 
-	data := map[string]string{
-		"template": info.TemplateName,
-		"name":     info.ResourceName,
+	data := map[string]any{
+		"template":    info.TemplateName,
+		"name":        info.ResourceName,
+		"namespace":   info.Namespace,
+		"labels":      nonNilMap(info.Labels),
+		"annotations": nonNilMap(info.Annotations),
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("executing filename template: %w", err)
+		return "", fmt.Errorf("executing %s template: %w", kind, err)
 	}
 
 	return buf.String(), nil
 }
 
-// WriteResults writes render results to files based on the output configuration
+// nonNilMap returns m, or an empty map if m is nil
+func nonNilMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+// resourceMetadata returns the namespace, labels and annotations of the
+// first document of rendered YAML; content that doesn't parse has none
+func resourceMetadata(content string) (namespace string, labels, annotations map[string]string) {
+	var doc struct {
+		Metadata struct {
+			Namespace   string            `yaml:"namespace"`
+			Labels      map[string]string `yaml:"labels"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", nil, nil
+	}
+	return doc.Metadata.Namespace, doc.Metadata.Labels, doc.Metadata.Annotations
+}
+
+// resultFileInfo returns the pattern data of a result, including the
+// metadata of its rendered content
+func resultFileInfo(r RenderResult) FileInfo {
+	info := FileInfo{TemplateName: r.TemplateName, ResourceName: r.ResourceName}
+	info.Namespace, info.Labels, info.Annotations = resourceMetadata(r.Content)
+	return info
+}
+
+// usesRenderedMetadata reports whether a filename or directory pattern
+// references the rendered resource's metadata, which is only known after rendering
+func usesRenderedMetadata(pattern string) bool {
+	return strings.Contains(pattern, ".labels") || strings.Contains(pattern, ".annotations") || strings.Contains(pattern, ".namespace")
+}
+
+// isDirPattern reports whether an output directory contains template actions
+func isDirPattern(dir string) bool {
+	return strings.Contains(dir, "{{")
+}
+
+// staticDir returns the leading part of an output directory pattern without
+// template actions, e.g. "clusters" for "clusters/{{.labels.cluster}}"
+func staticDir(dir string) string {
+	i := strings.Index(dir, "{{")
+	if i < 0 {
+		return dir
+	}
+	return filepath.Dir(dir[:i] + "x")
+}
+
+// filenameCollision is an output filename produced by more than one entry
+type filenameCollision struct {
+	Filename string
+	Entries  []string // "template/name" of each colliding entry, in input order
+}
+
+// findFilenameCollisions computes the output filename of every entry with
+// GenerateFilename and reports the filenames shared by several entries
+func findFilenameCollisions(pattern string, files []FileInfo) ([]filenameCollision, error) {
+	filenames := make([]string, len(files))
+	for i, f := range files {
+		filename, err := GenerateFilename(pattern, f)
+		if err != nil {
+			return nil, err
+		}
+		filenames[i] = filename
+	}
+	return groupFilenameCollisions(filenames, files), nil
+}
+
+// findResultPathCollisions reports the output paths shared by several of the
+// results written to their own file, honoring per-entry output overrides
+func findResultPathCollisions(results []RenderResult, config OutputConfig) ([]filenameCollision, error) {
+	var paths []string
+	var files []FileInfo
+	for _, r := range results {
+		if !splitsOutput(r, config) {
+			continue
+		}
+		path, err := resultFilePath(r, config)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+		files = append(files, resultFileInfo(r))
+	}
+	return groupFilenameCollisions(paths, files), nil
+}
+
+// groupFilenameCollisions groups files by their computed filename and returns
+// the filenames shared by several of them
+func groupFilenameCollisions(filenames []string, files []FileInfo) []filenameCollision {
+	var order []string
+	entries := make(map[string][]string)
+	for i, filename := range filenames {
+		if _, seen := entries[filename]; !seen {
+			order = append(order, filename)
+		}
+		entries[filename] = append(entries[filename], files[i].TemplateName+"/"+files[i].ResourceName)
+	}
+
+	var collisions []filenameCollision
+	for _, filename := range order {
+		if len(entries[filename]) > 1 {
+			collisions = append(collisions, filenameCollision{Filename: filename, Entries: entries[filename]})
+		}
+	}
+	return collisions
+}
+
+// formatFilenameCollisions renders one line per colliding filename
+func formatFilenameCollisions(collisions []filenameCollision) string {
+	lines := make([]string, len(collisions))
+	for i, c := range collisions {
+		lines[i] = fmt.Sprintf("  %s: %s", c.Filename, strings.Join(c.Entries, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WriteResults writes render results to files based on the output configuration.
+// A result's output override from the params file takes precedence over the
+// configuration for that result. It updates results in place: unless KeepCRLF
+// is set, each Content has its CRLF line endings converted to LF, and
+// OutputPath is set for files written per result.
 func WriteResults(results []RenderResult, config OutputConfig) error {
+	if !config.KeepCRLF {
+		for i := range results {
+			results[i].Content = normalizeLineEndings(results[i].Content)
+		}
+	}
+
+	if config.DryRun && config.Stdout {
+		return errDryRunStdout
+	}
 	if config.DryRun {
 		return printDryRun(results, config)
 	}
+	if config.Stdout {
+		return writeStdout(results)
+	}
 
-	// Ensure output directory exists
-	if err := os.MkdirAll(config.Directory, 0755); err != nil {
+	// Ensure output directory exists; a templated one is created per result
+	if err := os.MkdirAll(staticDir(config.Directory), 0755); err != nil {
 		return fmt.Errorf("creating output directory: %w", err)
 	}
 
-	if config.SingleFile {
-		return writeSingleFile(results, config)
+	if combined := combinedResults(results, config); len(combined) > 0 {
+		if err := writeSingleFile(combined, config); err != nil {
+			return err
+		}
 	}
 	return writeSeparateFiles(results, config)
 }
 
+// splitsOutput reports whether a result is written to its own file. Its
+// output override decides if it sets split or a dir or pattern; otherwise
+// --single-file does.
+func splitsOutput(r RenderResult, config OutputConfig) bool {
+	if o := r.Output; o != nil {
+		if o.Split != nil {
+			return *o.Split
+		}
+		if o.Dir != "" || o.Pattern != "" {
+			return true
+		}
+	}
+	return !config.SingleFile
+}
+
+// combinedResults returns the results that go into the combined file
+func combinedResults(results []RenderResult, config OutputConfig) []RenderResult {
+	var combined []RenderResult
+	for _, r := range results {
+		if !splitsOutput(r, config) {
+			combined = append(combined, r)
+		}
+	}
+	return combined
+}
+
 // writeSingleFile combines all results into a single YAML file separated by ---
 func writeSingleFile(results []RenderResult, config OutputConfig) error {
+	dir, err := combinedDir(results, config)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+	path := combinedFilePath(results, config)
+	if err := os.WriteFile(path, []byte(combineResults(results)), 0644); err != nil {
+		return fmt.Errorf("writing combined file: %w", err)
+	}
+
+	fmt.Printf("Saved combined file: %s\n", path)
+	return nil
+}
+
+// writeStdout streams the successful results to stdout as one YAML stream.
+// No files, directories or output paths are created, so the registry and git
+// steps have nothing to pick up.
+func writeStdout(results []RenderResult) error {
+	for _, r := range results {
+		if r.Error != nil {
+			continue // Skip failed renders
+		}
+		if err := writeStdoutDocument(r.Content); err != nil {
+			return fmt.Errorf("writing to stdout: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeStdoutDocument writes one YAML document to stdout, separated by ---
+// from the documents written before it
+func writeStdoutDocument(content string) error {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil
+	}
+	if stdoutStarted {
+		content = "---\n" + content
+	}
+	stdoutStarted = true
+	_, err := fmt.Fprintln(stdout, content)
+	return err
+}
+
+// redirectProgressToStderr points os.Stdout, where progress and decorative
+// output is printed, at stderr so that -o - keeps stdout for the claims. It
+// returns a function restoring os.Stdout.
+func redirectProgressToStderr() func() {
+	saved := os.Stdout
+	os.Stdout = os.Stderr
+	return func() { os.Stdout = saved }
+}
+
+// warnStdoutSkipsGit warns that configured git steps don't run with -o -
+func warnStdoutSkipsGit(config *RenderConfig) {
+	if gc := config.GitConfig; gc != nil && (gc.Commit || gc.Push) {
+		warnf("--output-dir - writes to stdout; skipping git commit, push and PR")
+	}
+}
+
+// combineResults joins the successful results into one YAML stream separated by ---
+func combineResults(results []RenderResult) string {
 	var combined strings.Builder
 
 	for i, r := range results {
@@ -78,39 +362,111 @@ func writeSingleFile(results []RenderResult, config OutputConfig) error {
 			combined.WriteString("\n")
 		}
 	}
+	return combined.String()
+}
 
-	// Use first template name for combined file
+// combinedFilePath returns the --single-file output path, named after the
+// first template. A templated output directory is expanded for the first
+// successful result, falling back to its static part if that fails.
+func combinedFilePath(results []RenderResult, config OutputConfig) string {
 	filename := "combined-claims.yaml"
 	if len(results) > 0 && results[0].TemplateName != "" {
 		filename = fmt.Sprintf("%s-combined.yaml", results[0].TemplateName)
 	}
+	dir, err := combinedDir(results, config)
+	if err != nil {
+		dir = staticDir(config.Directory)
+	}
+	return filepath.Join(dir, filename)
+}
 
-	path := filepath.Join(config.Directory, filename)
-	if err := os.WriteFile(path, []byte(combined.String()), 0644); err != nil {
-		return fmt.Errorf("writing combined file: %w", err)
+// combinedDir returns the directory of the --single-file output, expanding
+// a templated output directory for the first successful result
+func combinedDir(results []RenderResult, config OutputConfig) (string, error) {
+	if !isDirPattern(config.Directory) {
+		return config.Directory, nil
+	}
+	for _, r := range results {
+		if r.Error == nil {
+			return executePathPattern("output directory", config.Directory, resultFileInfo(r))
+		}
+	}
+	return staticDir(config.Directory), nil
+}
+
+// resultPath returns the output path of a result in separate-file mode like
+// resultFilePath, falling back to template-name.yaml if the pattern fails
+func resultPath(r RenderResult, config OutputConfig) string {
+	path, err := resultFilePath(r, config)
+	if err != nil {
+		dir, err := resultDir(r, config)
+		if err != nil {
+			dir = staticDir(config.Directory)
+		}
+		return filepath.Join(dir, fmt.Sprintf("%s-%s.yaml", r.TemplateName, r.ResourceName))
 	}
+	return path
+}
 
-	fmt.Printf("Saved combined file: %s\n", path)
-	return nil
+// resultFilePath returns the output path of a result in separate-file mode:
+// its TargetPath if set, else its directory joined with the filename from its
+// override pattern or the configured filename pattern
+func resultFilePath(r RenderResult, config OutputConfig) (string, error) {
+	if r.TargetPath != "" {
+		return r.TargetPath, nil
+	}
+	pattern := config.FilenamePattern
+	if r.Output != nil && r.Output.Pattern != "" {
+		pattern = r.Output.Pattern
+	}
+	info := resultFileInfo(r)
+	filename, err := GenerateFilename(pattern, info)
+	if err != nil {
+		return "", fmt.Errorf("%s/%s: %w", r.TemplateName, r.ResourceName, err)
+	}
+	dir, err := resultDir(r, config)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filename), nil
+}
+
+// resultDir returns the directory a result is written to: its override dir,
+// relative to the output directory unless absolute, or the output directory.
+// Template actions in it are expanded with the result's metadata.
+func resultDir(r RenderResult, config OutputConfig) (string, error) {
+	dir := config.Directory
+	if r.Output != nil && r.Output.Dir != "" {
+		dir = r.Output.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(config.Directory, dir)
+		}
+	}
+	if !isDirPattern(dir) {
+		return dir, nil
+	}
+	expanded, err := executePathPattern("output directory", dir, resultFileInfo(r))
+	if err != nil {
+		return "", fmt.Errorf("%s/%s: %w", r.TemplateName, r.ResourceName, err)
+	}
+	return expanded, nil
 }
 
-// writeSeparateFiles writes each result to its own file.
+// writeSeparateFiles writes each result that isn't combined to its own file.
 // In append mode, content is appended with a --- separator if the file already exists.
 func writeSeparateFiles(results []RenderResult, config OutputConfig) error {
 	for i, r := range results {
-		if r.Error != nil {
-			continue // Skip failed renders
+		if r.Error != nil || !splitsOutput(r, config) {
+			continue // Skip failed and combined renders
 		}
 
-		filename, err := GenerateFilename(config.FilenamePattern, FileInfo{
-			TemplateName: r.TemplateName,
-			ResourceName: r.ResourceName,
-		})
+		path, err := resultFilePath(r, config)
 		if err != nil {
 			return err
 		}
-
-		path := filepath.Join(config.Directory, filename)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("creating directory for %s: %w", path, err)
+		}
 
 		if config.FileMode == "append" {
 			if _, err := os.Stat(path); err == nil {
@@ -138,16 +494,13 @@ func writeSeparateFiles(results []RenderResult, config OutputConfig) error {
 // printDryRun displays what would be written without actually writing files
 func printDryRun(results []RenderResult, config OutputConfig) error {
 	fmt.Println("\n=== DRY RUN - No files written ===")
+	fmt.Println(summarizeDryRun(results, config))
 
-	if config.SingleFile {
-		filename := "combined-claims.yaml"
-		if len(results) > 0 && results[0].TemplateName != "" {
-			filename = fmt.Sprintf("%s-combined.yaml", results[0].TemplateName)
-		}
-		path := filepath.Join(config.Directory, filename)
+	if combined := combinedResults(results, config); len(combined) > 0 {
+		path := combinedFilePath(combined, config)
 		fmt.Printf("Would write combined file: %s\n\n", path)
 
-		for i, r := range results {
+		for i, r := range combined {
 			if r.Error != nil {
 				fmt.Printf("# Skipping failed render: %s/%s\n", r.TemplateName, r.ResourceName)
 				continue
@@ -157,36 +510,109 @@ func printDryRun(results []RenderResult, config OutputConfig) error {
 			}
 			fmt.Println(yamlStyle.Render(strings.TrimSpace(r.Content)))
 		}
-	} else {
-		for _, r := range results {
-			if r.Error != nil {
-				fmt.Printf("# Skipping failed render: %s/%s - %v\n", r.TemplateName, r.ResourceName, r.Error)
-				continue
-			}
+	}
 
-			filename, err := GenerateFilename(config.FilenamePattern, FileInfo{
-				TemplateName: r.TemplateName,
-				ResourceName: r.ResourceName,
-			})
-			if err != nil {
-				filename = fmt.Sprintf("%s-%s.yaml", r.TemplateName, r.ResourceName)
-			}
-			path := filepath.Join(config.Directory, filename)
+	for _, r := range results {
+		if !splitsOutput(r, config) {
+			continue
+		}
+		if r.Error != nil {
+			fmt.Printf("# Skipping failed render: %s/%s - %v\n", r.TemplateName, r.ResourceName, r.Error)
+			continue
+		}
 
-			action := "write"
-			if config.FileMode == "append" {
-				if _, err := os.Stat(path); err == nil {
-					action = "append to"
-				}
+		path := resultPath(r, config)
+
+		action := "write"
+		if config.FileMode == "append" {
+			if _, err := os.Stat(path); err == nil {
+				action = "append to"
 			}
-			fmt.Printf("Would %s: %s\n", action, path)
-			fmt.Println(yamlStyle.Render(strings.TrimSpace(r.Content)))
-			fmt.Println()
 		}
+		fmt.Printf("Would %s: %s\n", action, path)
+		fmt.Println(yamlStyle.Render(strings.TrimSpace(r.Content)))
+		fmt.Println()
 	}
 	return nil
 }
 
+// dryRunSummary totals what a dry run would write, over successful results only
+type dryRunSummary struct {
+	Files       int   `json:"files"`
+	Bytes       int64 `json:"bytes"`
+	Directories int   `json:"directories"`
+}
+
+// summarizeDryRun counts the files, bytes and distinct directories the
+// results would be written to with this output configuration
+func summarizeDryRun(results []RenderResult, config OutputConfig) dryRunSummary {
+	var s dryRunSummary
+	dirs := make(map[string]bool)
+	if combined := combinedResults(results, config); hasSuccessfulResult(combined) {
+		s.Files++
+		s.Bytes += int64(len(combineResults(combined)))
+		dirs[filepath.Dir(combinedFilePath(combined, config))] = true
+	}
+
+	for _, r := range results {
+		if r.Error != nil || !splitsOutput(r, config) {
+			continue
+		}
+		s.Files++
+		s.Bytes += int64(len(r.Content))
+		dirs[filepath.Dir(resultPath(r, config))] = true
+	}
+	s.Directories = len(dirs)
+	return s
+}
+
+// hasSuccessfulResult reports whether any result rendered without error
+func hasSuccessfulResult(results []RenderResult) bool {
+	for _, r := range results {
+		if r.Error == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the summary as "Would write 3 files totaling 1.2KB across 2 directories"
+func (s dryRunSummary) String() string {
+	return fmt.Sprintf("Would write %s totaling %s across %s",
+		plural(s.Files, "file"), formatBytes(s.Bytes), plural(s.Directories, "directory"))
+}
+
+// plural formats a count with its noun, e.g. "1 file" or "2 directories"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// formatBytes formats a size with a decimal unit, e.g. 512B, 1.2KB, 3.4MB
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// normalizeLineEndings converts CRLF line endings to LF. Lone CRs are kept,
+// since they may be part of scalar content.
+func normalizeLineEndings(content string) string {
+	return strings.ReplaceAll(content, "\r\n", "\n")
+}
+
 // appendToFile appends content to an existing file with a YAML document separator
 func appendToFile(path string, content string) error {
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
@@ -208,6 +634,7 @@ func WriteSingleResult(templateName, resourceName, content string, config Output
 		TemplateName: templateName,
 		ResourceName: resourceName,
 		Content:      content,
+		Documents:    parseDocuments(content),
 	}
 	return WriteResults([]RenderResult{result}, config)
 }
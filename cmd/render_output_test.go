@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/stuttgart-things/claims/internal/params"
 )
 
 func TestGenerateFilename(t *testing.T) {
@@ -60,6 +64,26 @@ func TestGenerateFilename(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:    "label and namespace of the rendered resource",
+			pattern: "{{.labels.cluster}}-{{.namespace}}-{{.name}}.yaml",
+			info: FileInfo{
+				TemplateName: "vsphere-vm",
+				ResourceName: "my-vm",
+				Namespace:    "infra",
+				Labels:       map[string]string{"cluster": "prod"},
+			},
+			expected: "prod-infra-my-vm.yaml",
+		},
+		{
+			name:    "missing label",
+			pattern: "{{.labels.cluster}}.yaml",
+			info: FileInfo{
+				TemplateName: "vsphere-vm",
+				ResourceName: "my-vm",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -139,6 +163,60 @@ func TestWriteResults_SeparateFiles(t *testing.T) {
 	}
 }
 
+func TestWriteResults_MetadataDirectory(t *testing.T) {
+	dir := t.TempDir()
+	vm := func(name, labels string) RenderResult {
+		return RenderResult{
+			TemplateName: "vsphere-vm",
+			ResourceName: name,
+			Content:      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\n  namespace: infra\n" + labels,
+		}
+	}
+
+	results := []RenderResult{
+		vm("web", "  labels:\n    cluster: prod\n"),
+		vm("db", "  labels:\n    cluster: dev\n"),
+	}
+	config := OutputConfig{
+		Directory:       filepath.Join(dir, "clusters", "{{.labels.cluster}}"),
+		FilenamePattern: "{{.namespace}}-{{.name}}.yaml",
+	}
+	if err := WriteResults(results, config); err != nil {
+		t.Fatalf("WriteResults: %v", err)
+	}
+	for _, want := range []string{
+		filepath.Join(dir, "clusters", "prod", "infra-web.yaml"),
+		filepath.Join(dir, "clusters", "dev", "infra-db.yaml"),
+	} {
+		if _, err := os.Stat(want); err != nil {
+			t.Errorf("expected %s to be written: %v", want, err)
+		}
+	}
+	if results[0].OutputPath != filepath.Join(dir, "clusters", "prod", "infra-web.yaml") {
+		t.Errorf("unexpected output path %q", results[0].OutputPath)
+	}
+
+	// A resource without the label fails with the entry and missing key named
+	err := WriteResults([]RenderResult{vm("cache", "")}, config)
+	if err == nil || !strings.Contains(err.Error(), "vsphere-vm/cache") || !strings.Contains(err.Error(), `"cluster"`) {
+		t.Errorf("expected a missing label error, got %v", err)
+	}
+}
+
+func TestStaticDir(t *testing.T) {
+	tests := map[string]string{
+		"claims/infra":                     "claims/infra",
+		"clusters/{{.labels.cluster}}":     "clusters",
+		"clusters/env-{{.labels.env}}/vms": "clusters",
+		"{{.namespace}}":                   ".",
+	}
+	for dir, want := range tests {
+		if got := staticDir(dir); got != want {
+			t.Errorf("staticDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
 func TestWriteResults_SingleFile(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "claims-test-*")
 	if err != nil {
@@ -226,6 +304,81 @@ func TestWriteResults_DryRun(t *testing.T) {
 	}
 }
 
+func TestSummarizeDryRun(t *testing.T) {
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\nname: web\n"},
+		{TemplateName: "vsphere-vm", ResourceName: "db", Content: "kind: VM\nname: db\n"},
+		{TemplateName: "postgresql", ResourceName: "db", Content: "kind: Postgres\n"},
+		{TemplateName: "broken", ResourceName: "x", Content: "ignored", Error: os.ErrInvalid},
+	}
+
+	var wantBytes int64
+	for _, r := range results {
+		if r.Error == nil {
+			wantBytes += int64(len(r.Content))
+		}
+	}
+
+	tests := []struct {
+		name      string
+		config    OutputConfig
+		wantFiles int
+		wantBytes int64
+		wantDirs  int
+	}{
+		{
+			name:      "separate files in one directory",
+			config:    OutputConfig{Directory: "out", FilenamePattern: "{{.template}}-{{.name}}.yaml"},
+			wantFiles: 3,
+			wantBytes: wantBytes,
+			wantDirs:  1,
+		},
+		{
+			name:      "pattern with per-template directories",
+			config:    OutputConfig{Directory: "out", FilenamePattern: "{{.template}}/{{.name}}.yaml"},
+			wantFiles: 3,
+			wantBytes: wantBytes,
+			wantDirs:  2,
+		},
+		{
+			name:      "single file",
+			config:    OutputConfig{Directory: "out", SingleFile: true},
+			wantFiles: 1,
+			wantBytes: int64(len(combineResults(results))),
+			wantDirs:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := summarizeDryRun(results, tt.config)
+			if got.Files != tt.wantFiles || got.Bytes != tt.wantBytes || got.Directories != tt.wantDirs {
+				t.Errorf("summarizeDryRun() = %+v, want {Files:%d Bytes:%d Directories:%d}", got, tt.wantFiles, tt.wantBytes, tt.wantDirs)
+			}
+		})
+	}
+
+	if got := summarizeDryRun(results[3:], OutputConfig{SingleFile: true}); got != (dryRunSummary{}) {
+		t.Errorf("summary with only failed results = %+v, want zero", got)
+	}
+}
+
+func TestDryRunSummaryString(t *testing.T) {
+	tests := []struct {
+		summary dryRunSummary
+		want    string
+	}{
+		{dryRunSummary{Files: 1, Bytes: 512, Directories: 1}, "Would write 1 file totaling 512B across 1 directory"},
+		{dryRunSummary{Files: 42, Bytes: 1_200_000, Directories: 3}, "Would write 42 files totaling 1.2MB across 3 directories"},
+		{dryRunSummary{Files: 2, Bytes: 1500, Directories: 2}, "Would write 2 files totaling 1.5KB across 2 directories"},
+	}
+	for _, tt := range tests {
+		if got := tt.summary.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func TestWriteResults_CreatesDirectory(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "claims-test-*")
 	if err != nil {
@@ -376,7 +529,352 @@ func TestAppendToFile(t *testing.T) {
 	}
 }
 
+func TestWriteResults_NormalizesCRLF(t *testing.T) {
+	crlf := "apiVersion: v1\r\nkind: ConfigMap\r\nmetadata:\r\n  name: test\r\n"
+	want := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n"
+
+	tests := []struct {
+		name     string
+		config   OutputConfig
+		filename string
+		check    func(t *testing.T, got string)
+	}{
+		{
+			name:     "separate files",
+			config:   OutputConfig{FilenamePattern: "{{.template}}-{{.name}}.yaml"},
+			filename: "tmpl-test.yaml",
+			check: func(t *testing.T, got string) {
+				if got != want {
+					t.Errorf("expected %q, got %q", want, got)
+				}
+			},
+		},
+		{
+			name:     "single file",
+			config:   OutputConfig{SingleFile: true},
+			filename: "tmpl-combined.yaml",
+		},
+		{
+			name:     "append mode",
+			config:   OutputConfig{FilenamePattern: "out.yaml", FileMode: "append"},
+			filename: "out.yaml",
+		},
+		{
+			name:     "keep CRLF",
+			config:   OutputConfig{FilenamePattern: "{{.template}}-{{.name}}.yaml", KeepCRLF: true},
+			filename: "tmpl-test.yaml",
+			check: func(t *testing.T, got string) {
+				if got != crlf {
+					t.Errorf("expected CRLF content to be kept, got %q", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			tt.config.Directory = tmpDir
+
+			path := filepath.Join(tmpDir, tt.filename)
+			if tt.config.FileMode == "append" {
+				if err := os.WriteFile(path, []byte("kind: Existing\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			results := []RenderResult{
+				{TemplateName: "tmpl", ResourceName: "test", Content: crlf},
+			}
+			if err := WriteResults(results, tt.config); err != nil {
+				t.Fatalf("WriteResults failed: %v", err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", path, err)
+			}
+
+			if tt.check != nil {
+				tt.check(t, string(content))
+				return
+			}
+			if strings.Contains(string(content), "\r") {
+				t.Errorf("expected LF-only output, got %q", string(content))
+			}
+		})
+	}
+}
+
 // testError is a simple error type for testing
 type testError struct{}
 
 func (e *testError) Error() string { return "test error" }
+
+func TestNormalizeLineEndings(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"crlf", "a: 1\r\nb: 2\r\n", "a: 1\nb: 2\n"},
+		{"lf unchanged", "a: 1\nb: 2\n", "a: 1\nb: 2\n"},
+		{"lone cr kept", "a: \"x\ry\"\r\n", "a: \"x\ry\"\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeLineEndings(tt.in); got != tt.want {
+				t.Errorf("normalizeLineEndings(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFilenameCollisions(t *testing.T) {
+	files := []FileInfo{
+		{TemplateName: "vsphere-vm", ResourceName: "web"},
+		{TemplateName: "postgres", ResourceName: "db"},
+		{TemplateName: "vsphere-vm", ResourceName: "web"},
+	}
+
+	collisions, err := findFilenameCollisions("{{.template}}-{{.name}}.yaml", files)
+	if err != nil {
+		t.Fatalf("findFilenameCollisions() error = %v", err)
+	}
+	if len(collisions) != 1 {
+		t.Fatalf("expected 1 collision, got %d: %+v", len(collisions), collisions)
+	}
+	if collisions[0].Filename != "vsphere-vm-web.yaml" || len(collisions[0].Entries) != 2 {
+		t.Errorf("unexpected collision: %+v", collisions[0])
+	}
+
+	// A pattern without the template name makes different templates collide
+	collisions, err = findFilenameCollisions("{{.name}}.yaml", []FileInfo{
+		{TemplateName: "vsphere-vm", ResourceName: "app"},
+		{TemplateName: "postgres", ResourceName: "app"},
+	})
+	if err != nil {
+		t.Fatalf("findFilenameCollisions() error = %v", err)
+	}
+	if len(collisions) != 1 || collisions[0].Filename != "app.yaml" {
+		t.Errorf("expected app.yaml collision, got %+v", collisions)
+	}
+}
+
+func TestWriteResults_OutputOverrides(t *testing.T) {
+	split, combine := true, false
+
+	tests := []struct {
+		name       string
+		singleFile bool
+		results    []RenderResult
+		wantFiles  map[string][]string // relative path -> contained kinds
+	}{
+		{
+			name:       "split entry alongside the combined file",
+			singleFile: true,
+			results: []RenderResult{
+				{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM"},
+				{TemplateName: "postgresql", ResourceName: "db", Content: "kind: Postgres", Output: &params.OutputOverride{Split: &split}},
+				{TemplateName: "redis", ResourceName: "cache", Content: "kind: Redis"},
+			},
+			wantFiles: map[string][]string{
+				"vsphere-vm-combined.yaml": {"VM", "Redis"},
+				"postgresql-db.yaml":       {"Postgres"},
+			},
+		},
+		{
+			name: "combined entries alongside separate files",
+			results: []RenderResult{
+				{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM"},
+				{TemplateName: "postgresql", ResourceName: "db", Content: "kind: Postgres", Output: &params.OutputOverride{Split: &combine}},
+				{TemplateName: "redis", ResourceName: "cache", Content: "kind: Redis", Output: &params.OutputOverride{Split: &combine}},
+			},
+			wantFiles: map[string][]string{
+				"vsphere-vm-web.yaml":      {"VM"},
+				"postgresql-combined.yaml": {"Postgres", "Redis"},
+			},
+		},
+		{
+			name:       "dir and pattern imply a separate file",
+			singleFile: true,
+			results: []RenderResult{
+				{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM", Output: &params.OutputOverride{Dir: "vms", Pattern: "{{.name}}.yaml"}},
+				{TemplateName: "postgresql", ResourceName: "db", Content: "kind: Postgres", Output: &params.OutputOverride{Dir: "databases"}},
+			},
+			wantFiles: map[string][]string{
+				filepath.Join("vms", "web.yaml"):                 {"VM"},
+				filepath.Join("databases", "postgresql-db.yaml"): {"Postgres"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			config := OutputConfig{Directory: dir, FilenamePattern: "{{.template}}-{{.name}}.yaml", SingleFile: tt.singleFile}
+
+			summary := summarizeDryRun(tt.results, config)
+			if summary.Files != len(tt.wantFiles) {
+				t.Errorf("dry-run summary counts %d files, want %d", summary.Files, len(tt.wantFiles))
+			}
+
+			if err := WriteResults(tt.results, config); err != nil {
+				t.Fatalf("WriteResults() error: %v", err)
+			}
+
+			var written []string
+			filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					rel, _ := filepath.Rel(dir, path)
+					written = append(written, rel)
+				}
+				return nil
+			})
+			if len(written) != len(tt.wantFiles) {
+				t.Errorf("wrote %v, want %d files", written, len(tt.wantFiles))
+			}
+			for file, kinds := range tt.wantFiles {
+				content, err := os.ReadFile(filepath.Join(dir, file))
+				if err != nil {
+					t.Errorf("expected %s: %v", file, err)
+					continue
+				}
+				for _, kind := range kinds {
+					if !strings.Contains(string(content), "kind: "+kind) {
+						t.Errorf("%s missing %s:\n%s", file, kind, content)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFindResultPathCollisions(t *testing.T) {
+	combine := false
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "app"},
+		{TemplateName: "postgresql", ResourceName: "app", Output: &params.OutputOverride{Pattern: "{{.template}}-{{.name}}.yaml"}},
+		{TemplateName: "redis", ResourceName: "app", Output: &params.OutputOverride{Dir: "cache"}},
+		{TemplateName: "vsphere-vm", ResourceName: "app", Output: &params.OutputOverride{Split: &combine}},
+	}
+	config := OutputConfig{Directory: "out", FilenamePattern: "vsphere-vm-{{.name}}.yaml"}
+
+	collisions, err := findResultPathCollisions(results, config)
+	if err != nil {
+		t.Fatalf("findResultPathCollisions() error = %v", err)
+	}
+	// The combined entry isn't written on its own and the others differ by
+	// pattern or directory
+	if len(collisions) != 0 {
+		t.Errorf("unexpected collisions: %+v", collisions)
+	}
+
+	results[1].Output.Pattern = "vsphere-vm-{{.name}}.yaml"
+	collisions, err = findResultPathCollisions(results, config)
+	if err != nil {
+		t.Fatalf("findResultPathCollisions() error = %v", err)
+	}
+	want := filepath.Join("out", "vsphere-vm-app.yaml")
+	if len(collisions) != 1 || collisions[0].Filename != want || len(collisions[0].Entries) != 2 {
+		t.Errorf("expected %s collision between two entries, got %+v", want, collisions)
+	}
+}
+
+func TestWriteResults_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	stdout, stdoutStarted = &buf, false
+	t.Cleanup(func() { stdout, stdoutStarted = os.Stdout, false })
+
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\nname: web\n"},
+		{TemplateName: "broken", ResourceName: "x", Content: "ignored", Error: os.ErrInvalid},
+		{TemplateName: "postgresql", ResourceName: "db", Content: "\nkind: Postgres\n"},
+	}
+	if err := WriteResults(results, OutputConfig{Directory: StdoutDir, FilenamePattern: "{{.template}}-{{.name}}.yaml", Stdout: true}); err != nil {
+		t.Fatalf("WriteResults() error: %v", err)
+	}
+
+	want := "kind: VM\nname: web\n---\nkind: Postgres\n"
+	if got := buf.String(); got != want {
+		t.Errorf("stdout = %q, want %q", got, want)
+	}
+	for _, r := range results {
+		if r.OutputPath != "" {
+			t.Errorf("%s: OutputPath = %q, want none", r.ResourceName, r.OutputPath)
+		}
+	}
+	if _, err := os.Stat(StdoutDir); err == nil {
+		t.Errorf("a %q directory was created", StdoutDir)
+	}
+}
+
+func TestWriteResults_StdoutField(t *testing.T) {
+	var buf bytes.Buffer
+	stdout, stdoutStarted = &buf, false
+	t.Cleanup(func() { stdout, stdoutStarted = os.Stdout, false })
+
+	dir := t.TempDir()
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\nname: web\n"},
+		{TemplateName: "vsphere-vm", ResourceName: "db", Content: "kind: VM\nname: db\n"},
+	}
+	config := OutputConfig{Directory: dir, FilenamePattern: "{{.template}}-{{.name}}.yaml", Stdout: true}
+	if err := WriteResults(results, config); err != nil {
+		t.Fatalf("WriteResults() error: %v", err)
+	}
+	if want := "kind: VM\nname: web\n---\nkind: VM\nname: db\n"; buf.String() != want {
+		t.Errorf("stdout = %q, want %q", buf.String(), want)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no files with Stdout, got %d", len(entries))
+	}
+
+	config.DryRun = true
+	if err := WriteResults(results, config); !errors.Is(err, errDryRunStdout) {
+		t.Errorf("expected --dry-run to be rejected with stdout, got %v", err)
+	}
+}
+
+func TestResolveStdoutFlags(t *testing.T) {
+	t.Cleanup(func() { toStdout, dryRun, outputDir, summaryJSONPath = false, false, ".", "" })
+
+	tests := []struct {
+		name    string
+		stdout  bool
+		dryRun  bool
+		dir     string
+		dirSet  bool
+		summary string
+		wantDir string
+		wantErr string
+	}{
+		{"stdout flag", true, false, ".", false, "", StdoutDir, ""},
+		{"stdout with -o -", true, false, StdoutDir, true, "", StdoutDir, ""},
+		{"stdout with output dir", true, false, "out", true, "", "", "--stdout and --output-dir out are mutually exclusive"},
+		{"dry-run with stdout", true, true, ".", false, "", "", "mutually exclusive"},
+		{"dry-run with -o -", false, true, StdoutDir, true, "", "", "--dry-run and --stdout"},
+		{"files", false, true, "out", true, "", "out", ""},
+		{"summary on stdout with -o -", false, false, StdoutDir, true, "-", "", "--summary-json - can't be combined"},
+		{"summary file with -o -", false, false, StdoutDir, true, "summary.json", StdoutDir, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toStdout, dryRun, outputDir, summaryJSONPath = tt.stdout, tt.dryRun, tt.dir, tt.summary
+			err := resolveStdoutFlags(tt.dirSet)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveStdoutFlags() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveStdoutFlags() error = %v", err)
+			}
+			if outputDir != tt.wantDir {
+				t.Errorf("outputDir = %q, want %q", outputDir, tt.wantDir)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/params"
+)
+
+// Output ordering for parallel renders
+const (
+	OutputOrderInput      = "input"      // input order: params file / selection order
+	OutputOrderCompletion = "completion" // order in which renders finished
+)
+
+// renderJob is a single template render tagged with its input position
+type renderJob struct {
+	Index        int
+	TemplateName string
+	Params       map[string]any
+	TargetPath   string // fixed output path (registry re-render); "" = filename pattern
+	Output       *params.OutputOverride
+}
+
+// validateOutputOrder checks a --render-concurrency-order value
+func validateOutputOrder(order string) error {
+	switch order {
+	case "", OutputOrderInput, OutputOrderCompletion:
+		return nil
+	default:
+		return fmt.Errorf("invalid --render-concurrency-order %q: must be %s or %s", order, OutputOrderInput, OutputOrderCompletion)
+	}
+}
+
+// renderJobsParallel runs render for every job using up to workers goroutines.
+// With the input ordering each result is stored at its job's index, so combined
+// output and listings match the input regardless of which render finishes first.
+func renderJobsParallel(jobs []renderJob, workers int, order string, render func(renderJob) RenderResult) []RenderResult {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	indexed := make([]RenderResult, len(jobs))
+	completed := make([]RenderResult, 0, len(jobs))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	queue := make(chan renderJob)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				result := render(job)
+				mu.Lock()
+				indexed[job.Index] = result
+				completed = append(completed, result)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	if order == OutputOrderCompletion {
+		return completed
+	}
+	return indexed
+}
+
+// renderContext derives the context bounding a batch render from parent. A
+// RenderTimeout of zero means no overall deadline.
+func (c *RenderConfig) renderContext(parent context.Context) (context.Context, context.CancelFunc) {
+	if c.RenderTimeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, c.RenderTimeout)
+}
+
+// renderTimedOut reports whether any render was cut short by the render deadline
+func renderTimedOut(results []RenderResult) bool {
+	for _, r := range results {
+		if errors.Is(r.Error, context.DeadlineExceeded) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderInterrupted reports whether any render was aborted by Ctrl-C
+func renderInterrupted(results []RenderResult) bool {
+	for _, r := range results {
+		if errors.Is(r.Error, context.Canceled) {
+			return true
+		}
+	}
+	return false
+}
+
+// printRenderTimeoutReport lists which templates completed before the render
+// deadline, which were cut off or never started, and which failed for other
+// reasons (e.g. API errors) before the deadline hit
+func printRenderTimeoutReport(results []RenderResult, timeout time.Duration) {
+	fmt.Print(renderTimeoutReport(results, timeout))
+}
+
+// renderTimeoutReport builds the text printed by printRenderTimeoutReport
+func renderTimeoutReport(results []RenderResult, timeout time.Duration) string {
+	var completed, timedOut, failed []string
+	for _, r := range results {
+		switch {
+		case r.Error == nil:
+			completed = append(completed, r.TemplateName)
+		case errors.Is(r.Error, context.DeadlineExceeded):
+			timedOut = append(timedOut, r.TemplateName)
+		default:
+			failed = append(failed, r.TemplateName)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Render timeout of %s exceeded\n", timeout))
+	if len(completed) > 0 {
+		sb.WriteString(fmt.Sprintf("  Completed: %s\n", strings.Join(completed, ", ")))
+	}
+	if len(timedOut) > 0 {
+		sb.WriteString(fmt.Sprintf("  Not completed (timed out): %s\n", strings.Join(timedOut, ", ")))
+	}
+	if len(failed) > 0 {
+		sb.WriteString(fmt.Sprintf("  Failed: %s\n", strings.Join(failed, ", ")))
+	}
+	return sb.String()
+}
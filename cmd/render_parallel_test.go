@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestRenderJobsParallel_InputOrder(t *testing.T) {
+	var jobs []renderJob
+	for i := 0; i < 8; i++ {
+		jobs = append(jobs, renderJob{Index: i, TemplateName: fmt.Sprintf("tmpl-%d", i)})
+	}
+
+	// Earlier jobs take longer, so completion order is roughly reversed
+	render := func(job renderJob) RenderResult {
+		time.Sleep(time.Duration(len(jobs)-job.Index) * 2 * time.Millisecond)
+		return RenderResult{TemplateName: job.TemplateName}
+	}
+
+	for run := 0; run < 5; run++ {
+		results := renderJobsParallel(jobs, 4, OutputOrderInput, render)
+		if len(results) != len(jobs) {
+			t.Fatalf("expected %d results, got %d", len(jobs), len(results))
+		}
+		for i, r := range results {
+			if r.TemplateName != jobs[i].TemplateName {
+				t.Fatalf("run %d: result %d is %s, want %s", run, i, r.TemplateName, jobs[i].TemplateName)
+			}
+		}
+	}
+
+	// Completion order still returns every result
+	results := renderJobsParallel(jobs, 4, OutputOrderCompletion, render)
+	if len(results) != len(jobs) {
+		t.Fatalf("expected %d results in completion order, got %d", len(jobs), len(results))
+	}
+}
+
+func TestValidateOutputOrder(t *testing.T) {
+	tests := []struct {
+		order   string
+		wantErr bool
+	}{
+		{"", false},
+		{OutputOrderInput, false},
+		{OutputOrderCompletion, false},
+		{"random", true},
+	}
+
+	for _, tt := range tests {
+		if err := validateOutputOrder(tt.order); (err != nil) != tt.wantErr {
+			t.Errorf("validateOutputOrder(%q) error = %v, wantErr %v", tt.order, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRunNonInteractive_ParallelSingleFileDeterministic(t *testing.T) {
+	names := []string{"alpha", "bravo", "charlie", "delta", "echo"}
+
+	// Templates listed first render slowest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/claim-templates" {
+			var items []templates.ClaimTemplate
+			for _, n := range names {
+				items = append(items, testTemplate(n))
+			}
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: items})
+			return
+		}
+		tmpl := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/claim-templates/"), "/order")
+		for i, n := range names {
+			if n == tmpl {
+				time.Sleep(time.Duration(len(names)-i) * 5 * time.Millisecond)
+			}
+		}
+		json.NewEncoder(w).Encode(templates.OrderResponse{
+			Rendered: fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n", tmpl),
+		})
+	}))
+	defer server.Close()
+
+	var previous string
+	for run := 0; run < 3; run++ {
+		outputDir := t.TempDir()
+		config := &RenderConfig{
+			APIUrl:          server.URL,
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+			Templates:       names,
+			InlineParamsRaw: []string{"name=combined"},
+			OutputDir:       outputDir,
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			SingleFile:      true,
+			Parallel:        len(names),
+			OutputOrder:     OutputOrderInput,
+		}
+
+		if err := runNonInteractive(context.Background(), config); err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+
+		entries, err := os.ReadDir(outputDir)
+		if err != nil || len(entries) != 1 {
+			t.Fatalf("expected a single combined file, got %v (%v)", entries, err)
+		}
+		content, err := os.ReadFile(filepath.Join(outputDir, entries[0].Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Resources appear in template order
+		last := -1
+		for _, n := range names {
+			idx := strings.Index(string(content), "name: "+n)
+			if idx <= last {
+				t.Fatalf("run %d: %s out of input order in combined output:\n%s", run, n, content)
+			}
+			last = idx
+		}
+
+		if run > 0 && string(content) != previous {
+			t.Fatalf("combined output differs between runs:\n%s\n---\n%s", previous, content)
+		}
+		previous = string(content)
+	}
+}
+
+func TestRunNonInteractive_RenderTimeout(t *testing.T) {
+	// "fast" renders immediately; "slow" blocks until the client gives up
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/claim-templates" {
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{
+				Items: []templates.ClaimTemplate{testTemplate("fast"), testTemplate("slow")},
+			})
+			return
+		}
+		if strings.Contains(r.URL.Path, "/slow/") {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		json.NewEncoder(w).Encode(templates.OrderResponse{Rendered: "apiVersion: v1\nkind: ConfigMap\n"})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	outputDir := t.TempDir()
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		Templates:       []string{"fast", "slow"},
+		InlineParamsRaw: []string{"name=my-claim"},
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		RenderTimeout:   200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	err := runNonInteractive(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), "render timeout") {
+		t.Fatalf("expected render timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("slow render was not cancelled promptly (took %v)", elapsed)
+	}
+
+	// The template that completed before the deadline is still written
+	if _, err := os.Stat(filepath.Join(outputDir, "fast-my-claim.yaml")); err != nil {
+		t.Errorf("expected completed template output: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "slow-my-claim.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected no output for the cancelled template")
+	}
+}
+
+func TestRunNonInteractive_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel (as Ctrl-C would) once the render request is in flight
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/claim-templates" {
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{
+				Items: []templates.ClaimTemplate{testTemplate("slow")},
+			})
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/order") {
+			http.NotFound(w, r)
+			return
+		}
+		// The server only notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	outputDir := t.TempDir()
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		Templates:       []string{"slow"},
+		InlineParamsRaw: []string{"name=my-claim"},
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+	}
+
+	err := runNonInteractive(ctx, config)
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("expected interrupted error, got %v", err)
+	}
+
+	entries, _ := os.ReadDir(outputDir)
+	if len(entries) != 0 {
+		t.Errorf("expected no output after interrupt, got %d files", len(entries))
+	}
+}
+
+func TestRenderTimeoutReport(t *testing.T) {
+	results := []RenderResult{
+		{TemplateName: "done"},
+		{TemplateName: "cut-off", Error: fmt.Errorf("HTTP request failed: %w", context.DeadlineExceeded)},
+		{TemplateName: "bad-request", Error: fmt.Errorf("API returned 400: invalid params")},
+	}
+
+	report := renderTimeoutReport(results, time.Minute)
+
+	for _, want := range []string{
+		"Completed: done\n",
+		"Not completed (timed out): cut-off\n",
+		"Failed: bad-request\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+}
@@ -7,15 +7,15 @@ import (
 	"github.com/stuttgart-things/claims/internal/gitops"
 )
 
-// executePRCreation creates a pull request after push
-func executePRCreation(results []RenderResult, config *RenderConfig, repoPath string) error {
+// executePRCreation creates a pull request after push and returns its URL
+func executePRCreation(results []RenderResult, config *RenderConfig, repoPath string) (string, error) {
 	if config.PRConfig == nil || !config.PRConfig.Create {
-		return nil
+		return "", nil
 	}
 
 	// Check gh authentication
 	if err := gitops.CheckGHAuth(); err != nil {
-		return err
+		return "", err
 	}
 
 	// Generate title if not provided
@@ -51,7 +51,7 @@ func executePRCreation(results []RenderResult, config *RenderConfig, repoPath st
 	prConfig := gitops.PRConfig{
 		Title:       title,
 		Description: description,
-		Labels:      config.PRConfig.Labels,
+		Labels:      resolvePRLabels(config.PRConfig.Labels, config.PRConfig.CreateMissingLabels, repoPath),
 		BaseBranch:  baseBranch,
 		HeadBranch:  headBranch,
 	}
@@ -59,11 +59,11 @@ func executePRCreation(results []RenderResult, config *RenderConfig, repoPath st
 	fmt.Println("Creating pull request...")
 	pr, err := gitops.CreatePR(prConfig, repoPath)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	fmt.Println(successStyle.Render(fmt.Sprintf("Created PR: %s", pr.URL)))
-	return nil
+	return pr.URL, nil
 }
 
 // generatePRDescription creates an auto-generated PR description
@@ -91,3 +91,52 @@ func generatePRDescription(results []RenderResult, config *RenderConfig) string
 
 	return sb.String()
 }
+
+// resolvePRLabels checks the requested labels against the repository so that
+// `gh pr create` doesn't fail on unknown ones. Missing labels are created when
+// createMissing is set and skipped with a warning otherwise. If the repository
+// labels can't be listed, the requested labels are passed through unchanged.
+func resolvePRLabels(labels []string, createMissing bool, repoPath string) []string {
+	if len(labels) == 0 {
+		return labels
+	}
+
+	existing, err := gitops.ListLabels(repoPath)
+	if err != nil {
+		warnf("could not verify PR labels: %v", err)
+		return labels
+	}
+
+	found, missing := gitops.ClassifyLabels(labels, existing)
+	for _, label := range missing {
+		if !createMissing {
+			warnf("label %q does not exist in the repository; skipping (use --create-missing-labels to create it)", label)
+			continue
+		}
+		if err := gitops.CreateLabel(label, repoPath); err != nil {
+			warnf("%v; skipping", err)
+			continue
+		}
+		fmt.Printf("Created label: %s\n", label)
+		found = append(found, label)
+	}
+
+	return found
+}
+
+// availablePRLabels lists the labels of the repository containing dir for the
+// interactive label picker. It returns nil when they can't be determined.
+func availablePRLabels(dir string) []string {
+	if !gitops.CheckGHInstalled() {
+		return nil
+	}
+	repoRoot, err := findRepoRoot(dir)
+	if err != nil {
+		return nil
+	}
+	labels, err := gitops.ListLabels(repoRoot)
+	if err != nil {
+		return nil
+	}
+	return labels
+}
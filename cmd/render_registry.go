@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stuttgart-things/claims/internal/params"
+	"github.com/stuttgart-things/claims/internal/registry"
+)
+
+// parseRegistryFilter parses --params-from-registry-filter selectors:
+// category=<name>, template=<name> and label=<key>=<value> (repeatable)
+func parseRegistryFilter(raw []string) (registry.Selector, error) {
+	var sel registry.Selector
+	for _, f := range raw {
+		key, value, ok := strings.Cut(f, "=")
+		if !ok || value == "" {
+			return sel, fmt.Errorf("invalid registry filter %q (expected category=, template=, source= or label=key=value)", f)
+		}
+		switch key {
+		case "category":
+			sel.Category = value
+		case "template":
+			sel.Template = value
+		case "source":
+			sel.Source = value
+		case "label":
+			lk, lv, ok := strings.Cut(value, "=")
+			if !ok || lk == "" {
+				return sel, fmt.Errorf("invalid label filter %q (expected label=key=value)", f)
+			}
+			if sel.Labels == nil {
+				sel.Labels = make(map[string]string)
+			}
+			sel.Labels[lk] = lv
+		default:
+			return sel, fmt.Errorf("unknown registry filter %q (expected category, template, source or label)", key)
+		}
+	}
+	return sel, nil
+}
+
+// registryRerenderEntries returns the active registry entries selected by
+// --params-from-registry-filter together with the repository root their
+// paths are relative to. Entries without stored parameters can't be
+// re-rendered and are returned by name in missing.
+func registryRerenderEntries(config *RenderConfig) (entries []registry.ClaimEntry, repoRoot string, missing []string, err error) {
+	sel, err := parseRegistryFilter(config.RegistryFilter)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	repoRoot, err = findRepoRoot(config.OutputDir)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("--params-from-registry-filter needs the output directory in a git repository: %w", err)
+	}
+	registryPath, err := registry.Discover(repoRoot)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	reg, err := registry.Load(registryPath)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, e := range registry.Select(reg, sel) {
+		if e.Status != "active" {
+			continue
+		}
+		if len(e.Parameters) == 0 {
+			missing = append(missing, e.Name)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, repoRoot, missing, nil
+}
+
+// validateRegistryRerender rejects options that don't fit re-rendering
+// registry entries in place
+func validateRegistryRerender(config *RenderConfig) error {
+	switch {
+	case len(config.ParamsFiles) > 0 || len(config.Templates) > 0:
+		return fmt.Errorf("--params-from-registry-filter cannot be combined with --params-file or --templates")
+	case config.ChangedOnly || config.OnlyNew:
+		return fmt.Errorf("--params-from-registry-filter cannot be combined with --changed-only or --only-new")
+	case len(config.MatrixRaw) > 0:
+		return fmt.Errorf("--params-from-registry-filter re-renders existing claims and cannot be combined with --matrix")
+	case config.SingleFile:
+		return fmt.Errorf("--params-from-registry-filter writes each claim back to its registry path and cannot be combined with --single-file")
+	case config.GitConfig != nil && config.GitConfig.RepoURL != "":
+		return fmt.Errorf("--params-from-registry-filter needs a local checkout and cannot be combined with --git-repo-url")
+	}
+	return nil
+}
+
+// registryTemplateParams converts registry entries to template params using
+// their stored parameters
+func registryTemplateParams(entries []registry.ClaimEntry) []params.TemplateParams {
+	templateParams := make([]params.TemplateParams, len(entries))
+	for i, e := range entries {
+		templateParams[i] = params.TemplateParams{
+			Name:       e.Template,
+			Parameters: params.MergeParams(e.Parameters, nil),
+		}
+	}
+	return templateParams
+}
+
+// registryTargetPath returns where a re-rendered entry is written: its
+// recorded path below the repository root
+func registryTargetPath(repoRoot string, e registry.ClaimEntry) string {
+	return filepath.Join(repoRoot, filepath.FromSlash(e.Path))
+}
+
+// registryCategory derives a claim's category from the first directory below
+// claims/ that holds it, or "" if the directory is outside claims/
+func registryCategory(repoRoot, dir string) string {
+	absDir, _ := filepath.Abs(dir)
+	rel, err := filepath.Rel(filepath.Join(repoRoot, "claims"), absDir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	parts := strings.SplitN(rel, string(filepath.Separator), 2)
+	if parts[0] == "." {
+		return ""
+	}
+	return parts[0]
+}
+
+// ciEnvVars are set by common CI systems; any of them marks a CI run
+var ciEnvVars = []string{"CI", "GITHUB_ACTIONS", "GITLAB_CI", "JENKINS_URL", "BUILDKITE", "CIRCLECI", "TF_BUILD"}
+
+// resolveRegistrySource returns the source recorded in registry entries of
+// rendered claims: the --source flag, else "ci" when a CI environment is
+// detected, else "cli"
+func resolveRegistrySource(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	for _, name := range ciEnvVars {
+		if v := os.Getenv(name); v != "" && v != "false" && v != "0" {
+			return "ci"
+		}
+	}
+	return "cli"
+}
+
+// registrySourceOrDefault returns source, or "cli" when it isn't set
+func registrySourceOrDefault(source string) string {
+	if source == "" {
+		return "cli"
+	}
+	return source
+}
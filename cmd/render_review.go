@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/term"
 )
 
 // ReviewAction represents the user's choice in the review step
@@ -35,9 +37,53 @@ var (
 			Padding(0, 1)
 )
 
-// ReviewResults displays rendered results and allows user to continue, edit, or cancel
+const (
+	// defaultPreviewLines is used when the terminal height can't be determined
+	defaultPreviewLines = 15
+	// minPreviewLines keeps previews useful on very short terminals
+	minPreviewLines = 3
+	// reviewChromeLines is reserved for the review header and action form
+	reviewChromeLines = 10
+	// resourceChromeLines is the per-resource overhead (header, borders, spacing)
+	resourceChromeLines = 4
+)
+
+// terminalHeight returns the height of the terminal attached to stdout.
+// It is a variable so tests can mock the terminal size.
+var terminalHeight = func() (int, bool) {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || height <= 0 {
+		return 0, false
+	}
+	return height, true
+}
+
+// previewLineCount returns how many YAML lines to show per resource in the review step.
+// An explicit override wins; otherwise the available terminal height is split across resources.
+func previewLineCount(override, resourceCount int) int {
+	if override > 0 {
+		return override
+	}
+
+	height, ok := terminalHeight()
+	if !ok {
+		return defaultPreviewLines
+	}
+
+	if resourceCount < 1 {
+		resourceCount = 1
+	}
+	lines := (height-reviewChromeLines)/resourceCount - resourceChromeLines
+	if lines < minPreviewLines {
+		return minPreviewLines
+	}
+	return lines
+}
+
+// ReviewResults displays rendered results and allows user to continue, edit, or cancel.
+// previewLines overrides the terminal-aware preview length when > 0.
 // Returns the chosen action, the index of the template to edit (if action is edit), and any error
-func ReviewResults(results []RenderResult) (ReviewAction, int, error) {
+func ReviewResults(results []RenderResult, previewLines int) (ReviewAction, int, error) {
 	fmt.Println(reviewHeaderStyle.Render("━━━ Review Rendered Resources ━━━"))
 
 	// Count successful renders
@@ -48,6 +94,8 @@ func ReviewResults(results []RenderResult) (ReviewAction, int, error) {
 		}
 	}
 
+	maxLines := previewLineCount(previewLines, successCount)
+
 	// Display each rendered resource
 	for _, r := range results {
 		if r.Error != nil {
@@ -63,7 +111,7 @@ func ReviewResults(results []RenderResult) (ReviewAction, int, error) {
 		fmt.Println(resourceHeaderStyle.Render(header))
 
 		// Truncate long YAML for preview
-		preview := truncateYAML(r.Content, 15)
+		preview := truncateYAML(r.Content, maxLines)
 		fmt.Println(previewStyle.Render(preview))
 		fmt.Println()
 	}
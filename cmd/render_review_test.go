@@ -85,6 +85,39 @@ func TestTruncateYAML_ShowsCorrectCount(t *testing.T) {
 	}
 }
 
+func TestPreviewLineCount(t *testing.T) {
+	tests := []struct {
+		name          string
+		override      int
+		height        int
+		hasTerminal   bool
+		resourceCount int
+		want          int
+	}{
+		{name: "override wins", override: 42, height: 100, hasTerminal: true, resourceCount: 1, want: 42},
+		{name: "no terminal falls back to default", hasTerminal: false, resourceCount: 1, want: defaultPreviewLines},
+		{name: "tall terminal single resource", height: 60, hasTerminal: true, resourceCount: 1, want: 46},
+		{name: "tall terminal split across resources", height: 60, hasTerminal: true, resourceCount: 2, want: 21},
+		{name: "short terminal clamps to minimum", height: 12, hasTerminal: true, resourceCount: 1, want: minPreviewLines},
+		{name: "zero resources treated as one", height: 40, hasTerminal: true, resourceCount: 0, want: 26},
+	}
+
+	orig := terminalHeight
+	defer func() { terminalHeight = orig }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminalHeight = func() (int, bool) { return tt.height, tt.hasTerminal }
+
+			got := previewLineCount(tt.override, tt.resourceCount)
+			if got != tt.want {
+				t.Errorf("previewLineCount(%d, %d) with height %d = %d, want %d",
+					tt.override, tt.resourceCount, tt.height, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestReviewAction_Constants(t *testing.T) {
 	// Verify constants are defined correctly
 	if ReviewActionContinue != "continue" {
@@ -55,13 +55,17 @@ func processTemplateSecrets(
 	}
 
 	// Check SOPS prerequisites
-	recipients, err := sops.CheckSOPSAvailable()
+	recipients, err := sops.CheckSOPSAvailable(sops.Recipients{})
 	if err != nil {
 		return nil, fmt.Errorf("SOPS prerequisites: %w", err)
 	}
 
 	var results []SecretRenderResult
 
+	// Secrets ready for encryption: their index in results and plaintext YAML
+	var pending []int
+	var plaintexts [][]byte
+
 	for _, secretDef := range tmpl.Spec.Secrets {
 		// Resolve name and namespace from template expressions
 		secretName, err := resolveTemplateName(secretDef.Name, renderParams)
@@ -116,48 +120,59 @@ func processTemplateSecrets(
 			continue
 		}
 
-		// Encrypt
-		fmt.Println("Encrypting with SOPS...")
-		encrypted, err := sops.Encrypt(secretYAML, recipients)
-		if err != nil {
-			results = append(results, SecretRenderResult{
-				SecretName:      secretName,
-				SecretNamespace: secretNamespace,
-				Error:           fmt.Errorf("encrypting: %w", err),
-			})
-			continue
-		}
-
-		result := SecretRenderResult{
+		pending = append(pending, len(results))
+		plaintexts = append(plaintexts, secretYAML)
+		results = append(results, SecretRenderResult{
 			SecretName:      secretName,
 			SecretNamespace: secretNamespace,
-			Content:         string(encrypted),
+		})
+	}
+
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	// The sops invocations are independent, so they run concurrently; the
+	// results keep the order of the template's secrets
+	fmt.Printf("Encrypting %s with SOPS...\n", plural(len(pending), "secret"))
+	encrypted, errs := sops.EncryptAll(plaintexts, recipients, sops.EncryptOptions{}, config.EncryptParallel)
+
+	for n, i := range pending {
+		result := &results[i]
+		if errs[n] != nil {
+			result.Error = fmt.Errorf("encrypting: %w", errs[n])
+			continue
 		}
+		result.Content = string(encrypted[n])
+		secretName := result.SecretName
 
-		// Write file (unless dry-run)
-		if !config.DryRun {
+		// Write file (unless dry-run); -o - streams it after the rendered claims
+		if config.OutputDir == StdoutDir && !config.DryRun {
+			if err := writeStdoutDocument(result.Content); err != nil {
+				result.Error = fmt.Errorf("writing encrypted secret to stdout: %w", err)
+			}
+		} else if !config.DryRun {
 			filename := fmt.Sprintf("%s-secret.enc.yaml", secretName)
-			if err := os.MkdirAll(config.OutputDir, 0755); err != nil {
+			// A templated output directory is expanded from the rendered
+			// claim's metadata; secrets go to its static part
+			outputDir := staticDir(config.OutputDir)
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
 				result.Error = fmt.Errorf("creating output directory: %w", err)
-				results = append(results, result)
 				continue
 			}
 
-			outputPath := filepath.Join(config.OutputDir, filename)
-			if err := os.WriteFile(outputPath, encrypted, 0644); err != nil {
+			outputPath := filepath.Join(outputDir, filename)
+			if err := os.WriteFile(outputPath, encrypted[n], 0644); err != nil {
 				result.Error = fmt.Errorf("writing encrypted file: %w", err)
-				results = append(results, result)
 				continue
 			}
 
 			result.OutputPath = outputPath
 			fmt.Printf("Saved encrypted secret: %s\n", outputPath)
 		} else {
-			fmt.Printf("\nWould write encrypted secret: %s/%s-secret.enc.yaml\n", config.OutputDir, secretName)
+			fmt.Printf("\nWould write encrypted secret: %s/%s-secret.enc.yaml\n", staticDir(config.OutputDir), secretName)
 			fmt.Println("[SOPS encrypted content omitted in dry-run]")
 		}
-
-		results = append(results, result)
 	}
 
 	return results, nil
@@ -226,12 +241,12 @@ func mergeSecretValues(fileSecrets map[string]string, inlineSecretsRaw []string)
 	}
 
 	// Overlay inline secrets
-	inlineSecrets, err := params.ParseInlineParams(inlineSecretsRaw)
+	inlineSecrets, err := params.ParseInlineSecrets(inlineSecretsRaw)
 	if err != nil {
 		return nil, fmt.Errorf("parsing inline secrets: %w", err)
 	}
 	for k, v := range inlineSecrets {
-		result[k] = fmt.Sprintf("%v", v)
+		result[k] = v
 	}
 
 	return result, nil
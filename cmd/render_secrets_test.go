@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/stuttgart-things/claims/internal/sops"
 	"github.com/stuttgart-things/claims/internal/templates"
 )
 
@@ -92,6 +96,18 @@ func TestMergeSecretValues(t *testing.T) {
 			inlineRaw:   []string{"TOKEN=new"},
 			want:        map[string]string{"TOKEN": "new"},
 		},
+		{
+			name:        "null is a literal secret value",
+			fileSecrets: map[string]string{"TOKEN": "old"},
+			inlineRaw:   []string{"TOKEN=null"},
+			want:        map[string]string{"TOKEN": "null"},
+		},
+		{
+			name:        "key- does not unset secrets",
+			fileSecrets: nil,
+			inlineRaw:   []string{"TOKEN-"},
+			wantErr:     true,
+		},
 		{
 			name:        "invalid inline format",
 			fileSecrets: nil,
@@ -204,3 +220,49 @@ func TestProcessTemplateSecrets_MissingRequiredParam(t *testing.T) {
 		t.Errorf("expected error for missing required secret param")
 	}
 }
+
+func TestProcessTemplateSecrets_Concurrent(t *testing.T) {
+	if !sops.CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+	if os.Getenv("SOPS_AGE_RECIPIENTS") == "" {
+		t.Skip("SOPS_AGE_RECIPIENTS not set, skipping integration test")
+	}
+
+	var secrets []templates.SecretTemplate
+	for i := 0; i < 6; i++ {
+		secrets = append(secrets, templates.SecretTemplate{
+			Name:       fmt.Sprintf("{{.name}}-%d", i),
+			Namespace:  "default",
+			Parameters: []templates.Parameter{{Name: "TOKEN", Required: true}},
+		})
+	}
+	tmpl := &templates.ClaimTemplate{Spec: templates.ClaimTemplateSpec{Secrets: secrets}}
+
+	outputDir := t.TempDir()
+	config := &RenderConfig{OutputDir: outputDir, EncryptParallel: 3}
+
+	results, err := processTemplateSecrets(tmpl, map[string]any{"name": "app"}, map[string]string{"TOKEN": "s3cret"}, config)
+	if err != nil {
+		t.Fatalf("processTemplateSecrets: %v", err)
+	}
+	if len(results) != len(secrets) {
+		t.Fatalf("expected %d results, got %d", len(secrets), len(results))
+	}
+	for i, r := range results {
+		name := fmt.Sprintf("app-%d", i)
+		if r.Error != nil {
+			t.Fatalf("secret %s: %v", name, r.Error)
+		}
+		// Results keep the order of the template's secrets
+		if r.SecretName != name {
+			t.Errorf("result %d is %s, want %s", i, r.SecretName, name)
+		}
+		if want := filepath.Join(outputDir, name+"-secret.enc.yaml"); r.OutputPath != want {
+			t.Errorf("output path = %s, want %s", r.OutputPath, want)
+		}
+		if _, err := os.Stat(r.OutputPath); err != nil {
+			t.Errorf("expected %s to be written: %v", r.OutputPath, err)
+		}
+	}
+}
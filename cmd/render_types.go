@@ -1,18 +1,62 @@
 package cmd
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/params"
+)
+
 // RenderConfig holds configuration for the render command
 type RenderConfig struct {
 	// API configuration
-	APIUrl  string
-	APIUrls []string // multiple endpoints parsed from CLAIM_API_URL
+	APIUrl        string
+	APIUrls       []string // multiple endpoints parsed from CLAIM_API_URL
+	APIUrlSource  string   // where the API URL came from (flag, env or default), for --explain
+	APIToken      string   // bearer token for the API; "" = unauthenticated
+	CACert        string   // PEM CA bundle trusted for an HTTPS API, in addition to the system roots
+	SkipTLSVerify bool     // don't verify the API's TLS certificate
+
+	// Catalog cache: Offline drives forms and validation from the cached
+	// catalog; rendering itself still calls the API.
+	Offline      bool
+	RefreshCache bool
+	NoCache      bool // always fetch the catalog from the API instead of reusing a fresh cache
+	CacheTTL     time.Duration
+	CatalogPath  string // empty = templates.DefaultCatalogPath()
+	AliasesPath  string // template aliases file; empty = $CLAIMS_ALIASES_FILE or templates.DefaultAliasesPath()
 
 	// Template selection
-	Templates []string
+	Templates       []string
+	Tags            []string // narrow the interactive selection to templates carrying all of these tags
+	TemplateVersion string   // tag to render at instead of the catalog default (non-interactive)
 
 	// Parameter input
-	ParamsFile      string
-	InlineParams    map[string]string
-	InlineParamsRaw []string
+	ParamsFiles      []string // merged in order, later files overriding earlier ones
+	ExpandEnv        bool     // expand environment variables in params files before parsing
+	StrictEnv        bool     // fail on unset environment variables without a default (implies ExpandEnv)
+	InlineParams     map[string]string
+	InlineParamsRaw  []string
+	RawStrings       bool     // keep --param values as strings instead of converting numbers and booleans
+	SetParamsRaw     []string // Helm-style dotted-path assignments (--set)
+	ParamFileYAMLRaw []string // path=file assignments whose file is parsed as YAML (--param-file-yaml)
+	ParamFileJSONRaw []string // path=file assignments whose file is parsed as JSON (--param-file-json)
+	MatrixRaw        []string // key=v1,v2 axes expanded into one entry per combination (--matrix)
+	NoDefaults       bool     // send only the given params, without filling in template defaults (non-interactive)
+	ChangedOnly      bool     // only render params file entries changed since BaseRef
+	BaseRef          string   // git revision --changed-only compares against
+	RegistryFilter   []string // re-render registry entries matching these selectors with their stored params
+
+	// Resource naming: prefix/suffix wrap the derived resource name used for
+	// filenames and registry entries. The rendered content is only affected
+	// when AffixNameParam is set, which rewrites the "name" parameter too.
+	ResourcePrefix string
+	ResourceSuffix string
+	AffixNameParam bool
+	// ResourceName replaces the name derived from the "name" parameter of a
+	// single-template render; SetNameParam also sends it as that parameter
+	ResourceName string
+	SetNameParam bool
 
 	// Secret input
 	InlineSecretsRaw []string
@@ -25,9 +69,36 @@ type RenderConfig struct {
 	SingleFile      bool
 	DryRun          bool
 	FileMode        string // "overwrite" (default) or "append"
+	KeepCRLF        bool   // keep CRLF line endings in rendered content
+	AllowCollisions bool   // warn instead of failing when entries share an output filename
+	MergeDuplicates bool   // merge entries rendering the same claim instead of failing
+	OnlyNew         bool   // skip entries whose resource name is already an active registry entry
+	Strict          bool   // fail instead of warning when output files are ignored by .gitignore
+	DiffOnly        bool   // print a diff against the files on disk instead of writing
+	Diff            bool   // print a diff against the files on disk before writing
+	Kustomization   string // kustomization.yaml (or its directory) to add the written files to
+	RootKustomize   bool   // list the written files in a kustomization.yaml at the output root
+	NoSort          bool   // keep the existing order of kustomization resources instead of sorting them
+	ArgoCDLayout    bool   // write claims to apps/<category>/<name>/ with an ArgoCD Application each
+	Source          string // source recorded in registry entries ("cli", "ci", ...); "" = cli
+	Apply           bool   // kubectl apply the rendered claims to the current kube-context
+
+	// Watch mode: re-render whenever a rendered template's spec changes on the server
+	WatchTemplates bool
+	WatchInterval  time.Duration // how often the catalog is polled
 
 	// Mode control
-	Interactive bool
+	Interactive   bool
+	PreviewLines  int    // review preview length; 0 = adapt to terminal height
+	ParamOrder    string // form field order: ParamOrderDeclared (default), ParamOrderRequiredFirst or ParamOrderAlphabetical
+	Explain       bool   // print the resolved plan and exit without rendering
+	CheckRequired bool   // only check that required params are set, then exit
+
+	// Parallel rendering
+	Parallel        int           // concurrent API renders; <= 1 renders sequentially
+	EncryptParallel int           // concurrent sops encryptions of secrets; 0 = sops.DefaultEncryptWorkers
+	OutputOrder     string        // OutputOrderInput (default) or OutputOrderCompletion
+	RenderTimeout   time.Duration // overall deadline for fetching and rendering; 0 = none
 
 	// Git configuration
 	GitConfig *GitConfig
@@ -47,6 +118,11 @@ type GitConfig struct {
 	RepoURL      string
 	User         string
 	Token        string
+	SSHKey       string // private key for SSH remotes; "" = $GIT_SSH_KEY or ~/.ssh/id_ed25519
+	CheckPush    bool   // verify push access before rendering
+	SignOff      bool   // append a DCO Signed-off-by trailer to the commit message
+	GPGSign      bool   // sign the commit; implied by GPGKey
+	GPGKey       string // signing key: armored key, its file or a gpg key ID; "" = $GIT_SIGNING_KEY
 }
 
 // PRConfig holds pull request configuration
@@ -56,14 +132,45 @@ type PRConfig struct {
 	Description string
 	Labels      []string
 	BaseBranch  string
+
+	// CreateMissingLabels creates requested labels that don't exist in the
+	// repository; otherwise they are skipped with a warning
+	CreateMissingLabels bool
+}
+
+// affixResourceName wraps a resource name with the configured prefix and suffix
+func (c *RenderConfig) affixResourceName(name string) string {
+	return c.ResourcePrefix + name + c.ResourceSuffix
+}
+
+// affixNameParam returns a copy of params with the prefix/suffix applied to the
+// "name" parameter when AffixNameParam is set. Other params are left untouched.
+func (c *RenderConfig) affixNameParam(params map[string]any) map[string]any {
+	if !c.AffixNameParam || (c.ResourcePrefix == "" && c.ResourceSuffix == "") {
+		return params
+	}
+	name, ok := params["name"]
+	if !ok {
+		return params
+	}
+
+	result := make(map[string]any, len(params))
+	for k, v := range params {
+		result[k] = v
+	}
+	result["name"] = c.affixResourceName(fmt.Sprintf("%v", name))
+	return result
 }
 
 // RenderResult holds the result of rendering a single template
 type RenderResult struct {
 	TemplateName string
 	ResourceName string
+	TargetPath   string                 // write here instead of the output directory and filename pattern
+	Output       *params.OutputOverride // per-entry output settings from the params file
 	OutputPath   string
 	Content      string
+	Documents    []RenderedDoc // Content split into its documents after a successful render
 	Params       map[string]interface{}
 	Error        error
 }
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+// DefaultWatchInterval is how often --watch-templates polls the catalog
+const DefaultWatchInterval = 5 * time.Second
+
+// runWatchTemplates renders once and then polls the template catalog every
+// WatchInterval, re-rendering whenever the spec (parameters, secrets, tag...)
+// of a rendered template changes on the server. Git and PR steps are skipped.
+// It returns when ctx is done or on Ctrl-C.
+func runWatchTemplates(ctx context.Context, config *RenderConfig) error {
+	if config.WatchInterval <= 0 {
+		return fmt.Errorf("--watch-interval must be positive, got %s", config.WatchInterval)
+	}
+	if config.GitConfig != nil || config.PRConfig != nil {
+		warnf("--watch-templates does not run git operations; ignoring git and PR flags")
+		config.GitConfig = nil
+		config.PRConfig = nil
+	}
+	// Every render must see the catalog the poll just fetched
+	config.NoCache = true
+
+	ctx, stop := signalContext(ctx)
+	defer stop()
+
+	client, err := newRenderClient(config)
+	if err != nil {
+		return err
+	}
+	available, err := client.FetchTemplatesContext(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching templates: %w", err)
+	}
+	names, err := watchedTemplateNames(config, available)
+	if err != nil {
+		return err
+	}
+	hashes := specHashes(available, names)
+
+	renderForWatch(ctx, config)
+	fmt.Printf("\nWatching %s every %s (Ctrl-C to stop)\n", strings.Join(names, ", "), config.WatchInterval)
+
+	ticker := time.NewTicker(config.WatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		available, err := client.FetchTemplatesContext(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			warnf("polling templates: %v", err)
+			continue
+		}
+		current := specHashes(available, names)
+		changed := changedSpecs(hashes, current)
+		if len(changed) == 0 {
+			continue
+		}
+		hashes = current
+
+		fmt.Printf("\nTemplate changed: %s; re-rendering\n", strings.Join(changed, ", "))
+		renderForWatch(ctx, config)
+	}
+}
+
+// renderForWatch runs one non-interactive render, reporting a failure
+// instead of returning it so that watching continues
+func renderForWatch(ctx context.Context, config *RenderConfig) {
+	if err := runNonInteractive(ctx, config); err != nil && ctx.Err() == nil {
+		fmt.Println(renderError(err.Error()))
+	}
+}
+
+// watchedTemplateNames returns the catalog names of the templates the
+// configured render uses, with aliases resolved, sorted and without duplicates
+func watchedTemplateNames(config *RenderConfig, available []templates.ClaimTemplate) ([]string, error) {
+	templateParams, err := resolveTemplateParams(config)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := config.loadAliases()
+	if err != nil {
+		return nil, err
+	}
+	known := catalogNames(available)
+
+	var names []string
+	for _, tp := range templateParams {
+		name, _ := aliases.Resolve(tp.Name, known)
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return slices.Compact(names), nil
+}
+
+// specHashes maps each name to a hash of its template spec; templates missing
+// from the catalog map to ""
+func specHashes(available []templates.ClaimTemplate, names []string) map[string]string {
+	hashes := make(map[string]string, len(names))
+	for _, name := range names {
+		hashes[name] = ""
+	}
+	for _, t := range available {
+		if _, ok := hashes[t.Metadata.Name]; ok {
+			hashes[t.Metadata.Name] = specHash(t.Spec)
+		}
+	}
+	return hashes
+}
+
+// specHash returns a hex SHA-256 of the JSON encoding of spec
+func specHash(spec templates.ClaimTemplateSpec) string {
+	data, _ := json.Marshal(spec)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// changedSpecs returns the sorted names whose hash differs between previous and current
+func changedSpecs(previous, current map[string]string) []string {
+	var changed []string
+	for name, hash := range current {
+		if previous[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestRunWatchTemplates(t *testing.T) {
+	// The template moves to a new tag from the third catalog fetch on: the
+	// first is the watch baseline, the second the initial render
+	var mu sync.Mutex
+	fetches := 0
+	rendered := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v1/claim-templates" {
+			mu.Lock()
+			fetches++
+			changed := fetches >= 3
+			mu.Unlock()
+
+			tmpl := testTemplate("vsphere-vm")
+			if changed {
+				tmpl.Spec.Tag = "v2"
+			}
+			json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: []templates.ClaimTemplate{tmpl}})
+			return
+		}
+		var req templates.OrderRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		json.NewEncoder(w).Encode(templates.OrderResponse{
+			Rendered: fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %v\n", req.Parameters["name"]),
+		})
+		rendered <- struct{}{}
+	}))
+	defer server.Close()
+
+	warnings.Reset()
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		OutputDir:       t.TempDir(),
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		WatchTemplates:  true,
+		WatchInterval:   10 * time.Millisecond,
+		GitConfig:       &GitConfig{Commit: true},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runWatchTemplates(ctx, config) }()
+
+	renders := 0
+	for renders < 2 {
+		select {
+		case <-rendered:
+			renders++
+		case <-time.After(5 * time.Second):
+			cancel()
+			t.Fatalf("expected an initial render and a re-render, got %d render(s)", renders)
+		}
+	}
+
+	// The changed spec is stable now: further polls don't re-render
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runWatchTemplates: %v", err)
+	}
+	close(rendered)
+	for range rendered {
+		renders++
+	}
+
+	if renders != 2 {
+		t.Errorf("expected 2 renders, got %d", renders)
+	}
+	if config.GitConfig != nil {
+		t.Error("expected git operations to be disabled")
+	}
+	if warnings.Count() != 1 || !strings.Contains(warnings.messages[0], "does not run git operations") {
+		t.Errorf("expected a warning about skipped git operations, got %v", warnings.messages)
+	}
+}
+
+func TestChangedSpecs(t *testing.T) {
+	previous := map[string]string{"a": "1", "b": "2", "c": ""}
+	current := map[string]string{"a": "1", "b": "3", "c": "4"}
+
+	if got := strings.Join(changedSpecs(previous, current), ","); got != "b,c" {
+		t.Errorf("changedSpecs = %q, want %q", got, "b,c")
+	}
+	if got := changedSpecs(current, current); len(got) != 0 {
+		t.Errorf("expected no changes, got %v", got)
+	}
+}
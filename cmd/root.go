@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/stuttgart-things/claims/internal/banner"
+	"github.com/stuttgart-things/claims/internal/redact"
 )
 
 var rootCmd = &cobra.Command{
@@ -12,9 +15,67 @@ var rootCmd = &cobra.Command{
 	Short: "Claims CLI tool",
 	Long:  `Claims is a CLI tool for managing claims.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		banner.Show()
+		showBanner()
 		_ = cmd.Usage()
 	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if err := warningsError(failOnWarning); err != nil {
+			fmt.Println(renderError(err.Error()))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&failOnWarning, "fail-on-warning", false, "Exit non-zero at the end if any warning was reported")
+	rootCmd.PersistentFlags().BoolVar(&noLogo, "no-logo", false, "Do not show the ASCII logo (default: $CLAIMS_NO_LOGO)")
+	rootCmd.PersistentFlags().IntVar(&maxParallelAPI, "max-parallel-api", 0, "Most API requests in flight at once, shared by template fetching and parallel rendering (0 = unlimited)")
+	rootCmd.PersistentFlags().BoolVar(&registryReadOnlyFlag, "registry-readonly", false, "Never modify registry.yaml, e.g. when it is generated elsewhere; files, kustomizations and git are still updated (default: $CLAIMS_REGISTRY_READONLY)")
+}
+
+// registryReadOnlyFlag leaves registry.yaml untouched by render, encrypt and delete
+var registryReadOnlyFlag bool
+
+// registryReadOnly reports whether the registry is read-only through
+// --registry-readonly or a true CLAIMS_REGISTRY_READONLY
+func registryReadOnly() bool {
+	if registryReadOnlyFlag {
+		return true
+	}
+	readOnly, err := strconv.ParseBool(os.Getenv("CLAIMS_REGISTRY_READONLY"))
+	return err == nil && readOnly
+}
+
+// printRegistryReadOnly tells the user the registry update was skipped
+func printRegistryReadOnly() {
+	fmt.Println("Registry is read-only (--registry-readonly); not updating registry.yaml")
+}
+
+// noLogo suppresses the logo; all other output is kept
+var noLogo bool
+
+// logoDisabled reports whether the logo is turned off by --no-logo or a true
+// CLAIMS_NO_LOGO
+func logoDisabled() bool {
+	if noLogo {
+		return true
+	}
+	disabled, err := strconv.ParseBool(os.Getenv("CLAIMS_NO_LOGO"))
+	return err == nil && disabled
+}
+
+// showBanner shows the logo unless it is disabled
+func showBanner() {
+	if logoDisabled() {
+		return
+	}
+	banner.Show()
+}
+
+// renderError styles an error message for output, masking any credentials
+// (e.g. tokens embedded in clone URLs) it may contain
+func renderError(msg string) string {
+	return errorStyle.Render(redact.String(msg))
 }
 
 func Execute() {
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestShowBanner_NoLogo(t *testing.T) {
+	server := newTestAPIServer(t, []templates.ClaimTemplate{testTemplate("vsphere-vm")})
+
+	// render's output with the banner, as runRender prints it
+	renderOutput := func(t *testing.T) string {
+		t.Helper()
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		showBanner()
+		err := runNonInteractive(context.Background(), &RenderConfig{
+			APIUrl:          server.URL,
+			Templates:       []string{"vsphere-vm"},
+			InlineParamsRaw: []string{"name=web"},
+			OutputDir:       t.TempDir(),
+			FilenamePattern: "{{.template}}-{{.name}}.yaml",
+			CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+		})
+
+		w.Close()
+		os.Stdout = old
+		var buf bytes.Buffer
+		io.Copy(&buf, r)
+		if err != nil {
+			t.Fatalf("runNonInteractive: %v", err)
+		}
+		return buf.String()
+	}
+
+	tests := []struct {
+		name     string
+		flag     bool
+		env      string
+		wantLogo bool
+	}{
+		{"default", false, "", true},
+		{"--no-logo", true, "", false},
+		{"CLAIMS_NO_LOGO=1", false, "1", false},
+		{"CLAIMS_NO_LOGO=false", false, "false", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CLAIMS_NO_LOGO", tt.env)
+			noLogo = tt.flag
+			t.Cleanup(func() { noLogo = false })
+
+			out := renderOutput(t)
+			if hasLogo := strings.Contains(out, "██"); hasLogo != tt.wantLogo {
+				t.Errorf("logo shown = %v, want %v:\n%s", hasLogo, tt.wantLogo, out)
+			}
+			if !strings.Contains(out, "Rendered vsphere-vm successfully") {
+				t.Errorf("progress output missing:\n%s", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// signalContext derives a context that is cancelled on Ctrl-C or SIGTERM so
+// in-flight API requests abort cleanly. It is meant to wrap only the API phase:
+// call stop as soon as that phase ends (stop also cancels the context) so that
+// Ctrl-C during file writes, git or PR steps terminates the process as usual.
+// Default handling is also restored once a signal fires, so a second Ctrl-C
+// always exits immediately.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/redact"
+)
+
+// summaryJSONPath is where --summary-json writes the run summary; "-" is stdout
+var summaryJSONPath string
+
+// addSummaryFlags registers --summary-json on cmd
+func addSummaryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&summaryJSONPath, "summary-json", "", "Write a JSON summary of the run (results, paths, errors, branch, commit, PR) to this file when it completes (- for stdout)")
+}
+
+// runSummary is the --summary-json report of a render or encrypt run
+type runSummary struct {
+	Command   string          `json:"command"` // render or encrypt
+	Success   bool            `json:"success"`
+	Error     string          `json:"error,omitempty"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Results   []summaryResult `json:"results"`
+	Branch    string          `json:"branch,omitempty"`
+	Commit    string          `json:"commit,omitempty"`
+	PRURL     string          `json:"prUrl,omitempty"`
+}
+
+// summaryResult is one rendered claim or encrypted secret of the run
+type summaryResult struct {
+	Template  string            `json:"template"`
+	Name      string            `json:"name"`
+	Path      string            `json:"path,omitempty"` // empty if nothing was written, e.g. with --dry-run
+	Error     string            `json:"error,omitempty"`
+	Documents []summaryDocument `json:"documents,omitempty"`
+}
+
+// summaryDocument is one rendered document of a result
+type summaryDocument struct {
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// newRunSummary builds the summary of command from its aggregated results.
+// The run failed if runErr is set or any result failed.
+func newRunSummary(command string, agg *RenderResults, branch string, runErr error) runSummary {
+	s := runSummary{
+		Command:   command,
+		Success:   runErr == nil && !agg.HasErrors(),
+		Succeeded: agg.SuccessCount(),
+		Failed:    agg.FailedCount(),
+		Results:   make([]summaryResult, 0, len(agg.Results)),
+		Branch:    branch,
+		Commit:    agg.GitCommit,
+		PRURL:     agg.PRUrl,
+	}
+	if runErr != nil {
+		s.Error = redact.String(runErr.Error())
+	}
+	for _, r := range agg.Results {
+		result := summaryResult{Template: r.TemplateName, Name: r.ResourceName, Path: r.OutputPath}
+		if r.Error != nil {
+			result.Error = redact.String(r.Error.Error())
+		}
+		for _, d := range r.Documents {
+			result.Documents = append(result.Documents, summaryDocument{Kind: d.Kind, Name: d.Name, Namespace: d.Namespace})
+		}
+		s.Results = append(s.Results, result)
+	}
+	return s
+}
+
+// writeRunSummary writes the summary of the recorded outcome to path (if
+// set); "-" writes it to stdout
+func writeRunSummary(path, command string, runErr error) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(outcome.Summary(command, runErr), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling summary: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteRunSummary(t *testing.T) {
+	outcome.Reset()
+	t.Cleanup(outcome.Reset)
+
+	dir := t.TempDir()
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\nmetadata:\n  name: web\n", Documents: parseDocuments("kind: VM\nmetadata:\n  name: web\n")},
+		{TemplateName: "vsphere-vm", ResourceName: "db", Error: errors.New("rendering failed: token=abc123")},
+	}
+	config := OutputConfig{Directory: dir, FilenamePattern: "{{.template}}-{{.name}}.yaml"}
+	if err := WriteResults(results, config); err != nil {
+		t.Fatalf("WriteResults() error: %v", err)
+	}
+	outcome.addRenderResults(results, config)
+	outcome.setCommit("abc1234")
+	outcome.setBranch("render-web")
+
+	path := filepath.Join(dir, "summary.json")
+	if err := writeRunSummary(path, "render", errors.New("1 claim failed")); err != nil {
+		t.Fatalf("writeRunSummary() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got runSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("summary is not valid JSON: %v\n%s", err, data)
+	}
+
+	if got.Command != "render" || got.Success || got.Error != "1 claim failed" {
+		t.Errorf("unexpected run status: %+v", got)
+	}
+	if got.Succeeded != 1 || got.Failed != 1 {
+		t.Errorf("succeeded/failed = %d/%d, want 1/1", got.Succeeded, got.Failed)
+	}
+	if got.Branch != "render-web" || got.Commit != "abc1234" || got.PRURL != "" {
+		t.Errorf("unexpected git fields: branch %q commit %q pr %q", got.Branch, got.Commit, got.PRURL)
+	}
+	if len(got.Results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", got.Results)
+	}
+	want := summaryResult{
+		Template:  "vsphere-vm",
+		Name:      "web",
+		Path:      filepath.Join(dir, "vsphere-vm-web.yaml"),
+		Documents: []summaryDocument{{Kind: "VM", Name: "web"}},
+	}
+	if !reflect.DeepEqual(got.Results[0], want) {
+		t.Errorf("results[0] = %+v, want %+v", got.Results[0], want)
+	}
+	if r := got.Results[1]; r.Name != "db" || r.Path != "" || r.Error != "rendering failed: token=***" {
+		t.Errorf("unexpected failed result: %+v", r)
+	}
+}
+
+func TestWriteRunSummary_SingleFileDryRun(t *testing.T) {
+	outcome.Reset()
+	t.Cleanup(outcome.Reset)
+
+	dir := t.TempDir()
+	results := []RenderResult{
+		{TemplateName: "vsphere-vm", ResourceName: "web", Content: "kind: VM\n"},
+		{TemplateName: "vsphere-vm", ResourceName: "db", Content: "kind: VM\n"},
+	}
+	config := OutputConfig{Directory: dir, SingleFile: true}
+	outcome.addRenderResults(results, config)
+
+	summary := outcome.Summary("render", nil)
+	for _, r := range summary.Results {
+		if r.Path != filepath.Join(dir, "vsphere-vm-combined.yaml") {
+			t.Errorf("expected %s in the combined file, got %q", r.Name, r.Path)
+		}
+	}
+	if !summary.Success || summary.Succeeded != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	outcome.Reset()
+	config.DryRun = true
+	outcome.addRenderResults(results, config)
+	for _, r := range outcome.Summary("render", nil).Results {
+		if r.Path != "" {
+			t.Errorf("expected no path with --dry-run, got %q", r.Path)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/templates"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	templateAPIURL     string
+	templateListOutput string
+	templateListTags   []string
+)
+
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Inspect claim templates",
+	Long:    `Inspect claim templates served by the claim-machinery API.`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Long:  `Lists the templates served by the API, so scripts can discover template names before a non-interactive render. --tag narrows the list to templates carrying all of the given tags.`,
+	Args:  cobra.NoArgs,
+	Run:   runTemplateList,
+}
+
+var templateVersionsCmd = &cobra.Command{
+	Use:   "versions <name>",
+	Short: "List available versions of a template",
+	Long:  `Lists the tags the API can render a template at. Pin one with 'claims render --template-version <tag>'.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   runTemplateVersions,
+}
+
+var templateValidateParamsCmd = &cobra.Command{
+	Use:   "validate-params <name>",
+	Short: "Validate a params map from stdin against a template",
+	Long:  `Reads a params map (YAML or JSON) from stdin and checks it against the template's parameter definitions: required parameters, enums, patterns and bounds. Prints OK or one line per problem and exits non-zero on problems. Use 'claims validate' for params files with several entries.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   runTemplateValidateParams,
+}
+
+var templateAliasesCmd = &cobra.Command{
+	Use:   "aliases",
+	Short: "List configured template aliases",
+	Long:  `Lists the shorthand template names defined in the aliases file ($CLAIMS_ALIASES_FILE or ~/.config/claims/aliases.yaml). Render resolves them to catalog names.`,
+	Args:  cobra.NoArgs,
+	Run:   runTemplateAliases,
+}
+
+func init() {
+	templateVersionsCmd.Flags().StringVarP(&templateAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	templateListCmd.Flags().StringVarP(&templateAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	templateListCmd.Flags().StringVarP(&templateListOutput, "output", "o", "table", "Output format (table, json)")
+	templateListCmd.Flags().StringSliceVar(&templateListTags, "tag", nil, "Only list templates carrying all of these tags (comma-separated or repeated)")
+
+	templateValidateParamsCmd.Flags().StringVarP(&templateAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateVersionsCmd)
+	templateCmd.AddCommand(templateValidateParamsCmd)
+	templateCmd.AddCommand(templateAliasesCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) {
+	templateAPIURL, _ = resolveAPIURL(templateAPIURL)
+	// Several endpoints may be configured; use the first like non-interactive render
+	templateAPIURL = splitAPIURLs(templateAPIURL)[0]
+
+	client, err := newCatalogClient(templateAPIURL, "", resolveAPIToken(""), "", false)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	if err := listTemplates(ctx, client, templateListTags, templateListOutput, os.Stdout); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+}
+
+// listTemplates fetches the catalog and writes the templates carrying all of
+// tags to out in the given format
+func listTemplates(ctx context.Context, client *templates.Client, tags []string, output string, out io.Writer) error {
+	available, err := client.FetchTemplatesContext(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching templates: %w", err)
+	}
+	available = templates.FilterByTags(available, tags)
+
+	if output == "json" {
+		if available == nil {
+			available = []templates.ClaimTemplate{}
+		}
+		data, err := json.MarshalIndent(available, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling JSON: %w", err)
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	if len(available) == 0 {
+		fmt.Fprintln(out, "No templates found.")
+		return nil
+	}
+	printTemplateTable(out, available)
+	return nil
+}
+
+// printTemplateTable writes one row per template, sorted by name
+func printTemplateTable(out io.Writer, list []templates.ClaimTemplate) {
+	sorted := slices.Clone(list)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Metadata.Name < sorted[j].Metadata.Name })
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTITLE\tTYPE\tTAGS")
+	fmt.Fprintln(w, "----\t-----\t----\t----")
+	for _, t := range sorted {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Metadata.Name, t.Metadata.Title, t.Spec.Type, strings.Join(t.Metadata.Tags, ","))
+	}
+	w.Flush()
+}
+
+func runTemplateValidateParams(cmd *cobra.Command, args []string) {
+	templateAPIURL, _ = resolveAPIURL(templateAPIURL)
+	// Several endpoints may be configured; use the first like non-interactive render
+	templateAPIURL = splitAPIURLs(templateAPIURL)[0]
+
+	client, err := newCatalogClient(templateAPIURL, "", resolveAPIToken(""), "", false)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+
+	ok, err := validateParamsInput(ctx, client, args[0], os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// validateParamsInput validates the params map read from in against template
+// name and writes OK or the problems to out. It reports whether the params are valid.
+func validateParamsInput(ctx context.Context, client *templates.Client, name string, in io.Reader, out io.Writer) (bool, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return false, fmt.Errorf("reading params: %w", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return false, fmt.Errorf("no params on stdin (expected a YAML or JSON map)")
+	}
+	// JSON is valid YAML, so one parser covers both
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return false, fmt.Errorf("parsing params: %w", err)
+	}
+
+	tmpl, err := fetchTemplate(ctx, client, name, false)
+	if errors.Is(err, templates.ErrTemplateNotFound) {
+		return false, fmt.Errorf("template %q not found", name)
+	}
+	if err != nil {
+		return false, fmt.Errorf("fetching template: %w", err)
+	}
+
+	problems := templates.ValidateParams(tmpl, values)
+	if len(problems) == 0 {
+		fmt.Fprintln(out, "OK")
+		return true, nil
+	}
+	for _, p := range problems {
+		fmt.Fprintln(out, p.Error())
+	}
+	return false, nil
+}
+
+func runTemplateAliases(cmd *cobra.Command, args []string) {
+	path, err := aliasesPath()
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	aliases, err := templates.LoadAliases(path)
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+
+	if len(aliases) == 0 {
+		fmt.Printf("No template aliases configured (%s).\n", path)
+		return
+	}
+
+	fmt.Printf("Aliases from %s:\n\n", path)
+	printAliasTable(os.Stdout, aliases)
+}
+
+// printAliasTable writes aliases sorted by alias name
+func printAliasTable(out io.Writer, aliases templates.Aliases) {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALIAS\tTEMPLATE")
+	fmt.Fprintln(w, "-----\t--------")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", name, aliases[name])
+	}
+	w.Flush()
+}
+
+func runTemplateVersions(cmd *cobra.Command, args []string) {
+	templateAPIURL, _ = resolveAPIURL(templateAPIURL)
+	// Several endpoints may be configured; use the first like non-interactive render
+	templateAPIURL = splitAPIURLs(templateAPIURL)[0]
+
+	client := templates.NewClientWithRetry(templateAPIURL, apiMaxRetries, apiRetryBaseDelay)
+	client.AuthToken = resolveAPIToken("")
+	client.Limiter = sharedAPILimiter()
+	ctx, stop := signalContext(context.Background())
+	defer stop()
+	versions, err := client.FetchTemplateVersionsContext(ctx, args[0])
+	if errors.Is(err, templates.ErrVersionsUnsupported) {
+		fmt.Printf("The API at %s does not expose template versions.\n", templateAPIURL)
+		return
+	}
+	if err != nil {
+		fmt.Println(renderError(fmt.Sprintf("Error fetching versions: %v", err)))
+		os.Exit(1)
+	}
+
+	if len(versions) == 0 {
+		fmt.Printf("No versions found for %s.\n", args[0])
+		return
+	}
+
+	// Mark the catalog default when the catalog can be fetched
+	defaultTag := ""
+	if available, err := client.FetchTemplatesContext(ctx); err == nil {
+		for _, t := range available {
+			if t.Metadata.Name == args[0] {
+				defaultTag = t.Spec.Tag
+				break
+			}
+		}
+	}
+
+	for _, v := range versions {
+		if v == defaultTag {
+			fmt.Printf("%s (default)\n", v)
+			continue
+		}
+		fmt.Println(v)
+	}
+}
+
+// resolveTemplateVersion checks that tag is an available version of
+// templateName and returns the tag to request. When the API doesn't expose
+// versions it warns and returns "", so the catalog default is rendered.
+func resolveTemplateVersion(ctx context.Context, client *templates.Client, templateName, tag string) (string, error) {
+	versions, err := client.FetchTemplateVersionsContext(ctx, templateName)
+	if errors.Is(err, templates.ErrVersionsUnsupported) {
+		warnf("API does not support template versions; ignoring --template-version %s", tag)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("fetching versions of %s: %w", templateName, err)
+	}
+	if !slices.Contains(versions, tag) {
+		return "", fmt.Errorf("version %q not available for template %s (see 'claims template versions %s')", tag, templateName, templateName)
+	}
+	return tag, nil
+}
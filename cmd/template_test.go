@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestListTemplates(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Metadata.Title = "vSphere VM"
+	vm.Metadata.Tags = []string{"vm", "vsphere"}
+	vm.Spec.Type = "crossplane"
+	db := testTemplate("postgres")
+	db.Metadata.Title = "PostgreSQL"
+	db.Metadata.Tags = []string{"database"}
+	db.Spec.Type = "crossplane"
+
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm, db})
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatalf("newCatalogClient: %v", err)
+	}
+
+	t.Run("table sorted by name", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := listTemplates(context.Background(), client, nil, "table", &out); err != nil {
+			t.Fatalf("listTemplates: %v", err)
+		}
+		want := `NAME        TITLE       TYPE        TAGS
+----        -----       ----        ----
+postgres    PostgreSQL  crossplane  database
+vsphere-vm  vSphere VM  crossplane  vm,vsphere
+`
+		if out.String() != want {
+			t.Errorf("unexpected table:\n%s\nwant:\n%s", out.String(), want)
+		}
+	})
+
+	t.Run("json filtered by tag", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := listTemplates(context.Background(), client, []string{"VM"}, "json", &out); err != nil {
+			t.Fatalf("listTemplates: %v", err)
+		}
+		var got []templates.ClaimTemplate
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("expected JSON output: %v\n%s", err, out.String())
+		}
+		if len(got) != 1 || got[0].Metadata.Name != "vsphere-vm" {
+			t.Errorf("expected only vsphere-vm, got %+v", got)
+		}
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := listTemplates(context.Background(), client, []string{"network"}, "json", &out); err != nil {
+			t.Fatalf("listTemplates: %v", err)
+		}
+		if out.String() != "[]\n" {
+			t.Errorf("expected an empty JSON list, got %q", out.String())
+		}
+
+		out.Reset()
+		if err := listTemplates(context.Background(), client, []string{"network"}, "table", &out); err != nil {
+			t.Fatalf("listTemplates: %v", err)
+		}
+		if out.String() != "No templates found.\n" {
+			t.Errorf("unexpected output %q", out.String())
+		}
+	})
+}
+
+func TestValidateParamsInput(t *testing.T) {
+	tmpl := testTemplate("postgres")
+	tmpl.Spec.Parameters = []templates.Parameter{
+		{Name: "name", Type: "string", Required: true, Pattern: "^[a-z][a-z0-9-]*$"},
+		{Name: "size", Type: "string", Enum: []string{"small", "large"}},
+	}
+	server := newTestAPIServer(t, []templates.ClaimTemplate{tmpl})
+	client, err := newCatalogClient(server.URL, filepath.Join(t.TempDir(), "catalog.json"), "", "", false)
+	if err != nil {
+		t.Fatalf("newCatalogClient: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		wantOK  bool
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "valid YAML",
+			input:   "name: orders\nsize: large\n",
+			wantOK:  true,
+			wantOut: "OK\n",
+		},
+		{
+			name:    "valid JSON",
+			input:   `{"name": "orders"}`,
+			wantOK:  true,
+			wantOut: "OK\n",
+		},
+		{
+			name:    "pattern and enum violations",
+			input:   "name: Orders\nsize: huge\n",
+			wantOut: "name: \"Orders\" does not match pattern ^[a-z][a-z0-9-]*$\nsize: \"huge\" is not one of small, large\n",
+		},
+		{
+			name:    "missing required",
+			input:   "size: small\n",
+			wantOut: "name: required parameter is not set\n",
+		},
+		{
+			name:    "empty input",
+			input:   "  \n",
+			wantErr: "no params on stdin",
+		},
+		{
+			name:    "not a map",
+			input:   "- a\n- b\n",
+			wantErr: "parsing params",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			ok, err := validateParamsInput(context.Background(), client, "postgres", strings.NewReader(tt.input), &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateParamsInput: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+
+	if _, err := validateParamsInput(context.Background(), client, "unknown", strings.NewReader("name: x\n"), io.Discard); err == nil || !strings.Contains(err.Error(), `template "unknown" not found`) {
+		t.Errorf("expected a not-found error, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stuttgart-things/claims/internal/params"
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+var (
+	validateParamsFiles []string
+	validateAPIURL      string
+	validateOffline     bool
+	validateFormat      string
+	validateTemplates   []string
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [params-file...]",
+	Short: "Validate params files against the template catalog",
+	Long:  `Checks every entry of the given params files against its catalog template: the template must exist, required parameters must be set, values must match the parameter's enum and pattern, and no two entries may render the same claim. Prints PASS or FAIL per entry with its problems and a summary line, and exits non-zero if there are problems, so it can run as a pre-commit hook or CI gate. Params files are taken from the arguments and --params-file, which accepts glob patterns; --templates restricts the check to some templates.`,
+	Run:   runValidate,
+}
+
+func init() {
+	validateCmd.Flags().StringSliceVarP(&validateParamsFiles, "params-file", "f", nil, "Params file(s) or glob pattern(s) to validate")
+	validateCmd.Flags().StringVarP(&validateAPIURL, "api-url", "a", "", "API URL (default: $CLAIM_API_URL or http://localhost:8080)")
+	validateCmd.Flags().BoolVar(&validateOffline, "offline", false, "Validate against the cached template catalog instead of fetching it from the API")
+	validateCmd.Flags().StringVarP(&validateFormat, "format", "o", "text", "Output format (text, json)")
+	validateCmd.Flags().StringSliceVarP(&validateTemplates, "templates", "t", nil, "Only check the entries of these templates (comma-separated or repeatable)")
+
+	rootCmd.AddCommand(validateCmd)
+}
+
+// paramsProblem is a validation problem of one params file entry
+type paramsProblem struct {
+	File      string `json:"file,omitempty"`
+	Entry     int    `json:"entry"` // position in the file, from 1
+	Template  string `json:"template"`
+	Parameter string `json:"parameter,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (p paramsProblem) String() string {
+	var sb strings.Builder
+	if p.File != "" {
+		sb.WriteString(p.File + ": ")
+	}
+	sb.WriteString(fmt.Sprintf("entry %d (%s): ", p.Entry, p.Template))
+	if p.Parameter != "" {
+		sb.WriteString(p.Parameter + ": ")
+	}
+	sb.WriteString(p.Message)
+	return sb.String()
+}
+
+// validateTemplateParams checks each entry against its catalog template. It
+// is shared by 'claims validate' and the pre-flight check of non-interactive
+// render. Entries are numbered from 1 in the order given.
+func validateTemplateParams(templateParams []params.TemplateParams, lookup map[string]*templates.ClaimTemplate) []paramsProblem {
+	var problems []paramsProblem
+	for i, tp := range templateParams {
+		tmpl := lookup[tp.Name]
+		if tmpl == nil {
+			problems = append(problems, paramsProblem{Entry: i + 1, Template: tp.Name, Message: "template not found"})
+			continue
+		}
+		for _, p := range templates.ValidateParams(tmpl, tp.Parameters) {
+			problems = append(problems, paramsProblem{Entry: i + 1, Template: tp.Name, Parameter: p.Parameter, Message: p.Message})
+		}
+	}
+	return problems
+}
+
+// formatParamsProblems renders one indented line per problem
+func formatParamsProblems(problems []paramsProblem) string {
+	lines := make([]string, len(problems))
+	for i, p := range problems {
+		lines[i] = "  " + p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validationReport is the result of 'claims validate'
+type validationReport struct {
+	OK        bool            `json:"ok"`
+	Templates int             `json:"templates"`
+	Entries   []entryResult   `json:"entries"`
+	Problems  []paramsProblem `json:"problems"`
+	Summary   string          `json:"summary"`
+}
+
+// entryResult is the pass/fail result of one params file entry
+type entryResult struct {
+	File     string `json:"file,omitempty"`
+	Entry    int    `json:"entry"` // position in the file, from 1
+	Template string `json:"template"`
+	OK       bool   `json:"ok"`
+}
+
+func (e entryResult) String() string {
+	status := "PASS"
+	if !e.OK {
+		status = "FAIL"
+	}
+	if e.File != "" {
+		return fmt.Sprintf("%s %s: entry %d (%s)", status, e.File, e.Entry, e.Template)
+	}
+	return fmt.Sprintf("%s entry %d (%s)", status, e.Entry, e.Template)
+}
+
+func newValidationReport(entries []entryResult, problems []paramsProblem) validationReport {
+	r := validationReport{OK: len(problems) == 0, Templates: len(entries), Entries: entries, Problems: problems}
+	if r.Entries == nil {
+		r.Entries = []entryResult{}
+	}
+	if r.Problems == nil {
+		r.Problems = []paramsProblem{}
+	}
+	r.Summary = r.summary()
+	return r
+}
+
+// summary renders the grep-friendly summary line, e.g. "OK: 5 templates, 0
+// problems" or "FAIL: 2 problems in 1 template"
+func (r validationReport) summary() string {
+	if r.OK {
+		return fmt.Sprintf("OK: %s, 0 problems", plural(r.Templates, "template"))
+	}
+	failing := make(map[string]bool)
+	for _, p := range r.Problems {
+		failing[fmt.Sprintf("%s#%d", p.File, p.Entry)] = true
+	}
+	return fmt.Sprintf("FAIL: %s in %s", plural(len(r.Problems), "problem"), plural(len(failing), "template"))
+}
+
+// ExitCode is the process exit code for the report: 0 if valid, 1 otherwise
+func (r validationReport) ExitCode() int {
+	if r.OK {
+		return 0
+	}
+	return 1
+}
+
+func runValidate(cmd *cobra.Command, args []string) {
+	if validateFormat != "text" && validateFormat != "json" {
+		fmt.Println(renderError(fmt.Sprintf("invalid --format %q: must be text or json", validateFormat)))
+		os.Exit(1)
+	}
+
+	validateAPIURL, _ = resolveAPIURL(validateAPIURL)
+	config := &RenderConfig{
+		APIUrl:    splitAPIURLs(validateAPIURL)[0],
+		APIToken:  resolveAPIToken(""),
+		Offline:   validateOffline,
+		CacheTTL:  templates.DefaultCatalogTTL,
+		Templates: validateTemplates,
+	}
+	config.APIUrls = []string{config.APIUrl}
+
+	ctx, stop := signalContext(context.Background())
+	report, err := validateParamsFilesReport(ctx, config, append(args, validateParamsFiles...))
+	stop()
+	if err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+
+	if err := printValidationReport(os.Stdout, report, validateFormat); err != nil {
+		fmt.Println(renderError(err.Error()))
+		os.Exit(1)
+	}
+	if code := report.ExitCode(); code != 0 {
+		os.Exit(code)
+	}
+}
+
+// validateParamsFilesReport validates every entry of the params files matched
+// by patterns against the template catalog. With config.Templates set, only
+// the entries of those templates are checked and reported.
+func validateParamsFilesReport(ctx context.Context, config *RenderConfig, patterns []string) (validationReport, error) {
+	if len(patterns) == 0 {
+		return validationReport{}, fmt.Errorf("no params files given (pass them as arguments or with --params-file)")
+	}
+
+	client, err := newRenderClient(config)
+	if err != nil {
+		return validationReport{}, err
+	}
+	available, err := loadTemplateCatalog(ctx, client, config)
+	if err != nil {
+		return validationReport{}, fmt.Errorf("fetching templates: %w", err)
+	}
+	aliases, err := config.loadAliases()
+	if err != nil {
+		return validationReport{}, err
+	}
+	known := catalogNames(available)
+	lookup := make(map[string]*templates.ClaimTemplate)
+	for i, t := range available {
+		lookup[t.Metadata.Name] = &available[i]
+	}
+
+	// Entries keep their position in the file when others are filtered out
+	only := make(map[string]bool, len(config.Templates))
+	for _, name := range config.Templates {
+		only[resolveTemplateAlias(name, aliases, known)] = true
+	}
+	checked := func(template string) bool { return len(only) == 0 || only[template] }
+
+	var entries []entryResult
+	var problems []paramsProblem
+	for _, pattern := range patterns {
+		files, err := params.ExpandFiles(pattern)
+		if err != nil {
+			return validationReport{}, err
+		}
+		for _, f := range files {
+			pf, err := params.ParseFile(f)
+			if err != nil {
+				return validationReport{}, err
+			}
+			for i := range pf.Templates {
+				pf.Templates[i].Name = resolveTemplateAlias(pf.Templates[i].Name, aliases, known)
+			}
+			failed := make(map[int]bool)
+			for _, p := range validateTemplateParams(pf.Templates, lookup) {
+				if !checked(p.Template) {
+					continue
+				}
+				p.File = f
+				problems = append(problems, p)
+				failed[p.Entry] = true
+			}
+			for _, d := range params.FindDuplicates(pf.Templates) {
+				if !checked(d.Template) {
+					continue
+				}
+				for _, e := range d.Entries[1:] {
+					problems = append(problems, paramsProblem{File: f, Entry: e, Template: d.Template, Message: fmt.Sprintf("duplicates entry %d (same template and name)", d.Entries[0])})
+					failed[e] = true
+				}
+			}
+			for i, tp := range pf.Templates {
+				if checked(tp.Name) {
+					entries = append(entries, entryResult{File: f, Entry: i + 1, Template: tp.Name, OK: !failed[i+1]})
+				}
+			}
+		}
+	}
+	if len(only) > 0 && len(entries) == 0 {
+		return validationReport{}, fmt.Errorf("no params file entries for --templates %s", strings.Join(config.Templates, ", "))
+	}
+	return newValidationReport(entries, problems), nil
+}
+
+// printValidationReport writes the report as text (PASS or FAIL per entry,
+// each followed by its problems, and the summary line) or as JSON
+func printValidationReport(out io.Writer, report validationReport, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling report: %w", err)
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+
+	byEntry := make(map[string][]paramsProblem)
+	for _, p := range report.Problems {
+		key := fmt.Sprintf("%s#%d", p.File, p.Entry)
+		byEntry[key] = append(byEntry[key], p)
+	}
+	for _, e := range report.Entries {
+		fmt.Fprintln(out, e.String())
+		if problems := byEntry[fmt.Sprintf("%s#%d", e.File, e.Entry)]; len(problems) > 0 {
+			fmt.Fprintln(out, formatParamsProblems(problems))
+		}
+	}
+	_, err := fmt.Fprintln(out, report.Summary)
+	return err
+}
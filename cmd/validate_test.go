@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stuttgart-things/claims/internal/templates"
+)
+
+func TestValidateParamsFilesReport(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Spec.Parameters = append(vm.Spec.Parameters,
+		templates.Parameter{Name: "cpu", Required: true},
+		templates.Parameter{Name: "size", Enum: []string{"small", "large"}},
+	)
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm, testTemplate("postgresql")})
+
+	tests := []struct {
+		name        string
+		content     string
+		wantSummary string
+		wantCode    int
+		wantLines   []string
+	}{
+		{
+			name: "valid",
+			content: `templates:
+  - name: vsphere-vm
+    parameters: {cpu: 2, size: small}
+  - name: postgresql
+`,
+			wantSummary: "OK: 2 templates, 0 problems",
+			wantCode:    0,
+		},
+		{
+			name: "problems",
+			content: `templates:
+  - name: vsphere-vm
+    parameters: {size: huge}
+  - name: postgresql
+  - name: redis
+  - name: postgresql
+`,
+			wantSummary: "FAIL: 4 problems in 3 templates",
+			wantCode:    1,
+			wantLines: []string{
+				"entry 1 (vsphere-vm): cpu: required parameter is not set",
+				`entry 1 (vsphere-vm): size: "huge" is not one of small, large`,
+				"entry 3 (redis): template not found",
+				"entry 4 (postgresql): duplicates entry 2 (same template and name)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("CLAIMS_ALIASES_FILE", filepath.Join(t.TempDir(), "aliases.yaml"))
+			paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+			if err := os.WriteFile(paramsFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config := &RenderConfig{
+				APIUrl:      server.URL,
+				CatalogPath: filepath.Join(t.TempDir(), "catalog.json"),
+			}
+
+			report, err := validateParamsFilesReport(context.Background(), config, []string{paramsFile})
+			if err != nil {
+				t.Fatalf("validateParamsFilesReport() error: %v", err)
+			}
+			if code := report.ExitCode(); code != tt.wantCode {
+				t.Errorf("ExitCode() = %d, want %d", code, tt.wantCode)
+			}
+
+			var out bytes.Buffer
+			if err := printValidationReport(&out, report, "text"); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if last := lines[len(lines)-1]; last != tt.wantSummary {
+				t.Errorf("summary line = %q, want %q", last, tt.wantSummary)
+			}
+			for _, want := range tt.wantLines {
+				if !strings.Contains(out.String(), paramsFile+": "+want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+
+			out.Reset()
+			if err := printValidationReport(&out, report, "json"); err != nil {
+				t.Fatal(err)
+			}
+			var decoded validationReport
+			if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+				t.Fatalf("invalid JSON report: %v\n%s", err, out.String())
+			}
+			if decoded.OK != (tt.wantCode == 0) || decoded.Summary != tt.wantSummary || len(decoded.Problems) != len(tt.wantLines) {
+				t.Errorf("JSON report = %+v", decoded)
+			}
+		})
+	}
+}
+
+func TestValidateParamsFilesReport_Templates(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Spec.Parameters = append(vm.Spec.Parameters, templates.Parameter{Name: "cpu", Required: true})
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm, testTemplate("postgresql")})
+	t.Setenv("CLAIMS_ALIASES_FILE", filepath.Join(t.TempDir(), "aliases.yaml"))
+
+	paramsFile := filepath.Join(t.TempDir(), "params.yaml")
+	content := `templates:
+  - name: vsphere-vm
+  - name: postgresql
+  - name: vsphere-vm
+    parameters: {name: web, cpu: 2}
+`
+	if err := os.WriteFile(paramsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newConfig := func(only ...string) *RenderConfig {
+		return &RenderConfig{
+			APIUrl:      server.URL,
+			CatalogPath: filepath.Join(t.TempDir(), "catalog.json"),
+			Templates:   only,
+		}
+	}
+
+	report, err := validateParamsFilesReport(context.Background(), newConfig(), []string{paramsFile})
+	if err != nil {
+		t.Fatalf("validateParamsFilesReport() error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := printValidationReport(&out, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	want := "FAIL " + paramsFile + ": entry 1 (vsphere-vm)\n" +
+		"  " + paramsFile + ": entry 1 (vsphere-vm): cpu: required parameter is not set\n" +
+		"PASS " + paramsFile + ": entry 2 (postgresql)\n" +
+		"PASS " + paramsFile + ": entry 3 (vsphere-vm)\n" +
+		"FAIL: 1 problem in 1 template\n"
+	if out.String() != want {
+		t.Errorf("text report =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	// Only postgresql is checked; entries keep their position in the file
+	report, err = validateParamsFilesReport(context.Background(), newConfig("postgresql"), []string{paramsFile})
+	if err != nil {
+		t.Fatalf("validateParamsFilesReport() error: %v", err)
+	}
+	if !report.OK || report.Summary != "OK: 1 template, 0 problems" {
+		t.Errorf("expected only postgresql to be checked, got %+v", report)
+	}
+	out.Reset()
+	if err := printValidationReport(&out, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded validationReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON report: %v", err)
+	}
+	if len(decoded.Entries) != 1 || decoded.Entries[0] != (entryResult{File: paramsFile, Entry: 2, Template: "postgresql", OK: true}) {
+		t.Errorf("JSON entries = %+v", decoded.Entries)
+	}
+
+	if _, err := validateParamsFilesReport(context.Background(), newConfig("redis"), []string{paramsFile}); err == nil || !strings.Contains(err.Error(), "no params file entries for --templates redis") {
+		t.Errorf("expected an error for a filter matching nothing, got %v", err)
+	}
+}
+
+func TestRunNonInteractive_PreflightMissingRequired(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Spec.Parameters = append(vm.Spec.Parameters,
+		templates.Parameter{Name: "cpu", Required: true},
+		templates.Parameter{Name: "datastore", Required: true},
+	)
+	renders := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/order") {
+			renders++
+			http.Error(w, "missing parameters", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/api/v1/claim-templates" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(templates.ClaimTemplateList{Items: []templates.ClaimTemplate{vm}})
+	}))
+	defer server.Close()
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web"},
+		OutputDir:       t.TempDir(),
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+
+	err := runNonInteractive(context.Background(), config)
+	if err == nil {
+		t.Fatal("expected pre-flight validation error")
+	}
+	// Every missing parameter is listed, not just the first
+	for _, want := range []string{"cpu: required parameter is not set", "datastore: required parameter is not set"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q: %v", want, err)
+		}
+	}
+	if renders != 0 {
+		t.Errorf("expected no render request, got %d", renders)
+	}
+}
+
+func TestRunNonInteractive_PreflightValidation(t *testing.T) {
+	vm := testTemplate("vsphere-vm")
+	vm.Spec.Parameters = append(vm.Spec.Parameters, templates.Parameter{Name: "size", Enum: []string{"small", "large"}})
+	server := newTestAPIServer(t, []templates.ClaimTemplate{vm})
+	outputDir := t.TempDir()
+
+	config := &RenderConfig{
+		APIUrl:          server.URL,
+		Templates:       []string{"vsphere-vm"},
+		InlineParamsRaw: []string{"name=web", "size=huge"},
+		OutputDir:       outputDir,
+		FilenamePattern: "{{.template}}-{{.name}}.yaml",
+		CatalogPath:     filepath.Join(t.TempDir(), "catalog.json"),
+	}
+
+	err := runNonInteractive(context.Background(), config)
+	if err == nil || !strings.Contains(err.Error(), `size: "huge" is not one of small, large`) {
+		t.Fatalf("expected pre-flight validation error, got %v", err)
+	}
+	if entries, _ := os.ReadDir(outputDir); len(entries) != 0 {
+		t.Errorf("expected nothing written, got %d files", len(entries))
+	}
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 	"github.com/stuttgart-things/claims/internal/banner"
 )
@@ -16,6 +18,10 @@ var versionCmd = &cobra.Command{
 	Short: "Print version information",
 	Long:  `Print the version, commit SHA, and build date of the claims CLI.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if logoDisabled() {
+			fmt.Println(banner.VersionLine())
+			return
+		}
 		banner.Show()
 	},
 }
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/stuttgart-things/claims/internal/redact"
+)
+
+// failOnWarning turns reported warnings into a non-zero exit (--fail-on-warning)
+var failOnWarning bool
+
+// warnings collects the non-fatal warnings reported during a command run
+var warnings warningCollector
+
+// warningCollector records warning messages; safe for concurrent use
+type warningCollector struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// warnf prints a warning and records it for --fail-on-warning
+func warnf(format string, args ...any) {
+	msg := redact.String(fmt.Sprintf(format, args...))
+	fmt.Printf("Warning: %s\n", msg)
+
+	warnings.mu.Lock()
+	warnings.messages = append(warnings.messages, msg)
+	warnings.mu.Unlock()
+}
+
+// Count returns the number of warnings reported so far
+func (w *warningCollector) Count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.messages)
+}
+
+// Reset discards all recorded warnings
+func (w *warningCollector) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = nil
+}
+
+// warningsError returns an error when failOnWarning is set and any warning
+// was reported, nil otherwise
+func warningsError(failOnWarning bool) error {
+	n := warnings.Count()
+	if !failOnWarning || n == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d warning(s) reported and --fail-on-warning is set", n)
+}
@@ -65,6 +65,11 @@ func SetVersionInfo(version, commit, buildDate string) {
 	buildStr = buildDate
 }
 
+// VersionLine returns the version details shown under the logo.
+func VersionLine() string {
+	return fmt.Sprintf("Version: %s | Commit: %s | Built: %s", versionStr, commitStr, buildStr)
+}
+
 // Show displays the animated banner when running in a TTY,
 // or prints a static logo otherwise.
 func Show() {
@@ -115,7 +120,7 @@ func renderGradient() string {
 	result.WriteString("\n\n")
 
 	// Version info
-	versionLine := fmt.Sprintf("Version: %s | Commit: %s | Built: %s", versionStr, commitStr, buildStr)
+	versionLine := VersionLine()
 	vPad := (maxWidth - len(versionLine)) / 2
 	if vPad < 0 {
 		vPad = 0
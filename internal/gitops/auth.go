@@ -1,8 +1,15 @@
 package gitops
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 // ResolveCredentials gets git credentials from flags or environment
@@ -44,3 +51,76 @@ func ResolveCredentialsOptional(user, token string) (string, string) {
 	}
 	return user, token
 }
+
+// SSHUser is the user SSH remotes are accessed as, as in git@github.com
+const SSHUser = "git"
+
+// ResolveSSHAuth loads the private key for SSH remotes from keyPath, else
+// GIT_SSH_KEY, else ~/.ssh/id_ed25519. An encrypted key is decrypted with
+// passphrase, else GIT_SSH_KEY_PASSPHRASE. A missing key file is reported
+// wrapping os.ErrNotExist.
+func ResolveSSHAuth(keyPath, passphrase string) (*ssh.PublicKeys, error) {
+	if keyPath == "" {
+		keyPath = os.Getenv("GIT_SSH_KEY")
+	}
+	if keyPath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("locating the default SSH key: %w", err)
+		}
+		keyPath = filepath.Join(home, ".ssh", "id_ed25519")
+	}
+	if passphrase == "" {
+		passphrase = os.Getenv("GIT_SSH_KEY_PASSPHRASE")
+	}
+
+	pemBytes, err := os.ReadFile(keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("SSH key %s not found (set --ssh-key or GIT_SSH_KEY): %w", keyPath, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading SSH key: %w", err)
+	}
+
+	signer, err := gossh.ParsePrivateKey(pemBytes)
+	var missing *gossh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("SSH key %s is encrypted: set GIT_SSH_KEY_PASSPHRASE", keyPath)
+		}
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing SSH key %s: %w", keyPath, err)
+	}
+
+	return &ssh.PublicKeys{User: SSHUser, Signer: signer}, nil
+}
+
+// ResolveAuth picks the credentials for remoteURL: the SSH key from
+// ResolveSSHAuth for ssh:// and scp-like remotes, else HTTP basic auth from
+// the user and token resolved like ResolveCredentials. Unless required is
+// set, missing credentials give a nil auth instead of an error, for local
+// commits that never reach the remote.
+func ResolveAuth(remoteURL, user, token, sshKey string, required bool) (transport.AuthMethod, error) {
+	if IsSSHURL(remoteURL) {
+		auth, err := ResolveSSHAuth(sshKey, "")
+		if err != nil {
+			if required {
+				return nil, err
+			}
+			return nil, nil
+		}
+		return auth, nil
+	}
+
+	if required {
+		var err error
+		if user, token, err = ResolveCredentials(user, token); err != nil {
+			return nil, err
+		}
+	} else if user, token = ResolveCredentialsOptional(user, token); user == "" || token == "" {
+		return nil, nil
+	}
+	return &http.BasicAuth{Username: user, Password: token}, nil
+}
@@ -1,9 +1,19 @@
 package gitops_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/stuttgart-things/claims/internal/gitops"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func TestResolveCredentials(t *testing.T) {
@@ -210,3 +220,144 @@ func TestResolveCredentialsOptional(t *testing.T) {
 		})
 	}
 }
+
+// writeSSHKey writes a new ed25519 private key to dir/name, encrypted with
+// passphrase unless it is empty, and returns its path
+func writeSSHKey(t *testing.T, dir, name, passphrase string) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = gossh.MarshalPrivateKey(key, "test")
+	} else {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(key, "test", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestResolveSSHAuth(t *testing.T) {
+	dir := t.TempDir()
+	plain := writeSSHKey(t, dir, "id_plain", "")
+	encrypted := writeSSHKey(t, dir, "id_encrypted", "s3cret")
+
+	tests := []struct {
+		name          string
+		keyPath       string
+		passphrase    string
+		envKey        string
+		envPassphrase string
+		wantErr       string
+	}{
+		{name: "key from flag", keyPath: plain},
+		{name: "key from GIT_SSH_KEY", envKey: plain},
+		{name: "flag overrides GIT_SSH_KEY", keyPath: plain, envKey: filepath.Join(dir, "missing")},
+		{name: "key not found", keyPath: filepath.Join(dir, "missing"), wantErr: "not found"},
+		{name: "encrypted key with passphrase", keyPath: encrypted, passphrase: "s3cret"},
+		{name: "encrypted key with GIT_SSH_KEY_PASSPHRASE", keyPath: encrypted, envPassphrase: "s3cret"},
+		{name: "encrypted key without passphrase", keyPath: encrypted, wantErr: "is encrypted"},
+		{name: "encrypted key with wrong passphrase", keyPath: encrypted, passphrase: "wrong", wantErr: "parsing SSH key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GIT_SSH_KEY", tt.envKey)
+			t.Setenv("GIT_SSH_KEY_PASSPHRASE", tt.envPassphrase)
+
+			auth, err := gitops.ResolveSSHAuth(tt.keyPath, tt.passphrase)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveSSHAuth() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveSSHAuth() error = %v", err)
+			}
+			if auth.User != gitops.SSHUser || auth.Signer == nil {
+				t.Errorf("ResolveSSHAuth() = %+v, want user %q with a signer", auth, gitops.SSHUser)
+			}
+		})
+	}
+}
+
+func TestResolveSSHAuth_DefaultKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GIT_SSH_KEY", "")
+	t.Setenv("GIT_SSH_KEY_PASSPHRASE", "")
+
+	_, err := gitops.ResolveSSHAuth("", "")
+	if !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "id_ed25519") {
+		t.Fatalf("ResolveSSHAuth() error = %v, want missing id_ed25519", err)
+	}
+
+	writeSSHKey(t, filepath.Join(home, ".ssh"), "id_ed25519", "")
+	if _, err := gitops.ResolveSSHAuth("", ""); err != nil {
+		t.Fatalf("ResolveSSHAuth() error = %v", err)
+	}
+}
+
+func TestResolveAuth(t *testing.T) {
+	t.Setenv("GIT_USER", "")
+	t.Setenv("GIT_TOKEN", "")
+	t.Setenv("GITHUB_USER", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("GIT_SSH_KEY", "")
+	t.Setenv("GIT_SSH_KEY_PASSPHRASE", "")
+	t.Setenv("HOME", t.TempDir())
+
+	key := writeSSHKey(t, t.TempDir(), "id_ed25519", "")
+	missing := filepath.Join(t.TempDir(), "missing")
+
+	tests := []struct {
+		name     string
+		url      string
+		user     string
+		token    string
+		sshKey   string
+		required bool
+		wantSSH  bool
+		wantHTTP bool
+		wantErr  bool
+	}{
+		{name: "scp-like remote uses the SSH key", url: "git@github.com:org/repo.git", sshKey: key, required: true, wantSSH: true},
+		{name: "ssh URL uses the SSH key", url: "ssh://git@gitlab.com:2222/org/repo.git", sshKey: key, required: true, wantSSH: true},
+		{name: "SSH remote ignores the token", url: "git@github.com:org/repo.git", user: "u", token: "t", sshKey: key, required: true, wantSSH: true},
+		{name: "SSH key missing for push", url: "git@github.com:org/repo.git", sshKey: missing, required: true, wantErr: true},
+		{name: "SSH key missing for commit", url: "git@github.com:org/repo.git", sshKey: missing},
+		{name: "HTTPS remote uses the token", url: "https://github.com/org/repo.git", user: "u", token: "t", sshKey: key, required: true, wantHTTP: true},
+		{name: "HTTPS credentials missing for push", url: "https://github.com/org/repo.git", required: true, wantErr: true},
+		{name: "HTTPS credentials missing for commit", url: "https://github.com/org/repo.git"},
+		{name: "no remote uses the token", user: "u", token: "t", required: true, wantHTTP: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := gitops.ResolveAuth(tt.url, tt.user, tt.token, tt.sshKey, tt.required)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			_, isSSH := auth.(*ssh.PublicKeys)
+			_, isHTTP := auth.(*http.BasicAuth)
+			if isSSH != tt.wantSSH || isHTTP != tt.wantHTTP {
+				t.Errorf("ResolveAuth() = %T, want ssh %v, http %v", auth, tt.wantSSH, tt.wantHTTP)
+			}
+			if !tt.wantSSH && !tt.wantHTTP && auth != nil {
+				t.Errorf("ResolveAuth() = %v, want nil", auth)
+			}
+		})
+	}
+}
@@ -1,22 +1,32 @@
 package gitops
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ProtonMail/go-crypto/openpgp"
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // GitOps handles git operations for the claims CLI
 type GitOps struct {
 	RepoPath string
 	repo     *git.Repository
-	auth     *http.BasicAuth
+	auth     transport.AuthMethod
+	signKey  *openpgp.Entity // signs commits when set, see SetSigningKey
 }
 
 // Config holds git-related configuration
@@ -53,20 +63,31 @@ func New(repoPath string, user, token string) (*GitOps, error) {
 	return g, nil
 }
 
+// SetAuth replaces the credentials used to push, e.g. with the SSH key from
+// ResolveAuth for an SSH remote
+func (g *GitOps) SetAuth(auth transport.AuthMethod) {
+	g.auth = auth
+}
+
 // Clone clones a repository to a temp directory
 func Clone(url, user, token string) (*GitOps, string, error) {
-	tmpDir, err := os.MkdirTemp("", "claims-gitops-*")
-	if err != nil {
-		return nil, "", fmt.Errorf("creating temp directory: %w", err)
-	}
-
-	var auth *http.BasicAuth
+	var auth transport.AuthMethod
 	if user != "" && token != "" {
 		auth = &http.BasicAuth{
 			Username: user,
 			Password: token,
 		}
 	}
+	return CloneWithAuth(url, auth)
+}
+
+// CloneWithAuth clones a repository to a temp directory using auth, which may
+// be nil for a public repository
+func CloneWithAuth(url string, auth transport.AuthMethod) (*GitOps, string, error) {
+	tmpDir, err := os.MkdirTemp("", "claims-gitops-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("creating temp directory: %w", err)
+	}
 
 	cloneOpts := &git.CloneOptions{
 		URL:      url,
@@ -97,15 +118,7 @@ func (g *GitOps) AddFiles(files []string) error {
 	}
 
 	for _, f := range files {
-		// Resolve to absolute path first, then convert to repo-relative
-		absFile, err := filepath.Abs(f)
-		if err != nil {
-			absFile = f
-		}
-		relPath, err := filepath.Rel(g.RepoPath, absFile)
-		if err != nil {
-			relPath = f
-		}
+		relPath := g.relPath(f)
 
 		// Verify file exists on disk before staging
 		absPath := filepath.Join(g.RepoPath, relPath)
@@ -122,39 +135,190 @@ func (g *GitOps) AddFiles(files []string) error {
 	return nil
 }
 
-// Commit creates a commit with the staged changes
-func (g *GitOps) Commit(message, authorName, authorEmail string) error {
+// relPath converts a file path to a path relative to the repository root
+func (g *GitOps) relPath(f string) string {
+	absFile, err := filepath.Abs(f)
+	if err != nil {
+		absFile = f
+	}
+	relPath, err := filepath.Rel(g.RepoPath, absFile)
+	if err != nil {
+		return f
+	}
+	return relPath
+}
+
+// IgnoredFiles returns the repository-relative paths of files that match a
+// .gitignore rule (or the repository's excludes). AddFiles skips such files
+// without an error, so they never make it into a commit.
+func (g *GitOps) IgnoredFiles(files []string) ([]string, error) {
 	worktree, err := g.repo.Worktree()
 	if err != nil {
-		return fmt.Errorf("getting worktree: %w", err)
+		return nil, fmt.Errorf("getting worktree: %w", err)
 	}
 
-	if authorName == "" {
-		authorName = "claims-cli"
+	patterns, err := gitignore.ReadPatterns(worktree.Filesystem, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading .gitignore: %w", err)
 	}
-	if authorEmail == "" {
-		authorEmail = "claims-cli@automated"
+	patterns = append(patterns, worktree.Excludes...)
+	if len(patterns) == 0 {
+		return nil, nil
 	}
+	matcher := gitignore.NewMatcher(patterns)
 
-	_, err = worktree.Commit(message, &git.CommitOptions{
+	var ignored []string
+	for _, f := range files {
+		rel := filepath.ToSlash(g.relPath(f))
+		if matcher.Match(strings.Split(rel, "/"), false) {
+			ignored = append(ignored, rel)
+		}
+	}
+	return ignored, nil
+}
+
+// HasStagedChanges reports whether the index differs from HEAD
+func (g *GitOps) HasStagedChanges() (bool, error) {
+	worktree, err := g.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("getting worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("getting status: %w", err)
+	}
+
+	for _, s := range status {
+		if s.Staging != git.Unmodified && s.Staging != git.Untracked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasChanges reports whether any of the given files differs from HEAD,
+// including new untracked files. Files unknown to git status are unchanged.
+func (g *GitOps) HasChanges(files []string) (bool, error) {
+	worktree, err := g.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("getting worktree: %w", err)
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("getting status: %w", err)
+	}
+
+	for _, f := range files {
+		absFile, err := filepath.Abs(f)
+		if err != nil {
+			absFile = f
+		}
+		relPath, err := filepath.Rel(g.RepoPath, absFile)
+		if err != nil {
+			relPath = f
+		}
+
+		s, ok := status[filepath.ToSlash(relPath)]
+		if ok && (s.Staging != git.Unmodified || s.Worktree != git.Unmodified) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Default commit author when no git user is configured
+const (
+	DefaultAuthorName  = "claims-cli"
+	DefaultAuthorEmail = "claims-cli@automated"
+)
+
+// AuthorIdentity returns the commit author, filling in the defaults for an
+// empty name or email
+func AuthorIdentity(name, email string) (string, string) {
+	if name == "" {
+		name = DefaultAuthorName
+	}
+	if email == "" {
+		email = DefaultAuthorEmail
+	}
+	return name, email
+}
+
+// AppendSignOff adds a DCO "Signed-off-by: name <email>" trailer to message.
+// The trailer joins an existing trailer block and is not added twice.
+func AppendSignOff(message, name, email string) string {
+	trailer := fmt.Sprintf("Signed-off-by: %s <%s>", name, email)
+	message = strings.TrimRight(message, "\n")
+
+	for _, line := range strings.Split(message, "\n") {
+		if strings.TrimSpace(line) == trailer {
+			return message
+		}
+	}
+
+	// Join the last paragraph if it is a trailer block (never the subject)
+	paragraphs := strings.Split(message, "\n\n")
+	if last := paragraphs[len(paragraphs)-1]; len(paragraphs) > 1 && isTrailerBlock(last) {
+		return message + "\n" + trailer
+	}
+	return message + "\n\n" + trailer
+}
+
+// isTrailerBlock reports whether every line of a paragraph is a git trailer
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLine.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
+
+// trailerLine matches a git trailer such as "Signed-off-by: A <a@example.com>"
+var trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// Commit creates a commit with the staged changes
+func (g *GitOps) Commit(message, authorName, authorEmail string) error {
+	_, err := g.CommitWithHash(message, authorName, authorEmail)
+	return err
+}
+
+// CommitWithHash creates a commit like Commit and returns the new commit hash.
+// The commit is signed if a signing key is set.
+func (g *GitOps) CommitWithHash(message, authorName, authorEmail string) (string, error) {
+	worktree, err := g.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("getting worktree: %w", err)
+	}
+
+	authorName, authorEmail = AuthorIdentity(authorName, authorEmail)
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  authorName,
 			Email: authorEmail,
 			When:  time.Now(),
 		},
+		SignKey: g.signKey,
 	})
 	if err != nil {
-		return fmt.Errorf("committing: %w", err)
+		return "", fmt.Errorf("committing: %w", err)
 	}
 
-	return nil
+	return hash.String(), nil
 }
 
-// GetRemoteURL returns the URL of the given remote (e.g. "origin")
+// GetRemoteURL returns the first URL of the given remote (e.g. "origin").
+// A missing remote is reported as such, wrapping git.ErrRemoteNotFound.
 func (g *GitOps) GetRemoteURL(remoteName string) (string, error) {
 	remote, err := g.repo.Remote(remoteName)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", fmt.Errorf("remote %q not found in %s: %w", remoteName, g.RepoPath, err)
+	}
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("reading remote %q: %w", remoteName, err)
 	}
 	urls := remote.Config().URLs
 	if len(urls) == 0 {
@@ -163,6 +327,20 @@ func (g *GitOps) GetRemoteURL(remoteName string) (string, error) {
 	return urls[0], nil
 }
 
+// RemoteNames returns the names of the repository's remotes, sorted
+func (g *GitOps) RemoteNames() ([]string, error) {
+	remotes, err := g.repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("listing remotes: %w", err)
+	}
+	names := make([]string, 0, len(remotes))
+	for _, r := range remotes {
+		names = append(names, r.Config().Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // Push pushes a specific branch to remote
 func (g *GitOps) Push(remote, branch string) error {
 	if g.auth == nil {
@@ -184,6 +362,82 @@ func (g *GitOps) Push(remote, branch string) error {
 	return nil
 }
 
+// CheckPushAccess verifies that remote is reachable with the configured
+// credentials by listing its refs, like git ls-remote. go-git has no dry-run
+// push, so this confirms reachability and authentication, not write permission.
+func (g *GitOps) CheckPushAccess(remote string) error {
+	if g.auth == nil {
+		return fmt.Errorf("git credentials required for push")
+	}
+
+	r, err := g.repo.Remote(remote)
+	if err != nil {
+		return fmt.Errorf("remote %q: %w", remote, err)
+	}
+	return listRemote(r, g.auth)
+}
+
+// CheckRemoteAccess verifies that the repository at url is reachable with the
+// given credentials, for the clone-based workflow where no local repo exists yet
+func CheckRemoteAccess(url, user, token string) error {
+	if user == "" || token == "" {
+		return fmt.Errorf("git credentials required for push")
+	}
+	return CheckRemoteAccessWithAuth(url, &http.BasicAuth{Username: user, Password: token})
+}
+
+// CheckRemoteAccessWithAuth is CheckRemoteAccess with resolved credentials,
+// e.g. an SSH key
+func CheckRemoteAccessWithAuth(url string, auth transport.AuthMethod) error {
+	if auth == nil {
+		return fmt.Errorf("git credentials required for push")
+	}
+
+	r := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	return listRemote(r, auth)
+}
+
+// listRemote lists the refs of r; an empty repository still counts as reachable
+func listRemote(r *git.Remote, auth transport.AuthMethod) error {
+	_, err := r.List(&git.ListOptions{Auth: auth})
+	if err == nil || errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return nil
+	}
+	return fmt.Errorf("listing %s: %w", r.Config().URLs[0], err)
+}
+
+// FileAtRevision returns the content of path (relative to the repository
+// root) as of rev, which may be a branch, tag or commit hash. If the file does
+// not exist at that revision the error wraps os.ErrNotExist.
+func (g *GitOps) FileAtRevision(rev, path string) ([]byte, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", rev, err)
+	}
+
+	commit, err := g.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", rev, err)
+	}
+
+	file, err := commit.File(filepath.ToSlash(path))
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, fmt.Errorf("%s at %s: %w", path, rev, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", path, rev, err)
+	}
+
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s at %s: %w", path, rev, err)
+	}
+	return []byte(content), nil
+}
+
 // RemoveFiles stages file removals in the worktree
 func (g *GitOps) RemoveFiles(files []string) error {
 	worktree, err := g.repo.Worktree()
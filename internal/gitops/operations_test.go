@@ -1,12 +1,15 @@
 package gitops_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stuttgart-things/claims/internal/gitops"
 )
@@ -320,3 +323,381 @@ func initTestRepo(t *testing.T) string {
 
 	return tmpDir
 }
+
+func TestHasStagedChanges(t *testing.T) {
+	repoPath := initTestRepo(t)
+
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("failed to create GitOps: %v", err)
+	}
+
+	changed, err := g.HasStagedChanges()
+	if err != nil {
+		t.Fatalf("HasStagedChanges() error = %v", err)
+	}
+	if changed {
+		t.Error("expected no staged changes in a clean repo")
+	}
+
+	// Untracked files are not staged changes
+	testFile := filepath.Join(repoPath, "claim.yaml")
+	if err := os.WriteFile(testFile, []byte("kind: Claim"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if changed, _ := g.HasStagedChanges(); changed {
+		t.Error("expected untracked file not to count as staged")
+	}
+
+	if err := g.AddFiles([]string{testFile}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if changed, _ := g.HasStagedChanges(); !changed {
+		t.Error("expected staged changes after AddFiles")
+	}
+
+	if err := g.Commit("add claim", "", ""); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// Re-staging identical content yields no changes
+	if err := os.WriteFile(testFile, []byte("kind: Claim"), 0644); err != nil {
+		t.Fatalf("failed to rewrite test file: %v", err)
+	}
+	if err := g.AddFiles([]string{testFile}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if changed, _ := g.HasStagedChanges(); changed {
+		t.Error("expected no staged changes after re-adding identical content")
+	}
+}
+
+func TestCommitWithHash(t *testing.T) {
+	repoPath := initTestRepo(t)
+
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("failed to create GitOps: %v", err)
+	}
+
+	testFile := filepath.Join(repoPath, "hash-test.yaml")
+	if err := os.WriteFile(testFile, []byte("test: hash"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := g.AddFiles([]string{testFile}); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+
+	hash, err := g.CommitWithHash("hash commit", "Test Author", "test@example.com")
+	if err != nil {
+		t.Fatalf("CommitWithHash() error = %v", err)
+	}
+
+	head, err := g.GetRepo().Head()
+	if err != nil {
+		t.Fatalf("failed to read HEAD: %v", err)
+	}
+	if hash != head.Hash().String() {
+		t.Errorf("CommitWithHash() = %s, want HEAD %s", hash, head.Hash())
+	}
+}
+
+func TestAppendSignOff(t *testing.T) {
+	const trailer = "Signed-off-by: Jane Doe <jane@example.com>"
+
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "subject only",
+			message: "Add claims",
+			want:    "Add claims\n\n" + trailer,
+		},
+		{
+			name:    "subject and body",
+			message: "Add claims\n\nRendered vsphere-vm.\n",
+			want:    "Add claims\n\nRendered vsphere-vm.\n\n" + trailer,
+		},
+		{
+			name:    "joins an existing trailer block",
+			message: "Add claims\n\nCo-authored-by: Bob <bob@example.com>",
+			want:    "Add claims\n\nCo-authored-by: Bob <bob@example.com>\n" + trailer,
+		},
+		{
+			name:    "subject that looks like a trailer",
+			message: "Fix: registry path",
+			want:    "Fix: registry path\n\n" + trailer,
+		},
+		{
+			name:    "already signed off",
+			message: "Add claims\n\n" + trailer + "\n",
+			want:    "Add claims\n\n" + trailer,
+		},
+		{
+			name:    "signed off by someone else",
+			message: "Add claims\n\nSigned-off-by: Bob <bob@example.com>",
+			want:    "Add claims\n\nSigned-off-by: Bob <bob@example.com>\n" + trailer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gitops.AppendSignOff(tt.message, "Jane Doe", "jane@example.com"); got != tt.want {
+				t.Errorf("AppendSignOff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorIdentity(t *testing.T) {
+	if name, email := gitops.AuthorIdentity("", ""); name != gitops.DefaultAuthorName || email != gitops.DefaultAuthorEmail {
+		t.Errorf("AuthorIdentity(\"\", \"\") = %s <%s>, want defaults", name, email)
+	}
+	if name, email := gitops.AuthorIdentity("jane", "jane@example.com"); name != "jane" || email != "jane@example.com" {
+		t.Errorf("AuthorIdentity() = %s <%s>, want jane <jane@example.com>", name, email)
+	}
+}
+
+func TestHasChanges(t *testing.T) {
+	repoPath := initTestRepo(t)
+
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("failed to create GitOps: %v", err)
+	}
+
+	readme := filepath.Join(repoPath, "README.md")
+	if changed, err := g.HasChanges([]string{readme}); err != nil || changed {
+		t.Errorf("expected committed file to be unchanged, got changed=%v err=%v", changed, err)
+	}
+
+	// New untracked files count as changes
+	newFile := filepath.Join(repoPath, "claim.yaml")
+	if err := os.WriteFile(newFile, []byte("kind: Claim"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if changed, _ := g.HasChanges([]string{newFile}); !changed {
+		t.Error("expected untracked file to count as a change")
+	}
+
+	// Only the given files are considered
+	if changed, _ := g.HasChanges([]string{readme}); changed {
+		t.Error("expected unrelated untracked file to be ignored")
+	}
+
+	// Modified tracked files count as changes
+	if err := os.WriteFile(readme, []byte("# Changed"), 0644); err != nil {
+		t.Fatalf("failed to modify README: %v", err)
+	}
+	if changed, _ := g.HasChanges([]string{readme}); !changed {
+		t.Error("expected modified file to count as a change")
+	}
+}
+
+func TestCheckPushAccess(t *testing.T) {
+	// A bare repo stands in for the remote
+	barePath := t.TempDir()
+	if _, err := git.PlainInit(barePath, true); err != nil {
+		t.Fatalf("failed to init bare repo: %v", err)
+	}
+
+	repoPath := initTestRepo(t)
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{barePath}}); err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "missing", URLs: []string{filepath.Join(t.TempDir(), "nope")}}); err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		token   string
+		remote  string
+		wantErr bool
+	}{
+		{name: "with auth", user: "testuser", token: "testtoken", remote: "origin"},
+		{name: "without auth", remote: "origin", wantErr: true},
+		{name: "unknown remote", user: "testuser", token: "testtoken", remote: "upstream", wantErr: true},
+		{name: "unreachable remote", user: "testuser", token: "testtoken", remote: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := gitops.New(repoPath, tt.user, tt.token)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			err = g.CheckPushAccess(tt.remote)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckPushAccess() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRemoteAccess(t *testing.T) {
+	barePath := t.TempDir()
+	if _, err := git.PlainInit(barePath, true); err != nil {
+		t.Fatalf("failed to init bare repo: %v", err)
+	}
+
+	if err := gitops.CheckRemoteAccess(barePath, "testuser", "testtoken"); err != nil {
+		t.Errorf("expected access with credentials, got %v", err)
+	}
+	if err := gitops.CheckRemoteAccess(barePath, "", ""); err == nil {
+		t.Error("expected error without credentials")
+	}
+}
+
+func TestIgnoredFiles(t *testing.T) {
+	dir := initTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.tmp\nbuild/\n!keep.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gitops.New(dir, "", "")
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	files := []string{
+		filepath.Join(dir, "claims", "vm.yaml"),
+		filepath.Join(dir, "scratch.tmp"),
+		filepath.Join(dir, "keep.tmp"),
+		filepath.Join(dir, "build", "out.yaml"),
+	}
+	got, err := g.IgnoredFiles(files)
+	if err != nil {
+		t.Fatalf("IgnoredFiles() error: %v", err)
+	}
+
+	want := []string{"scratch.tmp", "build/out.yaml"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IgnoredFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestFileAtRevision(t *testing.T) {
+	repoPath := initTestRepo(t)
+
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("failed to create GitOps: %v", err)
+	}
+	base, err := g.GetCurrentBranch()
+	if err != nil {
+		t.Fatalf("GetCurrentBranch() error = %v", err)
+	}
+
+	// Change README in a second commit so base~1 and HEAD differ
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AddFiles([]string{filepath.Join(repoPath, "README.md")}); err != nil {
+		t.Fatalf("AddFiles() error = %v", err)
+	}
+	if err := g.Commit("Change README", "Test", "test@test.com"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		rev      string
+		path     string
+		want     string
+		wantErr  bool
+		notExist bool
+	}{
+		{name: "branch", rev: base, path: "README.md", want: "# Changed"},
+		{name: "previous commit", rev: "HEAD~1", path: "README.md", want: "# Test Repo"},
+		{name: "missing file", rev: "HEAD", path: "params.yaml", wantErr: true, notExist: true},
+		{name: "unknown revision", rev: "does-not-exist", path: "README.md", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.FileAtRevision(tt.rev, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FileAtRevision() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.notExist && !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("expected os.ErrNotExist, got %v", err)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("FileAtRevision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRemoteURL(t *testing.T) {
+	repoPath := initTestRepo(t)
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"https://github.com/org/repo.git", "https://mirror.example.com/org/repo.git"},
+	}); err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	url, err := g.GetRemoteURL("origin")
+	if err != nil {
+		t.Fatalf("GetRemoteURL(origin) error = %v", err)
+	}
+	if url != "https://github.com/org/repo.git" {
+		t.Errorf("GetRemoteURL(origin) = %q, want the first URL", url)
+	}
+
+	_, err = g.GetRemoteURL("upstream")
+	if err == nil {
+		t.Fatal("expected error for missing remote")
+	}
+	if !errors.Is(err, git.ErrRemoteNotFound) {
+		t.Errorf("expected git.ErrRemoteNotFound, got %v", err)
+	}
+}
+
+func TestRemoteNames(t *testing.T) {
+	repoPath := initTestRepo(t)
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := gitops.New(repoPath, "", "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	names, err := g.RemoteNames()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("RemoteNames() = %v, %v; want none", names, err)
+	}
+
+	for _, name := range []string{"upstream", "origin", "fork"} {
+		if _, err := repo.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{"https://github.com/" + name + "/repo.git"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err = g.RemoteNames()
+	if err != nil {
+		t.Fatalf("RemoteNames() error = %v", err)
+	}
+	if want := []string{"fork", "origin", "upstream"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("RemoteNames() = %v, want %v", names, want)
+	}
+}
@@ -2,6 +2,7 @@ package gitops
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -96,6 +97,84 @@ func AddLabelsToPR(prNumber int, labels []string, repoPath string) error {
 	return nil
 }
 
+// ListLabels returns the label names defined in the repository via gh CLI
+func ListLabels(repoPath string) ([]string, error) {
+	cmd := exec.Command("gh", "label", "list", "--json", "name", "--limit", "1000")
+	cmd.Dir = repoPath
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := stderr.String()
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return nil, fmt.Errorf("gh label list failed: %s", errMsg)
+	}
+
+	return parseLabelList(stdout.Bytes())
+}
+
+// parseLabelList parses the JSON output of `gh label list --json name`
+func parseLabelList(data []byte) ([]string, error) {
+	var items []struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("parsing label list: %w", err)
+	}
+
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, item.Name)
+	}
+	return labels, nil
+}
+
+// CreateLabel creates a label in the repository via gh CLI
+func CreateLabel(name, repoPath string) error {
+	cmd := exec.Command("gh", "label", "create", name)
+	cmd.Dir = repoPath
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("creating label %q failed: %s", name, stderr.String())
+	}
+	return nil
+}
+
+// ClassifyLabels splits requested labels into those that exist in the
+// repository and those that are missing. GitHub label names are matched
+// case-insensitively; existing labels keep the repository's spelling.
+// Empty and duplicate requests are dropped.
+func ClassifyLabels(requested, existing []string) (found, missing []string) {
+	known := make(map[string]string, len(existing))
+	for _, l := range existing {
+		known[strings.ToLower(l)] = l
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range requested {
+		l = strings.TrimSpace(l)
+		key := strings.ToLower(l)
+		if l == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if name, ok := known[key]; ok {
+			found = append(found, name)
+		} else {
+			missing = append(missing, l)
+		}
+	}
+	return found, missing
+}
+
 // CheckGHAuth verifies gh CLI is authenticated
 func CheckGHAuth() error {
 	cmd := exec.Command("gh", "auth", "status")
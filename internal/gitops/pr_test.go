@@ -1,6 +1,7 @@
 package gitops_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stuttgart-things/claims/internal/gitops"
@@ -145,3 +146,59 @@ func TestFilterEmptyLabels(t *testing.T) {
 		t.Errorf("unexpected filtered labels: %v", filtered)
 	}
 }
+
+func TestClassifyLabels(t *testing.T) {
+	existing := []string{"infrastructure", "Automated", "bug"}
+
+	tests := []struct {
+		name        string
+		existing    []string
+		requested   []string
+		wantFound   []string
+		wantMissing []string
+	}{
+		{
+			name:      "all exist",
+			existing:  existing,
+			requested: []string{"infrastructure", "bug"},
+			wantFound: []string{"infrastructure", "bug"},
+		},
+		{
+			name:        "some missing",
+			existing:    existing,
+			requested:   []string{"infrastructure", "claims"},
+			wantFound:   []string{"infrastructure"},
+			wantMissing: []string{"claims"},
+		},
+		{
+			name:      "case-insensitive match keeps repo spelling",
+			existing:  existing,
+			requested: []string{"automated"},
+			wantFound: []string{"Automated"},
+		},
+		{
+			name:        "empty and duplicates dropped",
+			existing:    existing,
+			requested:   []string{"", " bug ", "BUG", "new", "new"},
+			wantFound:   []string{"bug"},
+			wantMissing: []string{"new"},
+		},
+		{
+			name:        "no existing labels",
+			requested:   []string{"claims"},
+			wantMissing: []string{"claims"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, missing := gitops.ClassifyLabels(tt.requested, tt.existing)
+			if strings.Join(found, ",") != strings.Join(tt.wantFound, ",") {
+				t.Errorf("found = %v, want %v", found, tt.wantFound)
+			}
+			if strings.Join(missing, ",") != strings.Join(tt.wantMissing, ",") {
+				t.Errorf("missing = %v, want %v", missing, tt.wantMissing)
+			}
+		})
+	}
+}
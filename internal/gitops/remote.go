@@ -0,0 +1,85 @@
+package gitops
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stuttgart-things/claims/internal/redact"
+)
+
+// RemoteInfo is a git remote URL split into its parts. For nested groups
+// (GitLab subgroups) Owner holds the full namespace, e.g. "org/subgroup".
+type RemoteInfo struct {
+	Scheme string // https, http, ssh or git; scp-like URLs report ssh
+	Host   string
+	Port   string // empty unless given explicitly
+	Owner  string
+	Repo   string // without .git suffix
+}
+
+// Slug returns "owner/repo" with the full owner namespace
+func (r RemoteInfo) Slug() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// IsSSHURL reports whether rawURL is reached over SSH: ssh:// URLs and
+// scp-like git@host:owner/repo.git remotes
+func IsSSHURL(rawURL string) bool {
+	ep, err := transport.NewEndpoint(rawURL)
+	return err == nil && ep.Protocol == "ssh"
+}
+
+// ParseRemoteURL parses HTTPS (https://host[:port]/owner/repo.git), ssh://
+// (ssh://git@host[:port]/owner/repo.git) and scp-like (git@host:owner/repo.git)
+// remote URLs. Credentials in the URL are dropped. Local paths and URLs
+// without an owner and repo are rejected.
+func ParseRemoteURL(rawURL string) (RemoteInfo, error) {
+	if rawURL == "" {
+		return RemoteInfo{}, fmt.Errorf("empty remote URL")
+	}
+
+	var info RemoteInfo
+	var path string
+
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return RemoteInfo{}, fmt.Errorf("parsing remote URL: %w", err)
+		}
+		if u.Scheme == "file" {
+			return RemoteInfo{}, fmt.Errorf("unsupported remote URL %q: local repository", rawURL)
+		}
+		info.Scheme = u.Scheme
+		info.Host = u.Hostname()
+		info.Port = u.Port()
+		path = u.Path
+	} else {
+		// scp-like syntax: [user@]host:owner/repo
+		hostPart, p, ok := strings.Cut(rawURL, ":")
+		if !ok || strings.Contains(hostPart, "/") {
+			return RemoteInfo{}, fmt.Errorf("unsupported remote URL %q", rawURL)
+		}
+		if i := strings.LastIndex(hostPart, "@"); i >= 0 {
+			hostPart = hostPart[i+1:]
+		}
+		info.Scheme = "ssh"
+		info.Host = hostPart
+		path = p
+	}
+
+	if info.Host == "" {
+		return RemoteInfo{}, fmt.Errorf("remote URL %q has no host", redact.String(rawURL))
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return RemoteInfo{}, fmt.Errorf("remote URL %q has no owner/repo path", redact.String(rawURL))
+	}
+	info.Owner = path[:i]
+	info.Repo = path[i+1:]
+
+	return info, nil
+}
@@ -0,0 +1,103 @@
+package gitops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// ResolveSigningKey loads the OpenPGP key commits are signed with from key,
+// else GIT_SIGNING_KEY. The key is an ASCII-armored private key, the path of
+// a file holding one, or the ID of a key in the local gpg keyring. An
+// encrypted key is unlocked with passphrase, else GIT_SIGNING_KEY_PASSPHRASE.
+// Signing was asked for, so a missing key is an error rather than a reason
+// to commit unsigned.
+func ResolveSigningKey(key, passphrase string) (*openpgp.Entity, error) {
+	if key == "" {
+		key = os.Getenv("GIT_SIGNING_KEY")
+	}
+	if key == "" {
+		return nil, fmt.Errorf("commit signing requested but no GPG key found:\nset --gpg-key or GIT_SIGNING_KEY to an armored private key, its file or a key ID")
+	}
+	if passphrase == "" {
+		passphrase = os.Getenv("GIT_SIGNING_KEY_PASSPHRASE")
+	}
+
+	armored, err := signingKeyArmor(key)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("reading GPG key: %w", err)
+	}
+
+	var entity *openpgp.Entity
+	for _, e := range entities {
+		if e.PrivateKey != nil {
+			entity = e
+			break
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("GPG key %s has no private key to sign with", keyLabel(key))
+	}
+
+	if entity.PrivateKey.Encrypted {
+		if passphrase == "" {
+			return nil, fmt.Errorf("GPG key %s is encrypted: set GIT_SIGNING_KEY_PASSPHRASE", keyLabel(key))
+		}
+		if err := entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("decrypting GPG key %s: %w", keyLabel(key), err)
+		}
+	}
+	return entity, nil
+}
+
+// signingKeyArmor returns the armored private key for key: key itself when
+// it is armored, else the content of the file it names, else the key
+// exported from the gpg keyring
+func signingKeyArmor(key string) (string, error) {
+	if strings.Contains(key, "-----BEGIN PGP") {
+		return key, nil
+	}
+	if data, err := os.ReadFile(key); err == nil {
+		return string(data), nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("reading GPG key: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("gpg", "--batch", "--armor", "--export-secret-keys", key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("GPG key %s is neither a file nor an armored key, and gpg is not installed to export it", key)
+		}
+		return "", fmt.Errorf("exporting GPG key %s: %s", key, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return "", fmt.Errorf("no secret key %s in the gpg keyring", key)
+	}
+	return stdout.String(), nil
+}
+
+// keyLabel names key in messages without echoing an armored key
+func keyLabel(key string) string {
+	if strings.Contains(key, "-----BEGIN PGP") {
+		return "(armored)"
+	}
+	return key
+}
+
+// SetSigningKey makes Commit and CommitWithHash sign commits with key; nil
+// commits unsigned
+func (g *GitOps) SetSigningKey(key *openpgp.Entity) {
+	g.signKey = key
+}
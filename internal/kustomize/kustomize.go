@@ -1,9 +1,14 @@
 package kustomize
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/stuttgart-things/claims/internal/yamlnode"
 	"gopkg.in/yaml.v3"
 )
 
@@ -12,6 +17,45 @@ type Kustomization struct {
 	APIVersion string   `yaml:"apiVersion,omitempty"`
 	Kind       string   `yaml:"kind,omitempty"`
 	Resources  []string `yaml:"resources"`
+	Bases      []string `yaml:"bases,omitempty"`
+	Generators []string `yaml:"generators,omitempty"`
+
+	// doc is the parsed file, kept so Save preserves comments and other keys
+	doc *yamlnode.Document
+	// resourcesKey is the key Resources was loaded from; "" = "resources"
+	resourcesKey string
+}
+
+// Keys a kustomization lists its resources under. The deprecated bases key
+// is only used by older kustomizations without resources.
+const (
+	ResourcesKey = "resources"
+	BasesKey     = "bases"
+)
+
+// Defaults for a newly created kustomization.yaml
+const (
+	DefaultAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	DefaultKind       = "Kustomization"
+)
+
+// NewKustomization returns an empty Kustomization with apiVersion and kind
+// set, so a saved file is valid for kustomize build
+func NewKustomization() *Kustomization {
+	return &Kustomization{
+		APIVersion: DefaultAPIVersion,
+		Kind:       DefaultKind,
+		Resources:  []string{},
+	}
+}
+
+// LoadOrNew loads the kustomization at path, or returns NewKustomization()
+// if the file doesn't exist yet. An existing file is never reset.
+func LoadOrNew(path string) (*Kustomization, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return NewKustomization(), nil
+	}
+	return Load(path)
 }
 
 // Load reads and parses a kustomization.yaml file
@@ -21,17 +65,42 @@ func Load(path string) (*Kustomization, error) {
 		return nil, fmt.Errorf("reading kustomization file: %w", err)
 	}
 
+	doc, err := yamlnode.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing kustomization file: %w", err)
+	}
+
 	var k Kustomization
-	if err := yaml.Unmarshal(data, &k); err != nil {
+	if err := doc.Root.Decode(&k); err != nil {
 		return nil, fmt.Errorf("parsing kustomization file: %w", err)
 	}
+	k.doc = doc
+
+	// Older kustomizations list their resources under bases; operate on
+	// those unless resources has entries
+	if len(k.Resources) == 0 && len(k.Bases) > 0 {
+		k.Resources = k.Bases
+		k.resourcesKey = BasesKey
+	}
 
 	return &k, nil
 }
 
-// Save writes a Kustomization to a YAML file
+// ResourcesKey returns the key Resources is read from and saved to:
+// ResourcesKey, or BasesKey for a kustomization that only lists bases
+func (k *Kustomization) ResourcesKey() string {
+	if k.resourcesKey == "" {
+		return ResourcesKey
+	}
+	return k.resourcesKey
+}
+
+// Save writes a Kustomization to a YAML file. A kustomization obtained from
+// Load is written back with its comments, key order and any other keys
+// intact; only apiVersion, kind and the resources (or bases, see
+// ResourcesKey) and generators lists are updated.
 func Save(path string, k *Kustomization) error {
-	data, err := yaml.Marshal(k)
+	data, err := marshal(k)
 	if err != nil {
 		return fmt.Errorf("marshalling kustomization: %w", err)
 	}
@@ -43,14 +112,89 @@ func Save(path string, k *Kustomization) error {
 	return nil
 }
 
-// AddResource adds a resource entry if it doesn't already exist
-func AddResource(k *Kustomization, resource string) {
+// marshal encodes k, editing the loaded document in place when there is one
+func marshal(k *Kustomization) ([]byte, error) {
+	m := k.doc.Mapping()
+	if m == nil {
+		return yaml.Marshal(k)
+	}
+
+	if k.APIVersion != "" {
+		yamlnode.SetScalar(m, "apiVersion", k.APIVersion)
+	}
+	if k.Kind != "" {
+		yamlnode.SetScalar(m, "kind", k.Kind)
+	}
+	syncSequence(m, k.ResourcesKey(), k.Resources)
+	if len(k.Generators) > 0 || yamlnode.MappingValue(m, "generators") != nil {
+		syncSequence(m, "generators", k.Generators)
+	}
+
+	return k.doc.Encode()
+}
+
+// syncSequence rewrites the string sequence under key to match values,
+// reusing the existing item nodes (and their comments) for entries that remain
+func syncSequence(m *yaml.Node, key string, values []string) {
+	seq := yamlnode.MappingValue(m, key)
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		yamlnode.SetMappingValue(m, key, seq)
+	}
+
+	existing := make(map[string][]*yaml.Node)
+	for _, n := range seq.Content {
+		existing[n.Value] = append(existing[n.Value], n)
+	}
+
+	// An empty list is usually written as [], switch to block style once filled
+	if len(seq.Content) == 0 {
+		seq.Style = 0
+	}
+
+	content := make([]*yaml.Node, 0, len(values))
+	for _, r := range values {
+		if nodes := existing[r]; len(nodes) > 0 {
+			content = append(content, nodes[0])
+			existing[r] = nodes[1:]
+			continue
+		}
+		content = append(content, yamlnode.StringNode(r))
+	}
+	seq.Content = content
+}
+
+// HasResource reports whether k lists resource
+func HasResource(k *Kustomization, resource string) bool {
 	for _, r := range k.Resources {
 		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// AddResource adds a resource entry if it doesn't already exist
+func AddResource(k *Kustomization, resource string) {
+	if !HasResource(k, resource) {
+		k.Resources = append(k.Resources, resource)
+	}
+}
+
+// SortResources sorts the resources of k, so entries added by different
+// renders end up in the same order and diffs stay small
+func SortResources(k *Kustomization) {
+	sort.Strings(k.Resources)
+}
+
+// AddGenerator adds a generator entry if it doesn't already exist
+func AddGenerator(k *Kustomization, generator string) {
+	for _, g := range k.Generators {
+		if g == generator {
 			return
 		}
 	}
-	k.Resources = append(k.Resources, resource)
+	k.Generators = append(k.Generators, generator)
 }
 
 // RemoveResource removes a resource entry by value.
@@ -64,3 +208,29 @@ func RemoveResource(k *Kustomization, resource string) error {
 	}
 	return fmt.Errorf("resource %q not found in kustomization", resource)
 }
+
+// IsRemote reports whether a resource is a remote reference (URL or repo
+// path such as github.com/org/repo) rather than a local file or directory
+func IsRemote(resource string) bool {
+	return strings.Contains(resource, "://") || strings.HasPrefix(resource, "github.com/") || strings.HasPrefix(resource, "git@")
+}
+
+// DanglingResources returns the local resources of k that don't exist,
+// resolved relative to dir, the directory of the kustomization file.
+// Remote resources are not checked.
+func DanglingResources(dir string, k *Kustomization) []string {
+	var dangling []string
+	for _, r := range k.Resources {
+		if IsRemote(r) {
+			continue
+		}
+		path := r
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, r)
+		}
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			dangling = append(dangling, r)
+		}
+	}
+	return dangling
+}
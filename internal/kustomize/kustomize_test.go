@@ -1,8 +1,13 @@
 package kustomize
 
 import (
+	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestLoadAndSave(t *testing.T) {
@@ -47,6 +52,45 @@ func TestAddResource(t *testing.T) {
 	}
 }
 
+func TestSortResources(t *testing.T) {
+	orders := [][]string{
+		{"web", "app-pvc", "db-pvc", "cache"},
+		{"db-pvc", "cache", "web", "app-pvc"},
+		{"cache", "web", "app-pvc", "db-pvc", "web"},
+	}
+
+	var saved []string
+	for _, order := range orders {
+		path := filepath.Join(t.TempDir(), "kustomization.yaml")
+		k := NewKustomization()
+		for _, r := range order {
+			AddResource(k, r)
+		}
+		SortResources(k)
+		if err := Save(path, k); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, string(data))
+	}
+
+	for i, s := range saved[1:] {
+		if s != saved[0] {
+			t.Errorf("order %d saved\n%s\nwant\n%s", i+1, s, saved[0])
+		}
+	}
+	var k Kustomization
+	if err := yaml.Unmarshal([]byte(saved[0]), &k); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"app-pvc", "cache", "db-pvc", "web"}; !reflect.DeepEqual(k.Resources, want) {
+		t.Errorf("Resources = %v, want %v", k.Resources, want)
+	}
+}
+
 func TestRemoveResource(t *testing.T) {
 	k := &Kustomization{Resources: []string{"a", "b", "c"}}
 
@@ -74,3 +118,331 @@ func TestLoadNotFound(t *testing.T) {
 		t.Fatal("expected error for missing file")
 	}
 }
+
+func TestNewKustomization(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+
+	k := NewKustomization()
+	AddResource(k, "app-pvc")
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.APIVersion != "kustomize.config.k8s.io/v1beta1" {
+		t.Errorf("expected apiVersion kustomize.config.k8s.io/v1beta1, got %q", loaded.APIVersion)
+	}
+	if loaded.Kind != "Kustomization" {
+		t.Errorf("expected kind Kustomization, got %q", loaded.Kind)
+	}
+	if len(loaded.Resources) != 1 || loaded.Resources[0] != "app-pvc" {
+		t.Errorf("unexpected resources: %v", loaded.Resources)
+	}
+}
+
+func TestLoadOrNew(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+
+	// Missing file: a fresh kustomization with defaults
+	k, err := LoadOrNew(path)
+	if err != nil {
+		t.Fatalf("LoadOrNew: %v", err)
+	}
+	if k.APIVersion != DefaultAPIVersion || k.Kind != DefaultKind || len(k.Resources) != 0 {
+		t.Errorf("unexpected new kustomization: %+v", k)
+	}
+
+	// Existing file: loaded as is
+	AddResource(k, "app-pvc")
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	k, err = LoadOrNew(path)
+	if err != nil {
+		t.Fatalf("LoadOrNew: %v", err)
+	}
+	if len(k.Resources) != 1 || k.Resources[0] != "app-pvc" {
+		t.Errorf("expected existing resources to be kept, got %v", k.Resources)
+	}
+}
+
+func TestSavePreservesCommentsAndOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+
+	original := `# Managed by hand, resources are added by claims
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: apps # all claims live here
+resources:
+  # storage
+  - app-pvc # primary volume
+  - db-pvc
+commonLabels:
+  team: platform
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	AddResource(k, "cache-pvc")
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	for _, want := range []string{
+		"# Managed by hand, resources are added by claims",
+		"namespace: apps # all claims live here",
+		"# storage",
+		"- app-pvc # primary volume",
+		"- cache-pvc",
+		"team: platform",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q to survive Save, got:\n%s", want, got)
+		}
+	}
+
+	// Keys keep their original order
+	if !(strings.Index(got, "namespace:") < strings.Index(got, "resources:") &&
+		strings.Index(got, "resources:") < strings.Index(got, "commonLabels:")) {
+		t.Errorf("expected original key order, got:\n%s", got)
+	}
+
+	// Removing an entry keeps the comments of the others
+	k, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := RemoveResource(k, "db-pvc"); err != nil {
+		t.Fatalf("RemoveResource: %v", err)
+	}
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "db-pvc") || !strings.Contains(string(data), "- app-pvc # primary volume") {
+		t.Errorf("unexpected content after RemoveResource:\n%s", data)
+	}
+}
+
+func TestRemoveResourceKeepsOtherFields(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+
+	original := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: apps
+resources:
+  - web
+  - db
+patches:
+  - path: replicas.yaml
+    target:
+      kind: Deployment
+      name: web
+images:
+  - name: nginx
+    newTag: "1.27"
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := RemoveResource(k, "db"); err != nil {
+		t.Fatalf("RemoveResource: %v", err)
+	}
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("saved kustomization is not valid YAML: %v\n%s", err, data)
+	}
+	want := map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"namespace":  "apps",
+		"resources":  []any{"web"},
+		"patches": []any{map[string]any{
+			"path":   "replicas.yaml",
+			"target": map[string]any{"kind": "Deployment", "name": "web"},
+		}},
+		"images": []any{map[string]any{"name": "nginx", "newTag": "1.27"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("saved kustomization = %v, want %v", got, want)
+	}
+}
+
+func TestAddGenerator(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+
+	original := `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - app-pvc
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	AddGenerator(k, "ksops.yaml")
+	AddGenerator(k, "ksops.yaml") // duplicate is a no-op
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Generators) != 1 || loaded.Generators[0] != "ksops.yaml" {
+		t.Errorf("expected generators [ksops.yaml], got %v", loaded.Generators)
+	}
+	if len(loaded.Resources) != 1 || loaded.Resources[0] != "app-pvc" {
+		t.Errorf("expected resources to be kept, got %v", loaded.Resources)
+	}
+
+	// A kustomization without generators doesn't gain an empty key
+	plain := filepath.Join(dir, "plain.yaml")
+	if err := Save(plain, NewKustomization()); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(plain)
+	if strings.Contains(string(data), "generators") {
+		t.Errorf("unexpected generators key:\n%s", data)
+	}
+}
+
+func TestDanglingResources(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "db-secret.enc.yaml"), []byte("kind: Secret\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k := &Kustomization{Resources: []string{
+		"web",
+		"removed-vm",
+		"db-secret.enc.yaml",
+		"gone.yaml",
+		"https://github.com/org/repo/deploy?ref=v1",
+		"github.com/org/repo/deploy",
+	}}
+
+	got := DanglingResources(dir, k)
+	want := []string{"removed-vm", "gone.yaml"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("DanglingResources() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadBasesFallback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+	original := "# legacy layout\nbases:\n  - web # frontend\n  - db\nnamespace: apps\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if k.ResourcesKey() != BasesKey {
+		t.Errorf("ResourcesKey() = %q, want %q", k.ResourcesKey(), BasesKey)
+	}
+	if !reflect.DeepEqual(k.Resources, []string{"web", "db"}) {
+		t.Errorf("Resources = %v, want the bases", k.Resources)
+	}
+	if !HasResource(k, "db") || HasResource(k, "cache") {
+		t.Errorf("HasResource() doesn't match the bases %v", k.Resources)
+	}
+
+	if err := RemoveResource(k, "db"); err != nil {
+		t.Fatalf("RemoveResource: %v", err)
+	}
+	AddResource(k, "cache")
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "# legacy layout\nbases:\n  - web # frontend\n  - cache\nnamespace: apps\n"
+	if string(data) != want {
+		t.Errorf("saved kustomization =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestLoadResourcesAndBases(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kustomization.yaml")
+	original := "resources:\n  - web\nbases:\n  - ../base\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if k.ResourcesKey() != ResourcesKey {
+		t.Errorf("ResourcesKey() = %q, want %q when resources has entries", k.ResourcesKey(), ResourcesKey)
+	}
+	if HasResource(k, "../base") {
+		t.Error("expected bases to be left out when resources has entries")
+	}
+
+	AddResource(k, "db")
+	if err := Save(path, k); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "resources:\n  - web\n  - db\nbases:\n  - ../base\n"; string(data) != want {
+		t.Errorf("saved kustomization =\n%s\nwant\n%s", data, want)
+	}
+
+	// An empty resources list falls back to bases
+	if err := os.WriteFile(path, []byte("resources: []\nbases:\n  - ../base\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	k, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if k.ResourcesKey() != BasesKey || !HasResource(k, "../base") {
+		t.Errorf("expected bases with an empty resources list, got key %q and %v", k.ResourcesKey(), k.Resources)
+	}
+}
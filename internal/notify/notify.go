@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a webhook delivery
+const DefaultTimeout = 10 * time.Second
+
+// Event summarizes a finished render, delete or encrypt run
+type Event struct {
+	Command   string    `json:"command"` // render, delete or encrypt
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	Templates []string  `json:"templates,omitempty"`
+	Claims    []string  `json:"claims,omitempty"`
+	Paths     []string  `json:"paths,omitempty"`
+	Commit    string    `json:"commit,omitempty"`
+	PRURL     string    `json:"prUrl,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Webhook posts events as JSON to a URL
+type Webhook struct {
+	URL        string
+	Headers    map[string]string // extra request headers, e.g. Authorization
+	HTTPClient *http.Client
+}
+
+// NewWebhook creates a webhook for url sending the given headers
+func NewWebhook(url string, headers map[string]string) *Webhook {
+	return &Webhook{
+		URL:        url,
+		Headers:    headers,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// ParseHeaders parses key=value header assignments
+func ParseHeaders(raw []string) (map[string]string, error) {
+	headers := make(map[string]string, len(raw))
+	for _, kv := range raw {
+		key, value, ok := strings.Cut(kv, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid webhook header %q: expected key=value", kv)
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// Send posts event to the webhook URL. Any response other than 2xx is an error.
+func (w *Webhook) Send(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshalling event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.Headers {
+		req.Header.Set(key, value)
+	}
+
+	client := w.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
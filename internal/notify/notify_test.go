@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookSend(t *testing.T) {
+	var got map[string]any
+	var auth, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		auth = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	event := Event{
+		Command:   "render",
+		Success:   true,
+		Templates: []string{"vsphere-vm"},
+		Claims:    []string{"web"},
+		Paths:     []string{"claims/infra/web.yaml"},
+		Commit:    "abc123",
+		PRURL:     "https://github.com/org/repo/pull/1",
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	webhook := NewWebhook(server.URL, map[string]string{"Authorization": "Bearer secret"})
+	if err := webhook.Send(context.Background(), event); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	want := map[string]any{
+		"command":   "render",
+		"success":   true,
+		"templates": []any{"vsphere-vm"},
+		"claims":    []any{"web"},
+		"paths":     []any{"claims/infra/web.yaml"},
+		"commit":    "abc123",
+		"prUrl":     "https://github.com/org/repo/pull/1",
+		"timestamp": "2025-01-01T00:00:00Z",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %v, want %v", got, want)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("expected the Authorization header, got %q", auth)
+	}
+	if contentType != "application/json" {
+		t.Errorf("expected a JSON content type, got %q", contentType)
+	}
+}
+
+func TestWebhookSend_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, nil).Send(context.Background(), Event{Command: "delete"})
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "bad token") {
+		t.Errorf("expected a 401 error, got %v", err)
+	}
+
+	server.Close()
+	if err := NewWebhook(server.URL, nil).Send(context.Background(), Event{}); err == nil {
+		t.Error("expected a connection error")
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders([]string{"Authorization=Bearer a=b", " X-Team = infra "})
+	if err != nil {
+		t.Fatalf("ParseHeaders: %v", err)
+	}
+	want := map[string]string{"Authorization": "Bearer a=b", "X-Team": "infra"}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+
+	for _, raw := range []string{"no-equals", "=value"} {
+		if _, err := ParseHeaders([]string{raw}); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
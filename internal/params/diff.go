@@ -0,0 +1,44 @@
+package params
+
+import "reflect"
+
+// ChangedTemplates returns the entries of current that have no identical
+// entry (same template, parameters and secrets) in base. Entries are matched
+// regardless of position, so reordering a file changes nothing, and each base
+// entry matches at most one current entry.
+func ChangedTemplates(current, base []TemplateParams) []TemplateParams {
+	used := make([]bool, len(base))
+	var changed []TemplateParams
+
+	for _, cur := range current {
+		matched := false
+		for i, b := range base {
+			if used[i] || !sameTemplateParams(cur, b) {
+				continue
+			}
+			used[i] = true
+			matched = true
+			break
+		}
+		if !matched {
+			changed = append(changed, cur)
+		}
+	}
+
+	return changed
+}
+
+// sameTemplateParams reports whether two entries render identically
+func sameTemplateParams(a, b TemplateParams) bool {
+	return a.Name == b.Name &&
+		equalMaps(a.Parameters, b.Parameters) &&
+		equalMaps(a.Secrets, b.Secrets)
+}
+
+// equalMaps compares maps treating nil and empty as equal
+func equalMaps[V any](a, b map[string]V) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
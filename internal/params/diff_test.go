@@ -0,0 +1,123 @@
+package params
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChangedTemplates(t *testing.T) {
+	base := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+      cpu: 2
+  - name: vsphere-vm
+    parameters:
+      name: web-2
+      cpu: 2
+  - name: postgres
+    parameters:
+      name: db
+`
+
+	tests := []struct {
+		name    string
+		current string
+		want    []string // resource names of the selected entries
+	}{
+		{
+			name:    "unchanged",
+			current: base,
+		},
+		{
+			name: "reordered entries are unchanged",
+			current: `templates:
+  - name: postgres
+    parameters:
+      name: db
+  - name: vsphere-vm
+    parameters:
+      cpu: 2
+      name: web-2
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+      cpu: 2
+`,
+		},
+		{
+			name: "changed parameter",
+			current: `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+      cpu: 4
+  - name: vsphere-vm
+    parameters:
+      name: web-2
+      cpu: 2
+  - name: postgres
+    parameters:
+      name: db
+`,
+			want: []string{"web-1"},
+		},
+		{
+			name: "added entry",
+			current: base + `  - name: redis
+    parameters:
+      name: cache
+`,
+			want: []string{"cache"},
+		},
+		{
+			name: "duplicated entry counts once",
+			current: base + `  - name: postgres
+    parameters:
+      name: db
+`,
+			want: []string{"db"},
+		},
+		{
+			name: "removed entry selects nothing",
+			current: `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+      cpu: 2
+`,
+		},
+	}
+
+	basePF, err := Parse(strings.NewReader(base), ".yaml")
+	if err != nil {
+		t.Fatalf("Parse(base) error = %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPF, err := Parse(strings.NewReader(tt.current), ".yaml")
+			if err != nil {
+				t.Fatalf("Parse(current) error = %v", err)
+			}
+
+			var got []string
+			for _, tp := range ChangedTemplates(currentPF.Templates, basePF.Templates) {
+				got = append(got, tp.Parameters["name"].(string))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedTemplates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangedTemplates_Secrets(t *testing.T) {
+	base := []TemplateParams{{Name: "postgres", Secrets: map[string]string{"password": "old"}}}
+	current := []TemplateParams{{Name: "postgres", Secrets: map[string]string{"password": "new"}}}
+
+	if got := ChangedTemplates(current, base); len(got) != 1 {
+		t.Errorf("expected a changed secret to select the entry, got %d entries", len(got))
+	}
+}
@@ -0,0 +1,98 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Duplicate is a group of entries that render the same claim: the same
+// template and the same name parameter
+type Duplicate struct {
+	Template     string
+	ResourceName string // the entries' name parameter; empty if unset
+	Entries      []int  // positions in the list, from 1
+}
+
+func (d Duplicate) String() string {
+	positions := make([]string, len(d.Entries))
+	for i, e := range d.Entries {
+		positions[i] = fmt.Sprint(e)
+	}
+	name := d.Template
+	if d.ResourceName != "" {
+		name += "/" + d.ResourceName
+	}
+	return fmt.Sprintf("%s (entries %s)", name, strings.Join(positions, ", "))
+}
+
+// duplicateKey identifies the claim an entry renders
+func duplicateKey(tp TemplateParams) string {
+	return tp.Name + "\x00" + resourceName(tp)
+}
+
+// resourceName returns the entry's name parameter, or "" if unset
+func resourceName(tp TemplateParams) string {
+	if name, ok := tp.Parameters["name"]; ok && name != nil {
+		return fmt.Sprint(name)
+	}
+	return ""
+}
+
+// FindDuplicates returns the groups of entries that render the same claim,
+// in the order their first entry appears. Entries of one template with
+// different names are separate claims and not duplicates.
+func FindDuplicates(entries []TemplateParams) []Duplicate {
+	var order []string
+	groups := make(map[string]*Duplicate)
+	for i, tp := range entries {
+		key := duplicateKey(tp)
+		if d, ok := groups[key]; ok {
+			d.Entries = append(d.Entries, i+1)
+			continue
+		}
+		groups[key] = &Duplicate{Template: tp.Name, ResourceName: resourceName(tp), Entries: []int{i + 1}}
+		order = append(order, key)
+	}
+
+	var duplicates []Duplicate
+	for _, key := range order {
+		if d := groups[key]; len(d.Entries) > 1 {
+			duplicates = append(duplicates, *d)
+		}
+	}
+	return duplicates
+}
+
+// MergeDuplicates merges the entries that render the same claim into the
+// position of the first one. Later entries win: parameters are deep-merged
+// (nested maps key by key, other values replaced), secrets are merged and a
+// later output override replaces an earlier one.
+func MergeDuplicates(entries []TemplateParams) []TemplateParams {
+	index := make(map[string]int)
+	var merged []TemplateParams
+	for _, tp := range entries {
+		key := duplicateKey(tp)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(merged)
+			merged = append(merged, tp)
+			continue
+		}
+		first := &merged[i]
+		first.Parameters = DeepMerge(first.Parameters, tp.Parameters)
+		if len(tp.Secrets) > 0 {
+			secrets := make(map[string]string, len(first.Secrets)+len(tp.Secrets))
+			for k, v := range first.Secrets {
+				secrets[k] = v
+			}
+			for k, v := range tp.Secrets {
+				secrets[k] = v
+			}
+			first.Secrets = secrets
+		}
+		if tp.Output != nil {
+			first.Output = tp.Output
+		}
+	}
+	return merged
+}
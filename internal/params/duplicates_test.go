@@ -0,0 +1,78 @@
+package params
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	entries := []TemplateParams{
+		{Name: "vsphere-vm", Parameters: map[string]any{"name": "web", "cpu": 2}},
+		{Name: "vsphere-vm", Parameters: map[string]any{"name": "db"}},
+		{Name: "postgresql"},
+		{Name: "vsphere-vm", Parameters: map[string]any{"name": "web", "cpu": 4}},
+		{Name: "postgresql", Parameters: map[string]any{"version": "16"}},
+	}
+
+	got := FindDuplicates(entries)
+	want := []Duplicate{
+		{Template: "vsphere-vm", ResourceName: "web", Entries: []int{1, 4}},
+		{Template: "postgresql", Entries: []int{3, 5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("FindDuplicates() = %+v, want %+v", got, want)
+	}
+	if s := got[0].String(); s != "vsphere-vm/web (entries 1, 4)" {
+		t.Errorf("String() = %q", s)
+	}
+	if s := got[1].String(); s != "postgresql (entries 3, 5)" {
+		t.Errorf("String() = %q", s)
+	}
+
+	if d := FindDuplicates(entries[:3]); d != nil {
+		t.Errorf("expected no duplicates, got %+v", d)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	split := true
+	entries := []TemplateParams{
+		{
+			Name:       "vsphere-vm",
+			Parameters: map[string]any{"name": "web", "cpu": 2, "disk": map[string]any{"size": "10Gi", "type": "hdd"}, "tags": []any{"a", "b"}},
+			Secrets:    map[string]string{"password": "old", "token": "t"},
+		},
+		{Name: "vsphere-vm", Parameters: map[string]any{"name": "db"}},
+		{
+			Name:       "vsphere-vm",
+			Parameters: map[string]any{"name": "web", "cpu": 4, "disk": map[string]any{"size": "20Gi"}, "tags": []any{"c"}},
+			Secrets:    map[string]string{"password": "new"},
+			Output:     &OutputOverride{Split: &split},
+		},
+	}
+
+	got := MergeDuplicates(entries)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %+v", len(got), got)
+	}
+
+	web := got[0]
+	wantParams := map[string]any{"name": "web", "cpu": 4, "disk": map[string]any{"size": "20Gi", "type": "hdd"}, "tags": []any{"c"}}
+	if !reflect.DeepEqual(web.Parameters, wantParams) {
+		t.Errorf("merged parameters = %v, want %v", web.Parameters, wantParams)
+	}
+	if want := map[string]string{"password": "new", "token": "t"}; !reflect.DeepEqual(web.Secrets, want) {
+		t.Errorf("merged secrets = %v, want %v", web.Secrets, want)
+	}
+	if web.Output == nil || web.Output.Split == nil || !*web.Output.Split {
+		t.Errorf("expected the later output override, got %+v", web.Output)
+	}
+	if got[1].Parameters["name"] != "db" {
+		t.Errorf("second entry = %+v, want db", got[1])
+	}
+
+	// The inputs are not modified
+	if entries[0].Parameters["cpu"] != 2 || entries[0].Secrets["password"] != "old" {
+		t.Errorf("input entry modified: %+v", entries[0])
+	}
+}
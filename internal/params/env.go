@@ -0,0 +1,46 @@
+package params
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ExpandEnv replaces $VAR, ${VAR} and ${VAR:-default} in params file content
+// with values from the environment. The default is used when the variable is
+// unset or empty, and "$$" is a literal "$". An unset variable without a
+// default becomes empty, or, with strict set, is an error naming it.
+func ExpandEnv(data []byte, strict bool) ([]byte, error) {
+	missing := make(map[string]bool)
+	expanded := os.Expand(string(data), func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		name, def, hasDefault := strings.Cut(name, ":-")
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+		if hasDefault {
+			return def
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			missing[name] = true
+		}
+		return ""
+	})
+
+	if strict && len(missing) > 0 {
+		names := make([]string, 0, len(missing))
+		for name := range missing {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		noun := "variable"
+		if len(names) > 1 {
+			noun = "variables"
+		}
+		return nil, fmt.Errorf("undefined environment %s %s (set it or give a default with ${NAME:-value})", noun, strings.Join(names, ", "))
+	}
+	return []byte(expanded), nil
+}
@@ -0,0 +1,82 @@
+package params
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("VM_NAME", "web-01")
+	t.Setenv("EMPTY", "")
+
+	tests := []struct {
+		name    string
+		content string
+		strict  bool
+		want    string
+		wantErr string
+	}{
+		{"braces", "name: ${VM_NAME}\n", false, "name: web-01\n", ""},
+		{"plain", "name: $VM_NAME-a\n", false, "name: web-01-a\n", ""},
+		{"default when unset", "cpu: ${CPU_UNSET_TEST:-2}\n", false, "cpu: 2\n", ""},
+		{"default when empty", "cpu: ${EMPTY:-2}\n", false, "cpu: 2\n", ""},
+		{"default ignored when set", "name: ${VM_NAME:-other}\n", false, "name: web-01\n", ""},
+		{"literal dollar", "password: pa$$word\n", false, "password: pa$word\n", ""},
+		{"undefined becomes empty", "name: ${UNDEFINED_TEST}\n", false, "name: \n", ""},
+		{"strict allows defaults and empty", "cpu: ${CPU_UNSET_TEST:-2}\nx: ${EMPTY}\n", true, "cpu: 2\nx: \n", ""},
+		{"strict names the variable", "name: ${UNDEFINED_TEST}\n", true, "", "undefined environment variable UNDEFINED_TEST"},
+		{"strict names every variable", "a: ${UNDEFINED_B}\nb: ${UNDEFINED_A}\n", true, "", "undefined environment variables UNDEFINED_A, UNDEFINED_B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv([]byte(tt.content), tt.strict)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ExpandEnv() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandEnv() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ExpandEnv() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFileWith_ExpandEnv(t *testing.T) {
+	t.Setenv("VM_NAME", "web-01")
+	content := `template: vsphere-vm
+parameters:
+  name: ${VM_NAME}
+  cpu: ${CPU_UNSET_TEST:-2}
+  owner: ${OWNER_UNSET_TEST}
+`
+	tmpFile := createTempFile(t, "params.yaml", content)
+
+	pf, err := ParseFileWith(tmpFile, ParseOptions{ExpandEnv: true})
+	if err != nil {
+		t.Fatalf("ParseFileWith() error = %v", err)
+	}
+	got := pf.Templates[0].Parameters
+	if got["name"] != "web-01" || got["cpu"] != 2 || got["owner"] != nil {
+		t.Errorf("unexpected parameters: %v", got)
+	}
+
+	// Without the option the placeholders are kept
+	pf, err = ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if pf.Templates[0].Parameters["name"] != "${VM_NAME}" {
+		t.Errorf("expected no expansion by default, got %v", pf.Templates[0].Parameters["name"])
+	}
+
+	_, err = ParseFileWith(tmpFile, ParseOptions{ExpandEnv: true, StrictEnv: true})
+	if err == nil || !strings.Contains(err.Error(), "OWNER_UNSET_TEST") {
+		t.Errorf("expected a strict-mode error naming OWNER_UNSET_TEST, got %v", err)
+	}
+}
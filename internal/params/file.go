@@ -1,41 +1,120 @@
 package params
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/BurntSushi/toml"
 	"gopkg.in/yaml.v3"
 )
 
-// ParseFile reads and parses a parameter file (YAML or JSON)
+// Stdin as a params file path reads the parameters from standard input
+const Stdin = "-"
+
+// stdinData caches standard input, which can only be read once but may be
+// parsed several times in one run (e.g. by --explain)
+var (
+	stdinOnce sync.Once
+	stdinData []byte
+	stdinErr  error
+)
+
+// ParseOptions controls how params files are read
+type ParseOptions struct {
+	ExpandEnv bool // expand environment variables in the content before parsing (see ExpandEnv)
+	StrictEnv bool // with ExpandEnv, an unset variable without a default is an error
+}
+
+// ParseFile reads and parses a parameter file (YAML, JSON or TOML). Stdin ("-")
+// reads standard input and detects the format from the content.
 func ParseFile(path string) (*ParameterFile, error) {
-	data, err := os.ReadFile(path)
+	return ParseFileWith(path, ParseOptions{})
+}
+
+// ParseFileWith is ParseFile with options, e.g. environment expansion
+func ParseFileWith(path string, opts ParseOptions) (*ParameterFile, error) {
+	if path == Stdin {
+		stdinOnce.Do(func() { stdinData, stdinErr = io.ReadAll(os.Stdin) })
+		if stdinErr != nil {
+			return nil, fmt.Errorf("reading params from stdin: %w", stdinErr)
+		}
+		if len(bytes.TrimSpace(stdinData)) == 0 {
+			return nil, fmt.Errorf("no params on stdin (expected YAML, JSON or TOML)")
+		}
+		return parse(bytes.NewReader(stdinData), "", opts)
+	}
+
+	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("reading params file: %w", err)
 	}
+	defer f.Close()
+	return parse(f, filepath.Ext(path), opts)
+}
 
-	var pf ParameterFile
+// Parse parses parameter file content read from r. The format is taken from
+// the file extension ext (.json, .yaml/.yml, .toml). Content with any other
+// extension, or none, is parsed as JSON if it starts with { or [ and
+// otherwise tries YAML, then JSON, then TOML.
+func Parse(r io.Reader, ext string) (*ParameterFile, error) {
+	return parse(r, ext, ParseOptions{})
+}
+
+// ParseWith is Parse with options, e.g. environment expansion
+func ParseWith(r io.Reader, ext string, opts ParseOptions) (*ParameterFile, error) {
+	return parse(r, ext, opts)
+}
 
-	// Detect format by extension or try both
-	ext := strings.ToLower(filepath.Ext(path))
+func parse(r io.Reader, ext string, opts ParseOptions) (*ParameterFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading params file: %w", err)
+	}
+	if opts.ExpandEnv {
+		if data, err = ExpandEnv(data, opts.StrictEnv); err != nil {
+			return nil, err
+		}
+	}
 
-	switch ext {
+	var pf ParameterFile
+	switch strings.ToLower(ext) {
 	case ".json":
-		if err := json.Unmarshal(data, &pf); err != nil {
+		if err := unmarshalJSON(data, &pf); err != nil {
 			return nil, fmt.Errorf("parsing JSON: %w", err)
 		}
 	case ".yaml", ".yml":
 		if err := yaml.Unmarshal(data, &pf); err != nil {
 			return nil, fmt.Errorf("parsing YAML: %w", err)
 		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &pf); err != nil {
+			return nil, fmt.Errorf("parsing TOML: %w", err)
+		}
 	default:
-		// Try YAML first, then JSON
+		// JSON is valid YAML, so detect it from the first byte rather than
+		// letting the YAML parser take it; TOML tables also start with [
+		if looksLikeJSON(data) {
+			if err := unmarshalJSON(data, &pf); err == nil {
+				break
+			}
+			pf = ParameterFile{}
+		}
+		// Try YAML first, then JSON, then TOML
 		if err := yaml.Unmarshal(data, &pf); err != nil {
-			if jsonErr := json.Unmarshal(data, &pf); jsonErr != nil {
-				return nil, fmt.Errorf("parsing params file (tried YAML and JSON): %w", err)
+			pf = ParameterFile{}
+			if jsonErr := unmarshalJSON(data, &pf); jsonErr != nil {
+				pf = ParameterFile{}
+				if tomlErr := toml.Unmarshal(data, &pf); tomlErr != nil {
+					return nil, fmt.Errorf("parsing params file (tried YAML, JSON and TOML): %w", err)
+				}
 			}
 		}
 	}
@@ -44,14 +123,206 @@ func ParseFile(path string) (*ParameterFile, error) {
 	return &pf, nil
 }
 
-// ParseInlineParams parses key=value strings into a map
+// looksLikeJSON reports whether the first non-whitespace byte of data opens
+// a JSON object or array
+func looksLikeJSON(data []byte) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// unmarshalJSON decodes a JSON params file. Integers are decoded as int, as
+// the YAML parser does, rather than float64, so large values keep their
+// precision; other numbers become float64.
+func unmarshalJSON(data []byte, pf *ParameterFile) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(pf); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected content after the top-level value")
+	}
+
+	pf.Parameters = convertNumbers(pf.Parameters).(map[string]any)
+	pf.Defaults = convertNumbers(pf.Defaults).(map[string]any)
+	for i := range pf.Templates {
+		pf.Templates[i].Parameters = convertNumbers(pf.Templates[i].Parameters).(map[string]any)
+	}
+	return nil
+}
+
+// convertNumbers replaces the json.Number values in v, recursively, with an
+// int or, for fractions and out-of-range values, a float64
+func convertNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := strconv.Atoi(v.String()); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for k, elem := range v {
+			v[k] = convertNumbers(elem)
+		}
+		return v
+	case []any:
+		for i, elem := range v {
+			v[i] = convertNumbers(elem)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// ParseFiles parses a params file path that may be a glob pattern
+// (e.g. "params/*.yaml"). Each matching file is parsed with ParseFile and
+// their templates are combined in lexical file order. A pattern that
+// matches nothing is an error.
+func ParseFiles(pattern string) (*ParameterFile, error) {
+	return ParseFilesWith(pattern, ParseOptions{})
+}
+
+// ParseFilesWith is ParseFiles with options, e.g. environment expansion
+func ParseFilesWith(pattern string, opts ParseOptions) (*ParameterFile, error) {
+	if !IsPattern(pattern) {
+		return ParseFileWith(pattern, opts)
+	}
+
+	matches, err := ExpandFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := &ParameterFile{}
+	for _, path := range matches {
+		pf, err := ParseFileWith(path, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		combined.Templates = append(combined.Templates, pf.Templates...)
+	}
+
+	return combined, nil
+}
+
+// IsPattern reports whether a params file path contains glob metacharacters
+func IsPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// ExpandFiles returns the files a params file path refers to: the matches of
+// a glob pattern in lexical order, or the path itself. A pattern that matches
+// nothing is an error.
+func ExpandFiles(pattern string) ([]string, error) {
+	if !IsPattern(pattern) {
+		return []string{pattern}, nil
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid params file pattern %q: %w", pattern, err)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("params file pattern %q matched no files", pattern)
+	}
+	return matches, nil
+}
+
+// unsetMarker is the type of the Unset sentinel
+type unsetMarker struct{}
+
+// Unset marks a parameter for removal when params are merged.
+// Inline params produce it for "key-" and "key=null".
+var Unset any = unsetMarker{}
+
+// IsUnset reports whether v is the Unset sentinel
+func IsUnset(v any) bool {
+	_, ok := v.(unsetMarker)
+	return ok
+}
+
+// ParseInlineParams parses key=value strings into a map.
+// "key-" and "key=null" mark the key for removal (see Unset). Integer, float
+// and true/false values are converted to their Go types, as in a params file;
+// a quoted value ("15" or '15') stays a string, without the quotes.
 func ParseInlineParams(params []string) (map[string]any, error) {
+	return parseInlineParams(params, true)
+}
+
+// ParseInlineParamsRaw is ParseInlineParams without type conversion: every
+// value is kept as the given string, quotes included
+func ParseInlineParamsRaw(params []string) (map[string]any, error) {
+	return parseInlineParams(params, false)
+}
+
+func parseInlineParams(params []string, coerce bool) (map[string]any, error) {
 	result := make(map[string]any)
 
 	for _, p := range params {
 		parts := strings.SplitN(p, "=", 2)
 		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid param format: %s (expected key=value)", p)
+			if key := strings.TrimSuffix(p, "-"); key != p && key != "" {
+				result[key] = Unset
+				continue
+			}
+			return nil, fmt.Errorf("invalid param format: %s (expected key=value, key- or key=null)", p)
+		}
+		if parts[1] == "null" {
+			result[parts[0]] = Unset
+			continue
+		}
+		if coerce {
+			result[parts[0]] = coerceScalar(parts[1])
+			continue
+		}
+		result[parts[0]] = parts[1]
+	}
+
+	return result, nil
+}
+
+// intPattern and floatPattern match the plain decimal numbers coerceScalar
+// converts; other spellings ParseFloat accepts (e.g. "inf", "0x1p-2") stay strings
+var (
+	intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// coerceScalar converts an inline value to an int, float64 or bool when it
+// is one, and strips the quotes from a quoted string
+func coerceScalar(s string) any {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	switch {
+	case s == "true":
+		return true
+	case s == "false":
+		return false
+	case intPattern.MatchString(s):
+		if n, err := strconv.Atoi(s); err == nil {
+			return n
+		}
+	case floatPattern.MatchString(s):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+// ParseInlineSecrets parses key=value strings into a map of secret values.
+// Unlike ParseInlineParams, values are always literal: "key=null" keeps the
+// string "null" and "key-" is rejected, so no secret value is silently dropped.
+func ParseInlineSecrets(secrets []string) (map[string]string, error) {
+	result := make(map[string]string)
+
+	for _, s := range secrets {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid secret format: %s (expected key=value)", s)
 		}
 		result[parts[0]] = parts[1]
 	}
@@ -59,16 +330,121 @@ func ParseInlineParams(params []string) (map[string]any, error) {
 	return result, nil
 }
 
-// MergeParams merges file params with inline params (inline takes precedence)
+// MergeParams merges file params with inline params (inline takes precedence).
+// Keys set to Unset in inlineParams are removed from the result, and Unset
+// markers are never carried over, so the result is always safe to send to the API.
 func MergeParams(fileParams, inlineParams map[string]any) map[string]any {
 	result := make(map[string]any)
 
 	for k, v := range fileParams {
+		if IsUnset(v) {
+			continue
+		}
 		result[k] = v
 	}
 	for k, v := range inlineParams {
+		if IsUnset(v) {
+			delete(result, k)
+			continue
+		}
 		result[k] = v
 	}
 
 	return result
 }
+
+// DeepMerge merges src into dst and returns the result; neither input is
+// modified. Maps present in both are merged recursively, so a nested key can
+// be overridden without repeating its siblings; scalars and slices in src
+// replace the dst value. Use MergeParams for flat inline keys.
+func DeepMerge(dst, src map[string]any) map[string]any {
+	result := make(map[string]any, len(dst)+len(src))
+	for k, v := range dst {
+		result[k] = v
+	}
+	for k, v := range src {
+		d, dIsMap := result[k].(map[string]any)
+		s, sIsMap := v.(map[string]any)
+		if dIsMap && sIsMap {
+			result[k] = DeepMerge(d, s)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// MergeParameterFiles merges parameter files in order, later files
+// overriding earlier ones. Entries are matched by template name and their
+// parameters deep-merged: when a template appears several times, the n-th
+// entry of a later file overrides the n-th earlier one, and an earlier
+// template with a single entry is the base of every later entry for it.
+// Entries without an earlier match are appended, with the defaults of the
+// earlier files applied. Files are not modified.
+func MergeParameterFiles(files []*ParameterFile) *ParameterFile {
+	merged := &ParameterFile{}
+	for _, pf := range files {
+		if pf == nil {
+			continue
+		}
+
+		earlier := merged.Templates
+		positions := make(map[string][]int)
+		for i, tp := range earlier {
+			positions[tp.Name] = append(positions[tp.Name], i)
+		}
+
+		// Later entries replacing each earlier entry, in order
+		replacements := make([][]TemplateParams, len(earlier))
+		var appended []TemplateParams
+		occurrence := make(map[string]int)
+		for _, tp := range pf.Templates {
+			n := occurrence[tp.Name]
+			occurrence[tp.Name]++
+
+			pos := positions[tp.Name]
+			switch {
+			case len(pos) == 1:
+				replacements[pos[0]] = append(replacements[pos[0]], overrideEntry(earlier[pos[0]], tp))
+			case n < len(pos):
+				replacements[pos[n]] = append(replacements[pos[n]], overrideEntry(earlier[pos[n]], tp))
+			default:
+				tp.Parameters = DeepMerge(merged.Defaults, tp.Parameters)
+				appended = append(appended, tp)
+			}
+		}
+
+		var templates []TemplateParams
+		for i, tp := range earlier {
+			if len(replacements[i]) == 0 {
+				templates = append(templates, tp)
+				continue
+			}
+			templates = append(templates, replacements[i]...)
+		}
+		merged.Templates = append(templates, appended...)
+		merged.Defaults = DeepMerge(merged.Defaults, pf.Defaults)
+	}
+	return merged
+}
+
+// overrideEntry returns base with the parameters and secrets of over merged
+// onto it and its output override replaced if over sets one
+func overrideEntry(base, over TemplateParams) TemplateParams {
+	result := base
+	result.Parameters = DeepMerge(base.Parameters, over.Parameters)
+	if len(over.Secrets) > 0 {
+		secrets := make(map[string]string, len(base.Secrets)+len(over.Secrets))
+		for k, v := range base.Secrets {
+			secrets[k] = v
+		}
+		for k, v := range over.Secrets {
+			secrets[k] = v
+		}
+		result.Secrets = secrets
+	}
+	if over.Output != nil {
+		result.Output = over.Output
+	}
+	return result
+}
@@ -3,6 +3,9 @@ package params
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -96,6 +99,112 @@ func TestParseFile_JSON(t *testing.T) {
 	}
 }
 
+func TestParseFile_SingleTemplateTOML(t *testing.T) {
+	content := `template = "vsphere-vm"
+
+[parameters]
+name = "my-vm"
+cpu = 4
+memory = "8Gi"
+
+[secrets]
+password = "s3cret"
+`
+	tmpFile := createTempFile(t, "params-single.toml", content)
+	defer os.Remove(tmpFile)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if len(pf.Templates) != 1 {
+		t.Fatalf("expected 1 template, got %d", len(pf.Templates))
+	}
+
+	if pf.Templates[0].Name != "vsphere-vm" {
+		t.Errorf("expected template name 'vsphere-vm', got '%s'", pf.Templates[0].Name)
+	}
+
+	if pf.Templates[0].Parameters["name"] != "my-vm" {
+		t.Errorf("expected name 'my-vm', got '%v'", pf.Templates[0].Parameters["name"])
+	}
+
+	// TOML integers decode as int64
+	if pf.Templates[0].Parameters["cpu"] != int64(4) {
+		t.Errorf("expected cpu 4, got '%v'", pf.Templates[0].Parameters["cpu"])
+	}
+
+	if pf.Templates[0].Secrets["password"] != "s3cret" {
+		t.Errorf("expected the top-level secrets on the template, got %v", pf.Templates[0].Secrets)
+	}
+}
+
+func TestParseFile_MultiTemplateTOML(t *testing.T) {
+	content := `[defaults]
+datacenter = "dc1"
+
+[[templates]]
+name = "vsphere-vm"
+
+[templates.parameters]
+name = "my-vm"
+cpu = 4
+
+[templates.parameters.disk]
+size = "20Gi"
+
+[[templates]]
+name = "postgres-db"
+
+[templates.parameters]
+name = "my-database"
+version = "15"
+
+[templates.output]
+split = true
+dir = "databases"
+`
+	tmpFile := createTempFile(t, "params-multi.toml", content)
+	defer os.Remove(tmpFile)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if len(pf.Templates) != 2 {
+		t.Fatalf("expected 2 templates, got %d", len(pf.Templates))
+	}
+
+	if pf.Templates[0].Name != "vsphere-vm" {
+		t.Errorf("expected first template 'vsphere-vm', got '%s'", pf.Templates[0].Name)
+	}
+
+	if pf.Templates[1].Name != "postgres-db" {
+		t.Errorf("expected second template 'postgres-db', got '%s'", pf.Templates[1].Name)
+	}
+
+	want := map[string]any{"name": "my-vm", "cpu": int64(4), "disk": map[string]any{"size": "20Gi"}, "datacenter": "dc1"}
+	if !reflect.DeepEqual(pf.Templates[0].Parameters, want) {
+		t.Errorf("parameters = %#v, want %#v", pf.Templates[0].Parameters, want)
+	}
+
+	out := pf.Templates[1].Output
+	if out == nil || out.Split == nil || !*out.Split || out.Dir != "databases" {
+		t.Errorf("output = %+v, want split=true dir=databases", out)
+	}
+}
+
+func TestParseFile_TOMLParseError(t *testing.T) {
+	tmpFile := createTempFile(t, "params.toml", "template = \n")
+	defer os.Remove(tmpFile)
+
+	if _, err := ParseFile(tmpFile); err == nil || !strings.Contains(err.Error(), "parsing TOML") {
+		t.Errorf("expected a TOML parse error, got %v", err)
+	}
+}
+
 func TestParseFile_UnknownExtension(t *testing.T) {
 	// YAML content with unknown extension - should try both parsers
 	content := `template: vsphere-vm
@@ -115,6 +224,227 @@ parameters:
 	}
 }
 
+func TestParseFile_UnknownExtensionJSON(t *testing.T) {
+	// JSON is valid YAML; it must still be parsed as JSON
+	content := `
+  {"templates": [{"name": "vsphere-vm", "parameters": {"name": "my-vm", "cpu": 4, "ratio": 0.5, "id": 9007199254740993, "disks": [{"size": 10}]}}]}`
+	tmpFile := createTempFile(t, "params.txt", content)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := map[string]any{
+		"name":  "my-vm",
+		"cpu":   4,
+		"ratio": 0.5,
+		"id":    9007199254740993,
+		"disks": []any{map[string]any{"size": 10}},
+	}
+	if got := pf.Templates[0].Parameters; !reflect.DeepEqual(got, want) {
+		t.Errorf("parameters = %#v, want %#v", got, want)
+	}
+
+	// A top-level array is not a params file; the error comes from the fallbacks
+	tmpFile = createTempFile(t, "list.txt", `[1, 2]`)
+	if _, err := ParseFile(tmpFile); err == nil {
+		t.Error("expected an error for a JSON array")
+	}
+}
+
+func TestLooksLikeJSON(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{`{"template": "x"}`, true},
+		{"\n\t [1]", true},
+		{"\xef\xbb\xbf{}", true},
+		{"template: x", false},
+		{"# {comment}\ntemplate: x", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeJSON([]byte(tt.content)); got != tt.want {
+			t.Errorf("looksLikeJSON(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestParseFile_UnknownExtensionTOML(t *testing.T) {
+	// TOML content with unknown extension - tried after YAML and JSON
+	content := `template = "vsphere-vm"
+
+[parameters]
+name = "my-vm"
+`
+	tmpFile := createTempFile(t, "params.txt", content)
+	defer os.Remove(tmpFile)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	if len(pf.Templates) != 1 || pf.Templates[0].Name != "vsphere-vm" || pf.Templates[0].Parameters["name"] != "my-vm" {
+		t.Errorf("unexpected templates: %+v", pf.Templates)
+	}
+}
+
+func TestParseFile_Defaults(t *testing.T) {
+	content := `defaults:
+  datacenter: dc1
+  cpu: 2
+  network: prod
+templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+  - name: vsphere-vm
+    parameters:
+      name: db-1
+      cpu: 8
+  - name: vsphere-vm
+`
+	tmpFile := createTempFile(t, "params-defaults.yaml", content)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	tests := []struct {
+		index int
+		want  map[string]any
+	}{
+		{0, map[string]any{"name": "web-1", "datacenter": "dc1", "cpu": 2, "network": "prod"}},
+		{1, map[string]any{"name": "db-1", "datacenter": "dc1", "cpu": 8, "network": "prod"}},
+		{2, map[string]any{"datacenter": "dc1", "cpu": 2, "network": "prod"}},
+	}
+	for _, tt := range tests {
+		if got := pf.Templates[tt.index].Parameters; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("template %d parameters = %v, want %v", tt.index, got, tt.want)
+		}
+	}
+
+	// Overriding one entry must not leak into the others
+	pf.Templates[0].Parameters["network"] = "dev"
+	if pf.Templates[1].Parameters["network"] != "prod" {
+		t.Error("entries share the defaults map")
+	}
+}
+
+func TestParseFile_DefaultsSingleTemplate(t *testing.T) {
+	content := `{"template": "vsphere-vm", "defaults": {"cpu": 2, "memory": "4Gi"}, "parameters": {"name": "vm", "memory": "8Gi"}}`
+	tmpFile := createTempFile(t, "params.json", content)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	want := map[string]any{"name": "vm", "cpu": 2, "memory": "8Gi"}
+	if got := pf.Templates[0].Parameters; !reflect.DeepEqual(got, want) {
+		t.Errorf("parameters = %v, want %v", got, want)
+	}
+}
+
+func TestParseFile_OutputOverride(t *testing.T) {
+	content := `templates:
+  - name: vsphere-vm
+    parameters:
+      name: web
+    output:
+      split: true
+      dir: vms
+      pattern: "{{.name}}.yaml"
+  - name: postgresql
+    parameters:
+      name: db
+`
+	tmpFile := createTempFile(t, "params-output.yaml", content)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+
+	out := pf.Templates[0].Output
+	if out == nil || out.Split == nil || !*out.Split || out.Dir != "vms" || out.Pattern != "{{.name}}.yaml" {
+		t.Errorf("output = %+v, want split=true dir=vms pattern={{.name}}.yaml", out)
+	}
+	if pf.Templates[1].Output != nil {
+		t.Errorf("entry without output has override %+v", pf.Templates[1].Output)
+	}
+}
+
+func TestParse_Reader(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ext     string
+	}{
+		{"YAML without extension", "template: vsphere-vm\nparameters:\n  name: my-vm\n", ""},
+		{"YAML extension", "template: vsphere-vm\nparameters:\n  name: my-vm\n", ".yaml"},
+		{"JSON without extension", `{"template": "vsphere-vm", "parameters": {"name": "my-vm"}}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pf, err := Parse(strings.NewReader(tt.content), tt.ext)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(pf.Templates) != 1 || pf.Templates[0].Name != "vsphere-vm" || pf.Templates[0].Parameters["name"] != "my-vm" {
+				t.Errorf("unexpected templates: %+v", pf.Templates)
+			}
+		})
+	}
+
+	if _, err := Parse(strings.NewReader("{not json"), ".json"); err == nil {
+		t.Error("expected a JSON parse error")
+	}
+}
+
+func TestParseFile_Stdin(t *testing.T) {
+	feed := func(t *testing.T, content string) {
+		t.Helper()
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.WriteString(content)
+		w.Close()
+
+		saved := os.Stdin
+		os.Stdin = r
+		stdinOnce = sync.Once{}
+		t.Cleanup(func() {
+			os.Stdin = saved
+			stdinOnce = sync.Once{}
+			r.Close()
+		})
+	}
+
+	feed(t, "templates:\n  - name: vsphere-vm\n    parameters:\n      name: web\n")
+	for i := 0; i < 2; i++ {
+		// Stdin is read once and cached, so it can be parsed again
+		pf, err := ParseFile(Stdin)
+		if err != nil {
+			t.Fatalf("ParseFile(-) error = %v", err)
+		}
+		if len(pf.Templates) != 1 || pf.Templates[0].Parameters["name"] != "web" {
+			t.Errorf("unexpected templates: %+v", pf.Templates)
+		}
+	}
+
+	feed(t, "\n  \n")
+	if _, err := ParseFile(Stdin); err == nil || !strings.Contains(err.Error(), "no params on stdin") {
+		t.Errorf("expected an empty stdin error, got %v", err)
+	}
+}
+
 func TestParseFile_NotFound(t *testing.T) {
 	_, err := ParseFile("/nonexistent/path/params.yaml")
 	if err == nil {
@@ -137,7 +467,22 @@ func TestParseInlineParams(t *testing.T) {
 		{
 			name:   "multiple params",
 			params: []string{"name=my-vm", "cpu=4", "memory=8Gi"},
-			want:   map[string]any{"name": "my-vm", "cpu": "4", "memory": "8Gi"},
+			want:   map[string]any{"name": "my-vm", "cpu": 4, "memory": "8Gi"},
+		},
+		{
+			name:   "typed scalars",
+			params: []string{"cpu=-2", "ratio=0.5", "scale=1.5e3", "enabled=true", "debug=false"},
+			want:   map[string]any{"cpu": -2, "ratio": 0.5, "scale": 1500.0, "enabled": true, "debug": false},
+		},
+		{
+			name:   "quoted values stay strings",
+			params: []string{`version="15"`, "flag='true'", `empty=""`},
+			want:   map[string]any{"version": "15", "flag": "true", "empty": ""},
+		},
+		{
+			name:   "other values stay strings",
+			params: []string{"size=10Gi", "mode=True", "hex=0x10", "inf=inf", "ip=10.0.0.1", "half=\"open"},
+			want:   map[string]any{"size": "10Gi", "mode": "True", "hex": "0x10", "inf": "inf", "ip": "10.0.0.1", "half": `"open`},
 		},
 		{
 			name:   "value with equals sign",
@@ -179,6 +524,17 @@ func TestParseInlineParams(t *testing.T) {
 	}
 }
 
+func TestParseInlineParamsRaw(t *testing.T) {
+	got, err := ParseInlineParamsRaw([]string{"cpu=4", "enabled=true", `version="15"`, "name-"})
+	if err != nil {
+		t.Fatalf("ParseInlineParamsRaw() error = %v", err)
+	}
+	want := map[string]any{"cpu": "4", "enabled": "true", "version": `"15"`, "name": Unset}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseInlineParamsRaw() = %#v, want %#v", got, want)
+	}
+}
+
 func TestMergeParams(t *testing.T) {
 	fileParams := map[string]any{
 		"name":   "file-name",
@@ -213,6 +569,145 @@ func TestMergeParams(t *testing.T) {
 	}
 }
 
+func TestDeepMerge(t *testing.T) {
+	dst := map[string]any{
+		"name":   "web",
+		"labels": map[string]any{"team": "x", "env": "dev"},
+		"disk":   map[string]any{"size": "10Gi", "tags": []any{"a", "b"}},
+		"zones":  []any{"eu-1", "eu-2"},
+	}
+	src := map[string]any{
+		"labels": map[string]any{"cost-center": "42"},
+		"disk":   map[string]any{"size": "50Gi", "tags": []any{"c"}},
+		"zones":  []any{"us-1"},
+	}
+
+	got := DeepMerge(dst, src)
+	want := map[string]any{
+		"name":   "web",
+		"labels": map[string]any{"team": "x", "env": "dev", "cost-center": "42"},
+		"disk":   map[string]any{"size": "50Gi", "tags": []any{"c"}},
+		"zones":  []any{"us-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeepMerge() = %v, want %v", got, want)
+	}
+
+	// Neither input is modified
+	if len(dst["labels"].(map[string]any)) != 2 || dst["disk"].(map[string]any)["size"] != "10Gi" {
+		t.Errorf("DeepMerge modified dst: %v", dst)
+	}
+
+	// A scalar replaces a map and vice versa
+	got = DeepMerge(map[string]any{"a": map[string]any{"b": 1}, "c": 1}, map[string]any{"a": "flat", "c": map[string]any{"d": 2}})
+	if got["a"] != "flat" || !reflect.DeepEqual(got["c"], map[string]any{"d": 2}) {
+		t.Errorf("expected type changes to replace the value, got %v", got)
+	}
+}
+
+func TestParseFile_DefaultsNested(t *testing.T) {
+	content := `defaults:
+  labels:
+    team: platform
+    env: prod
+templates:
+  - name: vsphere-vm
+    parameters:
+      name: web-1
+      labels:
+        env: dev
+`
+	tmpFile := createTempFile(t, "params-nested.yaml", content)
+
+	pf, err := ParseFile(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	want := map[string]any{"team": "platform", "env": "dev"}
+	if got := pf.Templates[0].Parameters["labels"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("labels = %v, want %v", got, want)
+	}
+}
+
+func TestParseInlineParams_Unset(t *testing.T) {
+	got, err := ParseInlineParams([]string{"cpu-", "memory=null", "name=my-vm", "suffix=a-"})
+	if err != nil {
+		t.Fatalf("ParseInlineParams() error = %v", err)
+	}
+
+	if !IsUnset(got["cpu"]) {
+		t.Errorf("expected cpu to be unset, got %v", got["cpu"])
+	}
+	if !IsUnset(got["memory"]) {
+		t.Errorf("expected memory to be unset, got %v", got["memory"])
+	}
+	if got["name"] != "my-vm" {
+		t.Errorf("expected name='my-vm', got %v", got["name"])
+	}
+	if got["suffix"] != "a-" {
+		t.Errorf("expected suffix='a-', got %v", got["suffix"])
+	}
+
+	if _, err := ParseInlineParams([]string{"-"}); err == nil {
+		t.Error("expected error for bare '-'")
+	}
+}
+
+func TestParseInlineSecrets(t *testing.T) {
+	got, err := ParseInlineSecrets([]string{"password=null", "token=a=b"})
+	if err != nil {
+		t.Fatalf("ParseInlineSecrets() error = %v", err)
+	}
+	if got["password"] != "null" {
+		t.Errorf("expected password='null', got %q", got["password"])
+	}
+	if got["token"] != "a=b" {
+		t.Errorf("expected token='a=b', got %q", got["token"])
+	}
+
+	if _, err := ParseInlineSecrets([]string{"password-"}); err == nil {
+		t.Error("expected error for key- in secrets")
+	}
+}
+
+func TestMergeParams_UnsetAcrossLayers(t *testing.T) {
+	base := map[string]any{
+		"name":   "base-name",
+		"cpu":    4,
+		"memory": "8Gi",
+	}
+	env := map[string]any{
+		"cpu":  Unset,
+		"disk": "20Gi",
+	}
+
+	inline, err := ParseInlineParams([]string{"memory-", "disk=null", "cpu=2"})
+	if err != nil {
+		t.Fatalf("ParseInlineParams() error = %v", err)
+	}
+
+	result := MergeParams(MergeParams(base, env), inline)
+
+	if result["name"] != "base-name" {
+		t.Errorf("expected name='base-name', got %v", result["name"])
+	}
+	if result["cpu"] != 2 {
+		t.Errorf("expected cpu re-set to 2 by top layer, got %v", result["cpu"])
+	}
+	if _, ok := result["memory"]; ok {
+		t.Errorf("expected memory to be removed, got %v", result["memory"])
+	}
+	if _, ok := result["disk"]; ok {
+		t.Errorf("expected disk to be removed, got %v", result["disk"])
+	}
+
+	// Unset markers must never leak into the merged result
+	onlyInline := MergeParams(nil, map[string]any{"name": Unset})
+	if _, ok := onlyInline["name"]; ok {
+		t.Error("expected unset marker to be dropped when no lower layer sets the key")
+	}
+}
+
 func TestParameterFile_Normalize(t *testing.T) {
 	pf := &ParameterFile{
 		Template: "vsphere-vm",
@@ -291,3 +786,158 @@ func createTempFile(t *testing.T, name, content string) string {
 	}
 	return tmpFile
 }
+
+func TestParseFiles_Glob(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b-db.yaml": "template: postgres\nparameters:\n  name: my-db\n",
+		"a-vm.yaml": "template: vsphere-vm\nparameters:\n  name: my-vm\n",
+		"c-multi.yaml": `templates:
+  - name: volumeclaim
+    parameters:
+      name: data
+  - name: volumeclaim
+    parameters:
+      name: logs
+`,
+		"ignored.txt": "template: not-matched\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pf, err := ParseFiles(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		t.Fatalf("ParseFiles() error = %v", err)
+	}
+
+	want := []string{"my-vm", "my-db", "data", "logs"}
+	if len(pf.Templates) != len(want) {
+		t.Fatalf("expected %d templates, got %d", len(want), len(pf.Templates))
+	}
+	for i, name := range want {
+		if pf.Templates[i].Parameters["name"] != name {
+			t.Errorf("template %d: expected name %q, got %v", i, name, pf.Templates[i].Parameters["name"])
+		}
+	}
+}
+
+func TestParseFiles_NoMatch(t *testing.T) {
+	if _, err := ParseFiles(filepath.Join(t.TempDir(), "*.yaml")); err == nil {
+		t.Fatal("expected error for glob matching no files")
+	}
+}
+
+func TestParseFiles_PlainPath(t *testing.T) {
+	tmpFile := createTempFile(t, "params.yaml", "template: vsphere-vm\nparameters:\n  name: my-vm\n")
+
+	pf, err := ParseFiles(tmpFile)
+	if err != nil {
+		t.Fatalf("ParseFiles() error = %v", err)
+	}
+	if len(pf.Templates) != 1 || pf.Templates[0].Name != "vsphere-vm" {
+		t.Errorf("unexpected templates: %+v", pf.Templates)
+	}
+}
+
+func TestMergeParameterFiles(t *testing.T) {
+	parse := func(t *testing.T, content string) *ParameterFile {
+		t.Helper()
+		pf, err := Parse(strings.NewReader(content), ".yaml")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		return pf
+	}
+
+	base := parse(t, `defaults:
+  datacenter: dc1
+templates:
+  - name: vsphere-vm
+    parameters:
+      name: web
+      cpu: 2
+      disk:
+        size: 20
+        type: thin
+  - name: postgres
+    parameters:
+      name: db
+      version: "15"
+`)
+	env := parse(t, `templates:
+  - name: vsphere-vm
+    parameters:
+      cpu: 4
+      disk:
+        size: 40
+  - name: vsphere-vm
+    parameters:
+      name: api
+  - name: redis
+    parameters:
+      name: cache
+`)
+	last := parse(t, `templates:
+  - name: vsphere-vm
+    parameters:
+      cpu: 8
+  - name: postgres
+    parameters:
+      version: "16"
+`)
+
+	t.Run("later files override earlier ones", func(t *testing.T) {
+		got := MergeParameterFiles([]*ParameterFile{base, last}).Templates
+		if len(got) != 2 {
+			t.Fatalf("expected 2 templates, got %+v", got)
+		}
+		if got[0].Parameters["cpu"] != 8 || got[0].Parameters["name"] != "web" {
+			t.Errorf("vsphere-vm = %v, want cpu 8 and name web", got[0].Parameters)
+		}
+		if got[1].Parameters["version"] != "16" || got[1].Parameters["name"] != "db" {
+			t.Errorf("postgres = %v, want version 16 and name db", got[1].Parameters)
+		}
+	})
+
+	t.Run("order decides which value wins", func(t *testing.T) {
+		got := MergeParameterFiles([]*ParameterFile{last, base}).Templates
+		if got[0].Parameters["cpu"] != 2 || got[1].Parameters["version"] != "15" {
+			t.Errorf("expected the last file to win, got %+v", got)
+		}
+	})
+
+	t.Run("same template in several files", func(t *testing.T) {
+		got := MergeParameterFiles([]*ParameterFile{base, env, last}).Templates
+		var names []string
+		for _, tp := range got {
+			names = append(names, tp.Name+"/"+tp.Parameters["name"].(string))
+		}
+		if want := []string{"vsphere-vm/web", "vsphere-vm/api", "postgres/db", "redis/cache"}; !reflect.DeepEqual(names, want) {
+			t.Fatalf("entries = %v, want %v", names, want)
+		}
+
+		// The single vsphere-vm of base is the base of both entries of env,
+		// which are each overridden by the n-th entry of last
+		web := got[0].Parameters
+		if web["cpu"] != 8 || !reflect.DeepEqual(web["disk"], map[string]interface{}{"size": 40, "type": "thin"}) {
+			t.Errorf("web = %v, want cpu 8 and the disk merged", web)
+		}
+		api := got[1].Parameters
+		if api["cpu"] != 2 || api["datacenter"] != "dc1" {
+			t.Errorf("api = %v, want the base values", api)
+		}
+		if got[3].Parameters["datacenter"] != "dc1" {
+			t.Errorf("expected the defaults of earlier files on new entries, got %v", got[3].Parameters)
+		}
+	})
+
+	t.Run("single file is unchanged", func(t *testing.T) {
+		got := MergeParameterFiles([]*ParameterFile{env})
+		if !reflect.DeepEqual(got.Templates, env.Templates) {
+			t.Errorf("got %+v, want %+v", got.Templates, env.Templates)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxMatrixCombinations caps the number of entries a --matrix expansion may
+// produce, so a typo like an extra axis doesn't render thousands of claims
+const MaxMatrixCombinations = 100
+
+// MatrixAxis is one --matrix parameter with the values to render it at
+type MatrixAxis struct {
+	Key    string
+	Values []string
+}
+
+// ParseMatrix parses --matrix key=v1,v2 assignments into axes, in the order given
+func ParseMatrix(entries []string) ([]MatrixAxis, error) {
+	var axes []MatrixAxis
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		key, list, ok := strings.Cut(e, "=")
+		if !ok || key == "" || list == "" {
+			return nil, fmt.Errorf("invalid --matrix format: %s (expected key=v1,v2)", e)
+		}
+		if key == "name" {
+			return nil, fmt.Errorf("--matrix cannot vary name; it is derived from the matrix values")
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("--matrix key %s given more than once", key)
+		}
+		seen[key] = true
+
+		var values []string
+		for _, v := range strings.Split(list, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid --matrix %s: no values", e)
+		}
+		axes = append(axes, MatrixAxis{Key: key, Values: values})
+	}
+	return axes, nil
+}
+
+// ExpandMatrix replaces each entry with one entry per combination of the axis
+// values (the cartesian product, first axis varying slowest). Each copy sets
+// the axis parameters and derives its name as <base>-<value>-<value>..., where
+// base is the entry's name parameter or, if unset, its template name. It
+// fails if more than MaxMatrixCombinations entries would result.
+func ExpandMatrix(entries []TemplateParams, axes []MatrixAxis) ([]TemplateParams, error) {
+	if len(axes) == 0 {
+		return entries, nil
+	}
+
+	total := len(entries)
+	for _, a := range axes {
+		if total *= len(a.Values); total > MaxMatrixCombinations {
+			break // stop before the product can overflow
+		}
+	}
+	if total > MaxMatrixCombinations {
+		return nil, fmt.Errorf("--matrix expands to more than %d entries; narrow the matrix or split the render", MaxMatrixCombinations)
+	}
+
+	var expanded []TemplateParams
+	for _, tp := range entries {
+		base := tp.Name
+		if name, ok := tp.Parameters["name"]; ok && name != nil && fmt.Sprint(name) != "" {
+			base = fmt.Sprint(name)
+		}
+		for _, combo := range matrixCombinations(axes) {
+			values := make(map[string]any, len(combo)+1)
+			nameParts := []string{base}
+			for i, v := range combo {
+				values[axes[i].Key] = v
+				nameParts = append(nameParts, v)
+			}
+			values["name"] = strings.Join(nameParts, "-")
+
+			entry := tp
+			entry.Parameters = MergeParams(tp.Parameters, values)
+			expanded = append(expanded, entry)
+		}
+	}
+	return expanded, nil
+}
+
+// matrixCombinations lists the value combinations of the axes, first axis varying slowest
+func matrixCombinations(axes []MatrixAxis) [][]string {
+	combos := [][]string{{}}
+	for _, a := range axes {
+		var next [][]string
+		for _, c := range combos {
+			for _, v := range a.Values {
+				next = append(next, append(append([]string{}, c...), v))
+			}
+		}
+		combos = next
+	}
+	return combos
+}
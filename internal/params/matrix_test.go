@@ -0,0 +1,81 @@
+package params
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	axes, err := ParseMatrix([]string{"region=eu, us", "size=small,large,"})
+	if err != nil {
+		t.Fatalf("ParseMatrix() error = %v", err)
+	}
+	want := []MatrixAxis{
+		{Key: "region", Values: []string{"eu", "us"}},
+		{Key: "size", Values: []string{"small", "large"}},
+	}
+	if !reflect.DeepEqual(axes, want) {
+		t.Errorf("ParseMatrix() = %v, want %v", axes, want)
+	}
+
+	for _, bad := range [][]string{{"region"}, {"=eu"}, {"region="}, {"region=,"}, {"name=a,b"}, {"size=s", "size=l"}} {
+		if _, err := ParseMatrix(bad); err == nil {
+			t.Errorf("ParseMatrix(%q) expected error", bad)
+		}
+	}
+}
+
+func TestExpandMatrix(t *testing.T) {
+	entries := []TemplateParams{
+		{Name: "vsphere-vm", Parameters: map[string]any{"name": "web", "cpu": 2}},
+		{Name: "postgresql"},
+	}
+	axes := []MatrixAxis{
+		{Key: "region", Values: []string{"eu", "us"}},
+		{Key: "size", Values: []string{"small", "large"}},
+	}
+
+	expanded, err := ExpandMatrix(entries, axes)
+	if err != nil {
+		t.Fatalf("ExpandMatrix() error = %v", err)
+	}
+
+	var names []string
+	for _, e := range expanded {
+		names = append(names, e.Name+":"+e.Parameters["name"].(string))
+	}
+	wantNames := []string{
+		"vsphere-vm:web-eu-small", "vsphere-vm:web-eu-large", "vsphere-vm:web-us-small", "vsphere-vm:web-us-large",
+		"postgresql:postgresql-eu-small", "postgresql:postgresql-eu-large", "postgresql:postgresql-us-small", "postgresql:postgresql-us-large",
+	}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("names = %v, want %v", names, wantNames)
+	}
+
+	wantParams := map[string]any{"name": "web-us-large", "cpu": 2, "region": "us", "size": "large"}
+	if !reflect.DeepEqual(expanded[3].Parameters, wantParams) {
+		t.Errorf("parameters = %v, want %v", expanded[3].Parameters, wantParams)
+	}
+	if _, ok := entries[0].Parameters["region"]; ok {
+		t.Error("expansion modified the original entry")
+	}
+}
+
+func TestExpandMatrix_Cap(t *testing.T) {
+	values := make([]string, 11)
+	for i := range values {
+		values[i] = strings.Repeat("v", i+1)
+	}
+	axes := []MatrixAxis{{Key: "a", Values: values}, {Key: "b", Values: values}}
+
+	if _, err := ExpandMatrix([]TemplateParams{{Name: "vm"}}, axes); err == nil {
+		t.Fatal("expected error for 121 combinations")
+	}
+	if got, err := ExpandMatrix([]TemplateParams{{Name: "vm"}}, axes[:1]); err != nil || len(got) != 11 {
+		t.Errorf("ExpandMatrix() = %d entries, %v; want 11", len(got), err)
+	}
+	if got, _ := ExpandMatrix([]TemplateParams{{Name: "vm"}}, nil); len(got) != 1 {
+		t.Errorf("no axes should leave the entries unchanged, got %d", len(got))
+	}
+}
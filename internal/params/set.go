@@ -0,0 +1,153 @@
+package params
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSetParams parses Helm-style --set assignments into a nested parameter
+// tree: "a.b.c=x" sets a nested map path and "a.b[0]=x" a list element.
+// Assignments to the same parent are merged. Values are kept as strings like
+// ParseInlineParams, and "path=null" marks the path for removal (see Unset).
+func ParseSetParams(entries []string) (map[string]any, error) {
+	result := make(map[string]any)
+
+	for _, e := range entries {
+		path, value, ok := strings.Cut(e, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --set format: %s (expected path=value)", e)
+		}
+		segments, err := parseSetPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --set %s: %w", e, err)
+		}
+
+		var v any = value
+		if value == "null" {
+			v = Unset
+		}
+		result = setPath(result, segments, v).(map[string]any)
+	}
+
+	return result, nil
+}
+
+// setSegment is one step of a --set path: a map key or a list index
+type setSegment struct {
+	key     string
+	index   int // list index when isIndex is set
+	isIndex bool
+}
+
+// parseSetPath splits "a.b[0].c" into key and index segments
+func parseSetPath(path string) ([]setSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	var segments []setSegment
+	for _, part := range strings.Split(path, ".") {
+		key, rest, hasIndex := strings.Cut(part, "[")
+		if key == "" {
+			return nil, fmt.Errorf("empty key in path %q", path)
+		}
+		segments = append(segments, setSegment{key: key})
+
+		for hasIndex {
+			var idx string
+			idx, rest, hasIndex = strings.Cut(rest, "]")
+			if !hasIndex {
+				return nil, fmt.Errorf("unclosed index in path %q", path)
+			}
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", idx, path)
+			}
+			segments = append(segments, setSegment{index: n, isIndex: true})
+
+			if rest == "" {
+				break
+			}
+			if !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("unexpected %q after index in path %q", rest, path)
+			}
+			rest, hasIndex = rest[1:], true
+		}
+	}
+	return segments, nil
+}
+
+// setPath assigns value at segments below node, creating maps and lists as
+// needed, and returns the (possibly new) node
+func setPath(node any, segments []setSegment, value any) any {
+	if len(segments) == 0 {
+		return value
+	}
+	seg := segments[0]
+
+	if seg.isIndex {
+		list, _ := node.([]any)
+		for len(list) <= seg.index {
+			list = append(list, nil)
+		}
+		list[seg.index] = setPath(list[seg.index], segments[1:], value)
+		return list
+	}
+
+	m, ok := node.(map[string]any)
+	if !ok {
+		m = make(map[string]any)
+	}
+	m[seg.key] = setPath(m[seg.key], segments[1:], value)
+	return m
+}
+
+// MergeTree deep-merges overlay into base and returns the result; neither
+// input is modified. Nested maps are merged key by key and lists element by
+// element, where nil overlay elements (indices not assigned by --set) keep
+// the base element. Unset removes a map key.
+func MergeTree(base, overlay map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		if IsUnset(v) {
+			continue
+		}
+		result[k] = v
+	}
+	for k, v := range overlay {
+		if IsUnset(v) {
+			delete(result, k)
+			continue
+		}
+		result[k] = mergeValue(result[k], v)
+	}
+	return result
+}
+
+// mergeValue merges an overlay value onto a base value of the same shape;
+// otherwise the overlay replaces the base
+func mergeValue(base, overlay any) any {
+	switch o := overlay.(type) {
+	case map[string]any:
+		b, _ := base.(map[string]any)
+		return MergeTree(b, o)
+	case []any:
+		b, _ := base.([]any)
+		merged := make([]any, max(len(b), len(o)))
+		copy(merged, b)
+		for i, v := range o {
+			switch {
+			case v == nil:
+				// Index not assigned: keep the base element
+			case IsUnset(v):
+				merged[i] = nil
+			default:
+				merged[i] = mergeValue(merged[i], v)
+			}
+		}
+		return merged
+	default:
+		return overlay
+	}
+}
@@ -0,0 +1,52 @@
+package params
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFileParams parses --param-file-yaml and --param-file-json assignments
+// ("path=file") into a nested parameter tree. Each file is parsed as format
+// ("yaml" or "json") and the structured value is placed at the dotted path,
+// which takes the same form as --set paths, so templates receive an object or
+// list instead of a string.
+func ParseFileParams(entries []string, format string) (map[string]any, error) {
+	flag := "--param-file-" + format
+	result := make(map[string]any)
+
+	for _, e := range entries {
+		path, file, ok := strings.Cut(e, "=")
+		if !ok || file == "" {
+			return nil, fmt.Errorf("invalid %s format: %s (expected path=file)", flag, e)
+		}
+		segments, err := parseSetPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s: %w", flag, e, err)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", flag, path, err)
+		}
+		var value any
+		switch format {
+		case "json":
+			err = json.Unmarshal(data, &value)
+		case "yaml":
+			err = yaml.Unmarshal(data, &value)
+		default:
+			return nil, fmt.Errorf("unsupported param file format %q", format)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: parsing %s as %s: %w", flag, path, file, strings.ToUpper(format), err)
+		}
+
+		result = setPath(result, segments, value).(map[string]any)
+	}
+
+	return result, nil
+}
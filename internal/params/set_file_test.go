@@ -0,0 +1,93 @@
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFileParams(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	resourcesYAML := write("resources.yaml", "requests:\n  cpu: 500m\n  memory: 1Gi\nlimits:\n  memory: 2Gi\n")
+	portsJSON := write("ports.json", `[{"name": "http", "port": 80}]`)
+	broken := write("broken.yaml", "requests: [unclosed\n")
+
+	tests := []struct {
+		name    string
+		entries []string
+		format  string
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:    "YAML object as a nested parameter",
+			entries: []string{"app.resources=" + resourcesYAML},
+			format:  "yaml",
+			want: map[string]any{
+				"app": map[string]any{"resources": map[string]any{
+					"requests": map[string]any{"cpu": "500m", "memory": "1Gi"},
+					"limits":   map[string]any{"memory": "2Gi"},
+				}},
+			},
+		},
+		{
+			name:    "JSON list at the top level",
+			entries: []string{"ports=" + portsJSON},
+			format:  "json",
+			want: map[string]any{
+				"ports": []any{map[string]any{"name": "http", "port": float64(80)}},
+			},
+		},
+		{
+			name:    "parse failure",
+			entries: []string{"resources=" + broken},
+			format:  "yaml",
+			wantErr: "parsing " + broken + " as YAML",
+		},
+		{
+			name:    "JSON flag rejects YAML",
+			entries: []string{"resources=" + resourcesYAML},
+			format:  "json",
+			wantErr: "as JSON",
+		},
+		{
+			name:    "missing file",
+			entries: []string{"resources=" + filepath.Join(dir, "missing.yaml")},
+			format:  "yaml",
+			wantErr: "--param-file-yaml resources",
+		},
+		{
+			name:    "missing file name",
+			entries: []string{"resources"},
+			format:  "yaml",
+			wantErr: "expected path=file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFileParams(tt.entries, tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFileParams: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package params
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSetParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name:    "top-level key",
+			entries: []string{"name=my-vm"},
+			want:    map[string]any{"name": "my-vm"},
+		},
+		{
+			name:    "dotted path",
+			entries: []string{"disk.root.size=20Gi"},
+			want: map[string]any{
+				"disk": map[string]any{"root": map[string]any{"size": "20Gi"}},
+			},
+		},
+		{
+			name:    "multiple assignments merge into the same parent",
+			entries: []string{"disk.size=20Gi", "disk.type=ssd", "name=vm"},
+			want: map[string]any{
+				"disk": map[string]any{"size": "20Gi", "type": "ssd"},
+				"name": "vm",
+			},
+		},
+		{
+			name:    "array index",
+			entries: []string{"network.dns[1]=8.8.8.8"},
+			want: map[string]any{
+				"network": map[string]any{"dns": []any{nil, "8.8.8.8"}},
+			},
+		},
+		{
+			name:    "map inside array",
+			entries: []string{"disks[0].size=10Gi", "disks[0].name=root", "disks[1].size=50Gi"},
+			want: map[string]any{
+				"disks": []any{
+					map[string]any{"size": "10Gi", "name": "root"},
+					map[string]any{"size": "50Gi"},
+				},
+			},
+		},
+		{
+			name:    "nested indices",
+			entries: []string{"matrix[0][1]=x"},
+			want:    map[string]any{"matrix": []any{[]any{nil, "x"}}},
+		},
+		{
+			name:    "null unsets",
+			entries: []string{"disk.size=null"},
+			want:    map[string]any{"disk": map[string]any{"size": Unset}},
+		},
+		{
+			name:    "value containing equals",
+			entries: []string{"labels.selector=app=web"},
+			want:    map[string]any{"labels": map[string]any{"selector": "app=web"}},
+		},
+		{name: "missing value", entries: []string{"disk.size"}, wantErr: true},
+		{name: "empty key", entries: []string{"disk..size=1"}, wantErr: true},
+		{name: "unclosed index", entries: []string{"dns[0=x"}, wantErr: true},
+		{name: "negative index", entries: []string{"dns[-1]=x"}, wantErr: true},
+		{name: "text after index", entries: []string{"dns[0]x=y"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSetParams(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSetParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSetParams() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTree(t *testing.T) {
+	base := map[string]any{
+		"name": "vm",
+		"disk": map[string]any{"size": "10Gi", "type": "hdd"},
+		"dns":  []any{"1.1.1.1", "9.9.9.9"},
+	}
+
+	set, err := ParseSetParams([]string{"disk.size=20Gi", "dns[1]=8.8.8.8", "network.vlan=42", "name=null"})
+	if err != nil {
+		t.Fatalf("ParseSetParams() error = %v", err)
+	}
+
+	got := MergeTree(base, set)
+	want := map[string]any{
+		"disk":    map[string]any{"size": "20Gi", "type": "hdd"},
+		"dns":     []any{"1.1.1.1", "8.8.8.8"},
+		"network": map[string]any{"vlan": "42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeTree() = %#v, want %#v", got, want)
+	}
+
+	// Inputs are left untouched so the same --set tree can be applied to many templates
+	if base["disk"].(map[string]any)["size"] != "10Gi" || base["dns"].([]any)[1] != "9.9.9.9" {
+		t.Errorf("MergeTree() modified base: %#v", base)
+	}
+}
@@ -3,21 +3,36 @@ package params
 // ParameterFile supports both single and multi-template formats
 type ParameterFile struct {
 	// Single template format
-	Template   string         `yaml:"template" json:"template"`
-	Parameters map[string]any `yaml:"parameters" json:"parameters"`
+	Template   string         `yaml:"template" json:"template" toml:"template"`
+	Parameters map[string]any `yaml:"parameters" json:"parameters" toml:"parameters"`
 
 	// Multi-template format
-	Templates []TemplateParams `yaml:"templates" json:"templates"`
+	Templates []TemplateParams `yaml:"templates" json:"templates" toml:"templates"`
 
 	// Secret values (kept separate from parameters for clarity)
-	Secrets map[string]string `yaml:"secrets,omitempty" json:"secrets,omitempty"`
+	Secrets map[string]string `yaml:"secrets,omitempty" json:"secrets,omitempty" toml:"secrets,omitempty"`
+
+	// Shared parameters applied to every template; entries override them
+	Defaults map[string]any `yaml:"defaults,omitempty" json:"defaults,omitempty" toml:"defaults,omitempty"`
 }
 
 // TemplateParams holds parameters for a single template
 type TemplateParams struct {
-	Name       string            `yaml:"name" json:"name"`
-	Parameters map[string]any    `yaml:"parameters" json:"parameters"`
-	Secrets    map[string]string `yaml:"secrets,omitempty" json:"secrets,omitempty"`
+	Name       string            `yaml:"name" json:"name" toml:"name"`
+	Parameters map[string]any    `yaml:"parameters" json:"parameters" toml:"parameters"`
+	Secrets    map[string]string `yaml:"secrets,omitempty" json:"secrets,omitempty" toml:"secrets,omitempty"`
+	Output     *OutputOverride   `yaml:"output,omitempty" json:"output,omitempty" toml:"output,omitempty"`
+}
+
+// OutputOverride overrides the global output settings for one template entry
+type OutputOverride struct {
+	// Split writes the entry to its own file (true) or into the combined
+	// file (false); unset follows --single-file
+	Split *bool `yaml:"split,omitempty" json:"split,omitempty" toml:"split,omitempty"`
+	// Dir is the entry's output directory, relative to --output-dir unless absolute
+	Dir string `yaml:"dir,omitempty" json:"dir,omitempty" toml:"dir,omitempty"`
+	// Pattern is the entry's filename pattern, replacing --filename-pattern
+	Pattern string `yaml:"pattern,omitempty" json:"pattern,omitempty" toml:"pattern,omitempty"`
 }
 
 // Normalize converts single-template format to multi-template format
@@ -29,6 +44,13 @@ func (pf *ParameterFile) Normalize() {
 			Secrets:    pf.Secrets,
 		}}
 	}
+	// Apply shared defaults below each template's own parameters; nested
+	// maps are merged so an entry can override a single nested key
+	if len(pf.Defaults) > 0 {
+		for i := range pf.Templates {
+			pf.Templates[i].Parameters = DeepMerge(pf.Defaults, pf.Templates[i].Parameters)
+		}
+	}
 	// Propagate top-level secrets to templates that don't have their own
 	if len(pf.Secrets) > 0 {
 		for i := range pf.Templates {
@@ -1,17 +1,48 @@
 package registry
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
+	"github.com/stuttgart-things/claims/internal/yamlnode"
 	"gopkg.in/yaml.v3"
 )
 
 const (
 	DefaultAPIVersion = "claim-registry.io/v1alpha1"
 	DefaultKind       = "ClaimRegistry"
+
+	// DefaultPath is where a new registry is created, relative to the repo root
+	DefaultPath = "claims/registry.yaml"
 )
 
+// SearchPaths are the conventional registry locations relative to the repo
+// root, in the order Discover checks them
+var SearchPaths = []string{
+	DefaultPath,
+	"registry.yaml",
+	".claims/registry.yaml",
+}
+
+// ErrNotFound is returned by Discover when no registry exists in the repo
+var ErrNotFound = errors.New("registry not found")
+
+// Discover returns the path of the first registry found under repoRoot at
+// one of the SearchPaths
+func Discover(repoRoot string) (string, error) {
+	for _, rel := range SearchPaths {
+		path := filepath.Join(repoRoot, filepath.FromSlash(rel))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%w in %s (looked for %s)", ErrNotFound, repoRoot, strings.Join(SearchPaths, ", "))
+}
+
 // Load reads and parses a registry.yaml file
 func Load(path string) (*ClaimRegistry, error) {
 	data, err := os.ReadFile(path)
@@ -19,15 +50,23 @@ func Load(path string) (*ClaimRegistry, error) {
 		return nil, fmt.Errorf("reading registry file: %w", err)
 	}
 
+	doc, err := yamlnode.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing registry file: %w", err)
+	}
+
 	var reg ClaimRegistry
-	if err := yaml.Unmarshal(data, &reg); err != nil {
+	if err := doc.Root.Decode(&reg); err != nil {
 		return nil, fmt.Errorf("parsing registry file: %w", err)
 	}
+	reg.doc = doc
 
 	return &reg, nil
 }
 
-// Save writes a ClaimRegistry to a YAML file
+// Save writes a ClaimRegistry to a YAML file. A registry obtained from Load
+// is written back with its comments and key order intact; unchanged entries
+// keep their original formatting.
 func Save(path string, reg *ClaimRegistry) error {
 	if reg.APIVersion == "" {
 		reg.APIVersion = DefaultAPIVersion
@@ -36,7 +75,7 @@ func Save(path string, reg *ClaimRegistry) error {
 		reg.Kind = DefaultKind
 	}
 
-	data, err := yaml.Marshal(reg)
+	data, err := marshal(reg)
 	if err != nil {
 		return fmt.Errorf("marshalling registry: %w", err)
 	}
@@ -48,6 +87,68 @@ func Save(path string, reg *ClaimRegistry) error {
 	return nil
 }
 
+// marshal encodes reg, editing the loaded document in place when there is one
+func marshal(reg *ClaimRegistry) ([]byte, error) {
+	m := reg.doc.Mapping()
+	if m == nil {
+		return yaml.Marshal(reg)
+	}
+
+	yamlnode.SetScalar(m, "apiVersion", reg.APIVersion)
+	yamlnode.SetScalar(m, "kind", reg.Kind)
+	if err := syncClaims(m, reg.Claims); err != nil {
+		return nil, err
+	}
+
+	return reg.doc.Encode()
+}
+
+// syncClaims rewrites the claims sequence to match claims. Entries that are
+// unchanged keep their original node; changed entries are re-encoded but keep
+// the comments attached to them.
+func syncClaims(m *yaml.Node, claims []ClaimEntry) error {
+	seq := yamlnode.MappingValue(m, "claims")
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		seq = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		yamlnode.SetMappingValue(m, "claims", seq)
+	}
+
+	existing := make(map[string]*yaml.Node)
+	for _, n := range seq.Content {
+		var e ClaimEntry
+		if err := n.Decode(&e); err == nil {
+			existing[e.Name] = n
+		}
+	}
+
+	if len(seq.Content) == 0 {
+		seq.Style = 0
+	}
+
+	content := make([]*yaml.Node, 0, len(claims))
+	for _, entry := range claims {
+		if n := existing[entry.Name]; n != nil {
+			var old ClaimEntry
+			if err := n.Decode(&old); err == nil && reflect.DeepEqual(old, entry) {
+				content = append(content, n)
+				continue
+			}
+		}
+
+		var n yaml.Node
+		if err := n.Encode(entry); err != nil {
+			return fmt.Errorf("encoding claim %s: %w", entry.Name, err)
+		}
+		if old := existing[entry.Name]; old != nil {
+			yamlnode.CopyComments(&n, old)
+		}
+		content = append(content, &n)
+	}
+	seq.Content = content
+
+	return nil
+}
+
 // AddEntry adds a claim entry to the registry.
 // If an entry with the same name already exists, it is replaced.
 func AddEntry(reg *ClaimRegistry, entry ClaimEntry) {
@@ -98,6 +199,30 @@ func FilterEntries(reg *ClaimRegistry, category, template string) []ClaimEntry {
 	return result
 }
 
+// Select returns the entries matching sel, in registry order
+func Select(reg *ClaimRegistry, sel Selector) []ClaimEntry {
+	var result []ClaimEntry
+	for _, e := range FilterEntries(reg, sel.Category, sel.Template) {
+		if sel.Source != "" && e.Source != sel.Source {
+			continue
+		}
+		if e.MatchesLabels(sel.Labels) {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// MatchesLabels reports whether the entry has every label in labels
+func (e ClaimEntry) MatchesLabels(labels map[string]string) bool {
+	for k, v := range labels {
+		if got, ok := e.Labels[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
 // NewRegistry creates an empty ClaimRegistry with default fields.
 func NewRegistry() *ClaimRegistry {
 	return &ClaimRegistry{
@@ -1,8 +1,10 @@
 package registry
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -125,6 +127,72 @@ func TestFilterEntries(t *testing.T) {
 	}
 }
 
+func TestSelect(t *testing.T) {
+	reg := NewRegistry()
+	AddEntry(reg, ClaimEntry{Name: "a", Category: "infra", Template: "vol", Source: "cli", Labels: map[string]string{"env": "prod", "team": "ops"}})
+	AddEntry(reg, ClaimEntry{Name: "b", Category: "infra", Template: "net", Source: "ci", Labels: map[string]string{"env": "dev"}})
+	AddEntry(reg, ClaimEntry{Name: "c", Category: "apps", Template: "vol", Source: "ci"})
+
+	tests := []struct {
+		name string
+		sel  Selector
+		want []string
+	}{
+		{"everything", Selector{}, []string{"a", "b", "c"}},
+		{"category", Selector{Category: "infra"}, []string{"a", "b"}},
+		{"label", Selector{Labels: map[string]string{"env": "prod"}}, []string{"a"}},
+		{"all labels must match", Selector{Labels: map[string]string{"env": "prod", "team": "dev"}}, nil},
+		{"template and label", Selector{Template: "vol", Labels: map[string]string{"team": "ops"}}, []string{"a"}},
+		{"source", Selector{Source: "ci"}, []string{"b", "c"}},
+		{"category and source", Selector{Category: "infra", Source: "ci"}, []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range Select(reg, tt.sel) {
+				got = append(got, e.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Select() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveRoundTripsParametersAndLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	reg := NewRegistry()
+	AddEntry(reg, ClaimEntry{
+		Name:       "my-vm",
+		Template:   "vsphere-vm",
+		Labels:     map[string]string{"env": "prod"},
+		Parameters: map[string]any{"name": "my-vm", "cpu": 4, "disks": []any{"a", "b"}},
+	})
+	if err := Save(path, reg); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	e := FindEntry(loaded, "my-vm")
+	if e.Labels["env"] != "prod" || e.Parameters["cpu"] != 4 || len(e.Parameters["disks"].([]any)) != 2 {
+		t.Errorf("entry not round-tripped: %+v", e)
+	}
+
+	// Saving the loaded registry unchanged keeps the file byte-identical
+	before, _ := os.ReadFile(path)
+	if err := Save(path, loaded); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Errorf("re-save changed the file:\n%s\n---\n%s", before, after)
+	}
+}
+
 func TestSaveCreatesDirectories(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "sub", "registry.yaml")
@@ -140,3 +208,146 @@ func TestSaveCreatesDirectories(t *testing.T) {
 		}
 	}
 }
+
+func TestSavePreservesComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "registry.yaml")
+
+	original := `# Claims managed by the platform team
+apiVersion: claim-registry.io/v1alpha1
+kind: ClaimRegistry
+claims:
+    # shared storage, do not delete
+    - name: app-pvc
+      template: volumeclaim
+      category: infra
+      namespace: ""
+      createdAt: "2024-01-01T00:00:00Z"
+      createdBy: cli
+      source: cli
+      repository: ""
+      path: claims/infra/app-pvc
+      status: active # pinned
+`
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	AddEntry(reg, ClaimEntry{Name: "db-pvc", Template: "volumeclaim", Status: "active"})
+	if err := Save(path, reg); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+
+	if !strings.HasPrefix(got, original) {
+		t.Errorf("expected the existing content to be kept verbatim, got:\n%s", got)
+	}
+	if !strings.Contains(got, "- name: db-pvc") {
+		t.Errorf("expected new entry, got:\n%s", got)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(reloaded.Claims) != 2 {
+		t.Fatalf("expected 2 claims, got %d", len(reloaded.Claims))
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "claims directory",
+			files: []string{"claims/registry.yaml"},
+			want:  "claims/registry.yaml",
+		},
+		{
+			name:  "repo root",
+			files: []string{"registry.yaml"},
+			want:  "registry.yaml",
+		},
+		{
+			name:  "hidden directory",
+			files: []string{".claims/registry.yaml"},
+			want:  ".claims/registry.yaml",
+		},
+		{
+			name:  "claims directory wins over others",
+			files: []string{".claims/registry.yaml", "registry.yaml", "claims/registry.yaml"},
+			want:  "claims/registry.yaml",
+		},
+		{
+			name:  "repo root wins over hidden directory",
+			files: []string{".claims/registry.yaml", "registry.yaml"},
+			want:  "registry.yaml",
+		},
+		{
+			name:    "not found",
+			files:   []string{"claims/other.yaml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoRoot := t.TempDir()
+			for _, f := range tt.files {
+				path := filepath.Join(repoRoot, f)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("claims: []\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := Discover(repoRoot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Discover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected ErrNotFound, got %v", err)
+				}
+				return
+			}
+			if want := filepath.Join(repoRoot, tt.want); got != want {
+				t.Errorf("Discover() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiscover_IgnoresDirectory(t *testing.T) {
+	repoRoot := t.TempDir()
+	// A directory named like a registry is skipped
+	if err := os.MkdirAll(filepath.Join(repoRoot, "claims", "registry.yaml"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoRoot, "registry.yaml"), []byte("claims: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Discover(repoRoot)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if want := filepath.Join(repoRoot, "registry.yaml"); got != want {
+		t.Errorf("Discover() = %s, want %s", got, want)
+	}
+}
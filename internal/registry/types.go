@@ -1,10 +1,15 @@
 package registry
 
+import "github.com/stuttgart-things/claims/internal/yamlnode"
+
 // ClaimRegistry represents the claims/registry.yaml file
 type ClaimRegistry struct {
 	APIVersion string       `yaml:"apiVersion"`
 	Kind       string       `yaml:"kind"`
 	Claims     []ClaimEntry `yaml:"claims"`
+
+	// doc is the parsed file, kept so Save preserves comments and other keys
+	doc *yamlnode.Document
 }
 
 // ClaimEntry represents a single claim in the registry
@@ -19,4 +24,18 @@ type ClaimEntry struct {
 	Repository string `yaml:"repository"`
 	Path       string `yaml:"path"`
 	Status     string `yaml:"status"`
+
+	// Labels are free-form selectors, e.g. for bulk re-renders
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Parameters are the template parameters the claim was last rendered with
+	Parameters map[string]any `yaml:"parameters,omitempty"`
+}
+
+// Selector picks registry entries by category, template, source and labels.
+// Empty fields match every entry; all labels must match.
+type Selector struct {
+	Category string
+	Template string
+	Source   string
+	Labels   map[string]string
 }
@@ -0,0 +1,100 @@
+package sops
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KSOPS generator defaults. The generator manifest lives next to the
+// encrypted files and is listed under generators: in kustomization.yaml.
+const (
+	KSOPSAPIVersion    = "viaduct.ai/v1"
+	KSOPSKind          = "ksops"
+	KSOPSGeneratorFile = "ksops.yaml"
+	KSOPSGeneratorName = "secret-generator"
+)
+
+// ksopsFunctionConfig tells kustomize to run ksops as an exec KRM function
+const ksopsFunctionConfig = "exec:\n  path: ksops\n"
+
+// KSOPSGenerator is a KSOPS generator manifest listing encrypted files
+type KSOPSGenerator struct {
+	APIVersion string        `yaml:"apiVersion"`
+	Kind       string        `yaml:"kind"`
+	Metadata   KSOPSMetadata `yaml:"metadata"`
+	Files      []string      `yaml:"files"`
+}
+
+// KSOPSMetadata holds the generator's name and KRM function annotation
+type KSOPSMetadata struct {
+	Name        string            `yaml:"name"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// NewKSOPSGenerator returns an empty generator manifest with the given name
+func NewKSOPSGenerator(name string) *KSOPSGenerator {
+	return &KSOPSGenerator{
+		APIVersion: KSOPSAPIVersion,
+		Kind:       KSOPSKind,
+		Metadata: KSOPSMetadata{
+			Name: name,
+			Annotations: map[string]string{
+				"config.kubernetes.io/function": ksopsFunctionConfig,
+			},
+		},
+		Files: []string{},
+	}
+}
+
+// LoadKSOPSGenerator reads the generator manifest at path, or returns a new
+// one named KSOPSGeneratorName if the file doesn't exist yet
+func LoadKSOPSGenerator(path string) (*KSOPSGenerator, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewKSOPSGenerator(KSOPSGeneratorName), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading KSOPS generator: %w", err)
+	}
+
+	var g KSOPSGenerator
+	if err := yaml.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parsing KSOPS generator: %w", err)
+	}
+	if g.Kind != KSOPSKind {
+		return nil, fmt.Errorf("%s is not a KSOPS generator (kind %q)", path, g.Kind)
+	}
+	return &g, nil
+}
+
+// SaveKSOPSGenerator writes the generator manifest to path, indented with
+// two spaces like hand-written kustomize manifests
+func SaveKSOPSGenerator(path string, g *KSOPSGenerator) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(g); err != nil {
+		return fmt.Errorf("marshalling KSOPS generator: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("marshalling KSOPS generator: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing KSOPS generator: %w", err)
+	}
+	return nil
+}
+
+// AddFile lists an encrypted file in the generator if it isn't already
+func (g *KSOPSGenerator) AddFile(file string) {
+	for _, f := range g.Files {
+		if f == file {
+			return
+		}
+	}
+	g.Files = append(g.Files, file)
+}
@@ -1,6 +1,7 @@
 package sops
 
 import (
+	"encoding/base64"
 	"fmt"
 
 	"gopkg.in/yaml.v3"
@@ -8,9 +9,12 @@ import (
 
 // SecretData holds the data needed to generate a Kubernetes Secret YAML.
 type SecretData struct {
-	Name       string
-	Namespace  string
-	StringData map[string]string
+	Name        string
+	Namespace   string
+	Type        string // defaults to Opaque
+	Labels      map[string]string
+	Annotations map[string]string
+	StringData  map[string]string
 }
 
 // GenerateSecretYAML produces a Kubernetes Secret manifest in YAML format.
@@ -22,14 +26,26 @@ func GenerateSecretYAML(data SecretData) ([]byte, error) {
 		return nil, fmt.Errorf("secret namespace is required")
 	}
 
+	metadata := map[string]any{
+		"name":      data.Name,
+		"namespace": data.Namespace,
+	}
+	if len(data.Labels) > 0 {
+		metadata["labels"] = data.Labels
+	}
+	if len(data.Annotations) > 0 {
+		metadata["annotations"] = data.Annotations
+	}
+	secretType := data.Type
+	if secretType == "" {
+		secretType = "Opaque"
+	}
+
 	secret := map[string]any{
 		"apiVersion": "v1",
 		"kind":       "Secret",
-		"metadata": map[string]any{
-			"name":      data.Name,
-			"namespace": data.Namespace,
-		},
-		"type":       "Opaque",
+		"metadata":   metadata,
+		"type":       secretType,
 		"stringData": data.StringData,
 	}
 
@@ -40,3 +56,49 @@ func GenerateSecretYAML(data SecretData) ([]byte, error) {
 
 	return out, nil
 }
+
+// ParseSecretYAML reads a Kubernetes Secret manifest, e.g. a decrypted one.
+// Values under data are base64-decoded and returned with the stringData
+// values, which take precedence as they do in Kubernetes. The type, labels
+// and annotations are kept so the Secret can be written back unchanged.
+func ParseSecretYAML(content []byte) (SecretData, error) {
+	var secret struct {
+		Kind     string `yaml:"kind"`
+		Metadata struct {
+			Name        string            `yaml:"name"`
+			Namespace   string            `yaml:"namespace"`
+			Labels      map[string]string `yaml:"labels"`
+			Annotations map[string]string `yaml:"annotations"`
+		} `yaml:"metadata"`
+		Type       string            `yaml:"type"`
+		Data       map[string]string `yaml:"data"`
+		StringData map[string]string `yaml:"stringData"`
+	}
+	if err := yaml.Unmarshal(content, &secret); err != nil {
+		return SecretData{}, fmt.Errorf("parsing secret YAML: %w", err)
+	}
+	if secret.Kind != "Secret" {
+		return SecretData{}, fmt.Errorf("not a Secret (kind %q)", secret.Kind)
+	}
+
+	stringData := make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for k, v := range secret.Data {
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return SecretData{}, fmt.Errorf("decoding data key %s: %w", k, err)
+		}
+		stringData[k] = string(decoded)
+	}
+	for k, v := range secret.StringData {
+		stringData[k] = v
+	}
+
+	return SecretData{
+		Name:        secret.Metadata.Name,
+		Namespace:   secret.Metadata.Namespace,
+		Type:        secret.Type,
+		Labels:      secret.Metadata.Labels,
+		Annotations: secret.Metadata.Annotations,
+		StringData:  stringData,
+	}, nil
+}
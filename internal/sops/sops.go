@@ -5,53 +5,234 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
 )
 
+// DefaultEncryptWorkers is how many sops processes EncryptAll runs at once
+// when no worker count is given
+const DefaultEncryptWorkers = 4
+
 // CheckSOPSInstalled returns true if the sops binary is on PATH.
 func CheckSOPSInstalled() bool {
 	_, err := exec.LookPath("sops")
 	return err == nil
 }
 
-// CheckSOPSAvailable verifies that the sops binary is installed and
-// the SOPS_AGE_RECIPIENTS environment variable is set.
-// It returns the recipients string on success.
-func CheckSOPSAvailable() (string, error) {
+// Recipients are the master keys sops encrypts to. Each field holds a
+// comma-separated list, as the matching sops flag does; any one is enough.
+type Recipients struct {
+	Age     string // age public keys (sops --age)
+	PGP     string // PGP fingerprints (sops --pgp)
+	KMS     string // AWS KMS key ARNs (sops --kms)
+	GCPKMS  string // GCP KMS resource IDs (sops --gcp-kms)
+	AzureKV string // Azure Key Vault key URLs (sops --azure-kv)
+}
+
+// IsZero reports whether no key is set
+func (r Recipients) IsZero() bool {
+	return r == Recipients{}
+}
+
+// Backends lists the key types that are set, e.g. "age, kms"
+func (r Recipients) Backends() string {
+	var backends []string
+	for _, b := range r.backends() {
+		if b.keys != "" {
+			backends = append(backends, b.name)
+		}
+	}
+	return strings.Join(backends, ", ")
+}
+
+// backends pairs each key type with its keys, in sops flag order
+func (r Recipients) backends() []struct{ name, keys string } {
+	return []struct{ name, keys string }{
+		{"age", r.Age},
+		{"pgp", r.PGP},
+		{"kms", r.KMS},
+		{"gcp-kms", r.GCPKMS},
+		{"azure-kv", r.AzureKV},
+	}
+}
+
+// args returns the sops flags selecting the keys that are set
+func (r Recipients) args() []string {
+	var args []string
+	for _, b := range r.backends() {
+		if b.keys != "" {
+			args = append(args, "--"+b.name, b.keys)
+		}
+	}
+	return args
+}
+
+// ResolveRecipients fills the age and PGP keys from SOPS_AGE_RECIPIENTS and
+// SOPS_PGP_FP unless they are already set
+func ResolveRecipients(keys Recipients) Recipients {
+	if keys.Age == "" {
+		keys.Age = os.Getenv("SOPS_AGE_RECIPIENTS")
+	}
+	if keys.PGP == "" {
+		keys.PGP = os.Getenv("SOPS_PGP_FP")
+	}
+	return keys
+}
+
+// CheckSOPSAvailable verifies that the sops binary is installed and that at
+// least one key is configured: the given keys (e.g. a KMS key from a flag),
+// SOPS_AGE_RECIPIENTS or SOPS_PGP_FP. It returns the resolved recipients.
+func CheckSOPSAvailable(keys Recipients) (Recipients, error) {
 	if !CheckSOPSInstalled() {
-		return "", fmt.Errorf("sops CLI not found: install from https://github.com/getsops/sops")
+		return keys, fmt.Errorf("sops CLI not found: install from https://github.com/getsops/sops")
 	}
 
-	recipients := os.Getenv("SOPS_AGE_RECIPIENTS")
-	if recipients == "" {
-		return "", fmt.Errorf("SOPS_AGE_RECIPIENTS environment variable is not set")
+	keys = ResolveRecipients(keys)
+	if keys.IsZero() {
+		return keys, fmt.Errorf("no encryption key: set SOPS_AGE_RECIPIENTS or SOPS_PGP_FP, or pass a --kms, --gcp-kms or --azure-kv key")
+	}
+
+	return keys, nil
+}
+
+// CheckDecryptKey checks that sops can find a private key to decrypt files
+// encrypted to recipients, e.g. before --verify. Only age keys are checked:
+// SOPS_AGE_KEY, SOPS_AGE_KEY_FILE (which must exist) or the default
+// sops/age/keys.txt in the user config directory. PGP and cloud KMS keys use
+// their own credentials and are left to sops.
+func CheckDecryptKey(recipients Recipients) error {
+	if recipients.Age == "" || recipients.Backends() != "age" {
+		return nil
+	}
+	if os.Getenv("SOPS_AGE_KEY") != "" {
+		return nil
+	}
+	if path := os.Getenv("SOPS_AGE_KEY_FILE"); path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("SOPS_AGE_KEY_FILE %s: %w", path, err)
+		}
+		return nil
 	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		if _, err := os.Stat(filepath.Join(dir, "sops", "age", "keys.txt")); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no age private key to decrypt with: set SOPS_AGE_KEY_FILE to your age key file or SOPS_AGE_KEY to the key")
+}
 
-	return recipients, nil
+// EncryptOptions controls which values sops encrypts.
+// At most one of the regex options may be set.
+type EncryptOptions struct {
+	// EncryptedRegex encrypts only keys matching the pattern (sops --encrypted-regex)
+	EncryptedRegex string
+	// UnencryptedRegex leaves keys matching the pattern in clear text (sops --unencrypted-regex)
+	UnencryptedRegex string
 }
 
-// Encrypt encrypts plaintext YAML using sops with age encryption.
+// Validate checks that the options can be passed to sops together.
+func (o EncryptOptions) Validate() error {
+	if o.EncryptedRegex != "" && o.UnencryptedRegex != "" {
+		return fmt.Errorf("only one of encrypted-regex and unencrypted-regex may be set")
+	}
+	if o.EncryptedRegex != "" {
+		if _, err := regexp.Compile(o.EncryptedRegex); err != nil {
+			return fmt.Errorf("invalid encrypted-regex: %w", err)
+		}
+	}
+	if o.UnencryptedRegex != "" {
+		if _, err := regexp.Compile(o.UnencryptedRegex); err != nil {
+			return fmt.Errorf("invalid unencrypted-regex: %w", err)
+		}
+	}
+	return nil
+}
+
+// encryptArgs builds the sops command-line arguments for encrypting path.
+func encryptArgs(path string, recipients Recipients, opts EncryptOptions) []string {
+	args := append([]string{"--encrypt"}, recipients.args()...)
+	if opts.EncryptedRegex != "" {
+		args = append(args, "--encrypted-regex", opts.EncryptedRegex)
+	}
+	if opts.UnencryptedRegex != "" {
+		args = append(args, "--unencrypted-regex", opts.UnencryptedRegex)
+	}
+	return append(args,
+		"--input-type", "yaml",
+		"--output-type", "yaml",
+		path,
+	)
+}
+
+// Encrypt encrypts plaintext YAML using sops with the given recipients.
 // It writes the plaintext to a temporary file, runs sops --encrypt, and
-// returns the encrypted output.
-func Encrypt(plaintext []byte, recipients string) ([]byte, error) {
+// returns the encrypted output. Every call uses its own temp file and sops
+// process, so it is safe for concurrent use.
+func Encrypt(plaintext []byte, recipients Recipients, opts EncryptOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if recipients.IsZero() {
+		return nil, fmt.Errorf("no encryption key given")
+	}
+
+	return runSOPS("encrypt", plaintext, func(path string) []string {
+		return encryptArgs(path, recipients, opts)
+	})
+}
+
+// Decrypt decrypts sops-encrypted YAML. sops runs with this process's
+// environment, so it finds the private key the usual way, e.g. through
+// SOPS_AGE_KEY_FILE or SOPS_AGE_KEY for age.
+func Decrypt(ciphertext []byte) ([]byte, error) {
+	return runSOPS("decrypt", ciphertext, func(path string) []string {
+		return []string{"--decrypt", "--input-type", "yaml", "--output-type", "yaml", path}
+	})
+}
+
+// Verify decrypts ciphertext and checks that it holds the same YAML document
+// as plaintext
+func Verify(ciphertext, plaintext []byte) error {
+	decrypted, err := Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+
+	var got, want any
+	if err := yaml.Unmarshal(decrypted, &got); err != nil {
+		return fmt.Errorf("parsing decrypted YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(plaintext, &want); err != nil {
+		return fmt.Errorf("parsing plaintext YAML: %w", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("decrypted content differs from the plaintext")
+	}
+	return nil
+}
+
+// runSOPS writes input to a temporary file and runs sops with the arguments
+// for that path, returning its output. The temp file is removed afterwards.
+func runSOPS(op string, input []byte, args func(path string) []string) ([]byte, error) {
 	tmpFile, err := os.CreateTemp("", "claims-secret-*.yaml")
 	if err != nil {
 		return nil, fmt.Errorf("creating temp file: %w", err)
 	}
 	defer os.Remove(tmpFile.Name())
 
-	if _, err := tmpFile.Write(plaintext); err != nil {
+	if _, err := tmpFile.Write(input); err != nil {
 		tmpFile.Close()
 		return nil, fmt.Errorf("writing temp file: %w", err)
 	}
 	tmpFile.Close()
 
-	cmd := exec.Command("sops",
-		"--encrypt",
-		"--age", recipients,
-		"--input-type", "yaml",
-		"--output-type", "yaml",
-		tmpFile.Name(),
-	)
+	// The environment is inherited, including the SOPS_* key settings
+	cmd := exec.Command("sops", args(tmpFile.Name())...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -62,8 +243,43 @@ func Encrypt(plaintext []byte, recipients string) ([]byte, error) {
 		if errMsg == "" {
 			errMsg = err.Error()
 		}
-		return nil, fmt.Errorf("sops encrypt failed: %s", errMsg)
+		return nil, fmt.Errorf("sops %s failed: %s", op, errMsg)
 	}
 
 	return stdout.Bytes(), nil
 }
+
+// EncryptAll encrypts each plaintext with Encrypt, running up to workers sops
+// processes at once (DefaultEncryptWorkers if workers < 1). The results and
+// per-plaintext errors are returned in input order.
+func EncryptAll(plaintexts [][]byte, recipients Recipients, opts EncryptOptions, workers int) ([][]byte, []error) {
+	if workers < 1 {
+		workers = DefaultEncryptWorkers
+	}
+	if workers > len(plaintexts) {
+		workers = len(plaintexts)
+	}
+
+	encrypted := make([][]byte, len(plaintexts))
+	errs := make([]error, len(plaintexts))
+
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				encrypted[i], errs[i] = Encrypt(plaintexts[i], recipients, opts)
+			}
+		}()
+	}
+
+	for i := range plaintexts {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	return encrypted, errs
+}
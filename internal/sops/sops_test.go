@@ -1,7 +1,11 @@
 package sops
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -38,6 +42,9 @@ func TestGenerateSecretYAML(t *testing.T) {
 	if !strings.Contains(yaml, "type: Opaque") {
 		t.Error("expected type: Opaque")
 	}
+	if strings.Contains(yaml, "labels:") || strings.Contains(yaml, "annotations:") {
+		t.Error("expected no empty labels or annotations")
+	}
 	if !strings.Contains(yaml, "username: admin") {
 		t.Error("expected username: admin in stringData")
 	}
@@ -46,6 +53,56 @@ func TestGenerateSecretYAML(t *testing.T) {
 	}
 }
 
+func TestParseSecretYAML(t *testing.T) {
+	content := []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: my-secret
+  namespace: apps
+  labels:
+    app: web
+  annotations:
+    owner: team-a
+type: kubernetes.io/basic-auth
+data:
+  token: czNjcmV0
+  password: b2xk
+stringData:
+  password: new
+`)
+	got, err := ParseSecretYAML(content)
+	if err != nil {
+		t.Fatalf("ParseSecretYAML() error = %v", err)
+	}
+	want := SecretData{
+		Name:        "my-secret",
+		Namespace:   "apps",
+		Type:        "kubernetes.io/basic-auth",
+		Labels:      map[string]string{"app": "web"},
+		Annotations: map[string]string{"owner": "team-a"},
+		StringData:  map[string]string{"token": "s3cret", "password": "new"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSecretYAML() = %+v, want %+v", got, want)
+	}
+
+	// A generated Secret round-trips
+	out, err := GenerateSecretYAML(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ParseSecretYAML(out); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, %v", got, err)
+	}
+
+	if _, err := ParseSecretYAML([]byte("kind: ConfigMap\n")); err == nil || !strings.Contains(err.Error(), "not a Secret") {
+		t.Errorf("expected a kind error, got %v", err)
+	}
+	if _, err := ParseSecretYAML([]byte("kind: Secret\ndata:\n  k: '!!'\n")); err == nil || !strings.Contains(err.Error(), "data key k") {
+		t.Errorf("expected a base64 error, got %v", err)
+	}
+}
+
 func TestGenerateSecretYAML_MissingName(t *testing.T) {
 	data := SecretData{
 		Namespace:  "default",
@@ -86,7 +143,9 @@ func TestCheckSOPSAvailable_NoRecipients(t *testing.T) {
 		}
 	}()
 
-	_, err := CheckSOPSAvailable()
+	t.Setenv("SOPS_PGP_FP", "")
+
+	_, err := CheckSOPSAvailable(Recipients{})
 	if err == nil {
 		t.Fatal("expected error when SOPS_AGE_RECIPIENTS is unset")
 	}
@@ -110,7 +169,7 @@ func TestEncrypt(t *testing.T) {
 
 	plaintext := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: test\nstringData:\n  key: value\n")
 
-	encrypted, err := Encrypt(plaintext, recipients)
+	encrypted, err := Encrypt(plaintext, Recipients{Age: recipients}, EncryptOptions{})
 	if err != nil {
 		t.Fatalf("Encrypt failed: %v", err)
 	}
@@ -124,3 +183,314 @@ func TestEncrypt(t *testing.T) {
 		t.Error("encrypted output should contain sops metadata")
 	}
 }
+
+func TestEncryptAll(t *testing.T) {
+	if !CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+
+	recipients := os.Getenv("SOPS_AGE_RECIPIENTS")
+	if recipients == "" {
+		t.Skip("SOPS_AGE_RECIPIENTS not set, skipping integration test")
+	}
+
+	var plaintexts [][]byte
+	for i := 0; i < 6; i++ {
+		plaintexts = append(plaintexts, []byte(fmt.Sprintf("apiVersion: v1\nkind: Secret\nmetadata:\n  name: secret-%d\nstringData:\n  key: value\n", i)))
+	}
+	// An invalid document fails on its own without affecting the others
+	plaintexts = append(plaintexts, []byte("key: [unclosed\n"))
+
+	// Leave the metadata readable to check the order of the results
+	opts := EncryptOptions{EncryptedRegex: "^stringData$"}
+	encrypted, errs := EncryptAll(plaintexts, Recipients{Age: recipients}, opts, 3)
+	if len(encrypted) != len(plaintexts) || len(errs) != len(plaintexts) {
+		t.Fatalf("expected %d results, got %d and %d errors", len(plaintexts), len(encrypted), len(errs))
+	}
+	for i := 0; i < 6; i++ {
+		if errs[i] != nil {
+			t.Fatalf("secret %d: %v", i, errs[i])
+		}
+		// Results keep the input order
+		if !strings.Contains(string(encrypted[i]), fmt.Sprintf("name: secret-%d", i)) || !strings.Contains(string(encrypted[i]), "sops") {
+			t.Errorf("secret %d: unexpected output:\n%s", i, encrypted[i])
+		}
+	}
+	if errs[6] == nil {
+		t.Error("expected the invalid document to fail")
+	}
+}
+
+func TestEncryptArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    EncryptOptions
+		want    string
+		notWant string
+	}{
+		{
+			name:    "no regex",
+			opts:    EncryptOptions{},
+			notWant: "regex",
+		},
+		{
+			name: "encrypted regex forwarded",
+			opts: EncryptOptions{EncryptedRegex: "^(data|stringData)$"},
+			want: "--encrypted-regex ^(data|stringData)$",
+		},
+		{
+			name: "unencrypted regex forwarded",
+			opts: EncryptOptions{UnencryptedRegex: "^metadata$"},
+			want: "--unencrypted-regex ^metadata$",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := strings.Join(encryptArgs("secret.yaml", Recipients{Age: "age1abc"}, tt.opts), " ")
+			if tt.want != "" && !strings.Contains(args, tt.want) {
+				t.Errorf("expected args to contain %q, got %q", tt.want, args)
+			}
+			if tt.notWant != "" && strings.Contains(args, tt.notWant) {
+				t.Errorf("expected args not to contain %q, got %q", tt.notWant, args)
+			}
+			if !strings.HasSuffix(args, "secret.yaml") {
+				t.Errorf("expected input file as last argument, got %q", args)
+			}
+		})
+	}
+}
+
+func TestEncryptArgs_Recipients(t *testing.T) {
+	tests := []struct {
+		name       string
+		recipients Recipients
+		want       string
+		backends   string
+	}{
+		{
+			name:       "age",
+			recipients: Recipients{Age: "age1abc"},
+			want:       "--encrypt --age age1abc --input-type",
+			backends:   "age",
+		},
+		{
+			name:       "pgp",
+			recipients: Recipients{PGP: "FBC7B9E2A4F9289AC0C1D4843D16CEE4A27381B4"},
+			want:       "--encrypt --pgp FBC7B9E2A4F9289AC0C1D4843D16CEE4A27381B4 --input-type",
+			backends:   "pgp",
+		},
+		{
+			name:       "aws kms",
+			recipients: Recipients{KMS: "arn:aws:kms:eu-central-1:123456789012:key/abc"},
+			want:       "--encrypt --kms arn:aws:kms:eu-central-1:123456789012:key/abc --input-type",
+			backends:   "kms",
+		},
+		{
+			name:       "gcp kms",
+			recipients: Recipients{GCPKMS: "projects/p/locations/global/keyRings/r/cryptoKeys/k"},
+			want:       "--encrypt --gcp-kms projects/p/locations/global/keyRings/r/cryptoKeys/k --input-type",
+			backends:   "gcp-kms",
+		},
+		{
+			name:       "azure key vault",
+			recipients: Recipients{AzureKV: "https://vault.vault.azure.net/keys/sops/1"},
+			want:       "--encrypt --azure-kv https://vault.vault.azure.net/keys/sops/1 --input-type",
+			backends:   "azure-kv",
+		},
+		{
+			name:       "kms alongside age",
+			recipients: Recipients{Age: "age1abc", KMS: "arn:aws:kms:key"},
+			want:       "--encrypt --age age1abc --kms arn:aws:kms:key --input-type",
+			backends:   "age, kms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := strings.Join(encryptArgs("secret.yaml", tt.recipients, EncryptOptions{}), " ")
+			if !strings.Contains(args, tt.want) {
+				t.Errorf("expected args to contain %q, got %q", tt.want, args)
+			}
+			if got := tt.recipients.Backends(); got != tt.backends {
+				t.Errorf("Backends() = %q, want %q", got, tt.backends)
+			}
+		})
+	}
+}
+
+func TestResolveRecipients(t *testing.T) {
+	t.Setenv("SOPS_AGE_RECIPIENTS", "age1env")
+	t.Setenv("SOPS_PGP_FP", "")
+
+	got := ResolveRecipients(Recipients{KMS: "arn:aws:kms:key"})
+	if got != (Recipients{Age: "age1env", KMS: "arn:aws:kms:key"}) {
+		t.Errorf("ResolveRecipients() = %+v", got)
+	}
+
+	// A KMS key alone is enough once the environment has no keys
+	t.Setenv("SOPS_AGE_RECIPIENTS", "")
+	if got := ResolveRecipients(Recipients{GCPKMS: "projects/p/k"}); got.IsZero() || got.Age != "" {
+		t.Errorf("ResolveRecipients() = %+v, want only the GCP KMS key", got)
+	}
+	if !ResolveRecipients(Recipients{}).IsZero() {
+		t.Error("expected no recipients without flags or environment")
+	}
+}
+
+func TestEncrypt_KMS(t *testing.T) {
+	if !CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+	arn := os.Getenv("SOPS_KMS_ARN")
+	if arn == "" {
+		t.Skip("SOPS_KMS_ARN not set, skipping KMS integration test")
+	}
+
+	plaintext := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: test\nstringData:\n  key: value\n")
+	encrypted, err := Encrypt(plaintext, Recipients{KMS: arn}, EncryptOptions{})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.Contains(string(encrypted), arn) {
+		t.Error("encrypted output should record the KMS key")
+	}
+}
+
+func TestEncryptOptions_Validate(t *testing.T) {
+	opts := EncryptOptions{EncryptedRegex: "^data$", UnencryptedRegex: "^metadata$"}
+	if err := opts.Validate(); err == nil {
+		t.Fatal("expected error when both regex options are set")
+	}
+	if err := (EncryptOptions{EncryptedRegex: "^data$"}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (EncryptOptions{EncryptedRegex: "^(data"}).Validate(); err == nil {
+		t.Fatal("expected error for invalid encrypted-regex")
+	}
+	if err := (EncryptOptions{UnencryptedRegex: "[metadata"}).Validate(); err == nil {
+		t.Fatal("expected error for invalid unencrypted-regex")
+	}
+}
+
+func TestEncrypt_EncryptedRegex(t *testing.T) {
+	if !CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+
+	recipients := os.Getenv("SOPS_AGE_RECIPIENTS")
+	if recipients == "" {
+		t.Skip("SOPS_AGE_RECIPIENTS not set, skipping integration test")
+	}
+
+	plaintext := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: test\nstringData:\n  key: value\n")
+
+	encrypted, err := Encrypt(plaintext, Recipients{Age: recipients}, EncryptOptions{EncryptedRegex: "^(data|stringData)$"})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	out := string(encrypted)
+	if !strings.Contains(out, "name: test") {
+		t.Error("metadata should remain readable with --encrypted-regex")
+	}
+	if strings.Contains(out, "key: value") {
+		t.Error("stringData values should be encrypted")
+	}
+	if !strings.Contains(out, "encrypted_regex") {
+		t.Error("sops metadata should record the encrypted_regex")
+	}
+}
+
+func TestCheckDecryptKey(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	age := Recipients{Age: "age1example"}
+
+	if err := CheckDecryptKey(age); err == nil || !strings.Contains(err.Error(), "SOPS_AGE_KEY_FILE") {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+	if err := CheckDecryptKey(Recipients{KMS: "arn:aws:kms:eu-central-1:1:key/x"}); err != nil {
+		t.Errorf("expected KMS keys to be left to sops, got %v", err)
+	}
+	if err := CheckDecryptKey(Recipients{Age: "age1example", PGP: "ABCD"}); err != nil {
+		t.Errorf("expected a PGP key to be enough, got %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	t.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+	if err := CheckDecryptKey(age); err == nil || !strings.Contains(err.Error(), keyFile) {
+		t.Errorf("expected an error naming the missing key file, got %v", err)
+	}
+	if err := os.WriteFile(keyFile, []byte("AGE-SECRET-KEY-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDecryptKey(age); err != nil {
+		t.Errorf("expected the key file to be accepted, got %v", err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY", "AGE-SECRET-KEY-1")
+	if err := CheckDecryptKey(age); err != nil {
+		t.Errorf("expected SOPS_AGE_KEY to be accepted, got %v", err)
+	}
+
+	t.Setenv("SOPS_AGE_KEY", "")
+	defaultKeys := filepath.Join(configDir, "sops", "age", "keys.txt")
+	if err := os.MkdirAll(filepath.Dir(defaultKeys), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(defaultKeys, []byte("AGE-SECRET-KEY-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckDecryptKey(age); err != nil {
+		t.Errorf("expected the default key file to be accepted, got %v", err)
+	}
+}
+
+func TestVerify_KeyFile(t *testing.T) {
+	if !CheckSOPSInstalled() {
+		t.Skip("sops not installed, skipping integration test")
+	}
+	if _, err := exec.LookPath("age-keygen"); err != nil {
+		t.Skip("age-keygen not installed, skipping integration test")
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "keys.txt")
+	if out, err := exec.Command("age-keygen", "-o", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("age-keygen: %v: %s", err, out)
+	}
+	out, err := exec.Command("age-keygen", "-y", keyFile).Output()
+	if err != nil {
+		t.Fatalf("age-keygen -y: %v", err)
+	}
+	recipients := Recipients{Age: strings.TrimSpace(string(out))}
+
+	plaintext := []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: test\nstringData:\n  key: value\n")
+	encrypted, err := Encrypt(plaintext, recipients, EncryptOptions{})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	// Decryption must find the key only through SOPS_AGE_KEY_FILE
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY_FILE", keyFile)
+	if err := CheckDecryptKey(recipients); err != nil {
+		t.Fatalf("CheckDecryptKey: %v", err)
+	}
+	if err := Verify(encrypted, plaintext); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if err := Verify(encrypted, []byte("stringData:\n  key: other\n")); err == nil {
+		t.Error("expected Verify to reject different plaintext")
+	}
+
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(t.TempDir(), "other.txt"))
+	if _, err := Decrypt(encrypted); err == nil {
+		t.Error("expected decryption to fail without the key file")
+	}
+}
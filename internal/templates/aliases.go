@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Aliases maps shorthand template names (e.g. "vm") to catalog names (e.g. "vsphere-vm")
+type Aliases map[string]string
+
+// aliasesFile is the on-disk format of the aliases config
+type aliasesFile struct {
+	Aliases Aliases `yaml:"aliases"`
+}
+
+// DefaultAliasesPath returns the aliases config location (~/.config/claims/aliases.yaml on Linux)
+func DefaultAliasesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving config directory: %w", err)
+	}
+	return filepath.Join(dir, "claims", "aliases.yaml"), nil
+}
+
+// LoadAliases reads template aliases from path. A missing file means no aliases.
+func LoadAliases(path string) (Aliases, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Aliases{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading aliases file: %w", err)
+	}
+
+	var f aliasesFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parsing aliases file: %w", err)
+	}
+	if f.Aliases == nil {
+		f.Aliases = Aliases{}
+	}
+
+	return f.Aliases, nil
+}
+
+// Resolve returns the catalog name for a user-supplied template name. Real
+// template names win: if name is both an alias and a template in known, name
+// is returned unchanged and shadowed is true.
+func (a Aliases) Resolve(name string, known map[string]bool) (resolved string, shadowed bool) {
+	target, isAlias := a[name]
+	if !isAlias {
+		return name, false
+	}
+	if known[name] {
+		return name, true
+	}
+	return target, false
+}
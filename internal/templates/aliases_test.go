@@ -0,0 +1,59 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAliases(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "aliases.yaml")
+
+	// Missing file: no aliases, no error
+	aliases, err := LoadAliases(path)
+	if err != nil {
+		t.Fatalf("LoadAliases() error = %v", err)
+	}
+	if len(aliases) != 0 {
+		t.Errorf("expected no aliases, got %v", aliases)
+	}
+
+	content := "aliases:\n  vm: vsphere-vm\n  db: postgresql\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	aliases, err = LoadAliases(path)
+	if err != nil {
+		t.Fatalf("LoadAliases() error = %v", err)
+	}
+	if aliases["vm"] != "vsphere-vm" || aliases["db"] != "postgresql" {
+		t.Errorf("unexpected aliases: %v", aliases)
+	}
+}
+
+func TestAliasesResolve(t *testing.T) {
+	aliases := Aliases{"vm": "vsphere-vm", "postgresql": "postgresql-ha"}
+	known := map[string]bool{"vsphere-vm": true, "postgresql": true, "postgresql-ha": true}
+
+	tests := []struct {
+		name         string
+		input        string
+		want         string
+		wantShadowed bool
+	}{
+		{name: "alias resolves", input: "vm", want: "vsphere-vm"},
+		{name: "real name passes through", input: "vsphere-vm", want: "vsphere-vm"},
+		{name: "unknown name passes through", input: "unknown", want: "unknown"},
+		{name: "real name wins over alias", input: "postgresql", want: "postgresql", wantShadowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, shadowed := aliases.Resolve(tt.input, known)
+			if got != tt.want || shadowed != tt.wantShadowed {
+				t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.input, got, shadowed, tt.want, tt.wantShadowed)
+			}
+		})
+	}
+}
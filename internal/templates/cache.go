@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCatalogTTL is how long a cached catalog is considered fresh
+const DefaultCatalogTTL = 24 * time.Hour
+
+// Catalog is a cached copy of the full template definitions returned by the API
+type Catalog struct {
+	APIUrl    string          `json:"apiUrl"`
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Items     []ClaimTemplate `json:"items"`
+}
+
+// DefaultCatalogPath returns the catalog cache location (~/.cache/claims/catalog.json on Linux)
+func DefaultCatalogPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving cache directory: %w", err)
+	}
+	return filepath.Join(dir, "claims", "catalog.json"), nil
+}
+
+// SaveCatalog writes a catalog to path, creating parent directories as needed
+func SaveCatalog(path string, catalog *Catalog) error {
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling catalog: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing catalog cache: %w", err)
+	}
+
+	return nil
+}
+
+// LoadCatalog reads a cached catalog from path
+func LoadCatalog(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading catalog cache: %w", err)
+	}
+
+	var catalog Catalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("parsing catalog cache: %w", err)
+	}
+
+	return &catalog, nil
+}
+
+// Expired reports whether the catalog is older than ttl at the given time.
+// A ttl <= 0 never expires.
+func (c *Catalog) Expired(ttl time.Duration, now time.Time) bool {
+	if ttl <= 0 {
+		return false
+	}
+	return now.Sub(c.FetchedAt) > ttl
+}
@@ -0,0 +1,171 @@
+package templates
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claims", "catalog.json")
+
+	fetchedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	catalog := &Catalog{
+		APIUrl:    "http://localhost:8080",
+		FetchedAt: fetchedAt,
+		Items: []ClaimTemplate{
+			{
+				Metadata: ClaimTemplateMetadata{Name: "vsphere-vm", Title: "vSphere VM"},
+				Spec: ClaimTemplateSpec{
+					Parameters: []Parameter{{Name: "name", Type: "string", Required: true}},
+				},
+			},
+		},
+	}
+
+	if err := SaveCatalog(path, catalog); err != nil {
+		t.Fatalf("SaveCatalog: %v", err)
+	}
+
+	loaded, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+
+	if loaded.APIUrl != catalog.APIUrl {
+		t.Errorf("expected apiUrl %s, got %s", catalog.APIUrl, loaded.APIUrl)
+	}
+	if !loaded.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("expected fetchedAt %v, got %v", fetchedAt, loaded.FetchedAt)
+	}
+	if len(loaded.Items) != 1 || loaded.Items[0].Metadata.Name != "vsphere-vm" {
+		t.Fatalf("unexpected items: %+v", loaded.Items)
+	}
+	if len(loaded.Items[0].Spec.Parameters) != 1 || !loaded.Items[0].Spec.Parameters[0].Required {
+		t.Errorf("expected full parameter definitions to be cached, got %+v", loaded.Items[0].Spec.Parameters)
+	}
+}
+
+func TestLoadCatalogNotFound(t *testing.T) {
+	if _, err := LoadCatalog(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected error for missing catalog")
+	}
+}
+
+func TestCatalogExpired(t *testing.T) {
+	fetchedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	catalog := &Catalog{FetchedAt: fetchedAt}
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		now  time.Time
+		want bool
+	}{
+		{"fresh", time.Hour, fetchedAt.Add(30 * time.Minute), false},
+		{"expired", time.Hour, fetchedAt.Add(2 * time.Hour), true},
+		{"zero ttl never expires", 0, fetchedAt.Add(1000 * time.Hour), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := catalog.Expired(tt.ttl, tt.now); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchTemplatesWritesCatalog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClaimTemplateList{
+			Items: []ClaimTemplate{{Metadata: ClaimTemplateMetadata{Name: "cached-template"}}},
+		})
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	client := NewClient(server.URL)
+	client.CatalogPath = path
+
+	if _, err := client.FetchTemplates(); err != nil {
+		t.Fatalf("FetchTemplates: %v", err)
+	}
+
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatalf("expected catalog to be written: %v", err)
+	}
+	if catalog.APIUrl != server.URL {
+		t.Errorf("expected apiUrl %s, got %s", server.URL, catalog.APIUrl)
+	}
+	if len(catalog.Items) != 1 || catalog.Items[0].Metadata.Name != "cached-template" {
+		t.Errorf("unexpected cached items: %+v", catalog.Items)
+	}
+}
+
+func TestFetchTemplatesCached(t *testing.T) {
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(ClaimTemplateList{
+			Items: []ClaimTemplate{{Metadata: ClaimTemplateMetadata{Name: "cached-template"}}},
+		})
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	client := NewClient(server.URL)
+	client.CatalogPath = path
+	client.Now = func() time.Time { return now }
+
+	fetch := func() {
+		t.Helper()
+		items, err := client.FetchTemplatesCached(time.Hour)
+		if err != nil {
+			t.Fatalf("FetchTemplatesCached: %v", err)
+		}
+		if len(items) != 1 || items[0].Metadata.Name != "cached-template" {
+			t.Fatalf("unexpected items: %+v", items)
+		}
+	}
+
+	fetch()
+	if fetches != 1 {
+		t.Fatalf("expected a fetch on an empty cache, got %d", fetches)
+	}
+
+	now = now.Add(30 * time.Minute)
+	fetch()
+	if fetches != 1 {
+		t.Errorf("expected a cache hit within the TTL, got %d fetches", fetches)
+	}
+
+	now = now.Add(time.Hour)
+	fetch()
+	if fetches != 2 {
+		t.Errorf("expected a refetch after the TTL, got %d fetches", fetches)
+	}
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !catalog.FetchedAt.Equal(now) {
+		t.Errorf("expected the cache to be rewritten at %s, got %s", now, catalog.FetchedAt)
+	}
+
+	// A catalog fetched from another API is a miss
+	other := NewClient(server.URL + "/")
+	other.CatalogPath = path
+	other.Now = client.Now
+	if _, err := other.FetchTemplatesCached(time.Hour); err != nil {
+		t.Fatalf("FetchTemplatesCached: %v", err)
+	}
+	if fetches != 3 {
+		t.Errorf("expected a refetch for another API URL, got %d fetches", fetches)
+	}
+}
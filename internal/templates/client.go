@@ -2,17 +2,51 @@ package templates
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 )
 
+// ErrVersionsUnsupported is returned by FetchTemplateVersions when the API
+// does not expose template versions
+var ErrVersionsUnsupported = errors.New("API does not support template versions")
+
+// ErrTemplateNotFound is returned by GetTemplate when the API has no template
+// of that name
+var ErrTemplateNotFound = errors.New("template not found")
+
 // Client is the API client for claim templates
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+
+	// CatalogPath, when set, receives a copy of every successful FetchTemplates result
+	CatalogPath string
+
+	// AuthToken, when set, is sent as "Authorization: Bearer <token>" with every request
+	AuthToken string
+
+	// MaxRetries is how often a request failing transiently (connection
+	// error, 502, 503, 504) is retried; 0 disables retries
+	MaxRetries int
+
+	// RetryBaseDelay is the wait before the first retry, doubled for each further one
+	RetryBaseDelay time.Duration
+
+	// Limiter, when set, bounds the requests in flight; share it between
+	// clients to bound them together. Each attempt holds a slot until its
+	// response body is closed.
+	Limiter *Limiter
+
+	// Now returns the current time for catalog timestamps and expiry; nil = time.Now
+	Now func() time.Time
 }
 
 // NewClient creates a new template API client
@@ -33,9 +67,175 @@ func NewClientWithHTTPClient(baseURL string, httpClient *http.Client) *Client {
 	}
 }
 
+// NewClientWithRetry creates a new template API client that retries
+// transient failures up to maxRetries times, waiting baseDelay before the
+// first retry and doubling the wait for each further one
+func NewClientWithRetry(baseURL string, maxRetries int, baseDelay time.Duration) *Client {
+	client := NewClient(baseURL)
+	client.MaxRetries = maxRetries
+	client.RetryBaseDelay = baseDelay
+	return client
+}
+
+// NewClientWithTLS creates a new template API client for an HTTPS API whose
+// certificate is signed by the CA bundle in caFile (PEM; "" = system roots
+// only). insecureSkipVerify disables certificate verification altogether.
+func NewClientWithTLS(baseURL, caFile string, insecureSkipVerify bool) (*Client, error) {
+	tlsConfig, err := NewTLSConfig(caFile, insecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return NewClientWithHTTPClient(baseURL, &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+	}), nil
+}
+
+// NewTLSConfig returns a TLS configuration trusting the system roots plus
+// the PEM certificates in caFile (if set)
+func NewTLSConfig(caFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if caFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in CA file %s", caFile)
+	}
+	config.RootCAs = pool
+	return config, nil
+}
+
+// do sends req, adding the bearer token if one is configured. Transient
+// failures are retried with exponential backoff (see MaxRetries) until the
+// request's context is done; the last response or error is returned.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.AuthToken)
+	}
+
+	delay := c.RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(req)
+		if attempt >= c.MaxRetries || !retryable(req.Context(), resp, err) {
+			return resp, err
+		}
+		if !waitForRetry(req.Context(), delay) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+		delay *= 2
+	}
+}
+
+// send performs a single attempt of req within a Limiter slot
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if err := c.Limiter.Acquire(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		c.Limiter.Release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: c.Limiter.Release}
+	return resp, nil
+}
+
+// retryable reports whether a request outcome is a transient failure worth
+// retrying: a connection error (unless ctx is done) or a 502, 503 or 504
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// waitForRetry sleeps for delay, returning false without waiting when ctx's
+// deadline would pass first, or as soon as ctx is done
+func waitForRetry(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// now returns the current time from Now, or time.Now if unset
+func (c *Client) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+// FetchTemplatesCached returns the templates from the catalog cache when it
+// is fresh, fetching them from the API otherwise
+func (c *Client) FetchTemplatesCached(ttl time.Duration) ([]ClaimTemplate, error) {
+	return c.FetchTemplatesCachedContext(context.Background(), ttl)
+}
+
+// FetchTemplatesCachedContext returns the templates from the catalog at
+// CatalogPath if it was fetched from this API within ttl (<= 0 never
+// expires). On a miss it fetches them like FetchTemplatesContext, which
+// rewrites the cache.
+func (c *Client) FetchTemplatesCachedContext(ctx context.Context, ttl time.Duration) ([]ClaimTemplate, error) {
+	if c.CatalogPath != "" {
+		catalog, err := LoadCatalog(c.CatalogPath)
+		if err == nil && catalog.APIUrl == c.BaseURL && !catalog.Expired(ttl, c.now()) {
+			return catalog.Items, nil
+		}
+	}
+	return c.FetchTemplatesContext(ctx)
+}
+
 // FetchTemplates retrieves all templates from the API
 func (c *Client) FetchTemplates() ([]ClaimTemplate, error) {
-	resp, err := c.HTTPClient.Get(c.BaseURL + "/api/v1/claim-templates")
+	return c.FetchTemplatesContext(context.Background())
+}
+
+// FetchTemplatesContext retrieves all templates from the API, aborting when ctx is done
+func (c *Client) FetchTemplatesContext(ctx context.Context) ([]ClaimTemplate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/claim-templates", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -51,19 +251,81 @@ func (c *Client) FetchTemplates() ([]ClaimTemplate, error) {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	// Caching is best effort; a read-only cache dir must not break fetching
+	if c.CatalogPath != "" {
+		_ = SaveCatalog(c.CatalogPath, &Catalog{
+			APIUrl:    c.BaseURL,
+			FetchedAt: c.now().UTC(),
+			Items:     list.Items,
+		})
+	}
+
 	return list.Items, nil
 }
 
+// GetTemplate retrieves a single template by name from the API
+func (c *Client) GetTemplate(name string) (*ClaimTemplate, error) {
+	return c.GetTemplateContext(context.Background(), name)
+}
+
+// GetTemplateContext retrieves a single template like GetTemplate, aborting when ctx is done
+func (c *Client) GetTemplateContext(ctx context.Context, name string) (*ClaimTemplate, error) {
+	url := fmt.Sprintf("%s/api/v1/claim-templates/%s", c.BaseURL, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tmpl ClaimTemplate
+	if err := json.NewDecoder(resp.Body).Decode(&tmpl); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &tmpl, nil
+}
+
 // RenderTemplate calls the API to render a template with the given parameters
 func (c *Client) RenderTemplate(templateName string, params map[string]interface{}) (string, error) {
-	reqBody := OrderRequest{Parameters: params}
+	return c.RenderTemplateContext(context.Background(), templateName, params)
+}
+
+// RenderTemplateContext renders a template like RenderTemplate, aborting when ctx is done
+func (c *Client) RenderTemplateContext(ctx context.Context, templateName string, params map[string]interface{}) (string, error) {
+	return c.RenderTemplateVersionContext(ctx, templateName, "", params)
+}
+
+// RenderTemplateVersionContext renders a template at the given tag instead of
+// the catalog default. An empty tag renders the catalog default.
+func (c *Client) RenderTemplateVersionContext(ctx context.Context, templateName, tag string, params map[string]interface{}) (string, error) {
+	reqBody := OrderRequest{Parameters: params, Tag: tag}
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/api/v1/claim-templates/%s/order", c.BaseURL, templateName)
-	resp, err := c.HTTPClient.Post(url, "application/json", bytes.NewReader(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -81,3 +343,40 @@ func (c *Client) RenderTemplate(templateName string, params map[string]interface
 
 	return orderResp.Rendered, nil
 }
+
+// FetchTemplateVersions lists the tags available for a template. It returns
+// ErrVersionsUnsupported when the API has no versions endpoint.
+func (c *Client) FetchTemplateVersions(templateName string) ([]string, error) {
+	return c.FetchTemplateVersionsContext(context.Background(), templateName)
+}
+
+// FetchTemplateVersionsContext lists template tags like FetchTemplateVersions, aborting when ctx is done
+func (c *Client) FetchTemplateVersionsContext(ctx context.Context, templateName string) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v1/claim-templates/%s/versions", c.BaseURL, templateName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusNotImplemented, http.StatusMethodNotAllowed:
+		return nil, ErrVersionsUnsupported
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var list TemplateVersionList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return list.Versions, nil
+}
@@ -1,10 +1,19 @@
 package templates
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -196,3 +205,510 @@ func TestRenderTemplateConnectionError(t *testing.T) {
 		t.Error("expected connection error, got nil")
 	}
 }
+
+func TestRenderTemplateContext_DeadlineExceeded(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewClient(server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.RenderTemplateContext(ctx, "slow", map[string]interface{}{"name": "x"})
+	if err == nil {
+		t.Fatal("expected error when the deadline is exceeded")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request was not cancelled promptly (took %v)", elapsed)
+	}
+
+	if _, err := client.FetchTemplatesContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected FetchTemplatesContext to fail with expired context, got %v", err)
+	}
+}
+
+func TestRenderTemplateContext_CancelledMidRequest(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, err := client.RenderTemplateContext(ctx, "slow", map[string]interface{}{"name": "x"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchTemplatesContext_CancelledMidRequest(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read
+		io.Copy(io.Discard, r.Body)
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	if _, err := client.FetchTemplatesContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchTemplateVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/claim-templates/vsphere-vm/versions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(TemplateVersionList{Versions: []string{"v1.0.0", "v1.1.0"}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	versions, err := client.FetchTemplateVersions("vsphere-vm")
+	if err != nil {
+		t.Fatalf("FetchTemplateVersions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "v1.0.0" || versions[1] != "v1.1.0" {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+}
+
+func TestFetchTemplateVersions_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.FetchTemplateVersions("vsphere-vm"); !errors.Is(err, ErrVersionsUnsupported) {
+		t.Errorf("expected ErrVersionsUnsupported, got %v", err)
+	}
+}
+
+func TestGetTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/claim-templates/vsphere-vm":
+			json.NewEncoder(w).Encode(ClaimTemplate{
+				Metadata: ClaimTemplateMetadata{Name: "vsphere-vm"},
+				Spec:     ClaimTemplateSpec{Parameters: []Parameter{{Name: "cpu", Type: "string"}}},
+			})
+		case "/api/v1/claim-templates/broken":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	tmpl, err := client.GetTemplate("vsphere-vm")
+	if err != nil {
+		t.Fatalf("GetTemplate() error = %v", err)
+	}
+	if tmpl.Metadata.Name != "vsphere-vm" || len(tmpl.Spec.Parameters) != 1 || tmpl.Spec.Parameters[0].Name != "cpu" {
+		t.Errorf("unexpected template: %+v", tmpl)
+	}
+
+	_, err = client.GetTemplate("missing")
+	if !errors.Is(err, ErrTemplateNotFound) || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("expected ErrTemplateNotFound naming the template, got %v", err)
+	}
+
+	_, err = client.GetTemplate("broken")
+	if err == nil || errors.Is(err, ErrTemplateNotFound) || !strings.Contains(err.Error(), "500") {
+		t.Errorf("expected an API error, got %v", err)
+	}
+}
+
+func TestRenderTemplateVersionContext_SendsTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		json.NewEncoder(w).Encode(OrderResponse{Rendered: "tag: " + req.Tag})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	rendered, err := client.RenderTemplateVersionContext(context.Background(), "vsphere-vm", "v1.1.0", map[string]interface{}{"name": "vm"})
+	if err != nil {
+		t.Fatalf("RenderTemplateVersionContext() error = %v", err)
+	}
+	if rendered != "tag: v1.1.0" {
+		t.Errorf("expected tag to be sent, got %q", rendered)
+	}
+
+	// No tag: the field is omitted so the catalog default applies
+	rendered, err = client.RenderTemplate("vsphere-vm", nil)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	if rendered != "tag: " {
+		t.Errorf("expected no tag, got %q", rendered)
+	}
+}
+
+func TestClient_AuthToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{name: "token sent as bearer", token: "s3cr3t", want: "Bearer s3cr3t"},
+		{name: "no header without token", token: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, r.Header.Get("Authorization"))
+				if r.Method == http.MethodPost {
+					json.NewEncoder(w).Encode(OrderResponse{Rendered: "kind: VM"})
+					return
+				}
+				json.NewEncoder(w).Encode(ClaimTemplateList{})
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL)
+			client.AuthToken = tt.token
+			if _, err := client.FetchTemplates(); err != nil {
+				t.Fatalf("FetchTemplates() error: %v", err)
+			}
+			if _, err := client.RenderTemplate("vm", nil); err != nil {
+				t.Fatalf("RenderTemplate() error: %v", err)
+			}
+
+			if len(got) != 2 || got[0] != tt.want || got[1] != tt.want {
+				t.Errorf("Authorization headers = %q, want %q on both requests", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientWithRetry(t *testing.T) {
+	client := NewClientWithRetry("http://example.com", 3, 100*time.Millisecond)
+
+	if client.MaxRetries != 3 || client.RetryBaseDelay != 100*time.Millisecond {
+		t.Errorf("retry settings = %d/%v, want 3/100ms", client.MaxRetries, client.RetryBaseDelay)
+	}
+	if client.HTTPClient == nil {
+		t.Error("expected HTTPClient to be initialized")
+	}
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int // responses with failStatus before succeeding
+		failStatus   int
+		maxRetries   int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "succeeds after two 503s", failures: 2, failStatus: http.StatusServiceUnavailable, maxRetries: 3, wantRequests: 3},
+		{name: "502 and 504 are retried", failures: 2, failStatus: http.StatusGatewayTimeout, maxRetries: 2, wantRequests: 3},
+		{name: "gives up after max retries", failures: 5, failStatus: http.StatusBadGateway, maxRetries: 2, wantRequests: 3, wantErr: true},
+		{name: "400 fails fast", failures: 5, failStatus: http.StatusBadRequest, maxRetries: 3, wantRequests: 1, wantErr: true},
+		{name: "404 fails fast", failures: 5, failStatus: http.StatusNotFound, maxRetries: 3, wantRequests: 1, wantErr: true},
+		{name: "422 fails fast", failures: 5, failStatus: http.StatusUnprocessableEntity, maxRetries: 3, wantRequests: 1, wantErr: true},
+		{name: "retries disabled", failures: 1, failStatus: http.StatusServiceUnavailable, maxRetries: 0, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				if requests <= tt.failures {
+					http.Error(w, "rolling out", tt.failStatus)
+					return
+				}
+				json.NewEncoder(w).Encode(OrderResponse{Rendered: "kind: VM"})
+			}))
+			defer server.Close()
+
+			client := NewClientWithRetry(server.URL, tt.maxRetries, time.Millisecond)
+			rendered, err := client.RenderTemplate("vm", map[string]interface{}{"name": "web"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && rendered != "kind: VM" {
+				t.Errorf("rendered = %q", rendered)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+			// Every retry must resend the full request body
+			for i, b := range bodies {
+				if b != bodies[0] || b == "" {
+					t.Errorf("request %d body = %q, want %q", i+1, b, bodies[0])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Limiter(t *testing.T) {
+	const limit = 2
+
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "/order") {
+			json.NewEncoder(w).Encode(OrderResponse{Rendered: "kind: VM"})
+			return
+		}
+		json.NewEncoder(w).Encode(ClaimTemplateList{})
+	}))
+	defer server.Close()
+
+	// Two clients sharing the limiter, as render and fetch do
+	limiter := NewLimiter(limit)
+	renderClient, fetchClient := NewClient(server.URL), NewClient(server.URL)
+	renderClient.Limiter, fetchClient.Limiter = limiter, limiter
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := renderClient.RenderTemplate("vm", map[string]interface{}{"name": "web"})
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := fetchClient.FetchTemplates()
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+	}
+
+	if peak > limit {
+		t.Errorf("%d requests were in flight at once, want at most %d", peak, limit)
+	}
+	if peak == 0 {
+		t.Error("expected the handler to see requests")
+	}
+}
+
+func TestClient_LimiterCancelled(t *testing.T) {
+	limiter := NewLimiter(1)
+	if err := limiter.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer limiter.Release()
+
+	client := NewClient("http://localhost:1")
+	client.Limiter = limiter
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The only slot is taken, so the request waits until ctx is done
+	if _, err := client.RenderTemplateContext(ctx, "vm", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded while waiting for a slot, got %v", err)
+	}
+}
+
+func TestNewLimiter_Unlimited(t *testing.T) {
+	if l := NewLimiter(0); l != nil {
+		t.Errorf("NewLimiter(0) = %v, want nil", l)
+	}
+	var l *Limiter
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("nil Limiter Acquire() error: %v", err)
+	}
+	l.Release()
+}
+
+func TestClient_RetryConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close() // connection refused from now on
+
+	client := NewClientWithRetry(url, 2, time.Millisecond)
+	start := time.Now()
+	if _, err := client.FetchTemplates(); err == nil {
+		t.Fatal("expected connection error")
+	}
+	// Backoff waits 1ms, then 2ms
+	if elapsed := time.Since(start); elapsed < 3*time.Millisecond {
+		t.Errorf("expected backoff between retries, finished in %v", elapsed)
+	}
+}
+
+func TestClient_RetryRespectsDeadline(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "rolling out", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The first backoff alone would outlast the deadline
+	client := NewClientWithRetry(server.URL, 5, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.FetchTemplatesContext(ctx)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry ignored the context deadline, took %v", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestRenderTemplateContext_CancelledDuringRetry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "rolling out", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Without a deadline the backoff would wait a minute before the first retry
+	client := NewClientWithRetry(server.URL, 3, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if _, err := client.RenderTemplateContext(ctx, "vm", nil); err == nil {
+		t.Fatal("expected error after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation did not interrupt the backoff, took %v", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestNewClientWithTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ClaimTemplateList{Items: []ClaimTemplate{{Metadata: ClaimTemplateMetadata{Name: "vsphere-vm"}}}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("trusts the CA file", func(t *testing.T) {
+		client, err := NewClientWithTLS(server.URL, caFile, false)
+		if err != nil {
+			t.Fatalf("NewClientWithTLS() error = %v", err)
+		}
+		items, err := client.FetchTemplates()
+		if err != nil {
+			t.Fatalf("FetchTemplates() error = %v", err)
+		}
+		if len(items) != 1 {
+			t.Errorf("expected 1 template, got %d", len(items))
+		}
+	})
+
+	t.Run("rejects an unknown CA", func(t *testing.T) {
+		client, err := NewClientWithTLS(server.URL, "", false)
+		if err != nil {
+			t.Fatalf("NewClientWithTLS() error = %v", err)
+		}
+		if _, err := client.FetchTemplates(); err == nil || !strings.Contains(err.Error(), "certificate") {
+			t.Errorf("expected a certificate error, got %v", err)
+		}
+	})
+
+	t.Run("skips verification", func(t *testing.T) {
+		client, err := NewClientWithTLS(server.URL, "", true)
+		if err != nil {
+			t.Fatalf("NewClientWithTLS() error = %v", err)
+		}
+		if _, err := client.FetchTemplates(); err != nil {
+			t.Errorf("FetchTemplates() error = %v", err)
+		}
+	})
+
+	t.Run("unreadable CA file", func(t *testing.T) {
+		_, err := NewClientWithTLS(server.URL, filepath.Join(dir, "missing.pem"), false)
+		if err == nil || !strings.Contains(err.Error(), "reading CA certificate") {
+			t.Errorf("expected a read error, got %v", err)
+		}
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		_, err := NewClientWithTLS(server.URL, garbage, false)
+		if err == nil || !strings.Contains(err.Error(), "no PEM certificates found") {
+			t.Errorf("expected a parse error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package templates
+
+// ApplyDefaults returns params with the template's default filled in for
+// every parameter that isn't set, as the interactive form does. Hidden and
+// valueFrom parameters are left to the API. params is not modified.
+func ApplyDefaults(tmpl ClaimTemplate, params map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(params)+len(tmpl.Spec.Parameters))
+	for k, v := range params {
+		result[k] = v
+	}
+	for _, p := range tmpl.Spec.Parameters {
+		if p.Default == nil || p.Hidden || p.ValueFrom != nil {
+			continue
+		}
+		if _, ok := result[p.Name]; !ok {
+			result[p.Name] = p.Default
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package templates
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	tmpl := ClaimTemplate{
+		Spec: ClaimTemplateSpec{
+			Parameters: []Parameter{
+				{Name: "name", Default: "default-vm"},
+				{Name: "cpu", Default: 2},
+				{Name: "size", Default: "small"},
+				{Name: "network"},
+				{Name: "token", Default: "generated", Hidden: true},
+				{Name: "zone", Default: "a", ValueFrom: &ValueFromSpec{Function: "zone"}},
+			},
+		},
+	}
+	params := map[string]interface{}{"name": "web", "size": ""}
+
+	got := ApplyDefaults(tmpl, params)
+	want := map[string]interface{}{"name": "web", "size": "", "cpu": 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyDefaults = %v, want %v", got, want)
+	}
+	if len(params) != 2 {
+		t.Errorf("expected params to be left alone, got %v", params)
+	}
+
+	if got := ApplyDefaults(tmpl, nil); !reflect.DeepEqual(got, map[string]interface{}{"name": "default-vm", "cpu": 2, "size": "small"}) {
+		t.Errorf("ApplyDefaults(nil) = %v", got)
+	}
+}
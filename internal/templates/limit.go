@@ -0,0 +1,58 @@
+package templates
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Limiter bounds the number of API requests in flight. One Limiter can be
+// shared by several clients so the bound holds across all of them. A nil
+// Limiter doesn't limit.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing n requests in flight, or nil (no
+// limit) if n is 0 or less
+func NewLimiter(n int) *Limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, n)}
+}
+
+// Acquire waits for a free slot, or returns ctx's error once it is done
+func (l *Limiter) Acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (l *Limiter) Release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// releasingBody releases the request's slot when the response body is
+// closed, so a request counts as in flight until its body was consumed
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
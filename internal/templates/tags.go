@@ -0,0 +1,32 @@
+package templates
+
+import (
+	"slices"
+	"strings"
+)
+
+// FilterByTags returns the templates carrying all of the given tags, in list
+// order. Tags are compared case-insensitively; no tags returns list unchanged.
+func FilterByTags(list []ClaimTemplate, tags []string) []ClaimTemplate {
+	if len(tags) == 0 {
+		return list
+	}
+
+	var filtered []ClaimTemplate
+	for _, t := range list {
+		if hasAllTags(t.Metadata.Tags, tags) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// hasAllTags reports whether have contains every tag in want
+func hasAllTags(have, want []string) bool {
+	for _, tag := range want {
+		if !slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, tag) }) {
+			return false
+		}
+	}
+	return true
+}
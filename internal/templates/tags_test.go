@@ -0,0 +1,39 @@
+package templates
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterByTags(t *testing.T) {
+	list := []ClaimTemplate{
+		{Metadata: ClaimTemplateMetadata{Name: "vsphere-vm", Tags: []string{"vm", "vsphere", "infra"}}},
+		{Metadata: ClaimTemplateMetadata{Name: "proxmox-vm", Tags: []string{"vm", "proxmox", "infra"}}},
+		{Metadata: ClaimTemplateMetadata{Name: "postgres", Tags: []string{"database", "infra"}}},
+		{Metadata: ClaimTemplateMetadata{Name: "untagged"}},
+	}
+
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{name: "no tags", tags: nil, want: []string{"vsphere-vm", "proxmox-vm", "postgres", "untagged"}},
+		{name: "single tag", tags: []string{"vm"}, want: []string{"vsphere-vm", "proxmox-vm"}},
+		{name: "all tags must match", tags: []string{"vm", "proxmox"}, want: []string{"proxmox-vm"}},
+		{name: "case-insensitive", tags: []string{"Infra", "DATABASE"}, want: []string{"postgres"}},
+		{name: "no match", tags: []string{"vm", "database"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, tmpl := range FilterByTags(list, tt.tags) {
+				got = append(got, tmpl.Metadata.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterByTags(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
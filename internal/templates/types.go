@@ -50,7 +50,12 @@ type Parameter struct {
 	Default     interface{} `json:"default,omitempty"`
 	Required    bool        `json:"required,omitempty"`
 	Enum        []string    `json:"enum,omitempty"`
+	Examples    []string    `json:"examples,omitempty"`  // sample values showing the expected format
 	Pattern     string      `json:"pattern,omitempty"`
+	Min         *int        `json:"min,omitempty"`       // smallest allowed integer value
+	Max         *int        `json:"max,omitempty"`       // largest allowed integer value
+	MinLength   *int        `json:"minLength,omitempty"` // shortest allowed string, in characters
+	MaxLength   *int        `json:"maxLength,omitempty"` // longest allowed string, in characters
 	Hidden      bool        `json:"hidden,omitempty"`
 	AllowRandom bool        `json:"allowRandom,omitempty"`
 	Multiselect bool        `json:"multiselect,omitempty"`
@@ -67,6 +72,12 @@ type ClaimTemplateList struct {
 // OrderRequest is the request body for rendering a template
 type OrderRequest struct {
 	Parameters map[string]interface{} `json:"parameters"`
+	Tag        string                 `json:"tag,omitempty"` // overrides the template's catalog tag
+}
+
+// TemplateVersionList is the response listing the tags available for a template
+type TemplateVersionList struct {
+	Versions []string `json:"versions"`
 }
 
 // OrderResponse is the response from rendering a template
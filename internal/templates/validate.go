@@ -0,0 +1,124 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Problem is a parameter value that doesn't satisfy its template's definition
+type Problem struct {
+	Parameter string `json:"parameter"`
+	Message   string `json:"message"`
+}
+
+// Error makes a Problem usable as an error, e.g. "size: \"huge\" is not one of small, large"
+func (p Problem) Error() string {
+	return p.Parameter + ": " + p.Message
+}
+
+// ValidateParams checks params against the template's parameter definitions:
+// required parameters must be set, and set values must match their enum and
+// pattern. Hidden and valueFrom parameters are resolved server-side and only
+// checked when set; parameters the template doesn't define are ignored.
+func ValidateParams(t *ClaimTemplate, params map[string]any) []Problem {
+	var problems []Problem
+	for _, p := range t.Spec.Parameters {
+		value, ok := params[p.Name]
+		if !ok || value == nil || value == "" {
+			if requiredUnset(p) {
+				problems = append(problems, Problem{p.Name, "required parameter is not set"})
+			}
+			continue
+		}
+
+		values := []string{fmt.Sprint(value)}
+		if list, isList := value.([]any); isList {
+			values = values[:0]
+			for _, v := range list {
+				values = append(values, fmt.Sprint(v))
+			}
+		}
+
+		for _, v := range values {
+			if len(p.Enum) > 0 && !slices.Contains(p.Enum, v) {
+				problems = append(problems, Problem{p.Name, fmt.Sprintf("%q is not one of %s", v, strings.Join(p.Enum, ", "))})
+			}
+			if err := ValidatePattern(p, v); err != nil {
+				problems = append(problems, Problem{p.Name, err.Error()})
+			}
+			if err := ValidateRange(p, v); err != nil {
+				problems = append(problems, Problem{p.Name, err.Error()})
+			}
+		}
+	}
+	return problems
+}
+
+// MissingRequired returns the names of t's required parameters that params
+// leaves unset, by the same rule as ValidateParams but without any other check
+func MissingRequired(t *ClaimTemplate, params map[string]any) []string {
+	var missing []string
+	for _, p := range t.Spec.Parameters {
+		value, ok := params[p.Name]
+		if (!ok || value == nil || value == "") && requiredUnset(p) {
+			missing = append(missing, p.Name)
+		}
+	}
+	return missing
+}
+
+// requiredUnset reports whether an unset parameter is a problem: it is
+// required and neither a default nor the API (hidden, valueFrom) fills it in
+func requiredUnset(p Parameter) bool {
+	return p.Required && p.Default == nil && p.ValueFrom == nil && !p.Hidden
+}
+
+// ValidatePattern checks a value against the parameter's pattern, if it has
+// one. The pattern must match somewhere in the value, so templates anchor it
+// with ^ and $ to match the whole value. A pattern that doesn't compile is
+// reported as an error too, rather than letting every value through.
+func ValidatePattern(p Parameter, value string) error {
+	if p.Pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.Pattern)
+	if err != nil {
+		return fmt.Errorf("template defines an invalid pattern %s: %v", p.Pattern, err)
+	}
+	if !re.MatchString(value) {
+		return fmt.Errorf("%q does not match pattern %s", value, p.Pattern)
+	}
+	return nil
+}
+
+// ValidateRange checks a value against the parameter's optional bounds: Min
+// and Max for the integer value, MinLength and MaxLength for the length in
+// characters. A value that must be within Min/Max but isn't an integer is an
+// error. Bounds that aren't set aren't checked.
+func ValidateRange(p Parameter, value string) error {
+	if p.Min != nil || p.Max != nil {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		if p.Min != nil && n < *p.Min {
+			return fmt.Errorf("%d is less than the minimum %d", n, *p.Min)
+		}
+		if p.Max != nil && n > *p.Max {
+			return fmt.Errorf("%d is greater than the maximum %d", n, *p.Max)
+		}
+	}
+
+	length := utf8.RuneCountInString(value)
+	if p.MinLength != nil && length < *p.MinLength {
+		return fmt.Errorf("%q is shorter than %d characters", value, *p.MinLength)
+	}
+	if p.MaxLength != nil && length > *p.MaxLength {
+		return fmt.Errorf("%q is longer than %d characters", value, *p.MaxLength)
+	}
+	return nil
+}
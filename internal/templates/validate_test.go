@@ -0,0 +1,201 @@
+package templates
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateParams(t *testing.T) {
+	tmpl := &ClaimTemplate{Spec: ClaimTemplateSpec{Parameters: []Parameter{
+		{Name: "name", Required: true},
+		{Name: "cpu", Required: true, Default: 2},
+		{Name: "size", Enum: []string{"small", "large"}},
+		{Name: "zones", Enum: []string{"a", "b"}, Multiselect: true},
+		{Name: "hostname", Pattern: "^[a-z0-9-]+$"},
+		{Name: "cluster", Required: true, ValueFrom: &ValueFromSpec{Function: "lookup"}},
+		{Name: "internal", Required: true, Hidden: true},
+	}}}
+
+	tests := []struct {
+		name   string
+		params map[string]any
+		want   []Problem
+	}{
+		{
+			name:   "valid",
+			params: map[string]any{"name": "web", "size": "small", "zones": []any{"a", "b"}, "hostname": "web-1"},
+		},
+		{
+			name:   "missing required",
+			params: map[string]any{"name": ""},
+			want:   []Problem{{"name", "required parameter is not set"}},
+		},
+		{
+			name:   "enum and pattern violations",
+			params: map[string]any{"name": "web", "size": "huge", "zones": []any{"a", "c"}, "hostname": "Web_1"},
+			want: []Problem{
+				{"size", `"huge" is not one of small, large`},
+				{"zones", `"c" is not one of a, b`},
+				{"hostname", `"Web_1" does not match pattern ^[a-z0-9-]+$`},
+			},
+		},
+		{
+			name:   "unknown parameters are ignored",
+			params: map[string]any{"name": "web", "extra": "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateParams(tmpl, tt.params); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingRequired(t *testing.T) {
+	tmpl := &ClaimTemplate{Spec: ClaimTemplateSpec{Parameters: []Parameter{
+		{Name: "name", Required: true, Pattern: "^[a-z]+$"},
+		{Name: "region", Required: true},
+		{Name: "cpu", Required: true, Default: 2},
+		{Name: "cluster", Required: true, ValueFrom: &ValueFromSpec{Function: "lookup"}},
+		{Name: "internal", Required: true, Hidden: true},
+		{Name: "size", Enum: []string{"small"}},
+	}}}
+
+	// Only required parameters are checked: the pattern and enum violations are ignored
+	got := MissingRequired(tmpl, map[string]any{"name": "Web_1", "region": "", "size": "huge"})
+	if want := []string{"region"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingRequired() = %v, want %v", got, want)
+	}
+	if got := MissingRequired(tmpl, map[string]any{"name": "web", "region": "eu"}); got != nil {
+		t.Errorf("MissingRequired() = %v, want none", got)
+	}
+}
+
+func TestValidatePattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		value   string
+		wantErr string
+	}{
+		{name: "no pattern", value: "anything"},
+		{name: "match", pattern: "^[a-z0-9-]+$", value: "web-1"},
+		{name: "no match", pattern: "^[a-z0-9-]+$", value: "Web_1", wantErr: `"Web_1" does not match pattern ^[a-z0-9-]+$`},
+		{name: "unanchored pattern matches a substring", pattern: "[0-9]+", value: "web-1"},
+		{name: "bad pattern", pattern: "^[a-z+$", value: "web", wantErr: "template defines an invalid pattern ^[a-z+$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePattern(Parameter{Name: "hostname", Pattern: tt.pattern}, tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidatePattern() error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidatePattern() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateParams_InvalidPattern(t *testing.T) {
+	tmpl := &ClaimTemplate{Spec: ClaimTemplateSpec{Parameters: []Parameter{
+		{Name: "hostname", Pattern: "^[a-z+$"},
+	}}}
+
+	// Set values are reported; unset optional ones have nothing to check
+	problems := ValidateParams(tmpl, map[string]any{"hostname": "web"})
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "invalid pattern") {
+		t.Errorf("ValidateParams() = %v, want an invalid pattern problem", problems)
+	}
+	if problems := ValidateParams(tmpl, map[string]any{}); problems != nil {
+		t.Errorf("ValidateParams() = %v, want none for an unset parameter", problems)
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	one, sixtyFour, three, eight := 1, 64, 3, 8
+	cpu := Parameter{Name: "cpu", Type: "integer", Min: &one, Max: &sixtyFour}
+	hostname := Parameter{Name: "hostname", MinLength: &three, MaxLength: &eight}
+
+	tests := []struct {
+		name    string
+		param   Parameter
+		value   string
+		wantErr string
+	}{
+		{name: "no constraints", param: Parameter{Name: "free"}, value: "-5"},
+		{name: "minimum", param: cpu, value: "1"},
+		{name: "maximum", param: cpu, value: "64"},
+		{name: "below minimum", param: cpu, value: "0", wantErr: "0 is less than the minimum 1"},
+		{name: "above maximum", param: cpu, value: "65", wantErr: "65 is greater than the maximum 64"},
+		{name: "only a maximum", param: Parameter{Max: &sixtyFour}, value: "-100"},
+		{name: "not a number", param: cpu, value: "four", wantErr: `"four" is not a number`},
+		{name: "shortest", param: hostname, value: "web"},
+		{name: "longest", param: hostname, value: "web-0001"},
+		{name: "too short", param: hostname, value: "db", wantErr: `"db" is shorter than 3 characters`},
+		{name: "too long", param: hostname, value: "web-00001", wantErr: `"web-00001" is longer than 8 characters`},
+		{name: "length counts characters, not bytes", param: hostname, value: "größe-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRange(tt.param, tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateRange() error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateRange() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateParams_Range(t *testing.T) {
+	var tmpl ClaimTemplate
+	data := `{"spec": {"parameters": [
+		{"name": "cpu", "type": "integer", "min": 1, "max": 64},
+		{"name": "hostname", "minLength": 3, "maxLength": 8},
+		{"name": "disk"}
+	]}}`
+	if err := json.Unmarshal([]byte(data), &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if p := tmpl.Spec.Parameters[0]; p.Min == nil || *p.Min != 1 || p.Max == nil || *p.Max != 64 {
+		t.Fatalf("min/max not decoded: %+v", p)
+	}
+	if p := tmpl.Spec.Parameters[2]; p.Min != nil || p.Max != nil || p.MinLength != nil || p.MaxLength != nil {
+		t.Fatalf("absent constraints should stay unset: %+v", p)
+	}
+
+	// YAML and JSON numbers both arrive as non-strings
+	problems := ValidateParams(&tmpl, map[string]any{"cpu": 128, "hostname": "db", "disk": "x"})
+	want := []Problem{
+		{"cpu", "128 is greater than the maximum 64"},
+		{"hostname", `"db" is shorter than 3 characters`},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("ValidateParams() = %v, want %v", problems, want)
+	}
+	if problems := ValidateParams(&tmpl, map[string]any{"cpu": float64(64), "hostname": "web"}); problems != nil {
+		t.Errorf("ValidateParams() = %v, want none at the bounds", problems)
+	}
+}
+
+func TestProblem_Error(t *testing.T) {
+	var err error = Problem{"size", `"huge" is not one of small, large`}
+	if got := err.Error(); got != `size: "huge" is not one of small, large` {
+		t.Errorf("Error() = %q", got)
+	}
+}
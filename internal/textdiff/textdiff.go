@@ -0,0 +1,143 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of a diff line
+type Op byte
+
+const (
+	Equal  Op = ' '
+	Delete Op = '-'
+	Insert Op = '+'
+)
+
+// Line is one line of a line-based diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines diffs a against b line by line using a longest common subsequence.
+// Rendered manifests are small, so the quadratic table is fine.
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []Line
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Delete, x[i]})
+			i++
+		default:
+			lines = append(lines, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, Line{Insert, y[j]})
+	}
+	return lines
+}
+
+// Unified renders the changes from a to b as a unified diff with the given
+// number of context lines, or "" if they are equal
+func Unified(a, b, fromName, toName string, context int) string {
+	lines := Lines(a, b)
+
+	// Mark the lines within context of a change
+	show := make([]bool, len(lines))
+	changed := false
+	for i, l := range lines {
+		if l.Op == Equal {
+			continue
+		}
+		changed = true
+		for k := max(i-context, 0); k <= min(i+context, len(lines)-1); k++ {
+			show[k] = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	aLine, bLine := 1, 1
+	for i := 0; i < len(lines); {
+		if !show[i] {
+			if lines[i].Op != Insert {
+				aLine++
+			}
+			if lines[i].Op != Delete {
+				bLine++
+			}
+			i++
+			continue
+		}
+
+		// Emit one hunk covering the consecutive shown lines
+		end := i
+		aCount, bCount := 0, 0
+		for ; end < len(lines) && show[end]; end++ {
+			if lines[end].Op != Insert {
+				aCount++
+			}
+			if lines[end].Op != Delete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", hunkStart(aLine, aCount), aCount, hunkStart(bLine, bCount), bCount)
+		for ; i < end; i++ {
+			sb.WriteByte(byte(lines[i].Op))
+			sb.WriteString(lines[i].Text)
+			sb.WriteByte('\n')
+		}
+		aLine += aCount
+		bLine += bCount
+	}
+	return sb.String()
+}
+
+// hunkStart returns a hunk's start line; an empty range refers to the line
+// before it, as in GNU diff
+func hunkStart(line, count int) int {
+	if count == 0 {
+		return line - 1
+	}
+	return line
+}
+
+// splitLines splits s into lines without their trailing newline
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
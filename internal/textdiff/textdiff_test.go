@@ -0,0 +1,44 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "changed line with context",
+			a:    "kind: VM\nname: web\ncpu: 2\nmemory: 4Gi\n",
+			b:    "kind: VM\nname: web\ncpu: 4\nmemory: 4Gi\n",
+			want: "--- old\n+++ new\n@@ -2,3 +2,3 @@\n name: web\n-cpu: 2\n+cpu: 4\n memory: 4Gi\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n",
+			b:    "x\n2\n3\n4\n5\n6\ny\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-1\n+x\n 2\n@@ -6,2 +6,2 @@\n 6\n-7\n+y\n",
+		},
+		{
+			name: "new file",
+			a:    "",
+			b:    "a\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,1 @@\n+a\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified(tt.a, tt.b, "old", "new", 1); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package yamlnode edits YAML documents through yaml.Node so that comments,
+// key order and unrelated content survive a load/save round trip.
+package yamlnode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultIndent matches yaml.Marshal, used when a document has no indented lines
+const defaultIndent = 4
+
+// Document is a parsed YAML document together with its indentation
+type Document struct {
+	Root   *yaml.Node // document node
+	Indent int
+}
+
+// Parse parses data into a Document, detecting the indentation it uses
+func Parse(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return &Document{Root: &root, Indent: detectIndent(data)}, nil
+}
+
+// Mapping returns the top-level mapping node, or nil if the document is
+// empty or not a mapping
+func (d *Document) Mapping() *yaml.Node {
+	if d == nil || d.Root == nil || d.Root.Kind != yaml.DocumentNode || len(d.Root.Content) == 0 {
+		return nil
+	}
+	if m := d.Root.Content[0]; m.Kind == yaml.MappingNode {
+		return m
+	}
+	return nil
+}
+
+// Encode serializes the document with its original indentation
+func (d *Document) Encode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(d.Indent)
+	if err := enc.Encode(d.Root); err != nil {
+		return nil, fmt.Errorf("encoding yaml: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding yaml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// MappingValue returns the value node for key in mapping m, or nil
+func MappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetMappingValue replaces the value of key in mapping m, or appends the key
+// if it is missing. Comments on a replaced value are carried over.
+func SetMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			CopyComments(value, m.Content[i+1])
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
+
+// SetScalar sets key in mapping m to a string value, updating the existing
+// node in place so its comments and style are kept
+func SetScalar(m *yaml.Node, key, value string) {
+	if v := MappingValue(m, key); v != nil && v.Kind == yaml.ScalarNode {
+		v.Value = value
+		return
+	}
+	SetMappingValue(m, key, StringNode(value))
+}
+
+// StringNode returns a plain string scalar node
+func StringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// CopyComments copies the comments of from onto to where to has none
+func CopyComments(to, from *yaml.Node) {
+	if to.HeadComment == "" {
+		to.HeadComment = from.HeadComment
+	}
+	if to.LineComment == "" {
+		to.LineComment = from.LineComment
+	}
+	if to.FootComment == "" {
+		to.FootComment = from.FootComment
+	}
+}
+
+// detectIndent returns the smallest indentation used in data, or
+// defaultIndent if no line is indented
+func detectIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	if indent < 2 {
+		return defaultIndent
+	}
+	return indent
+}
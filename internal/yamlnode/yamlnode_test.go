@@ -0,0 +1,54 @@
+package yamlnode
+
+import "testing"
+
+func TestDetectIndent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{"two spaces", "a:\n  b: 1\n  c:\n    - d\n", 2},
+		{"four spaces", "a:\n    - b: 1\n      c: 2\n", 4},
+		{"comments ignored", "a:\n # note\n    b: 1\n", 4},
+		{"no indentation", "a: 1\nb: []\n", defaultIndent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectIndent([]byte(tt.data)); got != tt.want {
+				t.Errorf("detectIndent() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetScalarKeepsComments(t *testing.T) {
+	doc, err := Parse([]byte("# head\nkind: Old # why\nother: 1\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	m := doc.Mapping()
+	SetScalar(m, "kind", "New")
+	SetScalar(m, "added", "x")
+
+	data, err := doc.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	want := "# head\nkind: New # why\nother: 1\nadded: x\n"
+	if string(data) != want {
+		t.Errorf("Encode() = %q, want %q", data, want)
+	}
+}
+
+func TestMappingEmptyDocument(t *testing.T) {
+	doc, err := Parse([]byte(""))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if doc.Mapping() != nil {
+		t.Error("expected nil mapping for empty document")
+	}
+}